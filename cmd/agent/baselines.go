@@ -0,0 +1,58 @@
+package main
+
+import (
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/baseline"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// baselineCollector exports tracker state at scrape time, one series per
+// (signal, namespace, service) the agent has observed.
+type baselineCollector struct {
+	tracker *baseline.Tracker
+
+	median       *prometheus.Desc
+	mad          *prometheus.Desc
+	mean         *prometheus.Desc
+	stddev       *prometheus.Desc
+	observations *prometheus.Desc
+	ready        *prometheus.Desc
+}
+
+// RegisterBaselines exposes adaptive baseline state on /metrics.
+func (m *agentMetrics) RegisterBaselines(tracker *baseline.Tracker) {
+	labels := []string{"signal", "namespace", "service"}
+	m.registry.MustRegister(&baselineCollector{
+		tracker:      tracker,
+		median:       prometheus.NewDesc("llm_slo_agent_baseline_median", "Rolling median of the signal baseline.", labels, nil),
+		mad:          prometheus.NewDesc("llm_slo_agent_baseline_mad", "Median absolute deviation of the signal baseline.", labels, nil),
+		mean:         prometheus.NewDesc("llm_slo_agent_baseline_ewma", "Time-decayed mean of the signal baseline.", labels, nil),
+		stddev:       prometheus.NewDesc("llm_slo_agent_baseline_ewm_stddev", "Time-decayed standard deviation of the signal baseline.", labels, nil),
+		observations: prometheus.NewDesc("llm_slo_agent_baseline_observations_total", "Values folded into the signal baseline.", labels, nil),
+		ready:        prometheus.NewDesc("llm_slo_agent_baseline_ready", "1 once the baseline has warmed up and produces z-scores.", labels, nil),
+	})
+}
+
+func (c *baselineCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.median
+	ch <- c.mad
+	ch <- c.mean
+	ch <- c.stddev
+	ch <- c.observations
+	ch <- c.ready
+}
+
+func (c *baselineCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, st := range c.tracker.Snapshot() {
+		labels := []string{st.Signal, st.Namespace, st.Service}
+		ready := 0.0
+		if st.Ready {
+			ready = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.median, prometheus.GaugeValue, st.Median, labels...)
+		ch <- prometheus.MustNewConstMetric(c.mad, prometheus.GaugeValue, st.MAD, labels...)
+		ch <- prometheus.MustNewConstMetric(c.mean, prometheus.GaugeValue, st.Mean, labels...)
+		ch <- prometheus.MustNewConstMetric(c.stddev, prometheus.GaugeValue, st.StdDev, labels...)
+		ch <- prometheus.MustNewConstMetric(c.observations, prometheus.CounterValue, float64(st.Observations), labels...)
+		ch <- prometheus.MustNewConstMetric(c.ready, prometheus.GaugeValue, ready, labels...)
+	}
+}
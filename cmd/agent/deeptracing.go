@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/collector"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signals"
+)
+
+// cgroupRefreshInterval is how often pod cgroups are rewalked for deep
+// tracing.
+const cgroupRefreshInterval = 30 * time.Second
+
+// deepTracingLookback bounds the deep-tracing annotations attached to an
+// incident.
+const deepTracingLookback = 15 * time.Minute
+
+// generatorScoper attaches deep-tracing signals in the synthetic
+// generator. It only emits for the agent's own process, so there is
+// nothing to scope: a non-empty scope enables the signal and an empty one
+// disables it.
+type generatorScoper struct {
+	generator *signals.Generator
+}
+
+func (s generatorScoper) SetScope(signal string, cgroups []uint64) error {
+	if len(cgroups) == 0 {
+		s.generator.Disable(signal)
+		return nil
+	}
+	if !s.generator.Enable(signal) && !containsSignal(s.generator.EnabledSignals(), signal) {
+		return fmt.Errorf("signal %s: not supported in capability mode %s", signal, s.generator.Mode())
+	}
+	return nil
+}
+
+// podCgroups resolves the agent's own pod, which synthetic SLO events are
+// labelled with by name, to the agent's cgroup, and pod UIDs through the
+// cgroup hierarchy.
+type podCgroups struct {
+	self    string
+	selfIDs []uint64
+	pods    *collector.CgroupPodResolver
+}
+
+func (p podCgroups) CgroupIDs(pod string) []uint64 {
+	if pod == p.self {
+		return p.selfIDs
+	}
+	return p.pods.CgroupIDs(pod)
+}
+
+// selfCgroupID returns the cgroup v2 ID, the directory inode, of the
+// agent's own cgroup.
+func selfCgroupID(procRoot, cgroupRoot string) (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(procRoot, "self", "cgroup"))
+	if err != nil {
+		return 0, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		path, ok := strings.CutPrefix(scanner.Text(), "0::")
+		if !ok {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(cgroupRoot, path))
+		if err != nil {
+			return 0, err
+		}
+		st, ok := info.Sys().(*syscall.Stat_t)
+		if !ok {
+			return 0, fmt.Errorf("stat %s: no inode", path)
+		}
+		return st.Ino, nil
+	}
+	return 0, fmt.Errorf("no cgroup v2 entry in %s/self/cgroup", procRoot)
+}
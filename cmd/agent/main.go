@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -13,16 +12,19 @@ import (
 	"runtime"
 	"sort"
 	"strings"
-	"sync"
 	"syscall"
 	"time"
 
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/attribution"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/baseline"
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/collector"
-	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/otel"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/deeptrace"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/dependency"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/output"
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/safety"
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signals"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/toolkitcfg"
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/webhook"
 	"github.com/prometheus/client_golang/prometheus"
@@ -31,11 +33,6 @@ import (
 
 var version = "dev"
 
-const (
-	schemaPathSLO   = "docs/contracts/v1/slo-event.schema.json"
-	schemaPathProbe = "docs/contracts/v1alpha1/probe-event.schema.json"
-)
-
 type eventKindMode int
 
 const (
@@ -65,75 +62,6 @@ func (k eventKindMode) includesProbe() bool {
 	return k == eventKindProbe || k == eventKindBoth
 }
 
-type outputWriters struct {
-	mode          string
-	sloExporter   *otel.SLOEventExporter
-	probeExporter *otel.ProbeEventExporter
-	encoder       *json.Encoder
-	file          *os.File
-	mu            sync.Mutex
-}
-
-func newOutputWriters(mode string, path string, endpoint string, timeout time.Duration) (*outputWriters, error) {
-	w := &outputWriters{mode: mode}
-	switch mode {
-	case "stdout":
-		w.encoder = json.NewEncoder(os.Stdout)
-		return w, nil
-	case "jsonl":
-		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-			return nil, err
-		}
-		f, err := os.Create(path)
-		if err != nil {
-			return nil, err
-		}
-		w.file = f
-		w.encoder = json.NewEncoder(f)
-		return w, nil
-	case "otlp":
-		w.sloExporter = otel.NewSLOEventExporter(endpoint, "llm-slo-ebpf-toolkit", "llm-slo-ebpf-toolkit/agent", timeout)
-		w.probeExporter = otel.NewProbeEventExporter(endpoint, "llm-slo-ebpf-toolkit", "llm-slo-ebpf-toolkit/agent", timeout)
-		return w, nil
-	default:
-		return nil, fmt.Errorf("unsupported output mode %q", mode)
-	}
-}
-
-func (w *outputWriters) EmitSLO(ev schema.SLOEvent) error {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-
-	if w.mode == "otlp" {
-		return w.sloExporter.ExportBatch([]schema.SLOEvent{ev})
-	}
-	return w.encoder.Encode(map[string]any{
-		"kind":    "slo",
-		"payload": ev,
-	})
-}
-
-func (w *outputWriters) EmitProbe(ev schema.ProbeEventV1) error {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-
-	if w.mode == "otlp" {
-		return w.probeExporter.ExportBatch([]schema.ProbeEventV1{ev})
-	}
-	return w.encoder.Encode(map[string]any{
-		"kind":    "probe",
-		"payload": ev,
-	})
-}
-
-func (w *outputWriters) Close() {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	if w.file != nil {
-		_ = w.file.Close()
-	}
-}
-
 type agentMetrics struct {
 	registry *prometheus.Registry
 
@@ -149,6 +77,12 @@ type agentMetrics struct {
 	helloSyscalls *prometheus.CounterVec
 	dnsLatency    *prometheus.HistogramVec
 	probeEvents   *prometheus.CounterVec
+	hostEvents    *prometheus.CounterVec
+
+	tlsUprobeStatus *prometheus.GaugeVec
+
+	deepTracingActive      prometheus.Gauge
+	deepTracingAnnotations *prometheus.CounterVec
 }
 
 func newAgentMetrics(eventKind string, capabilityMode string, supportedSignals []string, enabledSignals []string) *agentMetrics {
@@ -181,7 +115,7 @@ func newAgentMetrics(eventKind string, capabilityMode string, supportedSignals [
 		}, []string{"signal"}),
 		droppedEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Name: "llm_slo_agent_dropped_events_total",
-			Help: "Dropped probe events by reason.",
+			Help: "Dropped events by reason.",
 		}, []string{"reason"}),
 		helloSyscalls: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Name: "llm_ebpf_hello_syscalls_total",
@@ -196,6 +130,22 @@ func newAgentMetrics(eventKind string, capabilityMode string, supportedSignals [
 			Name: "llm_ebpf_probe_events_total",
 			Help: "Probe events observed by signal and status.",
 		}, []string{"signal", "status"}),
+		hostEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "llm_ebpf_dst_host_events_total",
+			Help: "Connection probe events by signal, status and destination host from passive DNS.",
+		}, []string{"signal", "status", "dst_host"}),
+		tlsUprobeStatus: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "llm_slo_agent_tls_uprobe_status",
+			Help: "TLS handshake uprobe attach state by binary (one-hot gauge).",
+		}, []string{"binary", "library", "state"}),
+		deepTracingActive: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "llm_slo_agent_deep_tracing_active",
+			Help: "Workloads with deep-tracing probes attached.",
+		}),
+		deepTracingAnnotations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "llm_slo_agent_deep_tracing_annotations_total",
+			Help: "Deep-tracing starts, stops and skips by kind.",
+		}, []string{"kind"}),
 	}
 
 	registry.MustRegister(
@@ -209,6 +159,10 @@ func newAgentMetrics(eventKind string, capabilityMode string, supportedSignals [
 		m.helloSyscalls,
 		m.dnsLatency,
 		m.probeEvents,
+		m.hostEvents,
+		m.tlsUprobeStatus,
+		m.deepTracingActive,
+		m.deepTracingAnnotations,
 	)
 
 	m.up.Set(1)
@@ -275,6 +229,9 @@ func (m *agentMetrics) SetEnabledSignals(supported []string, enabled []string) {
 
 func (m *agentMetrics) ObserveProbeEvent(ev schema.ProbeEventV1, enableRealProbeMetrics bool) {
 	m.probeEvents.WithLabelValues(ev.Signal, ev.Status).Inc()
+	if ev.ConnTuple != nil && ev.ConnTuple.DstHost != "" {
+		m.hostEvents.WithLabelValues(ev.Signal, ev.Status, ev.ConnTuple.DstHost).Inc()
+	}
 	if !enableRealProbeMetrics {
 		return
 	}
@@ -294,6 +251,23 @@ func (m *agentMetrics) IncHello(node string, pod string, comm string, count uint
 	m.helloSyscalls.WithLabelValues(nonEmpty(node, "unknown-node"), nonEmpty(pod, "unknown-pod"), nonEmpty(comm, "unknown")).Add(float64(count))
 }
 
+// SetTLSUprobeStatus replaces the per-binary TLS uprobe states with the
+// latest scan.
+func (m *agentMetrics) SetTLSUprobeStatus(status []collector.TLSAttachStatus) {
+	m.tlsUprobeStatus.Reset()
+	for _, s := range status {
+		m.tlsUprobeStatus.WithLabelValues(s.Binary, nonEmpty(s.Library, "none"), s.State).Set(1)
+	}
+}
+
+// ObserveDeepTracing records one controller tick.
+func (m *agentMetrics) ObserveDeepTracing(active int, notes []schema.Annotation) {
+	m.deepTracingActive.Set(float64(active))
+	for _, note := range notes {
+		m.deepTracingAnnotations.WithLabelValues(note.Kind).Inc()
+	}
+}
+
 func nonEmpty(v string, fallback string) string {
 	if strings.TrimSpace(v) == "" {
 		return fallback
@@ -344,7 +318,8 @@ func main() {
 		count      = flag.Int("count", 0, "sample count (0 = stream mode)")
 		intervalMS = flag.Int("interval-ms", 1000, "emit interval for stream mode")
 
-		eventKind = flag.String("event-kind", "probe", "event kind: slo|probe|both")
+		eventKind          = flag.String("event-kind", "probe", "event kind: slo|probe|both")
+		probeSchemaVersion = flag.String("probe-schema-version", schema.ProbeSchemaV1Alpha1, "probe event contract: v1alpha1|v1beta1")
 
 		outputMode   = flag.String("output", "stdout", "output mode: stdout|jsonl|otlp")
 		outputPath   = flag.String("output-path", "artifacts/agent/events.jsonl", "output file when output=jsonl")
@@ -355,6 +330,12 @@ func main() {
 		)
 		otlpTimeoutMS = flag.Int("otlp-timeout-ms", 5000, "OTLP export timeout in milliseconds")
 
+		outputMaxBytes    = flag.Int64("output-max-bytes", toolkitcfg.DefaultOutputMaxBytes, "rotate jsonl output after this many bytes (0 = no size rotation)")
+		outputRotateSec   = flag.Int("output-rotate-interval-s", 0, "rotate jsonl output after this many seconds (0 = no time rotation)")
+		outputMaxFiles    = flag.Int("output-max-files", toolkitcfg.DefaultOutputMaxFiles, "rotated jsonl segments to retain (0 = unlimited)")
+		outputCompression = flag.String("output-compression", "", "compress rotated jsonl segments: gzip|zstd (empty = none)")
+		outputAppend      = flag.Bool("output-append", true, "append to an existing jsonl output instead of truncating it")
+
 		webhookURL       = flag.String("webhook-url", "", "webhook endpoint URL (empty = disabled)")
 		webhookSecret    = flag.String("webhook-secret", "", "HMAC-SHA256 secret for webhook signing")
 		webhookFormat    = flag.String("webhook-format", "generic", "webhook payload format: generic|pagerduty|opsgenie")
@@ -366,6 +347,11 @@ func main() {
 		configPath          = flag.String("config", filepath.Join("config", "toolkit.yaml"), "toolkit config path")
 		enableHelloTracer   = flag.Bool("enable-hello-tracer", false, "enable hello tracer metric path")
 		helloTargetComm     = flag.String("hello-target-comm", "rag-service,llama-server", "comma-separated comm names for hello tracer")
+		memEventsInterval   = flag.Int("memory-events-interval-ms", 5000, "cgroup memory.events poll interval in milliseconds (0 disables)")
+		cgroupRoot          = flag.String("cgroup-root", "/sys/fs/cgroup", "cgroup v2 mount polled for memory.events")
+		tlsScanInterval     = flag.Int("tls-uprobe-scan-interval-ms", 30000, "interval for scanning /proc for Go and BoringSSL TLS binaries in milliseconds (0 disables)")
+		procRoot            = flag.String("proc-root", "/proc", "procfs mount scanned for TLS binaries and read for conntrack table size")
+		conntrackInterval   = flag.Int("conntrack-interval-ms", 5000, "nf_conntrack table utilization poll interval in milliseconds (0 disables)")
 		enableRealProbeMets = flag.Bool("enable-real-probe-metrics", true, "enable probe-derived metrics on /metrics")
 
 		metricsBind = flag.String("metrics-bind", ":2112", "metrics and health bind address")
@@ -401,6 +387,10 @@ func main() {
 	mode := signals.ParseCapabilityMode(*capabilityMode)
 	supportedSignals := signals.SupportedSignalsForMode(mode)
 	enabledSignalSet := chooseEnabledSignals(cfg.SignalSet, parseCSV(*disableSignals), supportedSignals)
+	if cfg.DeepTracing.Enabled {
+		// Deep-tracing signals run only while an SLO burns.
+		enabledSignalSet = withoutSignals(enabledSignalSet, cfg.DeepTracing.Signals)
+	}
 
 	enricher := signals.ProcMetadataEnricher{
 		Next: signals.StaticMetadataEnricher{Defaults: signals.Metadata{
@@ -414,14 +404,63 @@ func main() {
 			TID:       os.Getpid(),
 		}},
 	}
+	thresholds := cfg.Thresholds.Table()
+	baselines := baseline.NewTracker(cfg.Attribution.Baseline.Config())
+	dependencies, err := cfg.DependencyCatalog()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid dependencies: %v\n", err)
+		os.Exit(2)
+	}
 	generator := signals.NewGenerator(mode, enabledSignalSet, enricher)
+	generator.SetThresholds(thresholds)
+	sloValidator := schema.SLOEventValidator()
+	probes, err := newProbeContract(*probeSchemaVersion, signals.NodeIdentity(signals.Metadata{
+		Service:  *service,
+		Workload: *workload,
+	}))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
-	writers, err := newOutputWriters(*outputMode, *outputPath, *otlpEndpoint, time.Duration(*otlpTimeoutMS)*time.Millisecond)
+	// The --output flags select a single sink and override configured outputs
+	// when given explicitly; otherwise config outputs take precedence.
+	outputs := cfg.Outputs
+	if len(outputs) == 0 || flagPassed("output") {
+		outputs = []toolkitcfg.OutputConfig{{
+			Name:      *outputMode,
+			Type:      *outputMode,
+			Path:      *outputPath,
+			Endpoint:  *otlpEndpoint,
+			TimeoutMS: *otlpTimeoutMS,
+
+			MaxBytes:              outputMaxBytes,
+			RotateIntervalSeconds: *outputRotateSec,
+			MaxFiles:              outputMaxFiles,
+			Compression:           *outputCompression,
+			Truncate:              !*outputAppend,
+		}}
+	} else {
+		for _, name := range singleSinkFlags {
+			if flagPassed(name) {
+				log.Printf("--%s has no effect with configured outputs; pass --output to use a single sink", name)
+			}
+		}
+	}
+	writers, err := output.Build(outputs, output.BuildOptions{
+		ScopeName: "llm-slo-ebpf-toolkit/agent",
+		Envelope:  true,
+		Spool:     cfg.Spool,
+	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "open output failed: %v\n", err)
 		os.Exit(1)
 	}
-	defer writers.Close()
+	defer func() {
+		if err := writers.Close(); err != nil {
+			log.Printf("close outputs: %v", err)
+		}
+	}()
 
 	// Resolve webhook config: CLI flags override config file values.
 	whURL := *webhookURL
@@ -439,18 +478,32 @@ func main() {
 		}
 	}
 
-	var webhookExporter *webhook.Exporter
-	if whURL != "" {
-		webhookExporter = webhook.New(whURL, whSecret, webhook.Format(whFormat), whTimeout)
-		log.Printf("webhook exporter enabled: %s (format=%s)", whURL, whFormat)
-	}
-
 	var bayesAttributor *attribution.BayesianAttributor
-	if webhookExporter != nil {
+	if whURL != "" {
+		sp, spoolErr := output.OpenSpool(cfg.Spool, toolkitcfg.WebhookOutputName)
+		if spoolErr != nil {
+			log.Printf("output webhook: spool disabled: %v", spoolErr)
+		}
+		writers.Add(output.NewWebhookSink(webhook.New(whURL, whSecret, webhook.Format(whFormat), whTimeout)), output.SinkOptions{
+			Name:           toolkitcfg.WebhookOutputName,
+			Kinds:          []output.Kind{output.KindIncident},
+			BatchSize:      1,
+			Spool:          sp,
+			ReplayInterval: time.Duration(cfg.Spool.ReplayIntervalMS) * time.Millisecond,
+		})
 		bayesAttributor = attribution.NewBayesianAttributor()
+		bayesAttributor.Thresholds = thresholds
+		bayesAttributor.Elevation = cfg.Attribution.Elevation
+		bayesAttributor.ZThreshold = cfg.Attribution.ZScoreThreshold
+		bayesAttributor.Baselines = baselines
+		bayesAttributor.Dependencies = dependencies
+		log.Printf("webhook exporter enabled: %s (format=%s)", whURL, whFormat)
 	}
 
 	metrics := newAgentMetrics(*eventKind, string(mode), supportedSignals, generator.EnabledSignals())
+
+	metrics.RegisterOutputs(writers)
+	metrics.RegisterBaselines(baselines)
 	startMetricsServer(*metricsBind, metrics)
 
 	if *intervalMS <= 0 {
@@ -467,6 +520,33 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	var deepTracer *deeptrace.Controller
+	if cfg.DeepTracing.Enabled {
+		pods := collector.NewCgroupPodResolver(*cgroupRoot)
+		cgroups := podCgroups{self: *pod, pods: pods}
+		if id, err := selfCgroupID(*procRoot, *cgroupRoot); err != nil {
+			log.Printf("deep tracing: own cgroup unknown: %v", err)
+		} else {
+			cgroups.selfIDs = []uint64{id}
+		}
+		go func() {
+			ticker := time.NewTicker(cgroupRefreshInterval)
+			defer ticker.Stop()
+			for {
+				if err := pods.Refresh(); err != nil {
+					log.Printf("deep tracing: cgroup refresh warning: %v", err)
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+		deepTracer = deeptrace.NewController(cfg.DeepTracing.Config(), generatorScoper{generator: generator}, cgroups)
+		log.Printf("deep tracing enabled: %v while an SLO burns", deepTracer.Signals())
+	}
+
 	if *enableHelloTracer {
 		targetComms := parseCSV(*helloTargetComm)
 		helloTracer := collector.NewHelloTracer(targetComms, 2*time.Second)
@@ -492,24 +572,117 @@ func main() {
 				metrics.IncDropped("rate_limit")
 				return
 			}
-			if err := schema.ValidateAgainstSchema(schemaPathProbe, probeEvent); err != nil {
+			batch, err := probes.batch(probeEvent)
+			if err != nil {
 				metrics.IncDropped("schema")
 				log.Printf("hello tracer probe event dropped: %v", err)
 				return
 			}
-			if err := writers.EmitProbe(probeEvent); err != nil {
-				metrics.IncDropped("emit")
+			if err := metrics.emit(writers, batch); err != nil {
 				log.Printf("hello tracer probe emit failed: %v", err)
 			}
 		})
 	}
 
+	if *memEventsInterval > 0 && kindMode.includesProbe() {
+		poller := collector.NewMemoryEventsPoller(*cgroupRoot, time.Duration(*memEventsInterval)*time.Millisecond)
+		if err := poller.Available(); err != nil {
+			log.Printf("memory.events poller disabled: %v", err)
+		} else {
+			go poller.Start(ctx, func(samples []collector.MemoryEventsSample) {
+				for _, sample := range samples {
+					for _, event := range generator.MemoryEvents(sample, signals.Metadata{}) {
+						metrics.ObserveProbeEvent(event, *enableRealProbeMets)
+						if !runtimeLimiter.Allow(sample.Timestamp) {
+							metrics.IncDropped("rate_limit")
+							continue
+						}
+						batch, err := probes.batch(event)
+						if err != nil {
+							metrics.IncDropped("schema")
+							log.Printf("memory.events probe event dropped: %v", err)
+							continue
+						}
+						if err := metrics.emit(writers, batch); err != nil {
+							log.Printf("memory.events probe emit failed: %v", err)
+						}
+					}
+				}
+			}, func(err error) {
+				log.Printf("memory.events poll warning: %v", err)
+			})
+		}
+	}
+
+	if *conntrackInterval > 0 && kindMode.includesProbe() && containsSignal(generator.EnabledSignals(), signals.SignalConntrackUtilPct) {
+		poller := collector.NewConntrackPoller(*procRoot, time.Duration(*conntrackInterval)*time.Millisecond)
+		if err := poller.Available(); err != nil {
+			log.Printf("conntrack poller disabled: %v", err)
+		} else {
+			go poller.Start(ctx, func(sample collector.ConntrackSample) {
+				for _, event := range generator.Conntrack(sample, signals.Metadata{Node: *node}) {
+					metrics.ObserveProbeEvent(event, *enableRealProbeMets)
+					if !runtimeLimiter.Allow(sample.Timestamp) {
+						metrics.IncDropped("rate_limit")
+						continue
+					}
+					batch, err := probes.batch(event)
+					if err != nil {
+						metrics.IncDropped("schema")
+						log.Printf("conntrack probe event dropped: %v", err)
+						continue
+					}
+					if err := metrics.emit(writers, batch); err != nil {
+						log.Printf("conntrack probe emit failed: %v", err)
+					}
+				}
+			}, func(err error) {
+				log.Printf("conntrack poll warning: %v", err)
+			})
+		}
+	}
+
+	tlsProbesWanted := containsSignal(generator.EnabledSignals(), signals.SignalTLSHandshakeMS) || cfg.ProviderHTTP.Enabled
+	if *tlsScanInterval > 0 && runtime.GOOS == "linux" && tlsProbesWanted {
+		// The synthetic agent loads no eBPF objects, so binaries report
+		// their resolved attach points rather than attached uprobes.
+		tlsAttacher := collector.NewTLSUprobeAttacher(*procRoot, time.Duration(*tlsScanInterval)*time.Millisecond, collector.TLSUprobePrograms{})
+		defer tlsAttacher.Close()
+		lastState := map[string]string{}
+		go tlsAttacher.Start(ctx, func(status []collector.TLSAttachStatus) {
+			metrics.SetTLSUprobeStatus(status)
+			seen := make(map[string]string, len(status))
+			for _, s := range status {
+				seen[s.Binary] = s.State
+				if lastState[s.Binary] == s.State || s.State == collector.TLSAttachNoSymbol {
+					continue
+				}
+				if s.Reason != "" {
+					log.Printf("tls uprobe %s: %s (%s)", s.Binary, s.State, s.Reason)
+				} else {
+					log.Printf("tls uprobe %s: %s %s, %d uprobes", s.Binary, s.State, s.Library, s.Uprobes)
+				}
+			}
+			lastState = seen
+		}, func(err error) {
+			log.Printf("tls uprobe scan warning: %v", err)
+		})
+	}
+
+	if cfg.RequestTiming.Enabled {
+		// Kernel request timing comes from request_timing.bpf.c through
+		// RingBufConsumer.EnableRequestTiming; the synthetic agent loads
+		// no eBPF objects, so it only reports the configured ports.
+		log.Printf("request timing: server ports %v, idle timeout %s (needs the eBPF loader)", cfg.RequestTiming.ServerPorts, cfg.RequestTiming.Idle())
+	}
+
 	meta := collector.SampleMeta{
 		Cluster:   *cluster,
 		Namespace: *namespace,
 		Workload:  *workload,
 		Service:   *service,
 		Node:      *node,
+		Pod:       *pod,
 	}
 
 	emitOne := func(idx int, now time.Time) error {
@@ -518,16 +691,21 @@ func main() {
 			return err
 		}
 
-		if kindMode.includesSLO() {
+		if kindMode.includesSLO() || deepTracer != nil {
 			sloEvents := collector.NormalizeSample(sample)
 			for _, event := range sloEvents {
-				if err := schema.ValidateAgainstSchema(schemaPathSLO, event); err != nil {
+				if deepTracer != nil {
+					deepTracer.Observe(event)
+				}
+				if !kindMode.includesSLO() {
+					continue
+				}
+				if err := sloValidator.Validate(event); err != nil {
 					metrics.IncDropped("schema")
 					return err
 				}
-				if err := writers.EmitSLO(event); err != nil {
-					metrics.IncDropped("emit")
-					return err
+				if err := metrics.emit(writers, output.Batch{Kind: output.KindSLO, SLO: []schema.SLOEvent{event}}); err != nil {
+					log.Printf("slo emit failed: %v", err)
 				}
 			}
 		}
@@ -544,8 +722,13 @@ func main() {
 			TraceID:   sample.TraceID,
 		}
 		probeEvents := generator.Generate(sample, probeMeta)
+		sampleSignals := make(map[string]float64, len(probeEvents))
+		for i := range probeEvents {
+			dependencies.Label(&probeEvents[i])
+		}
 		for _, event := range probeEvents {
 			metrics.ObserveProbeEvent(event, *enableRealProbeMets)
+			sampleSignals[event.Signal] = event.Value
 			if !kindMode.includesProbe() {
 				continue
 			}
@@ -553,18 +736,18 @@ func main() {
 				metrics.IncDropped("rate_limit")
 				continue
 			}
-			if err := schema.ValidateAgainstSchema(schemaPathProbe, event); err != nil {
+			batch, err := probes.batch(event)
+			if err != nil {
 				metrics.IncDropped("schema")
 				log.Printf("probe schema validation failed: %v", err)
 				continue
 			}
-			if err := writers.EmitProbe(event); err != nil {
-				metrics.IncDropped("emit")
+			if err := metrics.emit(writers, batch); err != nil {
 				log.Printf("probe emit failed: %v", err)
 			}
 		}
 
-		if webhookExporter != nil {
+		if bayesAttributor != nil {
 			faultSample := attribution.FaultSample{
 				IncidentID:    fmt.Sprintf("agent-%s-%d", sample.TraceID, idx),
 				Timestamp:     now,
@@ -577,25 +760,55 @@ func main() {
 				WindowMinutes: 5,
 				RequestID:     sample.RequestID,
 				TraceID:       sample.TraceID,
+				Signals:       sampleSignals,
+				Dependencies:  dependency.SignalDependencies(probeEvents),
+				DstHosts:      dependency.SignalDstHosts(probeEvents),
 			}
 			attr := bayesAttributor.AttributeSample(faultSample)
-			if err := webhookExporter.Send(attr); err != nil {
-				log.Printf("webhook send failed: %v", err)
+			if deepTracer != nil {
+				attr.Annotations = deepTracer.Timeline(signalspec.Workload{Namespace: *namespace, Service: *service}, now.Add(-deepTracingLookback))
 			}
+			if err := metrics.emit(writers, output.Batch{Kind: output.KindIncident, Incidents: []schema.IncidentAttribution{attr}}); err != nil {
+				log.Printf("incident emit failed: %v", err)
+			}
+		}
+		// Baselines learn after attribution so a tick is never scored
+		// against itself.
+		workloadKey := signalspec.Workload{Namespace: *namespace, Service: *service}
+		for signal, value := range sampleSignals {
+			baselines.Observe(baseline.Key{Signal: signal, Workload: workloadKey}, value, now)
 		}
 
+		exceeded := false
 		if guard != nil {
-			pct, exceeded, guardErr := guard.Evaluate()
+			pct, over, guardErr := guard.Evaluate()
 			if guardErr != nil {
 				log.Printf("overhead guard warning: %v", guardErr)
 			} else {
 				metrics.SetCPUOverhead(pct)
 			}
-			if exceeded {
-				if disabledSignal, ok := generator.DisableHighestCost(); ok {
-					log.Printf("overhead budget exceeded: disabled signal %s", disabledSignal)
-					metrics.SetEnabledSignals(supportedSignals, generator.EnabledSignals())
-				}
+			exceeded = over
+		}
+		// Over budget, deep tracing is shed before always-on signals.
+		shed := false
+		if deepTracer != nil {
+			shed = exceeded && deepTracer.Active() > 0
+			notes, err := deepTracer.Tick(now, exceeded)
+			if err != nil {
+				log.Printf("deep tracing warning: %v", err)
+			}
+			for _, note := range notes {
+				log.Printf("deep tracing %s/%s: %s (%s)", note.Namespace, note.Service, note.Kind, note.Reason)
+			}
+			metrics.ObserveDeepTracing(deepTracer.Active(), notes)
+			if len(notes) > 0 {
+				metrics.SetEnabledSignals(supportedSignals, generator.EnabledSignals())
+			}
+		}
+		if exceeded && !shed {
+			if disabledSignal, ok := generator.DisableHighestCost(); ok {
+				log.Printf("overhead budget exceeded: disabled signal %s", disabledSignal)
+				metrics.SetEnabledSignals(supportedSignals, generator.EnabledSignals())
 			}
 		}
 
@@ -633,6 +846,30 @@ func main() {
 	}
 }
 
+// flagPassed reports whether a flag was set explicitly on the command line.
+// singleSinkFlags configure the sink selected by --output and are unused
+// when config outputs take precedence.
+var singleSinkFlags = []string{
+	"output-path",
+	"output-max-bytes",
+	"output-rotate-interval-s",
+	"output-max-files",
+	"output-compression",
+	"output-append",
+	"otlp-endpoint",
+	"otlp-timeout-ms",
+}
+
+func flagPassed(name string) bool {
+	passed := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			passed = true
+		}
+	})
+	return passed
+}
+
 func parseCSV(raw string) []string {
 	parts := strings.Split(raw, ",")
 	out := make([]string, 0, len(parts))
@@ -689,3 +926,23 @@ func chooseEnabledSignals(configSignals []string, disabled []string, supported [
 
 	return selected
 }
+
+// withoutSignals returns signals minus those in drop.
+func withoutSignals(signals []string, drop []string) []string {
+	out := make([]string, 0, len(signals))
+	for _, signal := range signals {
+		if !containsSignal(drop, signal) {
+			out = append(out, signal)
+		}
+	}
+	return out
+}
+
+func containsSignal(signals []string, signal string) bool {
+	for _, s := range signals {
+		if s == signal {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/output"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/spool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// emit hands one batch to the fan-out and counts the events it refused on
+// llm_slo_agent_dropped_events_total: queue_full when a sink's queue was
+// full, emit otherwise. Failures after a sink accepted the batch are
+// counted per sink by RegisterOutputs.
+func (m *agentMetrics) emit(writers *output.FanOut, b output.Batch) error {
+	err := writers.Emit(b)
+	switch {
+	case err == nil:
+	case errors.Is(err, output.ErrQueueFull):
+		m.droppedEvents.WithLabelValues("queue_full").Add(float64(b.Len()))
+	default:
+		m.droppedEvents.WithLabelValues("emit").Add(float64(b.Len()))
+	}
+	return err
+}
+
+// RegisterOutputs exposes per-sink delivery and spool counters on /metrics.
+func (m *agentMetrics) RegisterOutputs(out *output.FanOut) {
+	for idx, st := range out.Stats() {
+		idx := idx
+		stat := func() output.SinkStats { return out.Stats()[idx] }
+		labels := prometheus.Labels{"sink": st.Name}
+
+		for outcome, read := range map[string]func(output.SinkStats) uint64{
+			"sent":    func(s output.SinkStats) uint64 { return s.Sent },
+			"failed":  func(s output.SinkStats) uint64 { return s.Failed },
+			"dropped": func(s output.SinkStats) uint64 { return s.Dropped },
+			"spooled": func(s output.SinkStats) uint64 { return s.Spooled },
+		} {
+			read := read
+			m.registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Name:        "llm_slo_agent_output_events_total",
+				Help:        "Output events by sink and delivery outcome.",
+				ConstLabels: prometheus.Labels{"sink": st.Name, "outcome": outcome},
+			}, func() float64 { return float64(read(stat())) }))
+		}
+
+		if st.Spool == nil {
+			continue
+		}
+		spoolStat := func() spool.Stats { return *stat().Spool }
+		m.registry.MustRegister(
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name:        "llm_slo_agent_spool_bytes",
+				Help:        "On-disk bytes held by the output spool.",
+				ConstLabels: labels,
+			}, func() float64 { return float64(spoolStat().Bytes) }),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name:        "llm_slo_agent_spool_segments",
+				Help:        "Segment files held by the output spool.",
+				ConstLabels: labels,
+			}, func() float64 { return float64(spoolStat().Segments) }),
+			prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Name:        "llm_slo_agent_spool_evicted_segments_total",
+				Help:        "Spool segments evicted by max-bytes or max-age bounds.",
+				ConstLabels: labels,
+			}, func() float64 { return float64(spoolStat().EvictedSegments) }),
+		)
+		for outcome, read := range map[string]func(spool.Stats) uint64{
+			"spooled":  func(s spool.Stats) uint64 { return s.Appended },
+			"replayed": func(s spool.Stats) uint64 { return s.Replayed },
+			"expired":  func(s spool.Stats) uint64 { return s.Expired },
+			"corrupt":  func(s spool.Stats) uint64 { return s.Corrupt },
+			"dropped":  func(s spool.Stats) uint64 { return s.Dropped },
+		} {
+			read := read
+			m.registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Name:        "llm_slo_agent_spool_records_total",
+				Help:        "Output spool records by outcome.",
+				ConstLabels: prometheus.Labels{"sink": st.Name, "outcome": outcome},
+			}, func() float64 { return float64(read(spoolStat())) }))
+		}
+	}
+}
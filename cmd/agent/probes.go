@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/output"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signals"
+)
+
+// probeContract validates probe events and wraps them in an output batch
+// under the probe schema version selected by --probe-schema-version.
+// v1beta1 events carry the node identity plus the comm and netns of the
+// event's own PID.
+type probeContract struct {
+	version  string
+	node     schema.ProbeIdentity
+	v1alpha1 *schema.Validator[schema.ProbeEventV1]
+	v1beta1  *schema.Validator[schema.ProbeEventV1Beta1]
+}
+
+func newProbeContract(version string, node schema.ProbeIdentity) (*probeContract, error) {
+	switch version {
+	case schema.ProbeSchemaV1Alpha1:
+		return &probeContract{version: version, v1alpha1: schema.ProbeEventValidator()}, nil
+	case schema.ProbeSchemaV1Beta1:
+		return &probeContract{version: version, node: node, v1beta1: schema.ProbeEventV1Beta1Validator()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported probe schema version %q (expected %s|%s)", version, schema.ProbeSchemaV1Alpha1, schema.ProbeSchemaV1Beta1)
+	}
+}
+
+// batch validates event against the selected contract.
+func (p *probeContract) batch(event schema.ProbeEventV1) (output.Batch, error) {
+	if p.version == schema.ProbeSchemaV1Beta1 {
+		upgraded := schema.UpgradeProbeEvent(event, signals.ProcessIdentity(p.node, event.PID))
+		if err := p.v1beta1.Validate(upgraded); err != nil {
+			return output.Batch{}, err
+		}
+		return output.Batch{Kind: output.KindProbe, ProbeV1Beta1: []schema.ProbeEventV1Beta1{upgraded}}, nil
+	}
+	if err := p.v1alpha1.Validate(event); err != nil {
+		return output.Batch{}, err
+	}
+	return output.Batch{Kind: output.KindProbe, Probe: []schema.ProbeEventV1{event}}, nil
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/attribution"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/baseline"
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/toolkitcfg"
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/webhook"
@@ -54,13 +55,10 @@ func main() {
 	outPath := flag.String("out", "-", "Attribution JSONL output path ('-' for stdout)")
 	summaryPath := flag.String("summary-out", "", "Optional JSON summary output path")
 	confusionPath := flag.String("confusion-out", "", "Optional confusion matrix CSV output path")
-	schemaPath := flag.String(
-		"schema",
-		filepath.Join("docs", "contracts", "v1", "incident-attribution.schema.json"),
-		"Incident attribution JSON schema path",
-	)
+	schemaPath := flag.String("schema", "", "Incident attribution JSON schema path (empty = embedded v1 contract)")
 	configPath := flag.String("config", configPathValue, "toolkit config path")
 	attributionMode := flag.String("attribution-mode", attribution.AttributionModeBayes, "attribution mode: bayes|rule")
+	elevationMode := flag.String("elevation", cfg.Attribution.Elevation, "bayes evidence mode: static|zscore")
 	webhookEnabled := flag.Bool("webhook-enabled", cfg.Webhook.Enabled, "enable webhook delivery")
 	webhookURL := flag.String("webhook-url", cfg.Webhook.URL, "webhook endpoint URL")
 	webhookSecret := flag.String("webhook-secret", cfg.Webhook.Secret, "webhook secret for HMAC signature")
@@ -84,9 +82,34 @@ func main() {
 		os.Exit(1)
 	}
 
-	predictions := attribution.BuildAttributions(samples, *attributionMode)
+	validator := schema.IncidentAttributionValidator()
+	if *schemaPath != "" {
+		validator, err = schema.LoadValidator[schema.IncidentAttribution](*schemaPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load schema: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	elevation, err := baseline.ParseElevation(*elevationMode)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	dependencies, err := cfg.DependencyCatalog()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid dependencies: %v\n", err)
+		os.Exit(2)
+	}
+	predictions := attribution.BuildAttributionsWithOptions(samples, *attributionMode, attribution.EvidenceOptions{
+		Thresholds:   cfg.Thresholds.Table(),
+		Elevation:    elevation,
+		ZThreshold:   cfg.Attribution.ZScoreThreshold,
+		Baseline:     cfg.Attribution.Baseline.Config(),
+		Dependencies: dependencies,
+	})
 	for _, prediction := range predictions {
-		if err := schema.ValidateAgainstSchema(*schemaPath, prediction); err != nil {
+		if err := validator.Validate(prediction); err != nil {
 			fmt.Fprintf(os.Stderr, "schema validation failed: %v\n", err)
 			os.Exit(1)
 		}
@@ -2,16 +2,19 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/collector"
-	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/otel"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/output"
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/toolkitcfg"
 )
 
 var version = "dev"
@@ -31,6 +34,11 @@ func main() {
 		"OTLP/HTTP logs endpoint when output=otlp",
 	)
 	otlpTimeoutMS := flag.Int("otlp-timeout-ms", 5000, "OTLP export timeout in milliseconds")
+	outputMaxBytes := flag.Int64("output-max-bytes", toolkitcfg.DefaultOutputMaxBytes, "rotate jsonl output after this many bytes (0 = no size rotation)")
+	outputRotateSec := flag.Int("output-rotate-interval-s", 0, "rotate jsonl output after this many seconds (0 = no time rotation)")
+	outputMaxFiles := flag.Int("output-max-files", toolkitcfg.DefaultOutputMaxFiles, "rotated jsonl segments to retain (0 = unlimited)")
+	outputCompression := flag.String("output-compression", "", "compress rotated jsonl segments: gzip|zstd (empty = none)")
+	outputAppend := flag.Bool("output-append", true, "append to an existing jsonl output instead of truncating it")
 	cluster := flag.String("cluster", "local", "cluster name for synthetic generation")
 	namespace := flag.String("namespace", "default", "namespace for synthetic generation")
 	workload := flag.String("workload", "gateway", "workload for synthetic generation")
@@ -39,32 +47,67 @@ func main() {
 	scenario := flag.String("scenario", "baseline", "synthetic scenario name")
 	count := flag.Int("count", 1, "synthetic sample count (0 = stream mode)")
 	intervalMS := flag.Int("interval-ms", 1000, "stream interval milliseconds when count=0")
+	configPath := flag.String("config", "", "toolkit config path for multi-sink outputs (empty = use --output)")
 	flag.Parse()
 
-	schemaPath := filepath.Join("docs", "contracts", "v1", "slo-event.schema.json")
+	validator := schema.SLOEventValidator()
 	samples, err := loadInputSamples(*inputPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to read samples: %v\n", err)
 		os.Exit(1)
 	}
 
-	sink, closeFn, err := openOutput(
-		*outputMode,
-		*outputPath,
-		*otlpEndpoint,
-		time.Duration(*otlpTimeoutMS)*time.Millisecond,
-	)
+	outputs := []toolkitcfg.OutputConfig{{
+		Name:      *outputMode,
+		Type:      *outputMode,
+		Path:      *outputPath,
+		Endpoint:  *otlpEndpoint,
+		TimeoutMS: *otlpTimeoutMS,
+
+		MaxBytes:              outputMaxBytes,
+		RotateIntervalSeconds: *outputRotateSec,
+		MaxFiles:              outputMaxFiles,
+		Compression:           *outputCompression,
+		Truncate:              !*outputAppend,
+	}}
+	if *configPath != "" {
+		cfg, cfgErr := toolkitcfg.Load(*configPath)
+		if cfgErr != nil {
+			fmt.Fprintf(os.Stderr, "failed to load config: %v\n", cfgErr)
+			os.Exit(1)
+		}
+		if len(cfg.Outputs) > 0 {
+			outputs = cfg.Outputs
+		}
+	}
+	sink, err := output.Build(outputs, output.BuildOptions{
+		ScopeName: "llm-slo-ebpf-toolkit/collector",
+		Blocking:  true,
+	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to open output: %v\n", err)
 		os.Exit(1)
 	}
-	defer closeFn()
+	closeOutput := func() {
+		if err := sink.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "output failed: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	// fail flushes what the sinks still hold before exiting.
+	fail := func(format string, args ...any) {
+		fmt.Fprintf(os.Stderr, format, args...)
+		if err := sink.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "output failed: %v\n", err)
+		}
+		os.Exit(1)
+	}
 
 	if len(samples) > 0 {
-		if err := emitSamples(sink, schemaPath, samples); err != nil {
-			fmt.Fprintf(os.Stderr, "emit failed: %v\n", err)
-			os.Exit(1)
+		if err := emitSamples(sink, validator, samples); err != nil {
+			fail("emit failed: %v\n", err)
 		}
+		closeOutput()
 		return
 	}
 
@@ -77,43 +120,62 @@ func main() {
 	}
 
 	if *count < 0 {
-		fmt.Fprintln(os.Stderr, "count must be >= 0")
-		os.Exit(1)
+		fail("count must be >= 0\n")
 	}
 
 	if *count > 0 {
 		synthetic, genErr := collector.GenerateSyntheticSamples(*scenario, *count, time.Now().UTC(), meta)
 		if genErr != nil {
-			fmt.Fprintf(os.Stderr, "generate synthetic samples failed: %v\n", genErr)
-			os.Exit(1)
+			fail("generate synthetic samples failed: %v\n", genErr)
 		}
-		if err := emitSamples(sink, schemaPath, synthetic); err != nil {
-			fmt.Fprintf(os.Stderr, "emit failed: %v\n", err)
-			os.Exit(1)
+		if err := emitSamples(sink, validator, synthetic); err != nil {
+			fail("emit failed: %v\n", err)
 		}
+		closeOutput()
 		return
 	}
 
 	interval := time.Duration(*intervalMS) * time.Millisecond
 	if interval <= 0 {
-		fmt.Fprintln(os.Stderr, "interval-ms must be > 0")
-		os.Exit(1)
+		fail("interval-ms must be > 0\n")
 	}
 
+	// Stream until interrupted, then flush batched events on the way out.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	for idx := 0; ; idx++ {
 		sample, genErr := collector.BuildSyntheticSample(*scenario, idx, time.Now().UTC(), meta)
 		if genErr != nil {
-			fmt.Fprintf(os.Stderr, "generate synthetic sample failed: %v\n", genErr)
-			os.Exit(1)
+			fail("generate synthetic sample failed: %v\n", genErr)
 		}
-		if err := emitSamples(sink, schemaPath, []collector.RawSample{sample}); err != nil {
-			fmt.Fprintf(os.Stderr, "emit failed: %v\n", err)
-			os.Exit(1)
+		if err := emitSamples(sink, validator, []collector.RawSample{sample}); err != nil {
+			fail("emit failed: %v\n", err)
+		}
+		// Sinks deliver asynchronously; stop on the first failed delivery
+		// as a direct write would.
+		if err := deliveryFailure(sink); err != nil {
+			fail("emit failed: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			closeOutput()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliveryFailure reports the first sink that has failed to deliver
+// events.
+func deliveryFailure(sink *output.FanOut) error {
+	for _, st := range sink.Stats() {
+		if st.Failed > 0 {
+			return fmt.Errorf("output %s: %d events failed delivery", st.Name, st.Failed)
 		}
-		<-ticker.C
 	}
+	return nil
 }
 
 func loadInputSamples(path string) ([]collector.RawSample, error) {
@@ -128,14 +190,14 @@ func loadInputSamples(path string) ([]collector.RawSample, error) {
 	return readSamples(file)
 }
 
-func emitSamples(sink eventSink, schemaPath string, samples []collector.RawSample) error {
+func emitSamples(sink *output.FanOut, validator *schema.Validator[schema.SLOEvent], samples []collector.RawSample) error {
 	for _, sample := range samples {
 		events := collector.NormalizeSample(sample)
 		for _, event := range events {
-			if err := schema.ValidateAgainstSchema(schemaPath, event); err != nil {
+			if err := validator.Validate(event); err != nil {
 				return err
 			}
-			if err := sink.Emit(event); err != nil {
+			if err := sink.Emit(output.Batch{Kind: output.KindSLO, SLO: []schema.SLOEvent{event}}); err != nil {
 				return err
 			}
 		}
@@ -143,80 +205,6 @@ func emitSamples(sink eventSink, schemaPath string, samples []collector.RawSampl
 	return nil
 }
 
-type eventSink interface {
-	Emit(schema.SLOEvent) error
-	Close() error
-}
-
-type jsonEventSink struct {
-	encoder *json.Encoder
-	closeFn func() error
-}
-
-func (s *jsonEventSink) Emit(event schema.SLOEvent) error {
-	return s.encoder.Encode(event)
-}
-
-func (s *jsonEventSink) Close() error {
-	if s.closeFn == nil {
-		return nil
-	}
-	return s.closeFn()
-}
-
-type otlpEventSink struct {
-	exporter *otel.SLOEventExporter
-}
-
-func (s *otlpEventSink) Emit(event schema.SLOEvent) error {
-	return s.exporter.ExportBatch([]schema.SLOEvent{event})
-}
-
-func (s *otlpEventSink) Close() error {
-	return nil
-}
-
-func openOutput(
-	mode string,
-	path string,
-	otlpEndpoint string,
-	otlpTimeout time.Duration,
-) (eventSink, func(), error) {
-	switch mode {
-	case "stdout":
-		return &jsonEventSink{
-			encoder: json.NewEncoder(os.Stdout),
-			closeFn: func() error { return nil },
-		}, func() {}, nil
-	case "jsonl":
-		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-			return nil, func() {}, err
-		}
-		file, err := os.Create(path)
-		if err != nil {
-			return nil, func() {}, err
-		}
-		return &jsonEventSink{
-				encoder: json.NewEncoder(file),
-				closeFn: file.Close,
-			}, func() {
-				if closeErr := file.Close(); closeErr != nil {
-					fmt.Fprintf(os.Stderr, "close output file failed: %v\n", closeErr)
-				}
-			}, nil
-	case "otlp":
-		exporter := otel.NewSLOEventExporter(
-			otlpEndpoint,
-			"llm-slo-ebpf-toolkit",
-			"llm-slo-ebpf-toolkit/collector",
-			otlpTimeout,
-		)
-		return &otlpEventSink{exporter: exporter}, func() {}, nil
-	default:
-		return nil, func() {}, fmt.Errorf("unsupported output mode %q", mode)
-	}
-}
-
 func readSamples(reader *os.File) ([]collector.RawSample, error) {
 	scanner := bufio.NewScanner(reader)
 	samples := make([]collector.RawSample, 0)
@@ -8,7 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
-	"time"
+	"strings"
 
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/correlation"
 )
@@ -36,10 +36,17 @@ func main() {
 		filepath.Join("artifacts", "correlation", "predictions.csv"),
 		"predictions CSV output path",
 	)
-	windowMS := flag.Int("window-ms", 2000, "correlation window in milliseconds")
+	windowMS := flag.Int("window-ms", 0, "correlation window in milliseconds (0 = window_ms from --config, or 2000)")
 	threshold := flag.Float64("threshold", 0.7, "minimum confidence to count as positive correlation")
 	minPrecision := flag.Float64("min-precision", 0.90, "minimum precision gate")
 	minRecall := flag.Float64("min-recall", 0.85, "minimum recall gate")
+	configPath := flag.String("config", "", "toolkit config whose correlation tiers and window are evaluated (empty = built-in tiers)")
+	compare := flag.String("compare", "", "comma-separated toolkit configs whose correlation tiers are evaluated side by side with --config")
+	compareOut := flag.String(
+		"compare-out",
+		filepath.Join("artifacts", "correlation", "tier_comparison.json"),
+		"side-by-side comparison JSON output path, written with --compare",
+	)
 	flag.Parse()
 
 	pairs, err := correlation.LoadLabeledPairsFromJSONL(*inputPath)
@@ -48,15 +55,25 @@ func main() {
 		os.Exit(1)
 	}
 
-	report, predictions := correlation.EvaluateLabeledPairs(
-		pairs,
-		time.Duration(*windowMS)*time.Millisecond,
-		*threshold,
-	)
-	gate := correlation.EvaluateGate(report, *minPrecision, *minRecall)
-	report.MinPrecisionReq = *minPrecision
-	report.MinRecallReq = *minRecall
-	report.PassedGate = gate.Pass
+	primary, err := loadTierTable(*configPath, *windowMS)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load tier table failed: %v\n", err)
+		os.Exit(1)
+	}
+	var alternatives []tierTable
+	for _, path := range strings.Split(*compare, ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		table, err := loadTierTable(path, *windowMS)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "load tier table failed: %v\n", err)
+			os.Exit(1)
+		}
+		alternatives = append(alternatives, table)
+	}
+
+	report, predictions, gate := primary.evaluate(pairs, *threshold, *minPrecision, *minRecall)
 
 	if err := writeSummary(*outPath, report); err != nil {
 		fmt.Fprintf(os.Stderr, "write summary failed: %v\n", err)
@@ -77,6 +94,20 @@ func main() {
 	)
 	fmt.Printf("summary: %s\n", *outPath)
 	fmt.Printf("predictions: %s\n", *predictionsPath)
+
+	if len(alternatives) > 0 {
+		results := []tierTableResult{primary.result(report)}
+		for _, table := range alternatives {
+			altReport, _, _ := table.evaluate(pairs, *threshold, *minPrecision, *minRecall)
+			results = append(results, table.result(altReport))
+		}
+		printComparison(os.Stdout, results)
+		if err := writeJSON(*compareOut, results); err != nil {
+			fmt.Fprintf(os.Stderr, "write comparison failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("comparison: %s\n", *compareOut)
+	}
 	if !gate.Pass {
 		fmt.Fprintln(os.Stderr, gate.Message)
 		os.Exit(1)
@@ -84,10 +115,14 @@ func main() {
 }
 
 func writeSummary(path string, report correlation.EvalReport) error {
+	return writeJSON(path, report)
+}
+
+func writeJSON(path string, v any) error {
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return err
 	}
-	encoded, err := json.MarshalIndent(report, "", "  ")
+	encoded, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/correlation"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/toolkitcfg"
+)
+
+// tierTable is one correlation ladder under evaluation.
+type tierTable struct {
+	name   string
+	source string
+	tiers  correlation.Tiers
+	window time.Duration
+}
+
+// loadTierTable reads the correlation section of a toolkit config, or the
+// built-in ladder and window when path is empty. A positive windowMS
+// overrides the config's window.
+func loadTierTable(path string, windowMS int) (tierTable, error) {
+	table := tierTable{
+		name:   "default",
+		tiers:  correlation.DefaultTiers(),
+		window: correlation.DefaultWindow,
+	}
+	if path != "" {
+		cfg, err := toolkitcfg.Load(path)
+		if err != nil {
+			return table, err
+		}
+		table.name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		table.source = path
+		table.tiers = cfg.Correlation.TierTable()
+		table.window = time.Duration(cfg.Correlation.WindowMS) * time.Millisecond
+	}
+	if windowMS > 0 {
+		table.window = time.Duration(windowMS) * time.Millisecond
+	}
+	return table, nil
+}
+
+func (t tierTable) evaluate(
+	pairs []correlation.LabeledPair,
+	threshold, minPrecision, minRecall float64,
+) (correlation.EvalReport, []correlation.Prediction, correlation.GateResult) {
+	report, predictions := correlation.EvaluateLabeledPairsWithTiers(pairs, t.tiers, t.window, threshold)
+	gate := correlation.EvaluateGate(report, minPrecision, minRecall)
+	report.MinPrecisionReq = minPrecision
+	report.MinRecallReq = minRecall
+	report.PassedGate = gate.Pass
+	return report, predictions, gate
+}
+
+// tierTableResult is one row of the side-by-side comparison.
+type tierTableResult struct {
+	Name   string                 `json:"name"`
+	Source string                 `json:"source,omitempty"`
+	Tiers  []tierSummary          `json:"tiers"`
+	Report correlation.EvalReport `json:"report"`
+}
+
+type tierSummary struct {
+	Name       string  `json:"name"`
+	Key        string  `json:"key"`
+	WindowMS   int     `json:"window_ms,omitempty"`
+	Confidence float64 `json:"confidence"`
+}
+
+func (t tierTable) result(report correlation.EvalReport) tierTableResult {
+	out := tierTableResult{Name: t.name, Source: t.source, Report: report}
+	for _, tier := range t.tiers {
+		out.Tiers = append(out.Tiers, tierSummary{
+			Name:       tier.Name,
+			Key:        string(tier.Key),
+			WindowMS:   int(tier.Window / time.Millisecond),
+			Confidence: tier.Confidence,
+		})
+	}
+	return out
+}
+
+// printComparison prints one line per tier table, with positive
+// predictions by tier.
+func printComparison(w io.Writer, results []tierTableResult) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "tier table\twindow_ms\tprecision\trecall\tf1\ttier_accuracy\tgate\tpositives by tier")
+	for _, r := range results {
+		counts := make([]string, 0, len(r.Tiers))
+		for _, tier := range r.Tiers {
+			counts = append(counts, fmt.Sprintf("%s=%d", tier.Name, r.Report.TierCounts[tier.Name]))
+		}
+		fmt.Fprintf(
+			tw,
+			"%s\t%d\t%.4f\t%.4f\t%.4f\t%.4f\t%s\t%s\n",
+			r.Name,
+			r.Report.WindowMS,
+			r.Report.Precision,
+			r.Report.Recall,
+			r.Report.F1,
+			r.Report.TierAccuracy,
+			boolWord(r.Report.PassedGate),
+			strings.Join(counts, " "),
+		)
+	}
+	tw.Flush()
+}
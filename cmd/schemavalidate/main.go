@@ -50,6 +50,7 @@ func validateSchemaDocuments(root string) error {
 		filepath.Join(root, "docs", "contracts", "v1", "slo-event.schema.json"),
 		filepath.Join(root, "docs", "contracts", "v1", "incident-attribution.schema.json"),
 		filepath.Join(root, "docs", "contracts", "v1alpha1", "probe-event.schema.json"),
+		filepath.Join(root, "docs", "contracts", "v1beta1", "probe-event.schema.json"),
 		filepath.Join(root, "config", "toolkit.schema.json"),
 	}
 
@@ -134,6 +135,14 @@ func validateContractSamples(root string) error {
 		{schemaPath: filepath.Join(root, "docs", "contracts", "v1", "slo-event.schema.json"), payload: sloEvent},
 		{schemaPath: filepath.Join(root, "docs", "contracts", "v1", "incident-attribution.schema.json"), payload: incident},
 		{schemaPath: filepath.Join(root, "docs", "contracts", "v1alpha1", "probe-event.schema.json"), payload: probe},
+		{schemaPath: filepath.Join(root, "docs", "contracts", "v1beta1", "probe-event.schema.json"), payload: schema.UpgradeProbeEvent(probe, schema.ProbeIdentity{
+			Comm:       "rag-service",
+			CgroupID:   8812,
+			NetNS:      4026531840,
+			Service:    "rag-service",
+			Workload:   "rag-service",
+			NodeBootID: "2f1c2b3e-8d1a-4a7e-9c55-5f3f6a0e1d2c",
+		})},
 	}
 
 	for _, c := range checks {
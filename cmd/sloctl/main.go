@@ -25,6 +25,8 @@ func main() {
 		runPrereq(os.Args[2:])
 	case "cdgate":
 		runCDGate(os.Args[2:])
+	case "thresholds":
+		runThresholds(os.Args[2:])
 	case "help", "-h", "--help":
 		printUsage()
 	default:
@@ -117,6 +119,7 @@ func printUsage() {
 	fmt.Println("Usage:")
 	fmt.Println("  sloctl prereq check [--output text|json] [--strict]")
 	fmt.Println("  sloctl cdgate check [--config PATH] [--prometheus-url URL] [--ttft-p95-ms N] [--error-rate N] [--burn-rate N] [--fail-open] [--output text|json]")
+	fmt.Println("  sloctl thresholds explain [--config PATH] [--namespace NS] [--service SVC] [--signal NAME] [--output text|json]")
 }
 
 func printPrereqUsage() {
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/toolkitcfg"
+)
+
+func runThresholds(args []string) {
+	if len(args) == 0 {
+		printThresholdsUsage()
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "explain":
+		runThresholdsExplain(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown thresholds subcommand %q\n", args[0])
+		printThresholdsUsage()
+		os.Exit(2)
+	}
+}
+
+func runThresholdsExplain(args []string) {
+	fs := flag.NewFlagSet("sloctl thresholds explain", flag.ExitOnError)
+	configPath := fs.String("config", filepath.Join("config", "toolkit.yaml"), "toolkit config path")
+	namespace := fs.String("namespace", "", "workload namespace")
+	service := fs.String("service", "", "workload service")
+	signal := fs.String("signal", "", "limit output to one signal")
+	output := fs.String("output", "text", "output mode: text|json")
+	_ = fs.Parse(args)
+
+	cfg, err := loadThresholdsConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	table := cfg.Thresholds.Table()
+	workload := signalspec.Workload{Namespace: *namespace, Service: *service}
+	effective := table.Explain(workload)
+	if *signal != "" {
+		eff, ok := table.Resolve(*signal, workload)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown signal %q\n", *signal)
+			os.Exit(2)
+		}
+		effective = []signalspec.EffectiveThreshold{eff}
+	}
+
+	switch *output {
+	case "json":
+		payload, err := json.MarshalIndent(map[string]any{
+			"namespace":  *namespace,
+			"service":    *service,
+			"thresholds": effective,
+		}, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "marshal thresholds: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(payload))
+	case "text":
+		fmt.Printf("workload: namespace=%s service=%s\n\n", emptyFallback(*namespace, "*"), emptyFallback(*service, "*"))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SIGNAL\tUNIT\tWARNING\tSOURCE\tERROR\tSOURCE")
+		for _, eff := range effective {
+			fmt.Fprintf(w, "%s\t%s\t%g\t%s\t%g\t%s\n", eff.Signal, eff.Unit, eff.Warning, eff.WarningSource, eff.Error, eff.ErrorSource)
+		}
+		_ = w.Flush()
+	default:
+		fmt.Fprintf(os.Stderr, "unsupported output mode %q\n", *output)
+		os.Exit(2)
+	}
+}
+
+// loadThresholdsConfig falls back to built-in thresholds only when the
+// config file is missing; parse and validation errors are fatal because
+// explaining a config that agents would reject is misleading.
+func loadThresholdsConfig(path string) (toolkitcfg.ToolkitConfig, error) {
+	cfg, err := toolkitcfg.Load(path)
+	if err == nil {
+		return cfg, nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		log.Printf("warning: config %s not found; showing built-in thresholds", path)
+		return toolkitcfg.Default(), nil
+	}
+	return cfg, err
+}
+
+func printThresholdsUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  sloctl thresholds explain [--config PATH] [--namespace NS] [--service SVC] [--signal NAME] [--output text|json]")
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/otel/processor/ebpfcorrelator"
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/semconv"
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/slo"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/toolkitcfg"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/otel"
@@ -220,6 +221,7 @@ func main() {
 	fixtures := flag.String("fixtures", filepath.Join("demo", "rag-service", "fixtures", "corpus.json"), "fixtures corpus path")
 	backendFlag := flag.String("llm-backend", envOrDefault("LLM_BACKEND", "stub"), "LLM backend: stub|llama_cpp")
 	llamaURLFlag := flag.String("llama-cpp-url", envOrDefault("LLAMA_CPP_URL", "http://llama-cpp.default.svc.cluster.local:8080"), "llama.cpp base URL")
+	configPath := flag.String("config", "", "toolkit config whose correlation window and tiers the span correlator uses (empty = defaults)")
 	flag.Parse()
 
 	docs, err := loadCorpus(*fixtures)
@@ -260,9 +262,18 @@ func main() {
 		backend = stubBackend{}
 	}
 
+	correlator := ebpfcorrelator.New()
+	if *configPath != "" {
+		cfg, cfgErr := toolkitcfg.Load(*configPath)
+		if cfgErr != nil {
+			log.Fatalf("load toolkit config: %v", cfgErr)
+		}
+		correlator = cfg.Correlation.Correlator()
+	}
+
 	server := appServer{
 		tracer:     otel.Tracer("llm-slo/rag-service"),
-		correlator: ebpfcorrelator.New(),
+		correlator: correlator,
 		docs:       docs,
 		metrics:    metrics,
 		backend:    backend,
@@ -0,0 +1,10 @@
+// Package contracts embeds the versioned JSON schemas in this directory so
+// binaries validate against the same files that are published as docs.
+package contracts
+
+import "embed"
+
+// FS holds every published contract schema, keyed by "<version>/<file>".
+//
+//go:embed v1/*.schema.json v1alpha1/*.schema.json v1beta1/*.schema.json
+var FS embed.FS
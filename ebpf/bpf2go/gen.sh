@@ -37,10 +37,18 @@ $BPF2GO -cc clang -cflags "$CFLAGS" TCPRetransmit ../c/tcp_retransmit.bpf.c
 $BPF2GO -cc clang -cflags "$CFLAGS" RunqueueDelay ../c/runqueue_delay.bpf.c
 $BPF2GO -cc clang -cflags "$CFLAGS" ConnectLatency ../c/connect_latency.bpf.c
 $BPF2GO -cc clang -cflags "$CFLAGS" TLSHandshake ../c/tls_handshake.bpf.c
+$BPF2GO -cc clang -cflags "$CFLAGS" -target amd64,arm64 GoTLSHandshake ../c/go_tls_handshake.bpf.c
+$BPF2GO -cc clang -cflags "$CFLAGS" -target amd64,arm64 HTTPStatus ../c/http_status.bpf.c
 $BPF2GO -cc clang -cflags "$CFLAGS" CPUSteal ../c/cpu_steal.bpf.c
 $BPF2GO -cc clang -cflags "$CFLAGS" MemReclaim ../c/mem_reclaim.bpf.c
 $BPF2GO -cc clang -cflags "$CFLAGS" DiskIOLatency ../c/disk_io_latency.bpf.c
 $BPF2GO -cc clang -cflags "$CFLAGS" SyscallLatency ../c/syscall_latency.bpf.c
+$BPF2GO -cc clang -cflags "$CFLAGS" OOMKill ../c/oom_kill.bpf.c
+$BPF2GO -cc clang -cflags "$CFLAGS" TCPRTT ../c/tcp_rtt.bpf.c
+$BPF2GO -cc clang -cflags "$CFLAGS" TCPReset ../c/tcp_reset.bpf.c
+$BPF2GO -cc clang -cflags "$CFLAGS" ListenOverflow ../c/listen_overflow.bpf.c
+$BPF2GO -cc clang -cflags "$CFLAGS" ConntrackDrop ../c/conntrack_drop.bpf.c
+$BPF2GO -cc clang -cflags "$CFLAGS" RequestTiming ../c/request_timing.bpf.c
 $BPF2GO -cc clang -cflags "$CFLAGS" HelloSysEnterWrite ../c/hello_sys_enter_write.bpf.c
 
-echo "generated CO-RE bindings for 11 programs in ebpf/bpf2go"
+echo "generated CO-RE bindings for 19 programs in ebpf/bpf2go"
@@ -80,7 +80,9 @@ static __always_inline int exit_connect(int ret) {
     event->conn_src_port = ctx->src_port;
     event->conn_dst_port = ctx->dst_port;
     event->conn_dst_ip   = ctx->dst_ip;
+    event->conn_src_ip   = 0;
     event->errno_val     = ret < 0 ? -ret : 0;
+    llm_slo_fill_task(event);
 
     bpf_ringbuf_submit(event, 0);
     bpf_map_delete_elem(&connect_inflight, &pid_tgid);
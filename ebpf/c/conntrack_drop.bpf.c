@@ -0,0 +1,159 @@
+/*
+ * conntrack_drop.bpf.c — Counts packets netfilter drops because conntrack
+ * could not track their connection. On a busy egress node a full
+ * nf_conntrack table silently drops new provider connections, which
+ * otherwise look like a generic egress fault.
+ *
+ * Hook points:
+ *   kprobe+kretprobe/__nf_conntrack_alloc   — allocation fails with
+ *                                             -ENOMEM when the table is
+ *                                             full and nothing can be
+ *                                             early-dropped
+ *   kprobe+kretprobe/__nf_conntrack_confirm — NF_DROP when the new entry
+ *                                             cannot be inserted (clash)
+ *
+ * Signal: conntrack_drops_total (LLM_SLO_CONNTRACK_DROP)
+ *
+ * errno_val is ENOMEM for a full table and EEXIST for insert failures.
+ * The tuple is the connection's original direction, initiator first, and
+ * is left empty for IPv6.
+ */
+#include "vmlinux.h"
+#include "bpf_helpers.h"
+#include "llm_slo_event.h"
+
+char LICENSE[] SEC("license") = "GPL";
+
+#define LLM_SLO_AF_INET   2
+#define LLM_SLO_ENOMEM    12
+#define LLM_SLO_EEXIST    17
+#define LLM_SLO_NF_DROP   0
+#define LLM_SLO_NFCT_MASK (~7UL)
+#define LLM_SLO_MAX_ERRNO 4095
+
+struct {
+    __uint(type, BPF_MAP_TYPE_RINGBUF);
+    __uint(max_entries, 64 * 1024);
+} llm_slo_events SEC(".maps");
+
+struct ct_tuple {
+    __u32 src_ip;
+    __u32 dst_ip;
+    __u16 src_port;
+    __u16 dst_port;
+};
+
+/*
+ * Tuples saved at entry, as a per-CPU stack: a softirq may run conntrack
+ * between a process-context call's entry and return on the same CPU, but
+ * it always returns first.
+ */
+#define CT_STACK_DEPTH 4
+
+struct ct_stack {
+    __u32 depth;
+    struct ct_tuple slots[CT_STACK_DEPTH];
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(max_entries, 1);
+    __type(key, __u32);
+    __type(value, struct ct_stack);
+} ct_tuples SEC(".maps");
+
+static __always_inline void push_tuple(const struct nf_conntrack_tuple *t) {
+    __u32 zero = 0;
+    struct ct_stack *stack = bpf_map_lookup_elem(&ct_tuples, &zero);
+    if (!stack)
+        return;
+
+    __u32 depth = stack->depth;
+    stack->depth = depth + 1;
+    if (depth >= CT_STACK_DEPTH)
+        return;
+
+    struct ct_tuple *slot = &stack->slots[depth];
+    __builtin_memset(slot, 0, sizeof(*slot));
+    if (!t || BPF_CORE_READ(t, src.l3num) != LLM_SLO_AF_INET)
+        return;
+    slot->src_ip   = BPF_CORE_READ(t, src.u3.ip);
+    slot->dst_ip   = BPF_CORE_READ(t, dst.u3.ip);
+    slot->src_port = __builtin_bswap16(BPF_CORE_READ(t, src.u.all));
+    slot->dst_port = __builtin_bswap16(BPF_CORE_READ(t, dst.u.all));
+}
+
+static __always_inline int pop_tuple(struct ct_tuple *out) {
+    __u32 zero = 0;
+    struct ct_stack *stack = bpf_map_lookup_elem(&ct_tuples, &zero);
+    if (!stack || stack->depth == 0)
+        return 0;
+
+    __u32 depth = --stack->depth;
+    if (depth >= CT_STACK_DEPTH)
+        return 0;
+    *out = stack->slots[depth];
+    return 1;
+}
+
+static __always_inline void emit_drop(const struct ct_tuple *t, __s32 errno_val) {
+    struct llm_slo_event *event =
+        bpf_ringbuf_reserve(&llm_slo_events, sizeof(*event), 0);
+    if (!event)
+        return;
+
+    /* Conntrack runs in softirq for forwarded and inbound traffic, where
+     * current is unrelated to the connection; join by tuple. */
+    event->pid           = 0;
+    event->tid           = 0;
+    event->timestamp_ns  = bpf_ktime_get_ns();
+    event->signal_type   = LLM_SLO_CONNTRACK_DROP;
+    event->value_ns      = 1; /* count: 1 dropped packet */
+    event->conn_src_port = t->src_port;
+    event->conn_dst_port = t->dst_port;
+    event->conn_src_ip   = t->src_ip;
+    event->conn_dst_ip   = t->dst_ip;
+    event->errno_val     = errno_val;
+    event->cgroup_id     = 0;
+    __builtin_memset(event->comm, 0, LLM_SLO_COMM_LEN);
+
+    bpf_ringbuf_submit(event, 0);
+}
+
+SEC("kprobe/__nf_conntrack_alloc")
+int BPF_KPROBE(kprobe_nf_conntrack_alloc, struct net *net,
+               const struct nf_conntrack_zone *zone,
+               const struct nf_conntrack_tuple *orig) {
+    push_tuple(orig);
+    return 0;
+}
+
+SEC("kretprobe/__nf_conntrack_alloc")
+int BPF_KRETPROBE(kretprobe_nf_conntrack_alloc, void *ret) {
+    struct ct_tuple t = {};
+    if (!pop_tuple(&t))
+        return 0;
+    /* IS_ERR(ret): the only error is -ENOMEM, from a full table or a
+     * failed slab allocation. */
+    if ((unsigned long)ret >= (unsigned long)-LLM_SLO_MAX_ERRNO)
+        emit_drop(&t, LLM_SLO_ENOMEM);
+    return 0;
+}
+
+SEC("kprobe/__nf_conntrack_confirm")
+int BPF_KPROBE(kprobe_nf_conntrack_confirm, struct sk_buff *skb) {
+    unsigned long nfct = BPF_CORE_READ(skb, _nfct);
+    struct nf_conn *ct = (struct nf_conn *)(nfct & LLM_SLO_NFCT_MASK);
+    push_tuple(ct ? &ct->tuplehash[0].tuple : NULL);
+    return 0;
+}
+
+SEC("kretprobe/__nf_conntrack_confirm")
+int BPF_KRETPROBE(kretprobe_nf_conntrack_confirm, int ret) {
+    struct ct_tuple t = {};
+    if (!pop_tuple(&t))
+        return 0;
+    if (ret == LLM_SLO_NF_DROP)
+        emit_drop(&t, LLM_SLO_EEXIST);
+    return 0;
+}
@@ -52,7 +52,11 @@ int handle_sched_stat_wait(struct trace_event_raw_sched_stat_template *ctx) {
     event->conn_src_port = 0;
     event->conn_dst_port = 0;
     event->conn_dst_ip   = 0;
+    event->conn_src_ip   = 0;
     event->errno_val     = 0;
+    /* The waiting task may not be current; take comm from the tracepoint. */
+    event->cgroup_id     = 0;
+    __builtin_memcpy(event->comm, ctx->comm, LLM_SLO_COMM_LEN);
 
     bpf_ringbuf_submit(event, 0);
     return 0;
@@ -4,10 +4,15 @@
  * tracepoints. Events are emitted to a ring buffer for Go-side consumption.
  *
  * Hook points:
- *   tracepoint/block/block_rq_issue    — records issue timestamp keyed by (dev, sector)
+ *   tracepoint/block/block_rq_issue    — records the request keyed by (dev, sector)
  *   tracepoint/block/block_rq_complete — computes delta, emits event
  *
- * Signal: disk_io_latency_ms (LLM_SLO_DISK_IO_LATENCY)
+ * Signal: disk_io_latency_ms (LLM_SLO_DISK_IO_LATENCY, struct
+ * llm_slo_blk_event)
+ *
+ * Completions run in interrupt context, so the issuing task, its cgroup,
+ * the operation and the size are captured at issue and carried over.
+ * Userspace resolves device names through /sys/dev/block.
  */
 #include "vmlinux.h"
 #include "bpf_helpers.h"
@@ -15,17 +20,30 @@
 
 char LICENSE[] SEC("license") = "GPL";
 
+/* dev_t as encoded by the block tracepoints (MINORBITS = 20). */
+#define BLK_MINORBITS 20
+#define BLK_MINORMASK ((1U << BLK_MINORBITS) - 1)
+
 struct blk_key {
     __u32 dev;
     __u64 sector;
 };
 
+struct blk_start_info {
+    __u64 ts;
+    __u64 pid_tgid;
+    __u64 cgroup_id;
+    char  comm[LLM_SLO_COMM_LEN];
+    __u32 bytes;
+    __u8  op;
+};
+
 /* Tracks in-flight block I/O requests keyed by (dev, sector). */
 struct {
     __uint(type, BPF_MAP_TYPE_HASH);
     __uint(max_entries, 16384);
     __type(key, struct blk_key);
-    __type(value, __u64); /* start timestamp_ns */
+    __type(value, struct blk_start_info);
 } blk_start SEC(".maps");
 
 /* Ring buffer for emitting events to userspace. */
@@ -34,14 +52,32 @@ struct {
     __uint(max_entries, 256 * 1024);
 } llm_slo_events SEC(".maps");
 
+/* rwbs starts with the operation: R(ead), W(rite), F(lush), D(iscard), N(one). */
+static __always_inline __u8 blk_op(const char *rwbs) {
+    for (int i = 0; i < 2; i++) {
+        if (rwbs[i] == 'R')
+            return LLM_SLO_BLK_READ;
+        if (rwbs[i] == 'W')
+            return LLM_SLO_BLK_WRITE;
+    }
+    return LLM_SLO_BLK_OTHER;
+}
+
 SEC("tracepoint/block/block_rq_issue")
-int handle_block_rq_issue(struct trace_event_raw_block_rq_completion *ctx) {
+int handle_block_rq_issue(struct trace_event_raw_block_rq *ctx) {
     struct blk_key key = {
         .dev    = ctx->dev,
         .sector = ctx->sector,
     };
-    __u64 ts = bpf_ktime_get_ns();
-    bpf_map_update_elem(&blk_start, &key, &ts, BPF_ANY);
+    struct blk_start_info info = {
+        .ts        = bpf_ktime_get_ns(),
+        .pid_tgid  = bpf_get_current_pid_tgid(),
+        .cgroup_id = bpf_get_current_cgroup_id(),
+        .bytes     = ctx->bytes,
+        .op        = blk_op(ctx->rwbs),
+    };
+    bpf_get_current_comm(info.comm, sizeof(info.comm));
+    bpf_map_update_elem(&blk_start, &key, &info, BPF_ANY);
     return 0;
 }
 
@@ -51,33 +87,43 @@ int handle_block_rq_complete(struct trace_event_raw_block_rq_completion *ctx) {
         .dev    = ctx->dev,
         .sector = ctx->sector,
     };
-    __u64 *start_ns = bpf_map_lookup_elem(&blk_start, &key);
-    if (!start_ns)
+    struct blk_start_info *info = bpf_map_lookup_elem(&blk_start, &key);
+    if (!info)
         return 0;
 
-    __u64 delta_ns = bpf_ktime_get_ns() - *start_ns;
+    __u64 now = bpf_ktime_get_ns();
+    __u64 delta_ns = now - info->ts;
+    struct blk_start_info start = *info;
     bpf_map_delete_elem(&blk_start, &key);
 
     /* Filter out very fast I/O (<500us) to focus on blocking operations. */
     if (delta_ns < 500000)
         return 0;
 
-    __u64 pid_tgid = bpf_get_current_pid_tgid();
-
-    struct llm_slo_event *event =
+    struct llm_slo_blk_event *event =
         bpf_ringbuf_reserve(&llm_slo_events, sizeof(*event), 0);
     if (!event)
         return 0;
 
-    event->pid           = pid_tgid >> 32;
-    event->tid           = (__u32)pid_tgid;
-    event->timestamp_ns  = bpf_ktime_get_ns();
-    event->signal_type   = LLM_SLO_DISK_IO_LATENCY;
-    event->value_ns      = delta_ns;
-    event->conn_src_port = 0;
-    event->conn_dst_port = 0;
-    event->conn_dst_ip   = 0;
-    event->errno_val     = 0;
+    event->base.pid           = start.pid_tgid >> 32;
+    event->base.tid           = (__u32)start.pid_tgid;
+    event->base.timestamp_ns  = now;
+    event->base.signal_type   = LLM_SLO_DISK_IO_LATENCY;
+    event->base.value_ns      = delta_ns;
+    event->base.conn_src_port = 0;
+    event->base.conn_dst_port = 0;
+    event->base.conn_dst_ip   = 0;
+    event->base.conn_src_ip   = 0;
+    event->base.errno_val     = ctx->error;
+    /* Completion runs in IRQ context; identify the issuer instead. */
+    event->base.cgroup_id     = start.cgroup_id;
+    __builtin_memcpy(event->base.comm, start.comm, LLM_SLO_COMM_LEN);
+
+    event->dev_major = ctx->dev >> BLK_MINORBITS;
+    event->dev_minor = ctx->dev & BLK_MINORMASK;
+    event->bytes     = start.bytes;
+    event->op        = start.op;
+    __builtin_memset(event->pad, 0, sizeof(event->pad));
 
     bpf_ringbuf_submit(event, 0);
     return 0;
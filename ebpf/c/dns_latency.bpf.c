@@ -1,14 +1,25 @@
 /*
  * dns_latency.bpf.c — Measures DNS resolution latency by timing UDP
- * sends to port 53 (udp_sendmsg) and their corresponding receives
- * (udp_recvmsg). Events are emitted to a ring buffer for Go-side
- * consumption.
+ * sends to port 53 (udp_sendmsg) until the response datagram is consumed
+ * (skb_consume_udp), and parses the response header and question so
+ * userspace can tell NXDOMAIN search-path walks from slow resolvers. The
+ * raw answer section is passed along so userspace can map A/AAAA
+ * addresses back to the name that was asked for (passive DNS).
+ * Events are emitted to a ring buffer for Go-side consumption.
  *
  * Hook points:
- *   kprobe/udp_sendmsg   — records start timestamp keyed by (pid, tid)
- *   kretprobe/udp_recvmsg — computes delta if dst_port was 53
+ *   kprobe/udp_sendmsg     — records start timestamp keyed by (pid, tid)
+ *   kprobe/skb_consume_udp — computes delta and reads the DNS response;
+ *                            runs inside udp_recvmsg after the datagram
+ *                            was dequeued, with skb->data at the payload
  *
- * Signal: dns_latency_ms (LLM_SLO_DNS_LATENCY)
+ * Signals:
+ *   dns_latency_ms    (LLM_SLO_DNS_LATENCY)  — every answered query
+ *   dns_nxdomain_total (LLM_SLO_DNS_NXDOMAIN) — responses with rcode 3
+ *
+ * Both are emitted as struct llm_slo_dns_event with qtype, rcode, the
+ * wire-format question name and the answer section; conn_dst_ip is the
+ * responding resolver.
  */
 #include "vmlinux.h"
 #include "bpf_helpers.h"
@@ -16,13 +27,11 @@
 
 char LICENSE[] SEC("license") = "GPL";
 
-/* Tracks in-flight DNS send timestamps keyed by pid_tgid. */
-struct {
-    __uint(type, BPF_MAP_TYPE_HASH);
-    __uint(max_entries, 8192);
-    __type(key, __u64);   /* pid_tgid */
-    __type(value, __u64); /* start timestamp_ns */
-} dns_start SEC(".maps");
+#define DNS_PORT          53
+#define DNS_HEADER_LEN    12
+#define DNS_RCODE_NXDOMAIN 3
+#define DNS_MAX_LABELS    32
+#define DNS_QTAIL_LEN     4 /* qtype + qclass */
 
 /* Ring buffer for emitting events to userspace. */
 struct {
@@ -31,8 +40,8 @@ struct {
 } llm_slo_events SEC(".maps");
 
 /*
- * Temporary storage for destination port extracted from the socket.
- * Keyed by pid_tgid so we can check port 53 on the return path.
+ * Send-side context keyed by pid_tgid so the response can be matched to
+ * the query that started the timer.
  */
 struct send_ctx {
     __u64 start_ns;
@@ -48,6 +57,26 @@ struct {
     __type(value, struct send_ctx);
 } dns_inflight SEC(".maps");
 
+/* Parsed response, filled once and copied into each emitted event. */
+struct dns_answer {
+    __u32 resolver_ip;
+    __u16 qtype;
+    __u8  rcode;
+    __u8  flags;
+    __u8  qname[LLM_SLO_DNS_QNAME_LEN];
+    __u16 ancount;
+    __u16 answers_len;
+    __u8  answers[LLM_SLO_DNS_ANSWERS_LEN];
+};
+
+/* Per-CPU scratch space; struct dns_answer is too large for the stack. */
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(max_entries, 1);
+    __type(key, __u32);
+    __type(value, struct dns_answer);
+} dns_scratch SEC(".maps");
+
 SEC("kprobe/udp_sendmsg")
 int BPF_KPROBE(kprobe_udp_sendmsg, struct sock *sk) {
     __u64 pid_tgid = bpf_get_current_pid_tgid();
@@ -59,7 +88,7 @@ int BPF_KPROBE(kprobe_udp_sendmsg, struct sock *sk) {
     dst_port = __builtin_bswap16(dst_port);
 
     /* Only track DNS traffic (port 53). */
-    if (dst_port != 53)
+    if (dst_port != DNS_PORT)
         return 0;
 
     BPF_CORE_READ_INTO(&dst_ip, sk, __sk_common.skc_daddr);
@@ -76,33 +105,134 @@ int BPF_KPROBE(kprobe_udp_sendmsg, struct sock *sk) {
     return 0;
 }
 
-SEC("kprobe/udp_recvmsg")
-int BPF_KPROBE(kprobe_udp_recvmsg) {
+/*
+ * read_answers copies the answer section, which starts at off (after the
+ * question's qtype and qclass), into ans->answers.
+ */
+static __always_inline void read_answers(struct sk_buff *skb,
+                                         unsigned char *data, __u32 off,
+                                         struct dns_answer *ans) {
+    if (ans->ancount == 0)
+        return;
+    __u32 len = BPF_CORE_READ(skb, len);
+    if (len <= off)
+        return;
+    len -= off;
+    if (len > LLM_SLO_DNS_ANSWERS_LEN)
+        len = LLM_SLO_DNS_ANSWERS_LEN;
+    if (bpf_probe_read_kernel(ans->answers, sizeof(ans->answers),
+                              data + off) < 0)
+        return;
+    ans->answers_len = len;
+    ans->flags |= LLM_SLO_DNS_F_ANSWERS;
+}
+
+/*
+ * parse_response reads the resolver address from the IP header and the
+ * rcode, question name, qtype and answer section from the DNS payload.
+ * Returns 0 when the datagram did not come from port 53.
+ */
+static __always_inline int parse_response(struct sk_buff *skb,
+                                          struct dns_answer *ans) {
+    unsigned char *head = BPF_CORE_READ(skb, head);
+    unsigned char *data = BPF_CORE_READ(skb, data);
+    __u16 network_off = BPF_CORE_READ(skb, network_header);
+    __u16 transport_off = BPF_CORE_READ(skb, transport_header);
+
+    __u16 src_port = 0;
+    bpf_probe_read_kernel(&src_port, sizeof(src_port), head + transport_off);
+    if (__builtin_bswap16(src_port) != DNS_PORT)
+        return 0;
+
+    /* iphdr.saddr sits at offset 12. */
+    bpf_probe_read_kernel(&ans->resolver_ip, sizeof(ans->resolver_ip),
+                          head + network_off + 12);
+
+    __u8 hdr[DNS_HEADER_LEN];
+    ans->flags = 0;
+    ans->qtype = 0;
+    ans->rcode = 0;
+    ans->ancount = 0;
+    ans->answers_len = 0;
+    if (bpf_probe_read_kernel(hdr, sizeof(hdr), data) < 0)
+        return 1;
+    ans->rcode = hdr[3] & 0x0f;
+    ans->ancount = ((__u16)hdr[6] << 8) | hdr[7];
+
+    if (bpf_probe_read_kernel(ans->qname, sizeof(ans->qname),
+                              data + DNS_HEADER_LEN) < 0)
+        return 1;
+    ans->flags |= LLM_SLO_DNS_F_PARSED;
+
+    /* Walk labels to the terminating zero; qtype follows it. */
+    __u32 pos = 0;
+    for (int i = 0; i < DNS_MAX_LABELS; i++) {
+        __u8 len = ans->qname[pos & (LLM_SLO_DNS_QNAME_LEN - 1)];
+        if (len == 0) {
+            __u16 qtype = 0;
+            bpf_probe_read_kernel(&qtype, sizeof(qtype),
+                                  data + DNS_HEADER_LEN + pos + 1);
+            ans->qtype = __builtin_bswap16(qtype);
+            read_answers(skb, data, DNS_HEADER_LEN + pos + 1 + DNS_QTAIL_LEN, ans);
+            break;
+        }
+        pos += len + 1;
+        if (pos >= LLM_SLO_DNS_QNAME_LEN)
+            break;
+    }
+    return 1;
+}
+
+static __always_inline void emit(__u64 pid_tgid, struct send_ctx *ctx,
+                                 struct dns_answer *ans, __u32 signal_type,
+                                 __u64 value) {
+    struct llm_slo_dns_event *event =
+        bpf_ringbuf_reserve(&llm_slo_events, sizeof(*event), 0);
+    if (!event)
+        return;
+
+    event->base.pid           = pid_tgid >> 32;
+    event->base.tid           = (__u32)pid_tgid;
+    event->base.timestamp_ns  = bpf_ktime_get_ns();
+    event->base.signal_type   = signal_type;
+    event->base.value_ns      = value;
+    event->base.conn_src_port = ctx->src_port;
+    event->base.conn_dst_port = ctx->dst_port;
+    event->base.conn_dst_ip   = ans->resolver_ip ? ans->resolver_ip : ctx->dst_ip;
+    event->base.errno_val     = 0;
+    event->base.conn_src_ip   = 0;
+    llm_slo_fill_task(&event->base);
+
+    event->qtype = ans->qtype;
+    event->rcode = ans->rcode;
+    event->flags = ans->flags;
+    __builtin_memcpy(event->qname, ans->qname, LLM_SLO_DNS_QNAME_LEN);
+    event->ancount     = ans->ancount;
+    event->answers_len = ans->answers_len;
+    __builtin_memcpy(event->answers, ans->answers, LLM_SLO_DNS_ANSWERS_LEN);
+
+    bpf_ringbuf_submit(event, 0);
+}
+
+SEC("kprobe/skb_consume_udp")
+int BPF_KPROBE(kprobe_skb_consume_udp, struct sock *sk, struct sk_buff *skb) {
     __u64 pid_tgid = bpf_get_current_pid_tgid();
     struct send_ctx *ctx = bpf_map_lookup_elem(&dns_inflight, &pid_tgid);
     if (!ctx)
         return 0;
 
-    __u64 delta_ns = bpf_ktime_get_ns() - ctx->start_ns;
-
-    struct llm_slo_event *event =
-        bpf_ringbuf_reserve(&llm_slo_events, sizeof(*event), 0);
-    if (!event) {
-        bpf_map_delete_elem(&dns_inflight, &pid_tgid);
+    __u32 zero = 0;
+    struct dns_answer *ans = bpf_map_lookup_elem(&dns_scratch, &zero);
+    if (!ans)
         return 0;
-    }
+    if (!parse_response(skb, ans))
+        return 0; /* not the DNS reply; keep waiting */
 
-    event->pid           = pid_tgid >> 32;
-    event->tid           = (__u32)pid_tgid;
-    event->timestamp_ns  = bpf_ktime_get_ns();
-    event->signal_type   = LLM_SLO_DNS_LATENCY;
-    event->value_ns      = delta_ns;
-    event->conn_src_port = ctx->src_port;
-    event->conn_dst_port = ctx->dst_port;
-    event->conn_dst_ip   = ctx->dst_ip;
-    event->errno_val     = 0;
+    __u64 delta_ns = bpf_ktime_get_ns() - ctx->start_ns;
+    emit(pid_tgid, ctx, ans, LLM_SLO_DNS_LATENCY, delta_ns);
+    if (ans->rcode == DNS_RCODE_NXDOMAIN)
+        emit(pid_tgid, ctx, ans, LLM_SLO_DNS_NXDOMAIN, 1);
 
-    bpf_ringbuf_submit(event, 0);
     bpf_map_delete_elem(&dns_inflight, &pid_tgid);
     return 0;
 }
@@ -0,0 +1,44 @@
+#ifndef __LLM_SLO_GO_ABI_H
+#define __LLM_SLO_GO_ABI_H
+
+/*
+ * Register accessors for Go's internal register ABI (Go 1.17+ on amd64,
+ * Go 1.18+ on arm64). Integer arguments and results are passed in
+ * RAX, RBX, RCX, RDI... on amd64 and R0, R1, R2... on arm64, and the
+ * current goroutine (runtime.g) lives in R14 / R28.
+ *
+ * Goroutines migrate between threads when they block, so per-call state
+ * is keyed by go_call_key rather than pid_tgid.
+ */
+#if defined(__TARGET_ARCH_x86)
+#define GO_PARAM1(ctx) ((ctx)->ax)
+#define GO_PARAM2(ctx) ((ctx)->bx)
+#define GO_PARAM3(ctx) ((ctx)->cx)
+#define GO_G(ctx)      ((ctx)->r14)
+#elif defined(__TARGET_ARCH_arm64)
+#define GO_PARAM1(ctx) ((ctx)->regs[0])
+#define GO_PARAM2(ctx) ((ctx)->regs[1])
+#define GO_PARAM3(ctx) ((ctx)->regs[2])
+#define GO_G(ctx)      ((ctx)->regs[28])
+#else
+#error "Go uprobes support amd64 and arm64 only"
+#endif
+
+/* The first result shares the first argument register. */
+#define GO_RET1(ctx) GO_PARAM1(ctx)
+
+struct go_call_key {
+    __u32 tgid;
+    __u32 pad;
+    __u64 g; /* runtime.g pointer of the calling goroutine */
+};
+
+static __always_inline struct go_call_key go_call_key(struct pt_regs *ctx) {
+    struct go_call_key key = {
+        .tgid = bpf_get_current_pid_tgid() >> 32,
+        .g    = GO_G(ctx),
+    };
+    return key;
+}
+
+#endif /* __LLM_SLO_GO_ABI_H */
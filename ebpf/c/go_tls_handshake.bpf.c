@@ -0,0 +1,113 @@
+/*
+ * go_tls_handshake.bpf.c — Measures TLS handshake latency in Go binaries
+ * by attaching uprobes to crypto/tls.(*Conn).handshakeContext. Go links
+ * crypto/tls statically, so the libssl uprobes in tls_handshake.bpf.c
+ * never fire for Go LLM gateways.
+ *
+ * Hook points:
+ *   uprobe/go_tls_handshake_enter  — handshakeContext entry; records start
+ *   uprobe/go_tls_handshake_run    — clientHandshake/serverHandshake entry;
+ *                                    marks that a handshake actually ran
+ *   uprobe/go_tls_handshake_return — every RET instruction of
+ *                                    handshakeContext; emits the event
+ *
+ * Signal: tls_handshake_ms (LLM_SLO_TLS_HANDSHAKE)
+ *
+ * Uretprobes are not used: they overwrite the return address on the
+ * goroutine stack, which the Go runtime may copy to grow the stack while
+ * the handshake blocks on the network. The collector resolves each RET
+ * instruction from the binary's text instead and attaches a uprobe at
+ * its offset (pkg/collector/tls_uprobes.go).
+ *
+ * A goroutine that blocked can resume on another thread, so state is keyed
+ * by goroutine (go_abi.h) rather than pid_tgid. Conn.Read and Conn.Write call
+ * handshakeContext every time and return early once the handshake is done;
+ * those calls never reach the run probe and are not reported.
+ *
+ * Handshakes may be scoped to a set of cgroups (llm_slo_scope.h).
+ */
+#include "vmlinux.h"
+#include "bpf_helpers.h"
+#include "llm_slo_event.h"
+#include "go_abi.h"
+#include "llm_slo_scope.h"
+
+char LICENSE[] SEC("license") = "GPL";
+
+struct go_tls_state {
+    __u64 start_ns;
+    __u32 tid;
+    __u32 ran; /* set once clientHandshake/serverHandshake was entered */
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __uint(max_entries, 8192);
+    __type(key, struct go_call_key);
+    __type(value, struct go_tls_state);
+} go_tls_start SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_RINGBUF);
+    __uint(max_entries, 256 * 1024);
+} llm_slo_events SEC(".maps");
+
+SEC("uprobe/go_tls_handshake_enter")
+int BPF_UPROBE(uprobe_go_tls_handshake_enter) {
+    if (!llm_slo_in_scope())
+        return 0;
+    struct go_call_key key = go_call_key(ctx);
+    struct go_tls_state state = {
+        .start_ns = bpf_ktime_get_ns(),
+        .tid      = (__u32)bpf_get_current_pid_tgid(),
+        .ran      = 0,
+    };
+    bpf_map_update_elem(&go_tls_start, &key, &state, BPF_ANY);
+    return 0;
+}
+
+SEC("uprobe/go_tls_handshake_run")
+int BPF_UPROBE(uprobe_go_tls_handshake_run) {
+    struct go_call_key key = go_call_key(ctx);
+    struct go_tls_state *state = bpf_map_lookup_elem(&go_tls_start, &key);
+    if (state)
+        state->ran = 1;
+    return 0;
+}
+
+SEC("uprobe/go_tls_handshake_return")
+int BPF_UPROBE(uprobe_go_tls_handshake_return) {
+    struct go_call_key key = go_call_key(ctx);
+    struct go_tls_state *state = bpf_map_lookup_elem(&go_tls_start, &key);
+    if (!state)
+        return 0;
+    if (!state->ran) {
+        bpf_map_delete_elem(&go_tls_start, &key);
+        return 0;
+    }
+
+    __u64 now = bpf_ktime_get_ns();
+    struct llm_slo_event *event =
+        bpf_ringbuf_reserve(&llm_slo_events, sizeof(*event), 0);
+    if (!event) {
+        bpf_map_delete_elem(&go_tls_start, &key);
+        return 0;
+    }
+
+    event->pid           = key.tgid;
+    event->tid           = state->tid;
+    event->timestamp_ns  = now;
+    event->signal_type   = LLM_SLO_TLS_HANDSHAKE;
+    event->value_ns      = now - state->start_ns;
+    event->conn_src_port = 0;
+    event->conn_dst_port = 443; /* conventional TLS port */
+    event->conn_dst_ip   = 0;
+    event->conn_src_ip   = 0;
+    /* handshakeContext returns error; a non-nil itab word means failure. */
+    event->errno_val     = GO_RET1(ctx) != 0 ? 1 : 0;
+    llm_slo_fill_task(event);
+
+    bpf_ringbuf_submit(event, 0);
+    bpf_map_delete_elem(&go_tls_start, &key);
+    return 0;
+}
@@ -40,6 +40,7 @@ int handle_sys_enter_write(struct trace_event_raw_sys_enter *ctx) {
     event->conn_dst_port = 0;
     event->conn_dst_ip = 0;
     event->errno_val = 0;
+    llm_slo_fill_task(event);
 
     bpf_ringbuf_submit(event, 0);
     return 0;
@@ -0,0 +1,219 @@
+/*
+ * http_status.bpf.c — Captures HTTP traffic at the TLS plaintext boundary
+ * and in Go's net/http client so userspace can count provider 429 and 5xx
+ * responses and read Retry-After, without instrumenting the application.
+ *
+ * Hook points:
+ *   uprobe/SSL_write, uprobe+uretprobe/SSL_read — OpenSSL and BoringSSL
+ *   uprobe/go_tls_write                         — crypto/tls.(*Conn).Write
+ *   uprobe/go_tls_read_enter + go_tls_read_return
+ *                                               — crypto/tls.(*Conn).Read
+ *   uprobe/go_http_response_enter + go_http_response_return
+ *                                               — net/http.ReadResponse
+ *
+ * Record: LLM_SLO_RECORD_HTTP (struct llm_slo_http_event)
+ *
+ * TLS reads and writes are copied as-is, up to LLM_SLO_HTTP_DATA_LEN
+ * bytes per call. HTTP/1.1 status lines, HTTP/2 HEADERS frames and the
+ * request host (Host / :authority) are parsed in userspace
+ * (pkg/collector/http_status.go), which also drops connections to hosts
+ * that are not configured providers. Go return probes sit on RET
+ * instructions like go_tls_handshake.bpf.c.
+ *
+ * net/http.ReadResponse covers plain-HTTP Go clients, e.g. a gateway
+ * calling a local egress proxy. The status code and request host are read
+ * at fixed offsets: Response.StatusCode follows Response.Status, and
+ * Request.URL points at url.URL, whose Scheme and Host layout has been
+ * stable since Go 1.0. https responses are skipped there because the TLS
+ * probes already see them.
+ */
+#include "vmlinux.h"
+#include "bpf_helpers.h"
+#include "llm_slo_event.h"
+#include "go_abi.h"
+
+char LICENSE[] SEC("license") = "GPL";
+
+/* Field offsets in Go structs (64-bit). */
+#define GO_RESPONSE_STATUS_CODE_OFF 16 /* Status string, StatusCode int */
+#define GO_REQUEST_URL_OFF          16 /* Method string, URL *url.URL */
+#define GO_URL_SCHEME_OFF           0  /* Scheme string */
+#define GO_URL_HOST_OFF             40 /* Scheme, Opaque string, User *Userinfo, Host */
+
+struct read_args {
+    __u64 conn;
+    __u64 buf;
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __uint(max_entries, 8192);
+    __type(key, __u64); /* pid_tgid */
+    __type(value, struct read_args);
+} ssl_read_args SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __uint(max_entries, 8192);
+    __type(key, struct go_call_key);
+    __type(value, struct read_args);
+} go_read_args SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __uint(max_entries, 8192);
+    __type(key, struct go_call_key);
+    __type(value, __u64); /* *http.Request */
+} go_response_req SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_RINGBUF);
+    __uint(max_entries, 1024 * 1024);
+} llm_slo_events SEC(".maps");
+
+static __always_inline struct llm_slo_http_event *
+http_event_reserve(__u8 kind, __u64 conn, __u32 len) {
+    struct llm_slo_http_event *event =
+        bpf_ringbuf_reserve(&llm_slo_events, sizeof(*event), 0);
+    if (!event)
+        return 0;
+
+    __u64 pid_tgid = bpf_get_current_pid_tgid();
+    event->base.pid           = pid_tgid >> 32;
+    event->base.tid           = (__u32)pid_tgid;
+    event->base.timestamp_ns  = bpf_ktime_get_ns();
+    event->base.signal_type   = LLM_SLO_RECORD_HTTP;
+    event->base.value_ns      = 0;
+    event->base.conn_src_port = 0;
+    event->base.conn_dst_port = 0;
+    event->base.conn_dst_ip   = 0;
+    event->base.conn_src_ip   = 0;
+    event->base.errno_val     = 0;
+    llm_slo_fill_task(&event->base);
+
+    event->conn_id = conn;
+    event->len     = len;
+    event->status  = 0;
+    event->kind    = kind;
+    event->pad     = 0;
+    return event;
+}
+
+static __always_inline void emit_plaintext(__u8 kind, __u64 conn, __u64 buf, __s64 len) {
+    if (len <= 0 || !buf)
+        return;
+    struct llm_slo_http_event *event = http_event_reserve(kind, conn, (__u32)len);
+    if (!event)
+        return;
+
+    __u32 n = len < LLM_SLO_HTTP_DATA_LEN ? (__u32)len : LLM_SLO_HTTP_DATA_LEN;
+    if (bpf_probe_read_user(event->data, n, (void *)buf) < 0) {
+        bpf_ringbuf_discard(event, 0);
+        return;
+    }
+    bpf_ringbuf_submit(event, 0);
+}
+
+/* OpenSSL / BoringSSL: int SSL_write(SSL *ssl, const void *buf, int num) */
+SEC("uprobe/SSL_write")
+int BPF_UPROBE(uprobe_ssl_write, void *ssl, const void *buf, int num) {
+    emit_plaintext(LLM_SLO_HTTP_WRITE, (__u64)ssl, (__u64)buf, num);
+    return 0;
+}
+
+/* int SSL_read(SSL *ssl, void *buf, int num) */
+SEC("uprobe/SSL_read")
+int BPF_UPROBE(uprobe_ssl_read, void *ssl, void *buf, int num) {
+    __u64 pid_tgid = bpf_get_current_pid_tgid();
+    struct read_args args = {.conn = (__u64)ssl, .buf = (__u64)buf};
+    bpf_map_update_elem(&ssl_read_args, &pid_tgid, &args, BPF_ANY);
+    return 0;
+}
+
+SEC("uretprobe/SSL_read")
+int BPF_URETPROBE(uretprobe_ssl_read, int ret) {
+    __u64 pid_tgid = bpf_get_current_pid_tgid();
+    struct read_args *args = bpf_map_lookup_elem(&ssl_read_args, &pid_tgid);
+    if (!args)
+        return 0;
+    emit_plaintext(LLM_SLO_HTTP_READ, args->conn, args->buf, ret);
+    bpf_map_delete_elem(&ssl_read_args, &pid_tgid);
+    return 0;
+}
+
+/* func (c *Conn) Write(b []byte) (int, error): c, b.ptr, b.len */
+SEC("uprobe/go_tls_write")
+int BPF_UPROBE(uprobe_go_tls_write) {
+    emit_plaintext(LLM_SLO_HTTP_WRITE, GO_PARAM1(ctx), GO_PARAM2(ctx), (__s64)GO_PARAM3(ctx));
+    return 0;
+}
+
+/* func (c *Conn) Read(b []byte) (int, error) */
+SEC("uprobe/go_tls_read_enter")
+int BPF_UPROBE(uprobe_go_tls_read_enter) {
+    struct go_call_key key = go_call_key(ctx);
+    struct read_args args = {.conn = GO_PARAM1(ctx), .buf = GO_PARAM2(ctx)};
+    bpf_map_update_elem(&go_read_args, &key, &args, BPF_ANY);
+    return 0;
+}
+
+SEC("uprobe/go_tls_read_return")
+int BPF_UPROBE(uprobe_go_tls_read_return) {
+    struct go_call_key key = go_call_key(ctx);
+    struct read_args *args = bpf_map_lookup_elem(&go_read_args, &key);
+    if (!args)
+        return 0;
+    emit_plaintext(LLM_SLO_HTTP_READ, args->conn, args->buf, (__s64)GO_RET1(ctx));
+    bpf_map_delete_elem(&go_read_args, &key);
+    return 0;
+}
+
+/* func ReadResponse(r *bufio.Reader, req *Request) (*Response, error) */
+SEC("uprobe/go_http_response_enter")
+int BPF_UPROBE(uprobe_go_http_response_enter) {
+    struct go_call_key key = go_call_key(ctx);
+    __u64 req = GO_PARAM2(ctx);
+    if (req)
+        bpf_map_update_elem(&go_response_req, &key, &req, BPF_ANY);
+    return 0;
+}
+
+SEC("uprobe/go_http_response_return")
+int BPF_UPROBE(uprobe_go_http_response_return) {
+    struct go_call_key key = go_call_key(ctx);
+    __u64 *req = bpf_map_lookup_elem(&go_response_req, &key);
+    if (!req)
+        return 0;
+    __u64 reqp = *req;
+    bpf_map_delete_elem(&go_response_req, &key);
+
+    __u64 resp = GO_RET1(ctx);
+    if (!resp)
+        return 0;
+
+    __u64 url = 0, scheme_len = 0, host_ptr = 0, host_len = 0;
+    __s64 status = 0;
+    bpf_probe_read_user(&url, sizeof(url), (void *)(reqp + GO_REQUEST_URL_OFF));
+    if (!url)
+        return 0;
+    bpf_probe_read_user(&scheme_len, sizeof(scheme_len), (void *)(url + GO_URL_SCHEME_OFF + 8));
+    /* Only "http" (4) and "https" (5) reach the transport; https is
+     * already seen by the TLS probes. */
+    if (scheme_len == 5)
+        return 0;
+    bpf_probe_read_user(&host_ptr, sizeof(host_ptr), (void *)(url + GO_URL_HOST_OFF));
+    bpf_probe_read_user(&host_len, sizeof(host_len), (void *)(url + GO_URL_HOST_OFF + 8));
+    bpf_probe_read_user(&status, sizeof(status), (void *)(resp + GO_RESPONSE_STATUS_CODE_OFF));
+    if (status <= 0 || status > 999)
+        return 0;
+
+    struct llm_slo_http_event *event = http_event_reserve(LLM_SLO_HTTP_RESPONSE, 0, (__u32)host_len);
+    if (!event)
+        return 0;
+    event->status = (__u16)status;
+    __u32 n = host_len < LLM_SLO_HTTP_DATA_LEN ? (__u32)host_len : LLM_SLO_HTTP_DATA_LEN;
+    if (host_ptr && n > 0)
+        bpf_probe_read_user(event->data, n, (void *)host_ptr);
+    bpf_ringbuf_submit(event, 0);
+    return 0;
+}
@@ -0,0 +1,83 @@
+/*
+ * listen_overflow.bpf.c — Counts connections dropped because a listening
+ * socket's accept queue is full, the condition the kernel reports as
+ * ListenOverflows. A gateway that cannot accept() fast enough shows up
+ * here before clients see timeouts.
+ *
+ * Hook points:
+ *   kprobe/tcp_v4_syn_recv_sock — child socket creation on the final ACK
+ *   kprobe/tcp_v6_syn_recv_sock — same for IPv6 listeners
+ *
+ * Signal: listen_overflows_total (LLM_SLO_LISTEN_OVERFLOW)
+ *
+ * The tuple is oriented from the listener: source is the local address
+ * and port, destination the connecting client. errno_val is ENOBUFS.
+ */
+#include "vmlinux.h"
+#include "bpf_helpers.h"
+#include "llm_slo_event.h"
+
+char LICENSE[] SEC("license") = "GPL";
+
+#define LLM_SLO_ENOBUFS 105
+
+struct {
+    __uint(type, BPF_MAP_TYPE_RINGBUF);
+    __uint(max_entries, 256 * 1024);
+} llm_slo_events SEC(".maps");
+
+static __always_inline int check_overflow(struct sock *sk,
+                                          struct request_sock *req) {
+    __u32 backlog = 0;
+    __u32 max_backlog = 0;
+
+    BPF_CORE_READ_INTO(&backlog, sk, sk_ack_backlog);
+    BPF_CORE_READ_INTO(&max_backlog, sk, sk_max_ack_backlog);
+    /* Mirrors sk_acceptq_is_full(). */
+    if (backlog <= max_backlog)
+        return 0;
+
+    struct llm_slo_event *event =
+        bpf_ringbuf_reserve(&llm_slo_events, sizeof(*event), 0);
+    if (!event)
+        return 0;
+
+    __u16 src_port = 0;
+    __u16 dst_port = 0;
+    __u32 src_ip = 0;
+    __u32 dst_ip = 0;
+    BPF_CORE_READ_INTO(&src_port, req, __req_common.skc_num);
+    BPF_CORE_READ_INTO(&dst_port, req, __req_common.skc_dport);
+    BPF_CORE_READ_INTO(&src_ip, req, __req_common.skc_rcv_saddr);
+    BPF_CORE_READ_INTO(&dst_ip, req, __req_common.skc_daddr);
+
+    /* The final ACK is processed in softirq context; leave task identity
+     * empty and join by tuple. */
+    event->pid           = 0;
+    event->tid           = 0;
+    event->timestamp_ns  = bpf_ktime_get_ns();
+    event->signal_type   = LLM_SLO_LISTEN_OVERFLOW;
+    event->value_ns      = 1; /* count: 1 dropped connection */
+    event->conn_src_port = src_port;
+    event->conn_dst_port = __builtin_bswap16(dst_port);
+    event->conn_src_ip   = src_ip;
+    event->conn_dst_ip   = dst_ip;
+    event->errno_val     = LLM_SLO_ENOBUFS;
+    event->cgroup_id     = 0;
+    __builtin_memset(event->comm, 0, LLM_SLO_COMM_LEN);
+
+    bpf_ringbuf_submit(event, 0);
+    return 0;
+}
+
+SEC("kprobe/tcp_v4_syn_recv_sock")
+int BPF_KPROBE(kprobe_tcp_v4_syn_recv_sock, struct sock *sk,
+               struct sk_buff *skb, struct request_sock *req) {
+    return check_overflow(sk, req);
+}
+
+SEC("kprobe/tcp_v6_syn_recv_sock")
+int BPF_KPROBE(kprobe_tcp_v6_syn_recv_sock, struct sock *sk,
+               struct sk_buff *skb, struct request_sock *req) {
+    return check_overflow(sk, req);
+}
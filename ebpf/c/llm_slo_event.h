@@ -1,6 +1,10 @@
 #ifndef __LLM_SLO_EVENT_H
 #define __LLM_SLO_EVENT_H
 
+#define LLM_SLO_COMM_LEN 16
+#define LLM_SLO_DNS_QNAME_LEN 128
+#define LLM_SLO_DNS_ANSWERS_LEN 256
+
 /* Signal type identifiers for ring buffer event discrimination. */
 enum llm_slo_signal_type {
     LLM_SLO_DNS_LATENCY     = 1,
@@ -12,6 +16,13 @@ enum llm_slo_signal_type {
     LLM_SLO_MEM_RECLAIM     = 7,
     LLM_SLO_DISK_IO_LATENCY = 8,
     LLM_SLO_SYSCALL_LATENCY = 9,
+    LLM_SLO_OOM_KILL        = 10,
+    LLM_SLO_TCP_SRTT        = 11,
+    LLM_SLO_TCP_ZERO_WINDOW = 12,
+    LLM_SLO_TCP_RESET       = 13,
+    LLM_SLO_LISTEN_OVERFLOW = 14,
+    LLM_SLO_DNS_NXDOMAIN    = 15,
+    LLM_SLO_CONNTRACK_DROP  = 16,
 };
 
 /*
@@ -28,6 +39,13 @@ enum llm_slo_signal_type {
  *   conn_dst_port   — destination port (53=DNS, 443=TLS, etc.)
  *   conn_dst_ip     — destination IPv4 in network byte order
  *   errno_val       — kernel errno when applicable (connect failures)
+ *   cgroup_id       — cgroup v2 ID of the task; 0 when not known in context
+ *   comm            — task command name, NUL-padded
+ *   conn_src_ip     — source IPv4 in network byte order; 0 when unknown
+ *
+ * cgroup_id and comm were appended for the v1beta1 probe contract, and
+ * conn_src_ip for full-tuple TCP signals; the leading fields keep their
+ * offsets so older decoders stay compatible.
  */
 struct llm_slo_event {
     __u32 pid;
@@ -39,6 +57,161 @@ struct llm_slo_event {
     __u16 conn_dst_port;
     __u32 conn_dst_ip;
     __s32 errno_val;
+    __u64 cgroup_id;
+    char  comm[LLM_SLO_COMM_LEN];
+    __u32 conn_src_ip;
+} __attribute__((packed));
+
+/* llm_slo_dns_event.flags */
+#define LLM_SLO_DNS_F_PARSED  0x01 /* header and question were read */
+#define LLM_SLO_DNS_F_ANSWERS 0x02 /* answers holds the answer section */
+
+/*
+ * llm_slo_dns_event extends llm_slo_event for LLM_SLO_DNS_LATENCY and
+ * LLM_SLO_DNS_NXDOMAIN with fields parsed from the DNS response:
+ *
+ *   qtype  — question type (1=A, 28=AAAA, ...); 0 when the name did not
+ *            fit in qname
+ *   rcode  — response code (0=NOERROR, 2=SERVFAIL, 3=NXDOMAIN, ...)
+ *   flags  — LLM_SLO_DNS_F_* bits
+ *   qname  — question name in wire format (length-prefixed labels),
+ *            truncated to LLM_SLO_DNS_QNAME_LEN bytes
+ *   ancount     — answer record count from the header
+ *   answers_len — bytes of answers that hold response data
+ *   answers     — the answer section as sent, truncated to
+ *                 LLM_SLO_DNS_ANSWERS_LEN bytes; names in it are usually
+ *                 compression pointers and are not resolved
+ *
+ * conn_dst_ip holds the resolver address the response came from.
+ * Consumers that only know llm_slo_event ignore the trailing bytes.
+ */
+struct llm_slo_dns_event {
+    struct llm_slo_event base;
+    __u16 qtype;
+    __u8  rcode;
+    __u8  flags;
+    __u8  qname[LLM_SLO_DNS_QNAME_LEN];
+    __u16 ancount;
+    __u16 answers_len;
+    __u8  answers[LLM_SLO_DNS_ANSWERS_LEN];
+} __attribute__((packed));
+
+/*
+ * llm_slo_runq_event extends llm_slo_event for LLM_SLO_RUNQUEUE_DELAY with
+ * the task that was on the CPU when the waiting task was switched in
+ * (prev in sched_switch):
+ *
+ *   prev_cgroup_id — cgroup v2 ID of the outgoing task
+ *   prev_pid       — its thread ID; 0 when the CPU was idle
+ *   prev_comm      — its command name, NUL-padded
+ *
+ * base.cgroup_id is the waiting task's cgroup as of the last time it was
+ * switched out, or 0 if it has not run since the probe attached.
+ */
+struct llm_slo_runq_event {
+    struct llm_slo_event base;
+    __u64 prev_cgroup_id;
+    __u32 prev_pid;
+    char  prev_comm[LLM_SLO_COMM_LEN];
+} __attribute__((packed));
+
+/* llm_slo_blk_event.op */
+#define LLM_SLO_BLK_READ  1
+#define LLM_SLO_BLK_WRITE 2
+#define LLM_SLO_BLK_OTHER 3 /* flush, discard and other non-data requests */
+
+/*
+ * llm_slo_blk_event extends llm_slo_event for LLM_SLO_DISK_IO_LATENCY
+ * with the request that completed:
+ *
+ *   dev_major, dev_minor — block device numbers
+ *   bytes                — request size at issue
+ *   op                   — LLM_SLO_BLK_*
+ *
+ * The base pid, cgroup_id and comm identify the task that issued the
+ * request, not the one running at completion. Writeback and swap are
+ * issued by kernel threads outside pod cgroups.
+ */
+struct llm_slo_blk_event {
+    struct llm_slo_event base;
+    __u32 dev_major;
+    __u32 dev_minor;
+    __u32 bytes;
+    __u8  op;
+    __u8  pad[3];
 } __attribute__((packed));
 
+/*
+ * Record types share the signal_type discriminator but are not signals:
+ * userspace parses them into signal events. Values start at 64 so they
+ * never collide with enum llm_slo_signal_type.
+ */
+#define LLM_SLO_RECORD_HTTP 64
+
+#define LLM_SLO_HTTP_DATA_LEN 512
+
+/* llm_slo_http_event.kind */
+#define LLM_SLO_HTTP_READ     1 /* plaintext returned by a TLS read */
+#define LLM_SLO_HTTP_WRITE    2 /* plaintext passed to a TLS write */
+#define LLM_SLO_HTTP_RESPONSE 3 /* status of a Go net/http response */
+
+/*
+ * llm_slo_http_event carries HTTP traffic for provider status capture
+ * (signal_type LLM_SLO_RECORD_HTTP):
+ *
+ *   conn_id — SSL* or *tls.Conn of the connection; 0 for RESPONSE
+ *   len     — bytes the call transferred; data holds the first
+ *             min(len, LLM_SLO_HTTP_DATA_LEN) of them
+ *   status  — HTTP status code for RESPONSE records, else 0
+ *   kind    — LLM_SLO_HTTP_*
+ *   data    — plaintext for READ/WRITE; the request host for RESPONSE
+ */
+struct llm_slo_http_event {
+    struct llm_slo_event base;
+    __u64 conn_id;
+    __u32 len;
+    __u16 status;
+    __u8  kind;
+    __u8  pad;
+    __u8  data[LLM_SLO_HTTP_DATA_LEN];
+} __attribute__((packed));
+
+#define LLM_SLO_RECORD_SOCK_IO 65
+
+/* llm_slo_sock_io_event.kind */
+#define LLM_SLO_SOCK_READ  1 /* bytes received on a server socket */
+#define LLM_SLO_SOCK_WRITE 2 /* bytes sent on a server socket */
+#define LLM_SLO_SOCK_CLOSE 3 /* server socket closed */
+
+/*
+ * llm_slo_sock_io_event reports reads, writes and close on accepted
+ * sockets of configured server ports (signal_type LLM_SLO_RECORD_SOCK_IO).
+ * Userspace segments them into requests to time kernel TTFT:
+ *
+ *   sock_id — struct sock pointer, stable for the connection's lifetime
+ *   bytes   — bytes read or queued for sending; 0 for CLOSE
+ *   kind    — LLM_SLO_SOCK_*
+ *
+ * The base tuple is oriented from the server: source is the local
+ * address and port, destination the client.
+ */
+struct llm_slo_sock_io_event {
+    struct llm_slo_event base;
+    __u64 sock_id;
+    __u32 bytes;
+    __u8  kind;
+    __u8  pad[3];
+} __attribute__((packed));
+
+/*
+ * llm_slo_fill_task stamps identity from the current task. Only call it
+ * when the event's pid is the current task; probes that report another
+ * task (sched_switch next, sched_stat_wait) copy comm from the tracepoint
+ * and leave cgroup_id at 0.
+ */
+static __always_inline void llm_slo_fill_task(struct llm_slo_event *event) {
+    event->cgroup_id = bpf_get_current_cgroup_id();
+    bpf_get_current_comm(&event->comm, sizeof(event->comm));
+}
+
 #endif /* __LLM_SLO_EVENT_H */
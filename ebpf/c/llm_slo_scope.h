@@ -0,0 +1,35 @@
+#ifndef __LLM_SLO_SCOPE_H
+#define __LLM_SLO_SCOPE_H
+
+/*
+ * Optional cgroup scoping for expensive probes attached on demand.
+ *
+ * When the loader sets llm_slo_scoped to 1 before loading, a probe only
+ * records tasks whose cgroup v2 ID is a key of llm_slo_scope_cgroups.
+ * The deep-tracing controller rewrites the map while the probe is
+ * attached (pkg/deeptrace, collector.ProbeManager.SetScope). Left at 0,
+ * the probe records every task, as when the signal is always on.
+ *
+ * Probes check scope at entry, so out-of-scope calls cost one map lookup
+ * and never touch the probe's own state maps.
+ */
+
+#define LLM_SLO_SCOPE_MAX_CGROUPS 1024
+
+const volatile __u8 llm_slo_scoped = 0;
+
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __uint(max_entries, LLM_SLO_SCOPE_MAX_CGROUPS);
+    __type(key, __u64);  /* cgroup v2 ID */
+    __type(value, __u8); /* unused */
+} llm_slo_scope_cgroups SEC(".maps");
+
+static __always_inline int llm_slo_in_scope(void) {
+    if (!llm_slo_scoped)
+        return 1;
+    __u64 cgroup_id = bpf_get_current_cgroup_id();
+    return bpf_map_lookup_elem(&llm_slo_scope_cgroups, &cgroup_id) != NULL;
+}
+
+#endif /* __LLM_SLO_SCOPE_H */
@@ -64,7 +64,9 @@ int handle_reclaim_end(void *ctx) {
     event->conn_src_port = 0;
     event->conn_dst_port = 0;
     event->conn_dst_ip   = 0;
+    event->conn_src_ip   = 0;
     event->errno_val     = 0;
+    llm_slo_fill_task(event);
 
     bpf_ringbuf_submit(event, 0);
     return 0;
@@ -0,0 +1,49 @@
+/*
+ * oom_kill.bpf.c — Counts OOM kills by emitting one event per victim the
+ * kernel OOM killer selects. Cgroup-scoped kills (memory.max breaches) and
+ * global OOMs both pass through this tracepoint.
+ *
+ * Hook points:
+ *   tracepoint/oom/mark_victim — fires once per selected victim
+ *
+ * Signal: oom_kills_total (LLM_SLO_OOM_KILL)
+ */
+#include "vmlinux.h"
+#include "bpf_helpers.h"
+#include "llm_slo_event.h"
+
+char LICENSE[] SEC("license") = "GPL";
+
+struct {
+    __uint(type, BPF_MAP_TYPE_RINGBUF);
+    __uint(max_entries, 64 * 1024);
+} llm_slo_events SEC(".maps");
+
+SEC("tracepoint/oom/mark_victim")
+int handle_mark_victim(struct trace_event_raw_mark_victim *ctx) {
+    struct llm_slo_event *event =
+        bpf_ringbuf_reserve(&llm_slo_events, sizeof(*event), 0);
+    if (!event)
+        return 0;
+
+    /* pid is the victim's tgid; the victim's threads are not individually
+     * reported, so tid mirrors pid. */
+    event->pid           = ctx->pid;
+    event->tid           = ctx->pid;
+    event->timestamp_ns  = bpf_ktime_get_ns();
+    event->signal_type   = LLM_SLO_OOM_KILL;
+    event->value_ns      = 1;
+    event->conn_src_port = 0;
+    event->conn_dst_port = 0;
+    event->conn_dst_ip   = 0;
+    event->conn_src_ip   = 0;
+    event->errno_val     = 0;
+    /* current is the allocating task that triggered the OOM, not the
+     * victim, and mark_victim only carries comm on 6.8+ kernels. Leave the
+     * identity fields empty; userspace resolves the pod from the pid. */
+    event->cgroup_id     = 0;
+    __builtin_memset(event->comm, 0, LLM_SLO_COMM_LEN);
+
+    bpf_ringbuf_submit(event, 0);
+    return 0;
+}
@@ -0,0 +1,126 @@
+/*
+ * request_timing.bpf.c — Reports socket reads, writes and close on the
+ * accepted connections of configured server ports, so request timing
+ * (kernel TTFT and full latency) can be derived for LLM servers that carry
+ * no OTel instrumentation, e.g. vLLM or llama.cpp pods.
+ *
+ * Hook points:
+ *   kprobe+kretprobe/tcp_recvmsg — bytes returned to the server
+ *   kprobe/tcp_sendmsg           — response bytes queued by the server
+ *   kprobe/tcp_close             — connection closed by the server
+ *
+ * Record: LLM_SLO_RECORD_SOCK_IO (struct llm_slo_sock_io_event)
+ *
+ * server_ports holds the local ports to watch in host byte order; the
+ * loader fills it from toolkit config (request_timing.server_ports).
+ * Sockets on other ports return before touching the ring buffer. Request
+ * boundaries are found in userspace (pkg/collector/request_timing.go):
+ * a read after response bytes starts the next request on a connection.
+ */
+#include "vmlinux.h"
+#include "bpf_helpers.h"
+#include "llm_slo_event.h"
+
+char LICENSE[] SEC("license") = "GPL";
+
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __uint(max_entries, 64);
+    __type(key, __u16);  /* local port, host byte order */
+    __type(value, __u8);
+} server_ports SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __uint(max_entries, 8192);
+    __type(key, __u64);   /* pid_tgid */
+    __type(value, __u64); /* struct sock * */
+} recv_inflight SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_RINGBUF);
+    __uint(max_entries, 512 * 1024);
+} llm_slo_events SEC(".maps");
+
+static __always_inline int watched(struct sock *sk) {
+    __u16 port = 0;
+    BPF_CORE_READ_INTO(&port, sk, __sk_common.skc_num);
+    return bpf_map_lookup_elem(&server_ports, &port) != 0;
+}
+
+static __always_inline void emit_sock_io(struct sock *sk, __u8 kind, __u32 bytes) {
+    struct llm_slo_sock_io_event *event =
+        bpf_ringbuf_reserve(&llm_slo_events, sizeof(*event), 0);
+    if (!event)
+        return;
+
+    __u16 src_port = 0;
+    __u16 dst_port = 0;
+    __u32 src_ip = 0;
+    __u32 dst_ip = 0;
+    BPF_CORE_READ_INTO(&src_port, sk, __sk_common.skc_num);
+    BPF_CORE_READ_INTO(&dst_port, sk, __sk_common.skc_dport);
+    BPF_CORE_READ_INTO(&src_ip, sk, __sk_common.skc_rcv_saddr);
+    BPF_CORE_READ_INTO(&dst_ip, sk, __sk_common.skc_daddr);
+
+    __u64 pid_tgid = bpf_get_current_pid_tgid();
+    event->base.pid           = pid_tgid >> 32;
+    event->base.tid           = (__u32)pid_tgid;
+    event->base.timestamp_ns  = bpf_ktime_get_ns();
+    event->base.signal_type   = LLM_SLO_RECORD_SOCK_IO;
+    event->base.value_ns      = 0;
+    event->base.conn_src_port = src_port;
+    event->base.conn_dst_port = __builtin_bswap16(dst_port);
+    event->base.conn_src_ip   = src_ip;
+    event->base.conn_dst_ip   = dst_ip;
+    event->base.errno_val     = 0;
+    llm_slo_fill_task(&event->base);
+
+    event->sock_id = (__u64)sk;
+    event->bytes   = bytes;
+    event->kind    = kind;
+    __builtin_memset(event->pad, 0, sizeof(event->pad));
+
+    bpf_ringbuf_submit(event, 0);
+}
+
+/* tcp_recvmsg blocks until data arrives, so the read is reported on
+ * return, when the request bytes are handed to the server. */
+SEC("kprobe/tcp_recvmsg")
+int BPF_KPROBE(kprobe_tcp_recvmsg, struct sock *sk) {
+    if (!watched(sk))
+        return 0;
+    __u64 pid_tgid = bpf_get_current_pid_tgid();
+    __u64 skp = (__u64)sk;
+    bpf_map_update_elem(&recv_inflight, &pid_tgid, &skp, BPF_ANY);
+    return 0;
+}
+
+SEC("kretprobe/tcp_recvmsg")
+int BPF_KRETPROBE(kretprobe_tcp_recvmsg, int ret) {
+    __u64 pid_tgid = bpf_get_current_pid_tgid();
+    __u64 *skp = bpf_map_lookup_elem(&recv_inflight, &pid_tgid);
+    if (!skp)
+        return 0;
+    struct sock *sk = (struct sock *)*skp;
+    bpf_map_delete_elem(&recv_inflight, &pid_tgid);
+    if (ret > 0)
+        emit_sock_io(sk, LLM_SLO_SOCK_READ, (__u32)ret);
+    return 0;
+}
+
+SEC("kprobe/tcp_sendmsg")
+int BPF_KPROBE(kprobe_tcp_sendmsg, struct sock *sk, struct msghdr *msg, size_t size) {
+    if (size == 0 || !watched(sk))
+        return 0;
+    emit_sock_io(sk, LLM_SLO_SOCK_WRITE, (__u32)size);
+    return 0;
+}
+
+SEC("kprobe/tcp_close")
+int BPF_KPROBE(kprobe_tcp_close, struct sock *sk) {
+    if (!watched(sk))
+        return 0;
+    emit_sock_io(sk, LLM_SLO_SOCK_CLOSE, 0);
+    return 0;
+}
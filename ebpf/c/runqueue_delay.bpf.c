@@ -8,7 +8,14 @@
  *   tracepoint/sched/sched_wakeup_new   — records enqueue for new tasks
  *   tracepoint/sched/sched_switch       — computes delta on context switch
  *
- * Signal: runqueue_delay_ms (LLM_SLO_RUNQUEUE_DELAY)
+ * Signal: runqueue_delay_ms (LLM_SLO_RUNQUEUE_DELAY, struct
+ * llm_slo_runq_event)
+ *
+ * Each event also names the task that held the CPU until the waiting task
+ * ran (prev in sched_switch), so userspace can tell which neighbour the
+ * time was stolen by. sched_switch runs in the outgoing task's context, so
+ * its cgroup is known directly; the incoming task's cgroup is remembered
+ * from when it was last switched out.
  */
 #include "vmlinux.h"
 #include "bpf_helpers.h"
@@ -24,6 +31,14 @@ struct {
     __type(value, __u64); /* wakeup timestamp_ns */
 } runq_enqueue SEC(".maps");
 
+/* cgroup of each task as of its last switch-out. */
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __uint(max_entries, 32768);
+    __type(key, __u32);   /* pid */
+    __type(value, __u64); /* cgroup_id */
+} task_cgroup SEC(".maps");
+
 struct {
     __uint(type, BPF_MAP_TYPE_RINGBUF);
     __uint(max_entries, 256 * 1024);
@@ -47,6 +62,12 @@ int handle_sched_wakeup_new(struct trace_event_raw_sched_wakeup_template *ctx) {
 
 SEC("tracepoint/sched/sched_switch")
 int handle_sched_switch(struct trace_event_raw_sched_switch *ctx) {
+    /* current is still the outgoing task (prev_pid). */
+    __u32 prev_pid = ctx->prev_pid;
+    __u64 prev_cgroup = bpf_get_current_cgroup_id();
+    if (prev_pid)
+        bpf_map_update_elem(&task_cgroup, &prev_pid, &prev_cgroup, BPF_ANY);
+
     /* The task being switched IN is next_pid. */
     __u32 pid = ctx->next_pid;
     __u64 *enqueue_ts = bpf_map_lookup_elem(&runq_enqueue, &pid);
@@ -62,22 +83,31 @@ int handle_sched_switch(struct trace_event_raw_sched_switch *ctx) {
     if (delta_ns < 100000)
         return 0;
 
-    struct llm_slo_event *event =
+    struct llm_slo_runq_event *event =
         bpf_ringbuf_reserve(&llm_slo_events, sizeof(*event), 0);
     if (!event)
         return 0;
 
     __u64 pid_tgid = bpf_get_current_pid_tgid();
+    __u64 *next_cgroup = bpf_map_lookup_elem(&task_cgroup, &pid);
+
+    event->base.pid           = pid;
+    event->base.tid           = (__u32)pid_tgid;
+    event->base.timestamp_ns  = now;
+    event->base.signal_type   = LLM_SLO_RUNQUEUE_DELAY;
+    event->base.value_ns      = delta_ns;
+    event->base.conn_src_port = 0;
+    event->base.conn_dst_port = 0;
+    event->base.conn_dst_ip   = 0;
+    event->base.conn_src_ip   = 0;
+    event->base.errno_val     = 0;
+    /* current is the outgoing task; identify the incoming one instead. */
+    event->base.cgroup_id     = next_cgroup ? *next_cgroup : 0;
+    __builtin_memcpy(event->base.comm, ctx->next_comm, LLM_SLO_COMM_LEN);
 
-    event->pid           = pid;
-    event->tid           = (__u32)pid_tgid;
-    event->timestamp_ns  = now;
-    event->signal_type   = LLM_SLO_RUNQUEUE_DELAY;
-    event->value_ns      = delta_ns;
-    event->conn_src_port = 0;
-    event->conn_dst_port = 0;
-    event->conn_dst_ip   = 0;
-    event->errno_val     = 0;
+    event->prev_cgroup_id = prev_pid ? prev_cgroup : 0;
+    event->prev_pid       = prev_pid;
+    __builtin_memcpy(event->prev_comm, ctx->prev_comm, LLM_SLO_COMM_LEN);
 
     bpf_ringbuf_submit(event, 0);
     return 0;
@@ -11,10 +11,14 @@
  *   kretprobe/ksys_write — computes delta, emits if above threshold
  *
  * Signal: syscall_latency_ms (LLM_SLO_SYSCALL_LATENCY)
+ *
+ * Every read and write is timed, so this probe is usually attached only
+ * while an SLO burns, scoped to the workload's cgroups (llm_slo_scope.h).
  */
 #include "vmlinux.h"
 #include "bpf_helpers.h"
 #include "llm_slo_event.h"
+#include "llm_slo_scope.h"
 
 char LICENSE[] SEC("license") = "GPL";
 
@@ -33,6 +37,8 @@ struct {
 } llm_slo_events SEC(".maps");
 
 static __always_inline int handle_entry(void) {
+    if (!llm_slo_in_scope())
+        return 0;
     __u64 pid_tgid = bpf_get_current_pid_tgid();
     __u64 ts = bpf_ktime_get_ns();
     bpf_map_update_elem(&syscall_start, &pid_tgid, &ts, BPF_ANY);
@@ -65,7 +71,9 @@ static __always_inline int handle_return(void *ctx) {
     event->conn_src_port = 0;
     event->conn_dst_port = 0;
     event->conn_dst_ip   = 0;
+    event->conn_src_ip   = 0;
     event->errno_val     = 0;
+    llm_slo_fill_task(event);
 
     bpf_ringbuf_submit(event, 0);
     return 0;
@@ -0,0 +1,72 @@
+/*
+ * tcp_reset.bpf.c — Counts TCP resets sent and received, e.g. a provider
+ * or load balancer tearing down an in-flight stream. Each reset emits a
+ * ring buffer event with the IPv4 connection tuple.
+ *
+ * Hook points:
+ *   tracepoint/tcp/tcp_send_reset    — this host sent a RST
+ *   tracepoint/tcp/tcp_receive_reset — the peer sent a RST
+ *
+ * Signal: tcp_resets_total (LLM_SLO_TCP_RESET)
+ *
+ * errno_val carries the direction as the error the local socket sees:
+ * ECONNRESET for received resets, ECONNABORTED for sent resets.
+ */
+#include "vmlinux.h"
+#include "bpf_helpers.h"
+#include "llm_slo_event.h"
+
+char LICENSE[] SEC("license") = "GPL";
+
+#define LLM_SLO_AF_INET      2
+#define LLM_SLO_ECONNABORTED 103
+#define LLM_SLO_ECONNRESET   104
+
+struct {
+    __uint(type, BPF_MAP_TYPE_RINGBUF);
+    __uint(max_entries, 256 * 1024);
+} llm_slo_events SEC(".maps");
+
+static __always_inline void emit_reset(__u16 family, __u16 sport, __u16 dport,
+                                       const __u8 *saddr, const __u8 *daddr,
+                                       __s32 errno_val) {
+    struct llm_slo_event *event =
+        bpf_ringbuf_reserve(&llm_slo_events, sizeof(*event), 0);
+    if (!event)
+        return;
+
+    /* Resets are mostly handled in softirq context, where current is
+     * unrelated to the socket owner; userspace joins by tuple. */
+    event->pid           = 0;
+    event->tid           = 0;
+    event->timestamp_ns  = bpf_ktime_get_ns();
+    event->signal_type   = LLM_SLO_TCP_RESET;
+    event->value_ns      = 1; /* count: 1 reset */
+    event->conn_src_port = sport;
+    event->conn_dst_port = dport;
+    event->conn_src_ip   = 0;
+    event->conn_dst_ip   = 0;
+    if (family == LLM_SLO_AF_INET) {
+        __builtin_memcpy(&event->conn_src_ip, saddr, sizeof(__u32));
+        __builtin_memcpy(&event->conn_dst_ip, daddr, sizeof(__u32));
+    }
+    event->errno_val     = errno_val;
+    event->cgroup_id     = 0;
+    __builtin_memset(event->comm, 0, LLM_SLO_COMM_LEN);
+
+    bpf_ringbuf_submit(event, 0);
+}
+
+SEC("tracepoint/tcp/tcp_send_reset")
+int handle_tcp_send_reset(struct trace_event_raw_tcp_event_sk_skb *ctx) {
+    emit_reset(ctx->family, ctx->sport, ctx->dport, ctx->saddr, ctx->daddr,
+               LLM_SLO_ECONNABORTED);
+    return 0;
+}
+
+SEC("tracepoint/tcp/tcp_receive_reset")
+int handle_tcp_receive_reset(struct trace_event_raw_tcp_event_sk *ctx) {
+    emit_reset(ctx->family, ctx->sport, ctx->dport, ctx->saddr, ctx->daddr,
+               LLM_SLO_ECONNRESET);
+    return 0;
+}
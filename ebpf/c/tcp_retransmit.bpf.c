@@ -36,7 +36,9 @@ int handle_tcp_retransmit(struct trace_event_raw_tcp_retransmit_skb *ctx) {
     event->conn_src_port = ctx->sport;
     event->conn_dst_port = ctx->dport;
     event->conn_dst_ip   = 0; /* filled from skb if needed */
+    event->conn_src_ip   = 0;
     event->errno_val     = 0;
+    llm_slo_fill_task(event);
 
     bpf_ringbuf_submit(event, 0);
     return 0;
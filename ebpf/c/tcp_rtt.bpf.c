@@ -0,0 +1,105 @@
+/*
+ * tcp_rtt.bpf.c — Samples per-connection smoothed RTT and detects
+ * zero-window stalls from the tcp:tcp_probe tracepoint, which fires for
+ * each segment processed by tcp_rcv_established. Both signals carry the
+ * full IPv4 connection tuple so they join spans at the pod_conn tier.
+ *
+ * Hook point:
+ *   tracepoint/tcp/tcp_probe — srtt, snd_wnd and rcv_wnd per segment
+ *
+ * Signals:
+ *   tcp_srtt_ms           (LLM_SLO_TCP_SRTT)        — at most one sample
+ *                                                     per connection per
+ *                                                     100ms
+ *   tcp_zero_window_total (LLM_SLO_TCP_ZERO_WINDOW) — one event each time
+ *                                                     either side starts
+ *                                                     advertising a zero
+ *                                                     receive window
+ */
+#include "vmlinux.h"
+#include "bpf_helpers.h"
+#include "llm_slo_event.h"
+
+char LICENSE[] SEC("license") = "GPL";
+
+#define LLM_SLO_AF_INET 2
+#define SRTT_SAMPLE_INTERVAL_NS 100000000ULL
+
+/* Per-connection sampling and stall state, keyed by socket address. */
+struct conn_state {
+    __u64 last_srtt_ns;
+    __u8  zero_window;
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __uint(max_entries, 16384);
+    __type(key, __u64);               /* struct sock * */
+    __type(value, struct conn_state);
+} tcp_conn_state SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_RINGBUF);
+    __uint(max_entries, 256 * 1024);
+} llm_slo_events SEC(".maps");
+
+static __always_inline void emit(struct trace_event_raw_tcp_probe *ctx,
+                                 __u32 signal_type, __u64 value, __u64 now) {
+    struct llm_slo_event *event =
+        bpf_ringbuf_reserve(&llm_slo_events, sizeof(*event), 0);
+    if (!event)
+        return;
+
+    /* tcp_probe usually runs in softirq context, where current is
+     * unrelated to the socket owner. Leave task identity empty; userspace
+     * joins these events by connection tuple instead. */
+    event->pid           = 0;
+    event->tid           = 0;
+    event->timestamp_ns  = now;
+    event->signal_type   = signal_type;
+    event->value_ns      = value;
+    event->conn_src_port = ctx->sport;
+    event->conn_dst_port = ctx->dport;
+    /* saddr/daddr hold a struct sockaddr_in for AF_INET; the address
+     * follows the 2-byte family and 2-byte port. */
+    __builtin_memcpy(&event->conn_src_ip, &ctx->saddr[4], sizeof(__u32));
+    __builtin_memcpy(&event->conn_dst_ip, &ctx->daddr[4], sizeof(__u32));
+    event->errno_val     = 0;
+    event->cgroup_id     = 0;
+    __builtin_memset(event->comm, 0, LLM_SLO_COMM_LEN);
+
+    bpf_ringbuf_submit(event, 0);
+}
+
+SEC("tracepoint/tcp/tcp_probe")
+int handle_tcp_probe(struct trace_event_raw_tcp_probe *ctx) {
+    if (ctx->family != LLM_SLO_AF_INET)
+        return 0;
+
+    __u64 key = (__u64)ctx->skaddr;
+    struct conn_state *st = bpf_map_lookup_elem(&tcp_conn_state, &key);
+    if (!st) {
+        struct conn_state fresh = {};
+        bpf_map_update_elem(&tcp_conn_state, &key, &fresh, BPF_NOEXIST);
+        st = bpf_map_lookup_elem(&tcp_conn_state, &key);
+        if (!st)
+            return 0;
+    }
+
+    __u64 now = bpf_ktime_get_ns();
+
+    /* snd_wnd == 0: the peer stopped reading. rcv_wnd == 0: this socket's
+     * reader stopped draining, e.g. a stalled token stream consumer. */
+    __u8 stalled = ctx->snd_wnd == 0 || ctx->rcv_wnd == 0;
+    if (stalled && !st->zero_window)
+        emit(ctx, LLM_SLO_TCP_ZERO_WINDOW, 1, now);
+    st->zero_window = stalled;
+
+    /* srtt is reported in microseconds; emit nanoseconds like the other
+     * latency probes. */
+    if (ctx->srtt > 0 && now - st->last_srtt_ns >= SRTT_SAMPLE_INTERVAL_NS) {
+        st->last_srtt_ns = now;
+        emit(ctx, LLM_SLO_TCP_SRTT, (__u64)ctx->srtt * 1000, now);
+    }
+    return 0;
+}
@@ -9,13 +9,18 @@
  *
  * Signal: tls_handshake_ms (LLM_SLO_TLS_HANDSHAKE)
  *
- * Note: Uprobe attachment requires the path to libssl.so to be provided
- * at load time by the probe manager. The BPF program defines the probe
- * logic; the Go side handles finding and attaching to the correct library.
+ * Note: The BPF program defines the probe logic; the Go side
+ * (collector.TLSUprobeAttacher) finds every object defining
+ * SSL_do_handshake, covering both libssl.so and BoringSSL copies linked
+ * into executables or extension modules, and attaches to each. Go's
+ * crypto/tls is handled by go_tls_handshake.bpf.c.
+ *
+ * Handshakes may be scoped to a set of cgroups (llm_slo_scope.h).
  */
 #include "vmlinux.h"
 #include "bpf_helpers.h"
 #include "llm_slo_event.h"
+#include "llm_slo_scope.h"
 
 char LICENSE[] SEC("license") = "GPL";
 
@@ -33,6 +38,8 @@ struct {
 
 SEC("uprobe/SSL_do_handshake")
 int BPF_UPROBE(uprobe_ssl_do_handshake) {
+    if (!llm_slo_in_scope())
+        return 0;
     __u64 pid_tgid = bpf_get_current_pid_tgid();
     __u64 ts = bpf_ktime_get_ns();
     bpf_map_update_elem(&tls_start, &pid_tgid, &ts, BPF_ANY);
@@ -63,7 +70,9 @@ int BPF_URETPROBE(uretprobe_ssl_do_handshake, int ret) {
     event->conn_src_port = 0;
     event->conn_dst_port = 443; /* conventional TLS port */
     event->conn_dst_ip   = 0;
+    event->conn_src_ip   = 0;
     event->errno_val     = ret <= 0 ? 1 : 0; /* SSL_do_handshake: 1=success */
+    llm_slo_fill_task(event);
 
     bpf_ringbuf_submit(event, 0);
     bpf_map_delete_elem(&tls_start, &pid_tgid);
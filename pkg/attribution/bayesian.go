@@ -2,21 +2,27 @@ package attribution
 
 import (
 	"math"
+	"slices"
 	"sort"
 
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/baseline"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/dependency"
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/semconv"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
 )
 
 // FaultDomain enumerates the recognized fault domains for Bayesian attribution.
 const (
-	DomainNetworkDNS       = "network_dns"
-	DomainNetworkEgress    = "network_egress"
-	DomainCPUThrottle      = "cpu_throttle"
-	DomainMemoryPressure   = "memory_pressure"
-	DomainProviderThrottle = "provider_throttle"
-	DomainProviderError    = "provider_error"
-	DomainRetrievalBackend = "retrieval_backend"
-	DomainUnknown          = "unknown"
+	DomainNetworkDNS        = signalspec.DomainNetworkDNS
+	DomainNetworkEgress     = signalspec.DomainNetworkEgress
+	DomainCPUThrottle       = signalspec.DomainCPUThrottle
+	DomainMemoryPressure    = signalspec.DomainMemoryPressure
+	DomainProviderThrottle  = signalspec.DomainProviderThrottle
+	DomainProviderError     = signalspec.DomainProviderError
+	DomainRetrievalBackend  = signalspec.DomainRetrievalBackend
+	DomainGatewaySaturation = signalspec.DomainGatewaySaturation
+	DomainUnknown           = signalspec.DomainUnknown
 )
 
 // AllDomains returns the full set of fault domains used by the Bayesian engine.
@@ -29,6 +35,7 @@ func AllDomains() []string {
 		DomainProviderThrottle,
 		DomainProviderError,
 		DomainRetrievalBackend,
+		DomainGatewaySaturation,
 		DomainUnknown,
 	}
 }
@@ -39,8 +46,30 @@ func AllDomains() []string {
 type BayesianAttributor struct {
 	Priors      map[string]float64
 	Likelihoods map[string]map[string]float64 // signal -> domain -> P(signal_elevated|domain)
+	// Thresholds decides when a signal counts as elevated; nil uses the
+	// registry warning cutoffs.
+	Thresholds *signalspec.ThresholdTable
+	// Elevation selects static thresholds or baseline z-scores as evidence.
+	// Z-score mode falls back to Thresholds while a series warms up.
+	Elevation string
+	// ZThreshold is the z-score above which a signal is elevated; zero
+	// uses baseline.DefaultZThreshold.
+	ZThreshold float64
+	// Baselines holds per-workload baselines for z-score mode. Callers feed
+	// it; see Observe.
+	Baselines *baseline.Tracker
+	// Dependencies resolves FaultSample.Dependencies to domain hints; nil
+	// ignores them.
+	Dependencies *dependency.Catalog
 }
 
+// dependencyCredit is how far an elevated signal's dependency hint raises
+// P(signal elevated | domain) for the hinted domains, as a noisy-OR with the
+// row's own likelihood so hinted domains keep their relative order. The
+// other destination domains and unknown are capped at 1 - dependencyCredit:
+// slow connects to a model provider say little about the vector DB.
+const dependencyCredit = 0.9
+
 // NewBayesianAttributor returns an attributor with default uniform priors
 // and likelihoods derived from the signal generator fault profiles.
 func NewBayesianAttributor() *BayesianAttributor {
@@ -61,165 +90,64 @@ func DefaultPriors() map[string]float64 {
 	return priors
 }
 
-// DefaultLikelihoods returns P(signal_elevated|fault_domain) derived from
-// the generator.go profileForFault thresholds. High values mean the signal
-// is very likely elevated under that fault domain.
+// DefaultLikelihoods returns P(signal_elevated|fault_domain) from the
+// signalspec registry. High values mean the signal is very likely elevated
+// under that fault domain. The returned maps are copies.
 func DefaultLikelihoods() map[string]map[string]float64 {
-	return map[string]map[string]float64{
-		"dns_latency_ms": {
-			DomainNetworkDNS:       0.95,
-			DomainNetworkEgress:    0.70,
-			DomainCPUThrottle:      0.10,
-			DomainMemoryPressure:   0.10,
-			DomainProviderThrottle: 0.10,
-			DomainProviderError:    0.10,
-			DomainRetrievalBackend: 0.15,
-			DomainUnknown:          0.10,
-		},
-		"tcp_retransmits_total": {
-			DomainNetworkDNS:       0.15,
-			DomainNetworkEgress:    0.90,
-			DomainCPUThrottle:      0.10,
-			DomainMemoryPressure:   0.10,
-			DomainProviderThrottle: 0.10,
-			DomainProviderError:    0.15,
-			DomainRetrievalBackend: 0.10,
-			DomainUnknown:          0.10,
-		},
-		"runqueue_delay_ms": {
-			DomainNetworkDNS:       0.10,
-			DomainNetworkEgress:    0.10,
-			DomainCPUThrottle:      0.90,
-			DomainMemoryPressure:   0.60,
-			DomainProviderThrottle: 0.10,
-			DomainProviderError:    0.10,
-			DomainRetrievalBackend: 0.10,
-			DomainUnknown:          0.10,
-		},
-		"connect_latency_ms": {
-			DomainNetworkDNS:       0.50,
-			DomainNetworkEgress:    0.85,
-			DomainCPUThrottle:      0.10,
-			DomainMemoryPressure:   0.10,
-			DomainProviderThrottle: 0.75,
-			DomainProviderError:    0.40,
-			DomainRetrievalBackend: 0.30,
-			DomainUnknown:          0.10,
-		},
-		"tls_handshake_ms": {
-			DomainNetworkDNS:       0.10,
-			DomainNetworkEgress:    0.30,
-			DomainCPUThrottle:      0.10,
-			DomainMemoryPressure:   0.10,
-			DomainProviderThrottle: 0.80,
-			DomainProviderError:    0.50,
-			DomainRetrievalBackend: 0.20,
-			DomainUnknown:          0.10,
-		},
-		"cpu_steal_pct": {
-			DomainNetworkDNS:       0.10,
-			DomainNetworkEgress:    0.10,
-			DomainCPUThrottle:      0.90,
-			DomainMemoryPressure:   0.20,
-			DomainProviderThrottle: 0.10,
-			DomainProviderError:    0.10,
-			DomainRetrievalBackend: 0.10,
-			DomainUnknown:          0.10,
-		},
-		"cfs_throttled_ms": {
-			DomainNetworkDNS:       0.10,
-			DomainNetworkEgress:    0.10,
-			DomainCPUThrottle:      0.85,
-			DomainMemoryPressure:   0.75,
-			DomainProviderThrottle: 0.10,
-			DomainProviderError:    0.10,
-			DomainRetrievalBackend: 0.10,
-			DomainUnknown:          0.10,
-		},
-		"mem_reclaim_latency_ms": {
-			DomainNetworkDNS:       0.05,
-			DomainNetworkEgress:    0.05,
-			DomainCPUThrottle:      0.15,
-			DomainMemoryPressure:   0.95,
-			DomainProviderThrottle: 0.05,
-			DomainProviderError:    0.05,
-			DomainRetrievalBackend: 0.05,
-			DomainUnknown:          0.05,
-		},
-		"disk_io_latency_ms": {
-			DomainNetworkDNS:       0.05,
-			DomainNetworkEgress:    0.05,
-			DomainCPUThrottle:      0.10,
-			DomainMemoryPressure:   0.85,
-			DomainProviderThrottle: 0.05,
-			DomainProviderError:    0.05,
-			DomainRetrievalBackend: 0.30,
-			DomainUnknown:          0.05,
-		},
-		"syscall_latency_ms": {
-			DomainNetworkDNS:       0.10,
-			DomainNetworkEgress:    0.20,
-			DomainCPUThrottle:      0.15,
-			DomainMemoryPressure:   0.10,
-			DomainProviderThrottle: 0.90,
-			DomainProviderError:    0.60,
-			DomainRetrievalBackend: 0.40,
-			DomainUnknown:          0.10,
-		},
-		"connect_errors_total": {
-			DomainNetworkDNS:       0.10,
-			DomainNetworkEgress:    0.80,
-			DomainCPUThrottle:      0.05,
-			DomainMemoryPressure:   0.05,
-			DomainProviderThrottle: 0.60,
-			DomainProviderError:    0.85,
-			DomainRetrievalBackend: 0.15,
-			DomainUnknown:          0.10,
-		},
-		"tls_handshake_fail_total": {
-			DomainNetworkDNS:       0.05,
-			DomainNetworkEgress:    0.70,
-			DomainCPUThrottle:      0.05,
-			DomainMemoryPressure:   0.05,
-			DomainProviderThrottle: 0.30,
-			DomainProviderError:    0.60,
-			DomainRetrievalBackend: 0.10,
-			DomainUnknown:          0.05,
-		},
+	out := make(map[string]map[string]float64)
+	for _, desc := range signalspec.All() {
+		if len(desc.Likelihoods) == 0 {
+			continue
+		}
+		row := make(map[string]float64, len(desc.Likelihoods))
+		for domain, p := range desc.Likelihoods {
+			row[domain] = p
+		}
+		out[desc.Name] = row
 	}
+	return out
 }
 
-// signalThresholds maps signal names to their "elevated" thresholds.
-// A signal is considered evidence when its value exceeds this threshold.
-var signalThresholds = map[string]float64{
-	"dns_latency_ms":           40,
-	"tcp_retransmits_total":    2,
-	"runqueue_delay_ms":        10,
-	"connect_latency_ms":       80,
-	"tls_handshake_ms":         60,
-	"cpu_steal_pct":            2,
-	"cfs_throttled_ms":         40,
-	"mem_reclaim_latency_ms":   5,
-	"disk_io_latency_ms":       10,
-	"syscall_latency_ms":       50,
-	"connect_errors_total":     1,
-	"tls_handshake_fail_total": 1,
-}
+// Sub-causes that refine a domain when its evidence tells them apart.
+const (
+	SubCauseSearchPathAmplification = "search-path amplification"
+	SubCauseResolverLatency         = "resolver latency"
+	SubCauseConntrackExhaustion     = "conntrack exhaustion"
+)
+
+// ConnectionReuseRegression is the value of the
+// llm.ebpf.net.connection_reuse_regression evidence item, added when
+// connection churn is elevated: a client that lost keep-alive pays DNS,
+// connect and TLS on every request, whichever domain those point at.
+const ConnectionReuseRegression = "connection reuse regression"
 
 // Posterior holds one domain's posterior probability.
 type Posterior struct {
 	Domain    string
 	Posterior float64
 	Evidence  []string
+	// SubCause names the mechanism within the domain, when known.
+	SubCause string
 }
 
 // Attribute computes Bayesian posteriors over fault domains given observed signals.
 // Returns posteriors sorted by probability descending.
 func (b *BayesianAttributor) Attribute(signals map[string]float64) []Posterior {
+	return b.AttributeFor(signalspec.Workload{}, signals)
+}
+
+// AttributeFor is Attribute with elevation thresholds resolved for one workload.
+func (b *BayesianAttributor) AttributeFor(workload signalspec.Workload, signals map[string]float64) []Posterior {
+	return b.attribute(workload, signals, nil)
+}
+
+// attribute is AttributeFor with hints, the domain hints of the
+// dependencies behind each signal.
+func (b *BayesianAttributor) attribute(workload signalspec.Workload, signals map[string]float64, hints map[string]string) []Posterior {
 	// Determine which signals are elevated (above threshold).
 	elevated := make(map[string]bool)
 	for signal, value := range signals {
-		if thresh, ok := signalThresholds[signal]; ok && value >= thresh {
+		if b.isElevated(signal, workload, value) {
 			elevated[signal] = true
 		}
 	}
@@ -234,8 +162,11 @@ func (b *BayesianAttributor) Attribute(signals map[string]float64) []Posterior {
 		}
 		logP := math.Log(prior)
 
-		for signal := range b.Likelihoods {
-			likelihood := b.likelihoodFor(signal, domain, elevated[signal])
+		// Only observed signals are evidence: a signal the sample does not
+		// carry is unknown, not "not elevated", and counting it would let
+		// every unobserved row vote against the domains it is typical of.
+		for signal := range signals {
+			likelihood := b.likelihoodFor(signal, domain, elevated[signal], hints[signal])
 			logP += math.Log(likelihood)
 		}
 		logPosteriors[domain] = logP
@@ -261,10 +192,8 @@ func (b *BayesianAttributor) Attribute(signals map[string]float64) []Posterior {
 
 		evidence := make([]string, 0)
 		for signal := range elevated {
-			if ll, ok := b.Likelihoods[signal]; ok {
-				if ll[domain] >= 0.5 {
-					evidence = append(evidence, signal)
-				}
+			if p, ok := b.signalLikelihood(signal, domain, hints[signal]); ok && p >= 0.5 {
+				evidence = append(evidence, signal)
 			}
 		}
 		sort.Strings(evidence)
@@ -273,6 +202,7 @@ func (b *BayesianAttributor) Attribute(signals map[string]float64) []Posterior {
 			Domain:    domain,
 			Posterior: posterior,
 			Evidence:  evidence,
+			SubCause:  subCause(domain, elevated),
 		})
 	}
 
@@ -283,16 +213,64 @@ func (b *BayesianAttributor) Attribute(signals map[string]float64) []Posterior {
 	return result
 }
 
+// subCause splits network_dns into search-path amplification (NXDOMAIN
+// walks, several queries per lookup) and a slow resolver (latency alone),
+// and names a full conntrack table behind network_egress.
+func subCause(domain string, elevated map[string]bool) string {
+	switch domain {
+	case DomainNetworkDNS:
+		switch {
+		case elevated[signalspec.DNSQueriesPerLookup] || elevated[signalspec.DNSNXDomains]:
+			return SubCauseSearchPathAmplification
+		case elevated[signalspec.DNSLatencyMS]:
+			return SubCauseResolverLatency
+		}
+	case DomainNetworkEgress:
+		if elevated[signalspec.ConntrackDrops] || elevated[signalspec.ConntrackUtilizationPct] {
+			return SubCauseConntrackExhaustion
+		}
+	}
+	return ""
+}
+
+// isElevated applies the configured elevation mode to one signal value.
+func (b *BayesianAttributor) isElevated(signal string, workload signalspec.Workload, value float64) bool {
+	if b.Elevation == baseline.ElevationZScore && b.Baselines != nil {
+		if z, ok := b.Baselines.Score(baseline.Key{Signal: signal, Workload: workload}, value); ok {
+			k := b.ZThreshold
+			if k <= 0 {
+				k = baseline.DefaultZThreshold
+			}
+			return z >= k
+		}
+	}
+	eff, ok := b.Thresholds.Resolve(signal, workload)
+	return ok && eff.Elevated(value)
+}
+
+// Observe folds a sample's signals into Baselines. It is a no-op when no
+// tracker is set, and should run after the sample has been attributed so a
+// sample is never scored against itself.
+func (b *BayesianAttributor) Observe(sample FaultSample) {
+	if b.Baselines == nil {
+		return
+	}
+	workload := signalspec.Workload{Namespace: sample.Namespace, Service: sample.Service}
+	for signal, value := range sample.Signals {
+		b.Baselines.Observe(baseline.Key{Signal: signal, Workload: workload}, value, sample.Timestamp)
+	}
+}
+
 // likelihoodFor returns P(signal_state|domain). When the signal is elevated
-// it returns the configured likelihood, otherwise (1 - likelihood).
-func (b *BayesianAttributor) likelihoodFor(signal, domain string, isElevated bool) float64 {
-	ll, ok := b.Likelihoods[signal]
-	if !ok {
-		return 0.5 // uninformative
+// it returns the configured likelihood, adjusted for its dependency's
+// domain hint, otherwise (1 - likelihood).
+func (b *BayesianAttributor) likelihoodFor(signal, domain string, isElevated bool, hint string) float64 {
+	if !isElevated {
+		hint = ""
 	}
-	p, ok := ll[domain]
+	p, ok := b.signalLikelihood(signal, domain, hint)
 	if !ok {
-		return 0.5
+		return 0.5 // uninformative
 	}
 	if isElevated {
 		return clampLikelihood(p)
@@ -300,6 +278,93 @@ func (b *BayesianAttributor) likelihoodFor(signal, domain string, isElevated boo
 	return clampLikelihood(1 - p)
 }
 
+// signalLikelihood returns P(signal elevated|domain). A domain hint raises
+// it for the hinted domains and lowers it for unknown and the other
+// destination domains.
+func (b *BayesianAttributor) signalLikelihood(signal, domain, hint string) (float64, bool) {
+	ll, ok := b.Likelihoods[signal]
+	if !ok {
+		return 0, false
+	}
+	p, ok := ll[domain]
+	if !ok {
+		return 0, false
+	}
+	hinted := dependency.Domains(hint)
+	if len(hinted) == 0 {
+		return p, true
+	}
+	if slices.Contains(hinted, domain) {
+		return 1 - (1-p)*(1-dependencyCredit), true
+	}
+	if domain == DomainUnknown || slices.Contains(dependency.DestinationDomains(), domain) {
+		return math.Min(p, 1-dependencyCredit), true
+	}
+	return p, true
+}
+
+// dependencyHints resolves a sample's per-signal dependencies to domain
+// hints.
+func (b *BayesianAttributor) dependencyHints(sample FaultSample) map[string]string {
+	if b.Dependencies == nil || len(sample.Dependencies) == 0 {
+		return nil
+	}
+	hints := make(map[string]string, len(sample.Dependencies))
+	for signal, name := range sample.Dependencies {
+		if dep, ok := b.Dependencies.Get(name); ok {
+			hints[signal] = dep.Domain
+		}
+	}
+	return hints
+}
+
+// dependencyEvidence names the dependencies whose domain hint matches the
+// predicted domain, once each, in signal order.
+func dependencyEvidence(sample FaultSample, hints map[string]string, predicted string) []schema.Evidence {
+	signals := make([]string, 0, len(hints))
+	for signal, hint := range hints {
+		if slices.Contains(dependency.Domains(hint), predicted) {
+			signals = append(signals, signal)
+		}
+	}
+	sort.Strings(signals)
+	var out []schema.Evidence
+	seen := make(map[string]bool)
+	for _, signal := range signals {
+		name := sample.Dependencies[signal]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, schema.Evidence{
+			Signal: semconv.AttrDependency,
+			Value:  name,
+			Source: "ebpf",
+		})
+	}
+	return out
+}
+
+// dstHostEvidence names the destination hosts of the given evidence
+// signals, once each, in signal order.
+func dstHostEvidence(sample FaultSample, signals []string) []schema.Evidence {
+	var out []schema.Evidence
+	seen := make(map[string]bool)
+	for _, signal := range signals {
+		host := sample.DstHosts[signal]
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		out = append(out, schema.Evidence{
+			Signal: semconv.AttrDstHost,
+			Value:  host,
+			Source: "ebpf",
+		})
+	}
+	return out
+}
+
 func clampLikelihood(p float64) float64 {
 	if p < 0.01 {
 		return 0.01
@@ -319,17 +384,24 @@ func (b *BayesianAttributor) AttributeSample(sample FaultSample) schema.Incident
 		return base
 	}
 
-	posteriors := b.Attribute(sample.Signals)
+	hints := b.dependencyHints(sample)
+	workload := signalspec.Workload{Namespace: sample.Namespace, Service: sample.Service}
+	posteriors := b.attribute(workload, sample.Signals, hints)
 	hypotheses := make([]schema.FaultHypothesis, 0, len(posteriors))
 	for _, p := range posteriors {
 		if p.Posterior < 0.01 {
 			continue
 		}
-		hypotheses = append(hypotheses, schema.FaultHypothesis{
+		hypothesis := schema.FaultHypothesis{
 			Domain:    p.Domain,
 			Posterior: p.Posterior,
 			Evidence:  p.Evidence,
-		})
+			SubCause:  p.SubCause,
+		}
+		if p.Domain == DomainCPUThrottle {
+			hypothesis.Culprits = sample.Culprits
+		}
+		hypotheses = append(hypotheses, hypothesis)
 	}
 	base.FaultHypotheses = hypotheses
 
@@ -338,6 +410,26 @@ func (b *BayesianAttributor) AttributeSample(sample FaultSample) schema.Incident
 		base.PredictedFaultDomain = posteriors[0].Domain
 		base.Confidence = posteriors[0].Posterior
 	}
+	if base.PredictedFaultDomain == DomainCPUThrottle {
+		for _, c := range sample.Culprits {
+			base.Evidence = append(base.Evidence, schema.Evidence{
+				Signal: semconv.AttrNoisyNeighborPod,
+				Value:  c.Pod,
+				Source: "ebpf",
+			})
+		}
+	}
+	base.Evidence = append(base.Evidence, dependencyEvidence(sample, hints, base.PredictedFaultDomain)...)
+	if len(posteriors) > 0 {
+		base.Evidence = append(base.Evidence, dstHostEvidence(sample, posteriors[0].Evidence)...)
+	}
+	if churn, ok := sample.Signals[signalspec.ConnectionChurnPerS]; ok && b.isElevated(signalspec.ConnectionChurnPerS, workload, churn) {
+		base.Evidence = append(base.Evidence, schema.Evidence{
+			Signal: semconv.AttrConnReuseRegression,
+			Value:  ConnectionReuseRegression,
+			Source: "ebpf",
+		})
+	}
 
 	return base
 }
@@ -2,8 +2,15 @@ package attribution
 
 import (
 	"math"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/baseline"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/dependency"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/semconv"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
 )
 
 func TestPosteriorsSumToOne(t *testing.T) {
@@ -179,17 +186,523 @@ func TestAttributeSampleWithoutSignalsFallsBackToRuleBased(t *testing.T) {
 
 func TestAllDomainsCount(t *testing.T) {
 	domains := AllDomains()
-	if len(domains) != 8 {
-		t.Fatalf("expected 8 domains, got %d", len(domains))
+	if len(domains) != 9 {
+		t.Fatalf("expected 9 domains, got %d", len(domains))
 	}
 }
 
 func TestDefaultPriorsUniform(t *testing.T) {
 	priors := DefaultPriors()
-	expected := 1.0 / 8.0
+	expected := 1.0 / 9.0
 	for domain, p := range priors {
 		if math.Abs(p-expected) > 1e-10 {
 			t.Errorf("prior for %s: got %f, want %f", domain, p, expected)
 		}
 	}
 }
+
+func TestLikelihoodsCoverRegistry(t *testing.T) {
+	likelihoods := DefaultLikelihoods()
+	for _, desc := range signalspec.All() {
+		row, ok := likelihoods[desc.Name]
+		if !ok {
+			t.Errorf("%s: no likelihood row", desc.Name)
+			continue
+		}
+		for _, domain := range AllDomains() {
+			if _, ok := row[domain]; !ok {
+				t.Errorf("%s: no likelihood for domain %s", desc.Name, domain)
+			}
+		}
+		if _, ok := NewBayesianAttributor().Thresholds.Resolve(desc.Name, signalspec.Workload{}); !ok {
+			t.Errorf("%s: no elevation threshold", desc.Name)
+		}
+	}
+	if len(likelihoods) != len(signalspec.All()) {
+		t.Fatalf("likelihood rows %d != registry size %d", len(likelihoods), len(signalspec.All()))
+	}
+
+	likelihoods[signalspec.DNSLatencyMS][DomainNetworkDNS] = 0
+	if DefaultLikelihoods()[signalspec.DNSLatencyMS][DomainNetworkDNS] == 0 {
+		t.Fatal("DefaultLikelihoods must return copies of registry rows")
+	}
+}
+
+func TestAttributeSampleUsesWorkloadThresholds(t *testing.T) {
+	relaxed, relaxedErr := 300.0, 600.0
+	ba := NewBayesianAttributor()
+	ba.Thresholds = &signalspec.ThresholdTable{
+		Namespaces: map[string]map[string]signalspec.ThresholdOverride{
+			"apac": {signalspec.DNSLatencyMS: {Warning: &relaxed, Error: &relaxedErr}},
+		},
+	}
+	signals := map[string]float64{signalspec.DNSLatencyMS: 220}
+
+	strict := ba.AttributeSample(FaultSample{IncidentID: "a", Namespace: "default", Service: "rag", Signals: signals})
+	if strict.PredictedFaultDomain != DomainNetworkDNS {
+		t.Fatalf("expected %s with default thresholds, got %s", DomainNetworkDNS, strict.PredictedFaultDomain)
+	}
+	local := ba.AttributeSample(FaultSample{IncidentID: "b", Namespace: "apac", Service: "rag", Signals: signals})
+	for _, h := range local.FaultHypotheses {
+		for _, ev := range h.Evidence {
+			if ev == signalspec.DNSLatencyMS {
+				t.Fatalf("220ms DNS should not be evidence under the apac override: %+v", local.FaultHypotheses)
+			}
+		}
+	}
+}
+
+func TestZScoreElevationUsesWorkloadBaseline(t *testing.T) {
+	ba := NewBayesianAttributor()
+	ba.Elevation = baseline.ElevationZScore
+	ba.Baselines = baseline.NewTracker(baseline.Config{Window: 20, WarmupSamples: 10})
+	batch := FaultSample{Namespace: "batch", Service: "embedder"}
+	hasEvidence := func(result schema.IncidentAttribution) bool {
+		for _, h := range result.FaultHypotheses {
+			for _, ev := range h.Evidence {
+				if ev == signalspec.RunqueueDelayMS {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	// During warm-up z-score mode falls back to static thresholds, where
+	// 15ms runqueue delay is above the 10ms warning cutoff.
+	batch.Signals = map[string]float64{signalspec.RunqueueDelayMS: 15}
+	if !hasEvidence(ba.AttributeSample(batch)) {
+		t.Fatal("expected static fallback to treat 15ms as elevated during warm-up")
+	}
+
+	for i := 0; i < 20; i++ {
+		ba.Observe(FaultSample{Namespace: "batch", Service: "embedder", Signals: map[string]float64{
+			signalspec.RunqueueDelayMS: 14 + float64(i%3),
+		}})
+	}
+	if hasEvidence(ba.AttributeSample(batch)) {
+		t.Fatal("15ms is normal for this workload and should not be evidence")
+	}
+	batch.Signals = map[string]float64{signalspec.RunqueueDelayMS: 45}
+	if !hasEvidence(ba.AttributeSample(batch)) {
+		t.Fatal("45ms is anomalous for this workload and should be evidence")
+	}
+}
+
+func TestOOMEvidenceSupportsMemoryPressure(t *testing.T) {
+	ba := NewBayesianAttributor()
+	posteriors := ba.Attribute(map[string]float64{
+		signalspec.MemReclaimLatencyMS: 8,
+		signalspec.OOMKills:            1,
+		signalspec.MemcgMaxEvents:      4,
+	})
+	top := posteriors[0]
+	if top.Domain != DomainMemoryPressure {
+		t.Fatalf("top domain: got %s, want %s", top.Domain, DomainMemoryPressure)
+	}
+	want := []string{signalspec.MemReclaimLatencyMS, signalspec.MemcgMaxEvents, signalspec.OOMKills}
+	if strings.Join(top.Evidence, ",") != strings.Join(want, ",") {
+		t.Fatalf("evidence: got %v, want %v", top.Evidence, want)
+	}
+}
+
+// withRequiredAtBaseline adds the required probes, at their baselines, to
+// signals that do not already carry them.
+func withRequiredAtBaseline(signals map[string]float64) map[string]float64 {
+	out := make(map[string]float64, len(signals))
+	for _, name := range signalspec.Required() {
+		d, _ := signalspec.Lookup(name)
+		out[name] = d.Baseline
+	}
+	for name, value := range signals {
+		out[name] = value
+	}
+	return out
+}
+
+func TestProviderHTTPEvidenceAlone(t *testing.T) {
+	ba := NewBayesianAttributor()
+	tests := []struct {
+		name    string
+		signals map[string]float64
+		want    string
+	}{
+		{"429s", map[string]float64{signalspec.ProviderHTTP429s: 20}, DomainProviderThrottle},
+		{"429s with retry-after", map[string]float64{signalspec.ProviderHTTP429s: 20, signalspec.ProviderRetryAfterS: 30}, DomainProviderThrottle},
+		{"5xx", map[string]float64{signalspec.ProviderHTTP5xxs: 20}, DomainProviderError},
+	}
+	for _, tt := range tests {
+		for _, signals := range []map[string]float64{tt.signals, withRequiredAtBaseline(tt.signals)} {
+			posteriors := ba.Attribute(signals)
+			if posteriors[0].Domain != tt.want {
+				t.Errorf("%s (%d signals): got %s %.3f, want %s", tt.name, len(signals), posteriors[0].Domain, posteriors[0].Posterior, tt.want)
+			}
+			// A storm should name the provider clearly, not edge out unknown.
+			if posteriors[0].Posterior < 2*posteriors[1].Posterior {
+				t.Errorf("%s (%d signals): %s %.3f is not clear of %s %.3f", tt.name, len(signals), posteriors[0].Domain, posteriors[0].Posterior, posteriors[1].Domain, posteriors[1].Posterior)
+			}
+		}
+	}
+}
+
+func TestUnobservedSignalsAreNotEvidence(t *testing.T) {
+	signals := map[string]float64{
+		signalspec.DNSLatencyMS:    180,
+		signalspec.RunqueueDelayMS: 2,
+	}
+	full := NewBayesianAttributor()
+	// An attributor that only knows the observed rows, as if no opt-in
+	// probe had ever been registered.
+	observed := NewBayesianAttributor()
+	observed.Likelihoods = make(map[string]map[string]float64, len(signals))
+	for name := range signals {
+		observed.Likelihoods[name] = full.Likelihoods[name]
+	}
+
+	want := make(map[string]float64)
+	for _, p := range observed.Attribute(signals) {
+		want[p.Domain] = p.Posterior
+	}
+	for _, p := range full.Attribute(signals) {
+		if math.Abs(p.Posterior-want[p.Domain]) > 1e-12 {
+			t.Fatalf("%s: unobserved rows moved the posterior from %.4f to %.4f", p.Domain, want[p.Domain], p.Posterior)
+		}
+	}
+}
+
+func TestOOMKillAloneNamesMemoryPressure(t *testing.T) {
+	ba := NewBayesianAttributor()
+	posteriors := ba.Attribute(map[string]float64{signalspec.OOMKills: 1})
+	if posteriors[0].Domain != DomainMemoryPressure {
+		t.Fatalf("oom kill alone: got %s %.3f, want %s", posteriors[0].Domain, posteriors[0].Posterior, DomainMemoryPressure)
+	}
+
+	// The cgroup reports the same kill; together they outweigh reclaim
+	// latency that never rose.
+	posteriors = ba.Attribute(withRequiredAtBaseline(map[string]float64{
+		signalspec.OOMKills:           1,
+		signalspec.MemcgOOMKillEvents: 1,
+	}))
+	if posteriors[0].Domain != DomainMemoryPressure {
+		t.Fatalf("oom kill with probes at baseline: got %s %.3f, want %s", posteriors[0].Domain, posteriors[0].Posterior, DomainMemoryPressure)
+	}
+}
+
+func TestSRTTAndZeroWindowsNamePath(t *testing.T) {
+	ba := NewBayesianAttributor()
+	signals := map[string]float64{
+		signalspec.TCPSRTTMS:      400,
+		signalspec.TCPZeroWindows: 10,
+	}
+	for _, sample := range []map[string]float64{signals, withRequiredAtBaseline(signals)} {
+		top := ba.Attribute(sample)[0]
+		if top.Domain != DomainNetworkEgress && top.Domain != DomainProviderThrottle {
+			t.Errorf("srtt and zero windows (%d signals): got %s %.3f, want network_egress or provider_throttle", len(sample), top.Domain, top.Posterior)
+		}
+	}
+}
+
+func TestResetEvidence(t *testing.T) {
+	ba := NewBayesianAttributor()
+
+	gateway := ba.Attribute(map[string]float64{
+		signalspec.ListenOverflows: 14,
+		signalspec.TCPResets:       6,
+		signalspec.RunqueueDelayMS: 12,
+	})
+	if gateway[0].Domain != DomainGatewaySaturation {
+		t.Fatalf("top domain with listen overflows: got %s, want %s", gateway[0].Domain, DomainGatewaySaturation)
+	}
+	want := []string{signalspec.ListenOverflows, signalspec.TCPResets}
+	if strings.Join(gateway[0].Evidence, ",") != strings.Join(want, ",") {
+		t.Fatalf("gateway evidence: got %v, want %v", gateway[0].Evidence, want)
+	}
+
+	provider := ba.Attribute(map[string]float64{
+		signalspec.TCPResets:         9,
+		signalspec.TLSHandshakeFails: 1,
+	})
+	if provider[0].Domain != DomainProviderError {
+		t.Fatalf("top domain with resets only: got %s, want %s", provider[0].Domain, DomainProviderError)
+	}
+	if !strings.Contains(strings.Join(provider[0].Evidence, ","), signalspec.TCPResets) {
+		t.Fatalf("provider_error evidence should include resets, got %v", provider[0].Evidence)
+	}
+}
+
+func TestNetworkDNSSubCause(t *testing.T) {
+	ba := NewBayesianAttributor()
+
+	amplified := ba.AttributeSample(FaultSample{
+		FaultLabel: "dns_search_amplification",
+		Signals: map[string]float64{
+			signalspec.DNSLatencyMS:        55,
+			signalspec.DNSNXDomains:        24,
+			signalspec.DNSQueriesPerLookup: 5,
+		},
+	})
+	top := amplified.FaultHypotheses[0]
+	if top.Domain != DomainNetworkDNS || top.SubCause != SubCauseSearchPathAmplification {
+		t.Fatalf("expected network_dns/%s, got %s/%q", SubCauseSearchPathAmplification, top.Domain, top.SubCause)
+	}
+	want := []string{signalspec.DNSLatencyMS, signalspec.DNSNXDomains, signalspec.DNSQueriesPerLookup}
+	if strings.Join(top.Evidence, ",") != strings.Join(want, ",") {
+		t.Fatalf("evidence: got %v, want %v", top.Evidence, want)
+	}
+
+	slow := ba.Attribute(map[string]float64{signalspec.DNSLatencyMS: 220, signalspec.ConnectLatencyMS: 130})
+	if slow[0].Domain != DomainNetworkDNS || slow[0].SubCause != SubCauseResolverLatency {
+		t.Fatalf("expected network_dns/%s, got %s/%q", SubCauseResolverLatency, slow[0].Domain, slow[0].SubCause)
+	}
+	for _, p := range slow[1:] {
+		if p.SubCause != "" {
+			t.Errorf("%s should have no sub-cause, got %q", p.Domain, p.SubCause)
+		}
+	}
+}
+
+func TestNetworkEgressConntrackSubCause(t *testing.T) {
+	ba := NewBayesianAttributor()
+
+	exhausted := ba.AttributeSample(FaultSample{
+		FaultLabel: "conntrack_exhaustion",
+		Signals: map[string]float64{
+			signalspec.ConntrackUtilizationPct: 100,
+			signalspec.ConntrackDrops:          40,
+			signalspec.ConnectLatencyMS:        1100,
+			signalspec.ConnectErrors:           2,
+		},
+	})
+	if exhausted.PredictedFaultDomain != DomainNetworkEgress {
+		t.Fatalf("expected %s, got %s", DomainNetworkEgress, exhausted.PredictedFaultDomain)
+	}
+	top := exhausted.FaultHypotheses[0]
+	if top.SubCause != SubCauseConntrackExhaustion {
+		t.Fatalf("expected sub-cause %q, got %q", SubCauseConntrackExhaustion, top.SubCause)
+	}
+	evidence := strings.Join(top.Evidence, ",")
+	if !strings.Contains(evidence, signalspec.ConntrackDrops) || !strings.Contains(evidence, signalspec.ConntrackUtilizationPct) {
+		t.Fatalf("network_egress evidence should include conntrack signals, got %v", top.Evidence)
+	}
+
+	// A full table names itself, without connect failures to lean on.
+	conntrackOnly := map[string]float64{
+		signalspec.ConntrackUtilizationPct: 100,
+		signalspec.ConntrackDrops:          50,
+	}
+	for _, signals := range []map[string]float64{conntrackOnly, withRequiredAtBaseline(conntrackOnly)} {
+		top := ba.Attribute(signals)[0]
+		if top.Domain != DomainNetworkEgress || top.SubCause != SubCauseConntrackExhaustion {
+			t.Fatalf("conntrack only (%d signals): got %s/%q %.3f, want %s/%s",
+				len(signals), top.Domain, top.SubCause, top.Posterior, DomainNetworkEgress, SubCauseConntrackExhaustion)
+		}
+	}
+
+	// A partition without conntrack pressure stays unqualified.
+	partition := ba.Attribute(map[string]float64{
+		signalspec.ConnectLatencyMS:        350,
+		signalspec.ConnectErrors:           3,
+		signalspec.TCPRetransmits:          12,
+		signalspec.TCPSRTTMS:               420,
+		signalspec.ConntrackUtilizationPct: 20,
+	})
+	if partition[0].Domain != DomainNetworkEgress || partition[0].SubCause != "" {
+		t.Fatalf("expected network_egress without sub-cause, got %s/%q", partition[0].Domain, partition[0].SubCause)
+	}
+}
+
+func TestCPUThrottleCulprits(t *testing.T) {
+	ba := NewBayesianAttributor()
+	culprits := []schema.Culprit{
+		{Pod: "batch-uid", Comm: "spark-executor", StolenMS: 50, Share: 0.5},
+		{Pod: "etl-uid", Comm: "python3", StolenMS: 25, Share: 0.25},
+	}
+	result := ba.AttributeSample(FaultSample{
+		IncidentID:    "inc-cpu-1",
+		Cluster:       "prod",
+		Service:       "vllm",
+		FaultLabel:    "cpu_throttle",
+		WindowMinutes: 5,
+		Signals: map[string]float64{
+			signalspec.RunqueueDelayMS: 28,
+			signalspec.CPUStealPct:     9,
+			"cfs_throttled_ms":         170,
+		},
+		Culprits: culprits,
+	})
+	if result.PredictedFaultDomain != DomainCPUThrottle {
+		t.Fatalf("expected cpu_throttle, got %s", result.PredictedFaultDomain)
+	}
+	for _, h := range result.FaultHypotheses {
+		if h.Domain == DomainCPUThrottle && len(h.Culprits) != 2 {
+			t.Fatalf("expected culprits on cpu_throttle, got %+v", h)
+		}
+		if h.Domain != DomainCPUThrottle && h.Culprits != nil {
+			t.Fatalf("%s should carry no culprits", h.Domain)
+		}
+	}
+	var pods []string
+	for _, ev := range result.Evidence {
+		if ev.Signal == semconv.AttrNoisyNeighborPod {
+			pods = append(pods, ev.Value.(string))
+		}
+	}
+	if strings.Join(pods, ",") != "batch-uid,etl-uid" {
+		t.Fatalf("expected neighbour evidence, got %v", pods)
+	}
+	if err := schema.IncidentAttributionValidator().Validate(result); err != nil {
+		t.Fatalf("attribution with culprits fails the contract: %v", err)
+	}
+}
+
+func TestDependencyHintsCreditDestination(t *testing.T) {
+	catalog, err := dependency.NewCatalog([]dependency.Dependency{
+		{Name: "openai", Domain: dependency.DomainProvider, Hosts: []string{"api.openai.com"}},
+		{Name: "qdrant", Domain: DomainRetrievalBackend, Ports: []int{6333}},
+	})
+	if err != nil {
+		t.Fatalf("catalog: %v", err)
+	}
+	signals := map[string]float64{
+		signalspec.ConnectLatencyMS: 200,
+		signalspec.TLSHandshakeMS:   300,
+	}
+	attribute := func(dep string) schema.IncidentAttribution {
+		ba := NewBayesianAttributor()
+		ba.Dependencies = catalog
+		sample := FaultSample{IncidentID: "inc-dep-1", WindowMinutes: 5, Signals: signals}
+		if dep != "" {
+			sample.Dependencies = map[string]string{
+				signalspec.ConnectLatencyMS: dep,
+				signalspec.TLSHandshakeMS:   dep,
+			}
+		}
+		return ba.AttributeSample(sample)
+	}
+	posterior := func(result schema.IncidentAttribution, domain string) float64 {
+		for _, h := range result.FaultHypotheses {
+			if h.Domain == domain {
+				return h.Posterior
+			}
+		}
+		return 0
+	}
+	dependencyEvidence := func(result schema.IncidentAttribution) []string {
+		var names []string
+		for _, ev := range result.Evidence {
+			if ev.Signal == semconv.AttrDependency {
+				names = append(names, ev.Value.(string))
+			}
+		}
+		return names
+	}
+
+	plain := attribute("")
+	provider := attribute("openai")
+	providerPosterior := func(result schema.IncidentAttribution) float64 {
+		return posterior(result, DomainProviderThrottle) + posterior(result, DomainProviderError)
+	}
+	if providerPosterior(provider) <= providerPosterior(plain) {
+		t.Fatalf("provider hint should raise the provider domains: %v -> %v",
+			providerPosterior(plain), providerPosterior(provider))
+	}
+	if provider.PredictedFaultDomain != DomainProviderThrottle {
+		t.Fatalf("provider hint should keep provider_throttle on top: %+v", provider.FaultHypotheses)
+	}
+	if posterior(provider, DomainRetrievalBackend) >= posterior(provider, DomainProviderError) {
+		t.Fatalf("provider hint should rank provider_error above retrieval_backend: %+v", provider.FaultHypotheses)
+	}
+
+	retrieval := attribute("qdrant")
+	if retrieval.PredictedFaultDomain != DomainRetrievalBackend {
+		t.Fatalf("expected retrieval_backend, got %+v", retrieval.FaultHypotheses)
+	}
+	if names := dependencyEvidence(retrieval); strings.Join(names, ",") != "qdrant" {
+		t.Fatalf("expected qdrant dependency evidence, got %v", names)
+	}
+	if err := schema.IncidentAttributionValidator().Validate(retrieval); err != nil {
+		t.Fatalf("attribution with dependency evidence fails the contract: %v", err)
+	}
+
+	// Without a catalog the names mean nothing.
+	ba := NewBayesianAttributor()
+	unresolved := ba.AttributeSample(FaultSample{WindowMinutes: 5, Signals: signals, Dependencies: map[string]string{signalspec.ConnectLatencyMS: "qdrant"}})
+	if math.Abs(posterior(unresolved, DomainRetrievalBackend)-posterior(plain, DomainRetrievalBackend)) > 1e-12 {
+		t.Fatal("dependencies without a catalog should not change posteriors")
+	}
+}
+
+func TestDependencyHintKeepsRegistryOrder(t *testing.T) {
+	ba := NewBayesianAttributor()
+	// connect_latency_ms leans towards provider_throttle (0.75) over
+	// provider_error (0.40). A provider hint should raise both without
+	// flattening them to the same likelihood.
+	throttle, _ := ba.signalLikelihood(signalspec.ConnectLatencyMS, DomainProviderThrottle, dependency.DomainProvider)
+	providerErr, _ := ba.signalLikelihood(signalspec.ConnectLatencyMS, DomainProviderError, dependency.DomainProvider)
+	if providerErr < dependencyCredit || throttle <= providerErr {
+		t.Fatalf("expected hinted provider_throttle %.3f > provider_error %.3f >= %.2f", throttle, providerErr, dependencyCredit)
+	}
+	retrieval, _ := ba.signalLikelihood(signalspec.ConnectLatencyMS, DomainRetrievalBackend, dependency.DomainProvider)
+	if retrieval > 1-dependencyCredit {
+		t.Fatalf("expected retrieval_backend capped at %.2f, got %.3f", 1-dependencyCredit, retrieval)
+	}
+}
+
+func TestDstHostEvidence(t *testing.T) {
+	ba := NewBayesianAttributor()
+	result := ba.AttributeSample(FaultSample{
+		IncidentID:    "inc-host-1",
+		WindowMinutes: 5,
+		Signals: map[string]float64{
+			signalspec.TCPRetransmits:   12,
+			signalspec.ConnectLatencyMS: 400,
+			signalspec.ConnectErrors:    6,
+			signalspec.TCPSRTTMS:        600,
+			signalspec.RunqueueDelayMS:  1,
+		},
+		DstHosts: map[string]string{
+			signalspec.TCPRetransmits:   "api.openai.com",
+			signalspec.ConnectLatencyMS: "api.openai.com",
+			signalspec.RunqueueDelayMS:  "metrics.example.com",
+		},
+	})
+	if result.PredictedFaultDomain != DomainNetworkEgress {
+		t.Fatalf("expected network_egress, got %s", result.PredictedFaultDomain)
+	}
+	var hosts []string
+	for _, ev := range result.Evidence {
+		if ev.Signal == semconv.AttrDstHost {
+			hosts = append(hosts, ev.Value.(string))
+		}
+	}
+	if len(hosts) != 1 || hosts[0] != "api.openai.com" {
+		t.Fatalf("expected one dst_host evidence item for the elevated signals, got %v", hosts)
+	}
+}
+
+func TestConnectionReuseRegressionEvidence(t *testing.T) {
+	ba := NewBayesianAttributor()
+	regression := func(signals map[string]float64) bool {
+		result := ba.AttributeSample(FaultSample{IncidentID: "inc-churn-1", WindowMinutes: 5, Signals: signals})
+		for _, ev := range result.Evidence {
+			if ev.Signal == semconv.AttrConnReuseRegression {
+				return ev.Value == ConnectionReuseRegression
+			}
+		}
+		return false
+	}
+	// Keep-alive loss: every request pays connect and TLS again.
+	if !regression(map[string]float64{
+		signalspec.ConnectionChurnPerS: 12,
+		signalspec.ConnectLatencyMS:    190,
+		signalspec.TLSHandshakeMS:      260,
+	}) {
+		t.Fatal("elevated churn should add a connection reuse regression")
+	}
+	if regression(map[string]float64{
+		signalspec.ConnectionChurnPerS: 0.2,
+		signalspec.ConnectLatencyMS:    190,
+	}) {
+		t.Fatal("baseline churn should not add a connection reuse regression")
+	}
+}
@@ -23,14 +23,26 @@ type FaultSample struct {
 	WindowMinutes   int                `json:"window_minutes"`
 	RequestID       string             `json:"request_id"`
 	TraceID         string             `json:"trace_id"`
+	// Culprits are the sample's noisy neighbours, e.g. from
+	// collector.ContentionWindow.Culprits; they are reported on the
+	// cpu_throttle hypothesis.
+	Culprits []schema.Culprit `json:"culprits,omitempty"`
+	// Dependencies names, per signal, the dependency its elevated events
+	// went to, e.g. from dependency.SignalDependencies. With a catalog on
+	// the attributor, their domain hints steer the evidence.
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+	// DstHosts names, per signal, the destination host of its elevated
+	// events, e.g. from dependency.SignalDstHosts. Hosts behind the
+	// predicted domain's evidence are reported as evidence.
+	DstHosts map[string]string `json:"dst_hosts,omitempty"`
 }
 
 // MapFaultLabel maps scenario labels into schema-constrained domains.
 func MapFaultLabel(label string) string {
 	switch label {
-	case "dns_latency":
+	case "dns_latency", "dns_search_amplification":
 		return "network_dns"
-	case "egress_drop":
+	case "egress_drop", "conntrack_exhaustion":
 		return "network_egress"
 	case "cpu_throttle":
 		return "cpu_throttle"
@@ -44,6 +56,8 @@ func MapFaultLabel(label string) string {
 		return "provider_error"
 	case "retrieval_slowdown":
 		return "retrieval_backend"
+	case "gateway_saturation":
+		return "gateway_saturation"
 	default:
 		return "unknown"
 	}
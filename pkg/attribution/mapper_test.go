@@ -14,6 +14,9 @@ func TestMapFaultLabel(t *testing.T) {
 	if got := MapFaultLabel("network_partition"); got != "network_egress" {
 		t.Fatalf("unexpected network_partition mapping: %s", got)
 	}
+	if got := MapFaultLabel("gateway_saturation"); got != "gateway_saturation" {
+		t.Fatalf("unexpected gateway_saturation mapping: %s", got)
+	}
 	if got := MapFaultLabel("unknown_case"); got != "unknown" {
 		t.Fatalf("unexpected unknown mapping: %s", got)
 	}
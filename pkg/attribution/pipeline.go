@@ -2,7 +2,12 @@ package attribution
 
 import "strings"
 
-import "github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+import (
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/baseline"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/dependency"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
+)
 
 // MatrixKey represents one actual/predicted pair in confusion matrix output.
 type MatrixKey struct {
@@ -35,6 +40,7 @@ func BuildAttributionsBayes(samples []FaultSample, attributor *BayesianAttributo
 	out := make([]schema.IncidentAttribution, 0, len(samples))
 	for _, sample := range samples {
 		out = append(out, attributor.AttributeSample(sample))
+		attributor.Observe(sample)
 	}
 	return out
 }
@@ -42,11 +48,45 @@ func BuildAttributionsBayes(samples []FaultSample, attributor *BayesianAttributo
 // BuildAttributions dispatches attribution mode.
 // Supported values: "bayes", "rule". Unknown values fall back to "bayes".
 func BuildAttributions(samples []FaultSample, mode string) []schema.IncidentAttribution {
+	return BuildAttributionsWithThresholds(samples, mode, nil)
+}
+
+// BuildAttributionsWithThresholds is BuildAttributions with Bayesian evidence
+// cutoffs resolved per sample workload from table. Rule mode ignores them.
+func BuildAttributionsWithThresholds(samples []FaultSample, mode string, table *signalspec.ThresholdTable) []schema.IncidentAttribution {
+	return BuildAttributionsWithOptions(samples, mode, EvidenceOptions{Thresholds: table})
+}
+
+// EvidenceOptions controls how Bayesian attribution decides which signals
+// are elevated.
+type EvidenceOptions struct {
+	Thresholds *signalspec.ThresholdTable
+	// Elevation is baseline.ElevationStatic (default) or ElevationZScore.
+	Elevation  string
+	ZThreshold float64
+	// Baseline configures the tracker created for z-score mode.
+	Baseline baseline.Config
+	// Dependencies resolves sample dependencies to domain hints.
+	Dependencies *dependency.Catalog
+}
+
+// BuildAttributionsWithOptions is BuildAttributions with explicit evidence
+// options. In z-score mode samples are scored in order against baselines
+// learned from the samples before them, per workload.
+func BuildAttributionsWithOptions(samples []FaultSample, mode string, opts EvidenceOptions) []schema.IncidentAttribution {
 	switch normalizeAttributionMode(mode) {
 	case AttributionModeRule:
 		return BuildAttributionsRule(samples)
 	default:
-		return BuildAttributionsBayes(samples, nil)
+		attributor := NewBayesianAttributor()
+		attributor.Thresholds = opts.Thresholds
+		attributor.Elevation = opts.Elevation
+		attributor.ZThreshold = opts.ZThreshold
+		attributor.Dependencies = opts.Dependencies
+		if opts.Elevation == baseline.ElevationZScore {
+			attributor.Baselines = baseline.NewTracker(opts.Baseline)
+		}
+		return BuildAttributionsBayes(samples, attributor)
 	}
 }
 
@@ -4,7 +4,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/baseline"
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
 )
 
 func TestBuildAttributionsCount(t *testing.T) {
@@ -173,3 +175,50 @@ func TestCoverageAccuracyThresholdFilters(t *testing.T) {
 		t.Fatalf("expected 0.5 coverage accuracy with threshold, got %f", ca)
 	}
 }
+
+func TestBuildAttributionsZScoreLearnsInOrder(t *testing.T) {
+	samples := make([]FaultSample, 0, 31)
+	start := time.Unix(1710000000, 0).UTC()
+	for i := 0; i < 30; i++ {
+		samples = append(samples, FaultSample{
+			IncidentID: "normal",
+			Timestamp:  start.Add(time.Duration(i) * time.Minute),
+			Namespace:  "batch",
+			Service:    "embedder",
+			FaultLabel: "cpu_throttle",
+			Signals:    map[string]float64{signalspec.RunqueueDelayMS: 14 + float64(i%3)},
+		})
+	}
+	samples = append(samples, FaultSample{
+		IncidentID: "spike",
+		Timestamp:  start.Add(31 * time.Minute),
+		Namespace:  "batch",
+		Service:    "embedder",
+		FaultLabel: "cpu_throttle",
+		Signals:    map[string]float64{signalspec.RunqueueDelayMS: 60},
+	})
+
+	predictions := BuildAttributionsWithOptions(samples, AttributionModeBayes, EvidenceOptions{
+		Elevation: baseline.ElevationZScore,
+		Baseline:  baseline.Config{Window: 30, WarmupSamples: 10, HalfLife: 30 * time.Minute},
+	})
+	evidence := func(p schema.IncidentAttribution) bool {
+		for _, h := range p.FaultHypotheses {
+			for _, ev := range h.Evidence {
+				if ev == signalspec.RunqueueDelayMS {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	if !evidence(predictions[0]) {
+		t.Fatal("first sample should use static thresholds during warm-up")
+	}
+	if evidence(predictions[29]) {
+		t.Fatal("steady-state sample should not be evidence once the baseline is warm")
+	}
+	if !evidence(predictions[30]) {
+		t.Fatalf("spike should be evidence against the learned baseline: %+v", predictions[30].FaultHypotheses)
+	}
+}
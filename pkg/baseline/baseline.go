@@ -0,0 +1,273 @@
+package baseline
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
+)
+
+// Elevation modes decide when a signal value counts as attribution evidence.
+const (
+	// ElevationStatic compares values against resolved warning thresholds.
+	ElevationStatic = "static"
+	// ElevationZScore compares values against the workload's own baseline.
+	ElevationZScore = "zscore"
+)
+
+// DefaultZThreshold is the robust z-score above which a value is elevated.
+const DefaultZThreshold = 3.0
+
+// madScale converts a median absolute deviation into a standard deviation
+// estimate for normally distributed data.
+const madScale = 1.4826
+
+// winsorZ bounds how far a single observation may pull a warm baseline, so
+// an incident in progress does not become the new normal.
+const winsorZ = 6.0
+
+// ParseElevation normalizes an elevation mode; empty means static.
+func ParseElevation(mode string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", ElevationStatic:
+		return ElevationStatic, nil
+	case ElevationZScore:
+		return ElevationZScore, nil
+	default:
+		return "", fmt.Errorf("unsupported elevation mode %q (want %s|%s)", mode, ElevationStatic, ElevationZScore)
+	}
+}
+
+// Config tunes baseline tracking.
+type Config struct {
+	// Window is the number of samples kept for the median/MAD estimate.
+	Window int
+	// WarmupSamples is the number of window samples required before a
+	// series produces z-scores.
+	WarmupSamples int
+	// HalfLife is the wall-clock half-life of the EWMA mean and variance.
+	// The median window admits at most one sample per HalfLife/Window, so it
+	// also spans roughly one half-life regardless of event rate.
+	HalfLife time.Duration
+}
+
+// DefaultConfig returns a one-hour baseline: long enough to ride out a
+// minutes-long incident, short enough to follow diurnal load.
+func DefaultConfig() Config {
+	return Config{
+		Window:        120,
+		WarmupSamples: 20,
+		HalfLife:      time.Hour,
+	}
+}
+
+func (c Config) normalized() Config {
+	def := DefaultConfig()
+	if c.Window <= 0 {
+		c.Window = def.Window
+	}
+	if c.WarmupSamples <= 0 {
+		c.WarmupSamples = def.WarmupSamples
+	}
+	if c.WarmupSamples > c.Window {
+		c.WarmupSamples = c.Window
+	}
+	if c.HalfLife <= 0 {
+		c.HalfLife = def.HalfLife
+	}
+	return c
+}
+
+// Key identifies one baseline series.
+type Key struct {
+	Signal string
+	signalspec.Workload
+}
+
+// State is a point-in-time view of one series.
+type State struct {
+	Key
+	Observations uint64
+	WindowSize   int
+	Ready        bool
+	Mean         float64
+	StdDev       float64
+	Median       float64
+	MAD          float64
+	LastSeen     time.Time
+}
+
+type series struct {
+	window   []float64
+	next     int
+	filled   int
+	observed uint64
+	mean     float64
+	variance float64
+	last     time.Time
+	admitted time.Time
+}
+
+// Tracker keeps rolling baselines per (signal, namespace, service). It is
+// safe for concurrent use.
+type Tracker struct {
+	mu      sync.Mutex
+	cfg     Config
+	spacing time.Duration
+	series  map[Key]*series
+}
+
+// NewTracker returns an empty tracker; zero config fields take defaults.
+func NewTracker(cfg Config) *Tracker {
+	cfg = cfg.normalized()
+	return &Tracker{
+		cfg:     cfg,
+		spacing: cfg.HalfLife / time.Duration(cfg.Window),
+		series:  make(map[Key]*series),
+	}
+}
+
+// Config returns the normalized tracker configuration.
+func (t *Tracker) Config() Config {
+	return t.cfg
+}
+
+// Observe folds value into the baseline for key. A zero ts skips
+// time-based decay and spacing, which suits offline replays.
+func (t *Tracker) Observe(key Key, value float64, ts time.Time) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.series[key]
+	if !ok {
+		s = &series{window: make([]float64, t.cfg.Window)}
+		t.series[key] = s
+	}
+
+	if s.filled >= t.cfg.WarmupSamples {
+		median, scale := s.robust()
+		limit := winsorZ * scale
+		value = math.Max(median-limit, math.Min(median+limit, value))
+	}
+
+	if s.observed == 0 {
+		s.mean = value
+	} else {
+		alpha := 1.0 / float64(t.cfg.Window)
+		if !ts.IsZero() && !s.last.IsZero() {
+			dt := ts.Sub(s.last)
+			if dt < 0 {
+				dt = 0
+			}
+			alpha = 1 - math.Exp2(-float64(dt)/float64(t.cfg.HalfLife))
+		}
+		delta := value - s.mean
+		s.mean += alpha * delta
+		s.variance = (1 - alpha) * (s.variance + alpha*delta*delta)
+	}
+	s.observed++
+	if !ts.IsZero() {
+		s.last = ts
+	}
+
+	if ts.IsZero() || s.admitted.IsZero() || ts.Sub(s.admitted) >= t.spacing {
+		s.window[s.next] = value
+		s.next = (s.next + 1) % len(s.window)
+		if s.filled < len(s.window) {
+			s.filled++
+		}
+		if !ts.IsZero() {
+			s.admitted = ts
+		}
+	}
+}
+
+// Score returns the robust z-score of value against key's baseline. ok is
+// false while the series is still warming up.
+func (t *Tracker) Score(key Key, value float64) (z float64, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, found := t.series[key]
+	if !found || s.filled < t.cfg.WarmupSamples {
+		return 0, false
+	}
+	median, scale := s.robust()
+	return (value - median) / scale, true
+}
+
+// Snapshot returns every series sorted by signal, namespace and service.
+func (t *Tracker) Snapshot() []State {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]State, 0, len(t.series))
+	for key, s := range t.series {
+		median, mad := s.medianMAD()
+		out = append(out, State{
+			Key:          key,
+			Observations: s.observed,
+			WindowSize:   s.filled,
+			Ready:        s.filled >= t.cfg.WarmupSamples,
+			Mean:         s.mean,
+			StdDev:       math.Sqrt(s.variance),
+			Median:       median,
+			MAD:          mad,
+			LastSeen:     s.last,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Signal != out[j].Signal {
+			return out[i].Signal < out[j].Signal
+		}
+		if out[i].Namespace != out[j].Namespace {
+			return out[i].Namespace < out[j].Namespace
+		}
+		return out[i].Service < out[j].Service
+	})
+	return out
+}
+
+// robust returns the window median and a strictly positive scale: the
+// MAD-derived deviation, falling back to the EWMA deviation and finally to
+// 5% of the median for flat series.
+func (s *series) robust() (float64, float64) {
+	median, mad := s.medianMAD()
+	scale := madScale * mad
+	if scale <= 0 {
+		scale = math.Sqrt(s.variance)
+	}
+	if floor := math.Max(0.05*math.Abs(median), 1e-6); scale < floor {
+		scale = floor
+	}
+	return median, scale
+}
+
+func (s *series) medianMAD() (float64, float64) {
+	if s.filled == 0 {
+		return 0, 0
+	}
+	values := make([]float64, s.filled)
+	copy(values, s.window[:s.filled])
+	median := medianOf(values)
+	for i, v := range values {
+		values[i] = math.Abs(v - median)
+	}
+	return median, medianOf(values)
+}
+
+func medianOf(values []float64) float64 {
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 1 {
+		return values[mid]
+	}
+	return (values[mid-1] + values[mid]) / 2
+}
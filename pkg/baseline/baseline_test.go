@@ -0,0 +1,131 @@
+package baseline
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
+)
+
+var batchKey = Key{
+	Signal:   signalspec.RunqueueDelayMS,
+	Workload: signalspec.Workload{Namespace: "batch", Service: "embedder"},
+}
+
+func TestScoreRequiresWarmup(t *testing.T) {
+	tr := NewTracker(Config{Window: 10, WarmupSamples: 5})
+	for i := 0; i < 4; i++ {
+		tr.Observe(batchKey, 15, time.Time{})
+	}
+	if _, ok := tr.Score(batchKey, 100); ok {
+		t.Fatal("expected no score before warm-up")
+	}
+	tr.Observe(batchKey, 15, time.Time{})
+	if _, ok := tr.Score(batchKey, 100); !ok {
+		t.Fatal("expected score after warm-up")
+	}
+	if _, ok := tr.Score(Key{Signal: signalspec.RunqueueDelayMS}, 100); ok {
+		t.Fatal("expected no score for unseen workload")
+	}
+}
+
+func TestScoreIsRelativeToWorkload(t *testing.T) {
+	tr := NewTracker(Config{Window: 50, WarmupSamples: 10})
+	web := Key{Signal: signalspec.RunqueueDelayMS, Workload: signalspec.Workload{Namespace: "web", Service: "chat"}}
+	for i := 0; i < 50; i++ {
+		jitter := float64(i%5) - 2
+		tr.Observe(batchKey, 15+jitter, time.Time{})
+		tr.Observe(web, 2+jitter/10, time.Time{})
+	}
+
+	if z, _ := tr.Score(batchKey, 16); z >= DefaultZThreshold {
+		t.Fatalf("16ms is normal for the batch job, got z=%.2f", z)
+	}
+	if z, _ := tr.Score(web, 16); z < DefaultZThreshold {
+		t.Fatalf("16ms is anomalous for the web service, got z=%.2f", z)
+	}
+	if z, _ := tr.Score(batchKey, 60); z < DefaultZThreshold {
+		t.Fatalf("60ms is anomalous for the batch job, got z=%.2f", z)
+	}
+}
+
+func TestFlatSeriesUsesRelativeFloor(t *testing.T) {
+	tr := NewTracker(Config{Window: 10, WarmupSamples: 3})
+	for i := 0; i < 10; i++ {
+		tr.Observe(batchKey, 20, time.Time{})
+	}
+	z, ok := tr.Score(batchKey, 21)
+	if !ok || math.Abs(z-1) > 1e-9 {
+		t.Fatalf("expected z=1 against a 5%% floor, got %.4f (ok=%v)", z, ok)
+	}
+}
+
+func TestIncidentDoesNotBecomeBaseline(t *testing.T) {
+	tr := NewTracker(Config{Window: 60, WarmupSamples: 10, HalfLife: time.Hour})
+	start := time.Unix(1710000000, 0)
+	ts := start
+	for i := 0; i < 60; i++ {
+		ts = start.Add(time.Duration(i) * time.Minute)
+		tr.Observe(batchKey, 15+float64(i%3), ts)
+	}
+	// A ten-minute incident at one event per second.
+	for i := 0; i < 600; i++ {
+		ts = ts.Add(time.Second)
+		tr.Observe(batchKey, 90, ts)
+	}
+	if z, _ := tr.Score(batchKey, 90); z < DefaultZThreshold {
+		t.Fatalf("incident absorbed into baseline, z=%.2f", z)
+	}
+	for _, st := range tr.Snapshot() {
+		if st.Mean > 30 {
+			t.Fatalf("EWMA mean drifted to %.2f during incident", st.Mean)
+		}
+	}
+}
+
+func TestBaselineFollowsSustainedShift(t *testing.T) {
+	tr := NewTracker(Config{Window: 60, WarmupSamples: 10, HalfLife: time.Hour})
+	start := time.Unix(1710000000, 0)
+	for i := 0; i < 60; i++ {
+		tr.Observe(batchKey, 15+float64(i%3), start.Add(time.Duration(i)*time.Minute))
+	}
+	shifted := start.Add(time.Hour)
+	for i := 0; i < 180; i++ {
+		tr.Observe(batchKey, 25+float64(i%3), shifted.Add(time.Duration(i)*time.Minute))
+	}
+	if z, _ := tr.Score(batchKey, 26); z >= DefaultZThreshold {
+		t.Fatalf("expected baseline to follow a three-hour shift, got z=%.2f", z)
+	}
+}
+
+func TestSnapshotOrderAndState(t *testing.T) {
+	tr := NewTracker(Config{Window: 4, WarmupSamples: 2})
+	other := Key{Signal: signalspec.DNSLatencyMS, Workload: signalspec.Workload{Namespace: "web"}}
+	tr.Observe(batchKey, 10, time.Time{})
+	tr.Observe(other, 3, time.Time{})
+	tr.Observe(other, 5, time.Time{})
+
+	snap := tr.Snapshot()
+	if len(snap) != 2 || snap[0].Key != other || snap[1].Key != batchKey {
+		t.Fatalf("unexpected snapshot order: %+v", snap)
+	}
+	if !snap[0].Ready || snap[1].Ready {
+		t.Fatalf("unexpected readiness: %+v", snap)
+	}
+	if snap[0].Median != 4 || snap[0].MAD != 1 || snap[0].Observations != 2 {
+		t.Fatalf("unexpected dns state: %+v", snap[0])
+	}
+}
+
+func TestParseElevation(t *testing.T) {
+	for in, want := range map[string]string{"": ElevationStatic, "Static": ElevationStatic, " zscore ": ElevationZScore} {
+		got, err := ParseElevation(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseElevation(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseElevation("ewma"); err == nil {
+		t.Fatal("expected error for unknown mode")
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"runtime"
 	"sort"
 	"strconv"
 	"time"
@@ -52,9 +51,9 @@ func GenerateArtifactsWithOptions(
 	}
 	predictions := attribution.BuildAttributions(samples, attributionMode)
 
-	schemaPath := filepath.Join(projectRoot(), "docs", "contracts", "v1", "incident-attribution.schema.json")
+	validator := schema.IncidentAttributionValidator()
 	for _, prediction := range predictions {
-		if err := schema.ValidateAgainstSchema(schemaPath, prediction); err != nil {
+		if err := validator.Validate(prediction); err != nil {
 			return fmt.Errorf("validate attribution schema: %w", err)
 		}
 	}
@@ -470,14 +469,6 @@ func writeReportMarkdown(path string, summary benchmarkSummary) error {
 	return nil
 }
 
-func projectRoot() string {
-	_, filename, _, ok := runtime.Caller(0)
-	if !ok {
-		return "."
-	}
-	return filepath.Clean(filepath.Join(filepath.Dir(filename), "..", ".."))
-}
-
 func getenvOrDefault(key string, fallback string) string {
 	value := os.Getenv(key)
 	if value == "" {
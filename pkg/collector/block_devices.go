@@ -0,0 +1,71 @@
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// BlockDeviceResolver names block devices by number through
+// /sys/dev/block/<major>:<minor>, which links to the device's sysfs
+// directory. Names are cached; devices that cannot be resolved are
+// reported as "major:minor" and retried on the next lookup.
+type BlockDeviceResolver struct {
+	sysRoot string
+
+	mu    sync.Mutex
+	names map[[2]uint32]string
+}
+
+// NewBlockDeviceResolver creates a resolver over a sysfs mount, usually
+// /sys.
+func NewBlockDeviceResolver(sysRoot string) *BlockDeviceResolver {
+	return &BlockDeviceResolver{sysRoot: sysRoot, names: make(map[[2]uint32]string)}
+}
+
+// Name returns the kernel name of device major:minor, e.g. "nvme0n1p2".
+func (r *BlockDeviceResolver) Name(major, minor uint32) string {
+	key := [2]uint32{major, minor}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if name, ok := r.names[key]; ok {
+		return name
+	}
+	name, ok := r.lookup(major, minor)
+	if !ok {
+		return fmt.Sprintf("%d:%d", major, minor)
+	}
+	r.names[key] = name
+	return name
+}
+
+func (r *BlockDeviceResolver) lookup(major, minor uint32) (string, bool) {
+	dir := filepath.Join(r.sysRoot, "dev", "block", fmt.Sprintf("%d:%d", major, minor))
+	if name, ok := ueventDevName(filepath.Join(dir, "uevent")); ok {
+		return name, true
+	}
+	target, err := os.Readlink(dir)
+	if err != nil {
+		return "", false
+	}
+	return filepath.Base(target), true
+}
+
+// ueventDevName reads DEVNAME from a sysfs uevent file.
+func ueventDevName(path string) (string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if name, ok := strings.CutPrefix(scanner.Text(), "DEVNAME="); ok && name != "" {
+			return name, true
+		}
+	}
+	return "", false
+}
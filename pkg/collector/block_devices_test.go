@@ -0,0 +1,51 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBlockDeviceResolver(t *testing.T) {
+	sys := t.TempDir()
+	devBlock := filepath.Join(sys, "dev", "block")
+	nvme := filepath.Join(sys, "devices", "pci0000:00", "0000:00:04.0", "nvme", "nvme0", "nvme0n1", "nvme0n1p2")
+	loop := filepath.Join(sys, "devices", "virtual", "block", "loop3")
+	for _, dir := range []string{devBlock, nvme, loop} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(nvme, "uevent"), []byte("MAJOR=259\nMINOR=2\nDEVNAME=nvme0n1p2\nDEVTYPE=partition\n"), 0o644); err != nil {
+		t.Fatalf("write uevent: %v", err)
+	}
+	if err := os.Symlink(nvme, filepath.Join(devBlock, "259:2")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	// No uevent: the link target names the device.
+	if err := os.Symlink(loop, filepath.Join(devBlock, "7:3")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	r := NewBlockDeviceResolver(sys)
+	for _, tc := range []struct {
+		major, minor uint32
+		want         string
+	}{
+		{259, 2, "nvme0n1p2"},
+		{7, 3, "loop3"},
+		{8, 16, "8:16"},
+	} {
+		if got := r.Name(tc.major, tc.minor); got != tc.want {
+			t.Errorf("Name(%d, %d) = %q, expected %q", tc.major, tc.minor, got, tc.want)
+		}
+	}
+
+	// Resolved names are cached.
+	if err := os.Remove(filepath.Join(devBlock, "259:2")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if got := r.Name(259, 2); got != "nvme0n1p2" {
+		t.Fatalf("expected cached name, got %q", got)
+	}
+}
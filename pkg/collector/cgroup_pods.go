@@ -0,0 +1,111 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// PodResolver maps cgroup IDs to pod UIDs. *CgroupPodResolver implements
+// it.
+type PodResolver interface {
+	PodUID(cgroupID uint64) (string, bool)
+}
+
+// podLabel labels a cgroup by its pod UID when known, else as
+// "cgroup/<id>".
+func podLabel(pods PodResolver, cgroupID uint64) string {
+	if pods != nil {
+		if uid, ok := pods.PodUID(cgroupID); ok {
+			return uid
+		}
+	}
+	return fmt.Sprintf("cgroup/%d", cgroupID)
+}
+
+// CgroupPodResolver maps cgroup v2 IDs, as stamped on kernel events, to
+// pod UIDs. A cgroup v2 ID is the inode number of the cgroup's directory,
+// so walking the hierarchy finds the pod of every ID; container cgroups
+// below a pod resolve to that pod.
+type CgroupPodResolver struct {
+	root string
+
+	mu   sync.RWMutex
+	pods map[uint64]string
+}
+
+// NewCgroupPodResolver creates a resolver rooted at a cgroup v2 mount,
+// usually /sys/fs/cgroup. Call Refresh before the first lookup.
+func NewCgroupPodResolver(root string) *CgroupPodResolver {
+	return &CgroupPodResolver{root: root, pods: make(map[uint64]string)}
+}
+
+// Refresh rewalks the hierarchy, picking up new pods and forgetting
+// removed ones.
+func (r *CgroupPodResolver) Refresh() error {
+	pods := make(map[uint64]string, len(r.pods))
+	var podDir, podUID string
+
+	err := filepath.WalkDir(r.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Pods come and go between readdir and open; skip vanished paths.
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if podDir == "" || !strings.HasPrefix(path, podDir+string(filepath.Separator)) {
+			podDir, podUID = "", ""
+			if uid, ok := podUIDFromCgroupDir(d.Name()); ok {
+				podDir, podUID = path, uid
+			}
+		}
+		if podUID == "" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if st, ok := info.Sys().(*syscall.Stat_t); ok {
+			pods[st.Ino] = podUID
+		}
+		return nil
+	})
+
+	r.mu.Lock()
+	r.pods = pods
+	r.mu.Unlock()
+	return err
+}
+
+// PodUID returns the pod owning cgroup id.
+func (r *CgroupPodResolver) PodUID(id uint64) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	uid, ok := r.pods[id]
+	return uid, ok
+}
+
+// CgroupIDs returns the IDs of the pod's cgroup and the container cgroups
+// below it, in ascending order.
+func (r *CgroupPodResolver) CgroupIDs(podUID string) []uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var ids []uint64
+	for id, uid := range r.pods {
+		if uid == podUID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,70 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func cgroupInode(t *testing.T, dir string) uint64 {
+	t.Helper()
+	fi, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	return fi.Sys().(*syscall.Stat_t).Ino
+}
+
+func TestCgroupPodResolver(t *testing.T) {
+	root := t.TempDir()
+	burstable := filepath.Join(root, "kubepods.slice", "kubepods-burstable.slice")
+	podDir := filepath.Join(burstable, "kubepods-burstable-pod"+strings.ReplaceAll(testPodUID, "-", "_")+".slice")
+	container := filepath.Join(podDir, "cri-containerd-4b1d2c3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c.scope")
+	system := filepath.Join(root, "system.slice", "kubelet.service")
+	for _, dir := range []string{container, system} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+
+	r := NewCgroupPodResolver(root)
+	if err := r.Refresh(); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	for _, dir := range []string{podDir, container} {
+		if uid, ok := r.PodUID(cgroupInode(t, dir)); !ok || uid != testPodUID {
+			t.Fatalf("PodUID(%s) = %q, %v; expected %s", dir, uid, ok, testPodUID)
+		}
+	}
+	for _, dir := range []string{burstable, system} {
+		if uid, ok := r.PodUID(cgroupInode(t, dir)); ok {
+			t.Fatalf("%s is not a pod cgroup, got %q", dir, uid)
+		}
+	}
+
+	ids := r.CgroupIDs(testPodUID)
+	if len(ids) != 2 || ids[0] > ids[1] {
+		t.Fatalf("expected the pod and container cgroups in order, got %v", ids)
+	}
+	for _, dir := range []string{podDir, container} {
+		if id := cgroupInode(t, dir); id != ids[0] && id != ids[1] {
+			t.Fatalf("CgroupIDs missing %s (%d): %v", dir, id, ids)
+		}
+	}
+
+	id := cgroupInode(t, container)
+	if err := os.RemoveAll(podDir); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := r.Refresh(); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if _, ok := r.PodUID(id); ok {
+		t.Fatal("removed pod should be forgotten")
+	}
+	if ids := r.CgroupIDs(testPodUID); len(ids) != 0 {
+		t.Fatalf("removed pod should have no cgroups, got %v", ids)
+	}
+}
@@ -0,0 +1,224 @@
+package collector
+
+import (
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
+)
+
+// DefaultChurnWindow is the length of one connection churn window.
+const DefaultChurnWindow = 10 * time.Second
+
+const (
+	// churnBaselineAlpha is the EWMA weight of one window in a baseline.
+	churnBaselineAlpha = 0.05
+	// churnWarmupWindows is the number of windows a baseline needs before
+	// it can flag a regression.
+	churnWarmupWindows = 6
+	// churnRegressionRatio is how far above its baseline a rate must be
+	// to count as a regression; it also caps what one window may add to
+	// the baseline, so a regression does not become the new normal.
+	churnRegressionRatio = 4.0
+	// churnIdleWindows is how long a pair may go without connects before
+	// its baseline is forgotten.
+	churnIdleWindows = 360
+)
+
+// ChurnKey identifies one pod and destination. Pod is a pod UID, or
+// "cgroup/<id>" for tasks outside pod cgroups; Destination is the host
+// (from passive DNS) or IP, with port.
+type ChurnKey struct {
+	Pod         string
+	Destination string
+}
+
+// ChurnSummary is one pair's new-connection rate over a window.
+type ChurnSummary struct {
+	ChurnKey
+	End          time.Time
+	Connects     int
+	Window       time.Duration
+	RatePerS     float64
+	BaselinePerS float64
+	// Regression is set when a warm baseline is exceeded by
+	// churnRegressionRatio and the rate is at least the signal's warning
+	// threshold.
+	Regression bool
+	// PID, Comm, CgroupID and Tuple are those of the window's last
+	// connect.
+	PID      int
+	Comm     string
+	CgroupID uint64
+	Tuple    schema.ConnTuple
+}
+
+type churnCount struct {
+	connects int
+	pid      int
+	comm     string
+	cgroupID uint64
+	tuple    schema.ConnTuple
+}
+
+type churnBaseline struct {
+	rate    float64
+	windows int
+	last    time.Time // start of the last window folded in
+}
+
+// ConnectionChurnTracker turns connect_latency_ms events into per-pod,
+// per-destination new-connection rates and compares each window's rate to
+// the pair's own baseline. Healthy HTTP keep-alive makes connects rare; a
+// client that loses it opens one per request. Windows are aligned and
+// closed like NoisyNeighborTracker's. It is safe for concurrent use.
+type ConnectionChurnTracker struct {
+	mu        sync.Mutex
+	window    time.Duration
+	pods      PodResolver
+	start     time.Time
+	counts    map[ChurnKey]*churnCount
+	baselines map[ChurnKey]*churnBaseline
+}
+
+// NewConnectionChurnTracker creates a tracker; window <= 0 uses
+// DefaultChurnWindow. A nil pods labels every pod by cgroup.
+func NewConnectionChurnTracker(window time.Duration, pods PodResolver) *ConnectionChurnTracker {
+	if window <= 0 {
+		window = DefaultChurnWindow
+	}
+	return &ConnectionChurnTracker{
+		window:    window,
+		pods:      pods,
+		counts:    make(map[ChurnKey]*churnCount),
+		baselines: make(map[ChurnKey]*churnBaseline),
+	}
+}
+
+// Observe adds one event and returns the summaries of the window it
+// closed, if any. Events other than connect_latency_ms with a connection
+// tuple are ignored.
+func (t *ConnectionChurnTracker) Observe(ev schema.ProbeEventV1Beta1) ([]ChurnSummary, bool) {
+	if ev.Signal != signalspec.ConnectLatencyMS || ev.ConnTuple == nil {
+		return nil, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ts := time.Unix(0, ev.TSUnixNano)
+	var (
+		closed []ChurnSummary
+		ok     bool
+	)
+	if !t.start.IsZero() && !ts.Before(t.start.Add(t.window)) {
+		closed, ok = t.closeLocked(), true
+	}
+	if t.start.IsZero() {
+		t.start = ts.Truncate(t.window)
+	}
+
+	key := ChurnKey{Pod: podLabel(t.pods, ev.CgroupID), Destination: churnDestination(ev.ConnTuple)}
+	count := t.counts[key]
+	if count == nil {
+		count = &churnCount{}
+		t.counts[key] = count
+	}
+	count.connects++
+	count.pid = ev.PID
+	count.comm = ev.Comm
+	count.cgroupID = ev.CgroupID
+	count.tuple = *ev.ConnTuple
+	return closed, ok
+}
+
+// Flush closes the open window if now is past its end.
+func (t *ConnectionChurnTracker) Flush(now time.Time) ([]ChurnSummary, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.start.IsZero() || now.Before(t.start.Add(t.window)) {
+		return nil, false
+	}
+	return t.closeLocked(), true
+}
+
+// closeLocked scores the window against each pair's baseline, folds it in
+// and returns the summaries, highest rate first.
+func (t *ConnectionChurnTracker) closeLocked() []ChurnSummary {
+	desc, _ := signalspec.Lookup(signalspec.ConnectionChurnPerS)
+	seconds := t.window.Seconds()
+	out := make([]ChurnSummary, 0, len(t.counts))
+	for key, count := range t.counts {
+		base := t.baselines[key]
+		if base == nil {
+			base = &churnBaseline{}
+			t.baselines[key] = base
+		}
+		// Windows without connects since the last one count as zero.
+		if !base.last.IsZero() {
+			if idle := int(t.start.Sub(base.last)/t.window) - 1; idle > 0 {
+				base.rate *= math.Pow(1-churnBaselineAlpha, float64(idle))
+				base.windows += idle
+			}
+		}
+		rate := float64(count.connects) / seconds
+		s := ChurnSummary{
+			ChurnKey: key,
+			End:      t.start.Add(t.window),
+			Connects: count.connects,
+			Window:   t.window,
+			RatePerS: rate,
+			PID:      count.pid,
+			Comm:     count.comm,
+			CgroupID: count.cgroupID,
+			Tuple:    count.tuple,
+		}
+		if base.windows >= churnWarmupWindows {
+			s.BaselinePerS = base.rate
+			s.Regression = rate >= desc.Warning && rate >= churnRegressionRatio*base.rate
+		}
+		if base.windows == 0 {
+			base.rate = rate
+		} else {
+			folded := rate
+			if base.windows >= churnWarmupWindows {
+				folded = math.Min(rate, churnRegressionRatio*math.Max(base.rate, 1/seconds))
+			}
+			base.rate += churnBaselineAlpha * (folded - base.rate)
+		}
+		base.windows++
+		base.last = t.start
+		out = append(out, s)
+	}
+	for key, base := range t.baselines {
+		if t.start.Sub(base.last) > churnIdleWindows*t.window {
+			delete(t.baselines, key)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].RatePerS != out[j].RatePerS {
+			return out[i].RatePerS > out[j].RatePerS
+		}
+		if out[i].Pod != out[j].Pod {
+			return out[i].Pod < out[j].Pod
+		}
+		return out[i].Destination < out[j].Destination
+	})
+	t.start = time.Time{}
+	t.counts = make(map[ChurnKey]*churnCount)
+	return out
+}
+
+// churnDestination names a connection's destination by host when passive
+// DNS knows it, so a provider behind rotating addresses stays one pair.
+func churnDestination(tuple *schema.ConnTuple) string {
+	host := tuple.DstHost
+	if host == "" {
+		host = tuple.DstIP
+	}
+	return net.JoinHostPort(host, strconv.Itoa(tuple.DstPort))
+}
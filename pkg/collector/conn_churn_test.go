@@ -0,0 +1,113 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
+)
+
+func connectEvent(ts time.Time, cgroupID uint64, ip, host string) schema.ProbeEventV1Beta1 {
+	return schema.ProbeEventV1Beta1{
+		SchemaVersion:  schema.ProbeSchemaV1Beta1,
+		TSUnixNano:     ts.UnixNano(),
+		Signal:         signalspec.ConnectLatencyMS,
+		Node:           "node-1",
+		Namespace:      "default",
+		Pod:            "agent-0",
+		PID:            4242,
+		TID:            4242,
+		Comm:           "python3",
+		CgroupID:       cgroupID,
+		ConnTuple:      &schema.ConnTuple{SrcIP: "10.0.0.7", DstIP: ip, SrcPort: 40000, DstPort: 443, Protocol: "tcp", DstHost: host},
+		Value:          40,
+		Unit:           "ms",
+		Status:         "ok",
+		SamplingWeight: 1,
+	}
+}
+
+func TestConnectionChurnTrackerFlagsReuseRegression(t *testing.T) {
+	tr := NewConnectionChurnTracker(10*time.Second, staticPods{101: "pod-a"})
+	start := time.Unix(1700000000, 0)
+
+	// One connect per window to rotating provider addresses is the
+	// keep-alive baseline; the host keeps it one pair.
+	var last []ChurnSummary
+	for w := 0; w < churnWarmupWindows+1; w++ {
+		ip := []string{"104.18.6.192", "104.18.7.192"}[w%2]
+		if closed, ok := tr.Observe(connectEvent(start.Add(time.Duration(w)*10*time.Second), 101, ip, "api.openai.com")); ok {
+			last = closed
+		}
+	}
+	if len(last) != 1 || last[0].Destination != "api.openai.com:443" || last[0].Pod != "pod-a" || last[0].Regression {
+		t.Fatalf("unexpected warm-up window %+v", last)
+	}
+
+	// Then every request opens a connection.
+	regressed := start.Add(time.Duration(churnWarmupWindows+1) * 10 * time.Second)
+	for i := 0; i < 60; i++ {
+		tr.Observe(connectEvent(regressed.Add(time.Duration(i)*100*time.Millisecond), 101, "104.18.7.192", "api.openai.com"))
+	}
+	closed, ok := tr.Flush(regressed.Add(10 * time.Second))
+	if !ok || len(closed) != 1 {
+		t.Fatalf("expected one summary, got %+v (%v)", closed, ok)
+	}
+	s := closed[0]
+	if s.Connects != 60 || s.RatePerS != 6 || !s.Regression {
+		t.Fatalf("expected a regression at 6/s, got %+v", s)
+	}
+	if s.BaselinePerS < 0.09 || s.BaselinePerS > 0.11 {
+		t.Fatalf("baseline should stay near 0.1/s, got %v", s.BaselinePerS)
+	}
+	if !s.End.Equal(regressed.Add(10*time.Second)) || s.PID != 4242 || s.Tuple.DstIP != "104.18.7.192" {
+		t.Fatalf("unexpected summary identity %+v", s)
+	}
+}
+
+func TestConnectionChurnTrackerNeedsWarmBaseline(t *testing.T) {
+	tr := NewConnectionChurnTracker(10*time.Second, nil)
+	start := time.Unix(1700000000, 0)
+	for i := 0; i < 80; i++ {
+		tr.Observe(connectEvent(start.Add(time.Duration(i)*100*time.Millisecond), 7, "10.244.3.17", ""))
+	}
+	tr.Observe(connectEvent(start, 7, "10.244.3.17", "api.openai.com"))
+	closed, ok := tr.Flush(start.Add(10 * time.Second))
+	if !ok || len(closed) != 2 {
+		t.Fatalf("expected two pairs, got %+v", closed)
+	}
+	if closed[0].Destination != "10.244.3.17:443" || closed[0].Pod != "cgroup/7" || closed[0].RatePerS != 8 {
+		t.Fatalf("unexpected top pair %+v", closed[0])
+	}
+	if closed[0].Regression || closed[0].BaselinePerS != 0 {
+		t.Fatalf("a cold pair should not be flagged: %+v", closed[0])
+	}
+	if _, ok := tr.Flush(start.Add(20 * time.Second)); ok {
+		t.Fatal("flush without an open window should not close one")
+	}
+}
+
+func TestChurnEventsValidate(t *testing.T) {
+	c := NewRingBufConsumer(1, EventMetadata{Node: "node-1"})
+	start := time.Unix(1700000000, 0)
+	for i := 0; i < 30; i++ {
+		if out := c.churnEvents(connectEvent(start.Add(time.Duration(i)*300*time.Millisecond), 9, "104.18.7.192", "api.openai.com")); len(out) != 0 {
+			t.Fatalf("window should still be open, got %+v", out)
+		}
+	}
+	out := c.churnEvents(connectEvent(start.Add(12*time.Second), 9, "104.18.7.192", "api.openai.com"))
+	if len(out) != 1 {
+		t.Fatalf("expected one churn event, got %+v", out)
+	}
+	ev := out[0]
+	if ev.Signal != signalspec.ConnectionChurnPerS || ev.Value != 3 || ev.Status != "warning" || ev.Unit != "per_s" {
+		t.Fatalf("unexpected churn event %s=%v %s (%s)", ev.Signal, ev.Value, ev.Unit, ev.Status)
+	}
+	if ev.Churn == nil || ev.Churn.Destination != "api.openai.com:443" || ev.Churn.Connects != 30 || ev.Churn.WindowS != 10 {
+		t.Fatalf("unexpected churn details %+v", ev.Churn)
+	}
+	if err := schema.ProbeEventV1Beta1Validator().Validate(ev); err != nil {
+		t.Fatalf("churn event should validate: %v", err)
+	}
+}
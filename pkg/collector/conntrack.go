@@ -0,0 +1,111 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConntrackSample is one reading of the node's conntrack table size.
+type ConntrackSample struct {
+	Timestamp time.Time
+	Count     uint64
+	Max       uint64
+}
+
+// UtilizationPct returns Count as a percentage of Max, or 0 when Max is
+// unknown.
+func (s ConntrackSample) UtilizationPct() float64 {
+	if s.Max == 0 {
+		return 0
+	}
+	return float64(s.Count) / float64(s.Max) * 100
+}
+
+// ConntrackPoller reads nf_conntrack_count and nf_conntrack_max from the
+// node's procfs. The agent runs in the host network namespace, so these
+// are the table that pod egress is tracked in.
+type ConntrackPoller struct {
+	dir      string
+	interval time.Duration
+}
+
+// NewConntrackPoller creates a poller for the procfs mounted at procRoot,
+// usually /proc.
+func NewConntrackPoller(procRoot string, interval time.Duration) *ConntrackPoller {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	return &ConntrackPoller{
+		dir:      filepath.Join(procRoot, "sys", "net", "netfilter"),
+		interval: interval,
+	}
+}
+
+// Available reports whether the nf_conntrack module is loaded.
+func (p *ConntrackPoller) Available() error {
+	if _, err := os.Stat(filepath.Join(p.dir, "nf_conntrack_count")); err != nil {
+		return fmt.Errorf("nf_conntrack not loaded: %w", err)
+	}
+	return nil
+}
+
+// Poll reads the current table size and limit.
+func (p *ConntrackPoller) Poll(now time.Time) (ConntrackSample, error) {
+	count, err := readProcUint(filepath.Join(p.dir, "nf_conntrack_count"))
+	if err != nil {
+		return ConntrackSample{}, err
+	}
+	limit, err := readProcUint(filepath.Join(p.dir, "nf_conntrack_max"))
+	if err != nil {
+		return ConntrackSample{}, err
+	}
+	return ConntrackSample{Timestamp: now.UTC(), Count: count, Max: limit}, nil
+}
+
+// Start polls until context cancellation, calling emit with each sample
+// and onErr with read errors.
+func (p *ConntrackPoller) Start(ctx context.Context, emit func(ConntrackSample), onErr func(error)) {
+	if emit == nil {
+		return
+	}
+	poll := func(ts time.Time) {
+		sample, err := p.Poll(ts)
+		if err != nil {
+			if onErr != nil {
+				onErr(err)
+			}
+			return
+		}
+		emit(sample)
+	}
+
+	poll(time.Now())
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ts := <-ticker.C:
+			poll(ts)
+		}
+	}
+}
+
+func readProcUint(path string) (uint64, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseUint(strings.TrimSpace(string(raw)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+	return value, nil
+}
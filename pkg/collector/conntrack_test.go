@@ -0,0 +1,51 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConntrackPollerReadsTable(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "sys", "net", "netfilter")
+	p := NewConntrackPoller(root, 0)
+	if err := p.Available(); err == nil {
+		t.Fatal("poller should be unavailable without nf_conntrack")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, value string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("nf_conntrack_count", "235930\n")
+	write("nf_conntrack_max", "262144\n")
+	if err := p.Available(); err != nil {
+		t.Fatalf("expected poller to be available: %v", err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	sample, err := p.Poll(now)
+	if err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if sample.Count != 235930 || sample.Max != 262144 || !sample.Timestamp.Equal(now) {
+		t.Fatalf("unexpected sample %+v", sample)
+	}
+	if pct := sample.UtilizationPct(); pct < 89.9 || pct > 90.1 {
+		t.Fatalf("expected ~90%% utilization, got %v", pct)
+	}
+	if (ConntrackSample{Count: 5}).UtilizationPct() != 0 {
+		t.Fatal("unknown max should report 0%")
+	}
+
+	write("nf_conntrack_max", "unlimited\n")
+	if _, err := p.Poll(now); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
@@ -0,0 +1,171 @@
+package collector
+
+import (
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
+)
+
+// DefaultDiskIOWindow is the length of one disk I/O summary window.
+const DefaultDiskIOWindow = time.Minute
+
+// diskIOReservoir bounds the latencies kept per series for percentiles.
+const diskIOReservoir = 1024
+
+// DiskIOKey identifies one disk I/O latency series. Pod is the issuing
+// pod's UID, or "cgroup/<id>" for tasks outside pod cgroups.
+type DiskIOKey struct {
+	Device string
+	Pod    string
+	Op     string
+}
+
+// DiskIOSummary summarizes one series over a window.
+type DiskIOSummary struct {
+	DiskIOKey
+	Count int
+	Bytes int64
+	P50MS float64
+	P95MS float64
+	MaxMS float64
+	// Background is set for I/O issued outside pod cgroups, such as
+	// writeback and swap by kernel threads. Slow background writes point
+	// at memory_pressure, slow pod reads at the pod's storage backend.
+	Background bool
+}
+
+type diskIOSeries struct {
+	count      int
+	bytes      int64
+	max        float64
+	samples    []float64
+	background bool
+}
+
+// DiskIOSummarizer aggregates disk_io_latency_ms events that carry block
+// request details (schema.DiskIO) into per-device, per-pod, per-operation
+// latency summaries. Windows are aligned and closed like
+// NoisyNeighborTracker's. It is safe for concurrent use.
+type DiskIOSummarizer struct {
+	mu     sync.Mutex
+	window time.Duration
+	pods   PodResolver
+	start  time.Time
+	series map[DiskIOKey]*diskIOSeries
+}
+
+// NewDiskIOSummarizer creates a summarizer; window <= 0 uses
+// DefaultDiskIOWindow. With a nil pods every series is keyed by cgroup and
+// none is marked Background.
+func NewDiskIOSummarizer(window time.Duration, pods PodResolver) *DiskIOSummarizer {
+	if window <= 0 {
+		window = DefaultDiskIOWindow
+	}
+	return &DiskIOSummarizer{
+		window: window,
+		pods:   pods,
+		series: make(map[DiskIOKey]*diskIOSeries),
+	}
+}
+
+// Observe adds one event and returns the summaries of the window it
+// closed, if any. Events without disk details are ignored.
+func (s *DiskIOSummarizer) Observe(ev schema.ProbeEventV1Beta1) ([]DiskIOSummary, bool) {
+	if ev.Signal != signalspec.DiskIOLatencyMS || ev.Disk == nil {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ts := time.Unix(0, ev.TSUnixNano)
+	var (
+		closed []DiskIOSummary
+		ok     bool
+	)
+	if !s.start.IsZero() && !ts.Before(s.start.Add(s.window)) {
+		closed, ok = s.closeLocked(), true
+	}
+	if s.start.IsZero() {
+		s.start = ts.Truncate(s.window)
+	}
+
+	key := DiskIOKey{Device: ev.Disk.Device, Pod: podLabel(s.pods, ev.CgroupID), Op: ev.Disk.Op}
+	series := s.series[key]
+	if series == nil {
+		series = &diskIOSeries{background: s.pods != nil && strings.HasPrefix(key.Pod, "cgroup/")}
+		s.series[key] = series
+	}
+	series.count++
+	series.bytes += int64(ev.Disk.Bytes)
+	if ev.Value > series.max {
+		series.max = ev.Value
+	}
+	if len(series.samples) < diskIOReservoir {
+		series.samples = append(series.samples, ev.Value)
+	} else if i := rand.IntN(series.count); i < diskIOReservoir {
+		series.samples[i] = ev.Value
+	}
+	return closed, ok
+}
+
+// Flush closes the open window if now is past its end.
+func (s *DiskIOSummarizer) Flush(now time.Time) ([]DiskIOSummary, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.start.IsZero() || now.Before(s.start.Add(s.window)) {
+		return nil, false
+	}
+	return s.closeLocked(), true
+}
+
+// closeLocked returns the window's summaries, slowest p95 first.
+func (s *DiskIOSummarizer) closeLocked() []DiskIOSummary {
+	out := make([]DiskIOSummary, 0, len(s.series))
+	for key, series := range s.series {
+		sort.Float64s(series.samples)
+		out = append(out, DiskIOSummary{
+			DiskIOKey:  key,
+			Count:      series.count,
+			Bytes:      series.bytes,
+			P50MS:      sortedQuantile(series.samples, 0.50),
+			P95MS:      sortedQuantile(series.samples, 0.95),
+			MaxMS:      series.max,
+			Background: series.background,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].P95MS != out[j].P95MS {
+			return out[i].P95MS > out[j].P95MS
+		}
+		a, b := out[i].DiskIOKey, out[j].DiskIOKey
+		if a.Device != b.Device {
+			return a.Device < b.Device
+		}
+		if a.Pod != b.Pod {
+			return a.Pod < b.Pod
+		}
+		return a.Op < b.Op
+	})
+	s.start = time.Time{}
+	s.series = make(map[DiskIOKey]*diskIOSeries)
+	return out
+}
+
+// sortedQuantile interpolates the q-quantile of ascending values.
+func sortedQuantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q * float64(len(sorted)-1)
+	lo := int(pos)
+	if lo+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(lo)
+	return sorted[lo] + frac*(sorted[lo+1]-sorted[lo])
+}
@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
+)
+
+func diskEvent(at time.Duration, cgroup uint64, device, op string, ms float64) schema.ProbeEventV1Beta1 {
+	return schema.ProbeEventV1Beta1{
+		TSUnixNano: time.Unix(1700000040, 0).Add(at).UnixNano(),
+		Signal:     signalspec.DiskIOLatencyMS,
+		CgroupID:   cgroup,
+		Value:      ms,
+		Disk:       &schema.DiskIO{Device: device, Op: op, Bytes: 4096},
+	}
+}
+
+func TestDiskIOSummarizer(t *testing.T) {
+	pods := staticPods{101: "qdrant-uid", 102: "vllm-uid"}
+	s := NewDiskIOSummarizer(time.Minute, pods)
+
+	events := []schema.ProbeEventV1Beta1{
+		// Kernel writeback from the root cgroup.
+		diskEvent(0, 1, "nvme0n1", "write", 40),
+		diskEvent(time.Second, 1, "nvme0n1", "write", 80),
+		// Model weights read by the inference pod.
+		diskEvent(2*time.Second, 102, "nvme1n1", "read", 3),
+		// Vector DB reads.
+		diskEvent(3*time.Second, 101, "nvme0n1", "read", 2),
+		diskEvent(4*time.Second, 101, "nvme0n1", "read", 4),
+		diskEvent(5*time.Second, 101, "nvme0n1", "read", 6),
+		// Ignored: no disk details.
+		{Signal: signalspec.DiskIOLatencyMS, CgroupID: 101, Value: 900},
+	}
+	for _, ev := range events {
+		if _, ok := s.Observe(ev); ok {
+			t.Fatalf("window closed early at %+v", ev)
+		}
+	}
+
+	out, ok := s.Flush(time.Unix(1700000100, 0))
+	if !ok || len(out) != 3 {
+		t.Fatalf("expected three series, got %+v", out)
+	}
+	wb := out[0]
+	if wb.Device != "nvme0n1" || wb.Pod != "cgroup/1" || wb.Op != "write" || !wb.Background {
+		t.Fatalf("expected background writeback first, got %+v", wb)
+	}
+	if wb.Count != 2 || wb.Bytes != 8192 || wb.P50MS != 60 || wb.P95MS != 78 || wb.MaxMS != 80 {
+		t.Fatalf("unexpected writeback summary %+v", wb)
+	}
+	db := out[1]
+	if db.Pod != "qdrant-uid" || db.Op != "read" || db.Background || db.Count != 3 || db.P50MS != 4 || db.MaxMS != 6 {
+		t.Fatalf("unexpected vector DB summary %+v", db)
+	}
+	if out[2].Pod != "vllm-uid" || out[2].Device != "nvme1n1" {
+		t.Fatalf("unexpected model read summary %+v", out[2])
+	}
+
+	if _, ok := s.Flush(time.Unix(1700000200, 0)); ok {
+		t.Fatal("no window should be open after flush")
+	}
+	if _, ok := s.Observe(diskEvent(time.Minute, 101, "nvme0n1", "read", 1)); ok {
+		t.Fatal("first event of a new window should not close one")
+	}
+	if out, ok := s.Observe(diskEvent(2*time.Minute, 101, "nvme0n1", "read", 1)); !ok || len(out) != 1 {
+		t.Fatalf("expected the next window to close on a later event, got %+v", out)
+	}
+}
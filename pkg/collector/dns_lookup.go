@@ -0,0 +1,167 @@
+package collector
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// DNS response codes the lookup tracker distinguishes.
+const (
+	DNSRCodeNoError  = 0
+	DNSRCodeNXDomain = 3
+)
+
+// DefaultDNSLookupWindow bounds the gap between two queries of the same
+// logical lookup. Resolvers walk the search path back to back, so a longer
+// pause starts a new lookup.
+const DefaultDNSLookupWindow = 2 * time.Second
+
+// DNSQueryObservation is one answered DNS query as reported by the probe.
+type DNSQueryObservation struct {
+	Timestamp time.Time
+	PID       int
+	QName     string
+	QType     int
+	RCode     int
+}
+
+// DNSLookup is one logical name resolution: every query a resolver issued
+// for a name while walking the search path, up to the first answer that
+// was not NXDOMAIN or until it gave up.
+type DNSLookup struct {
+	PID   int
+	QType int
+	// Name is the leading labels shared by all queries, i.e. the name the
+	// application asked for before search suffixes were appended.
+	Name      string
+	Queries   int
+	NXDomains int
+	// RCode is the final query's response code.
+	RCode int
+	Start time.Time
+	End   time.Time
+}
+
+// Amplified reports whether search-path expansion added queries.
+func (l DNSLookup) Amplified() bool {
+	return l.Queries > 1 && l.NXDomains > 0
+}
+
+type dnsLookupKey struct {
+	pid   int
+	qtype int
+}
+
+// DNSLookupTracker groups per-query DNS observations into logical lookups.
+// A query continues the open lookup of the same process and qtype when
+// the previous answer was NXDOMAIN, it arrived within the window, and the
+// two names share their leading labels; anything else closes the lookup.
+// A and AAAA lookups issued in parallel are tracked separately.
+type DNSLookupTracker struct {
+	mu     sync.Mutex
+	window time.Duration
+	open   map[dnsLookupKey]*DNSLookup
+}
+
+// NewDNSLookupTracker creates a tracker; window <= 0 uses
+// DefaultDNSLookupWindow.
+func NewDNSLookupTracker(window time.Duration) *DNSLookupTracker {
+	if window <= 0 {
+		window = DefaultDNSLookupWindow
+	}
+	return &DNSLookupTracker{
+		window: window,
+		open:   make(map[dnsLookupKey]*DNSLookup),
+	}
+}
+
+// Observe records one answered query and returns the lookups it closed,
+// including lookups of other processes that have been idle past the window.
+func (t *DNSLookupTracker) Observe(q DNSQueryObservation) []DNSLookup {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	closed := t.expire(q.Timestamp)
+	key := dnsLookupKey{pid: q.PID, qtype: q.QType}
+	name := strings.TrimSuffix(strings.ToLower(q.QName), ".")
+
+	cur, ok := t.open[key]
+	if ok {
+		shared := sharedLeadingLabels(cur.Name, name)
+		if cur.RCode == DNSRCodeNXDomain && shared != "" && q.Timestamp.Sub(cur.End) <= t.window {
+			cur.Name = shared
+		} else {
+			closed = append(closed, *cur)
+			delete(t.open, key)
+			cur = nil
+		}
+	}
+	if cur == nil {
+		cur = &DNSLookup{PID: q.PID, QType: q.QType, Name: name, Start: q.Timestamp}
+		t.open[key] = cur
+	}
+
+	cur.Queries++
+	cur.RCode = q.RCode
+	cur.End = q.Timestamp
+	if q.RCode == DNSRCodeNXDomain {
+		cur.NXDomains++
+		return closed
+	}
+	// Any other answer ends the search-path walk.
+	closed = append(closed, *cur)
+	delete(t.open, key)
+	return closed
+}
+
+// Flush returns and forgets lookups idle past the window at now.
+func (t *DNSLookupTracker) Flush(now time.Time) []DNSLookup {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.expire(now)
+}
+
+func (t *DNSLookupTracker) expire(now time.Time) []DNSLookup {
+	var out []DNSLookup
+	for key, l := range t.open {
+		if now.Sub(l.End) > t.window {
+			out = append(out, *l)
+			delete(t.open, key)
+		}
+	}
+	return out
+}
+
+// sharedLeadingLabels returns the longest run of whole leading labels two
+// names have in common ("api.openai.com.svc.cluster.local" and
+// "api.openai.com.cluster.local" share "api.openai.com").
+func sharedLeadingLabels(a, b string) string {
+	la := strings.Split(a, ".")
+	lb := strings.Split(b, ".")
+	n := 0
+	for n < len(la) && n < len(lb) && la[n] == lb[n] && la[n] != "" {
+		n++
+	}
+	return strings.Join(la[:n], ".")
+}
+
+// dnsNameFromWire decodes a wire-format question name (length-prefixed
+// labels ending in a zero byte). When buf ends before the terminator the
+// labels that fit are returned with truncated set.
+func dnsNameFromWire(buf []byte) (name string, truncated bool) {
+	labels := make([]string, 0, 8)
+	for pos := 0; pos < len(buf); {
+		n := int(buf[pos])
+		if n == 0 {
+			return strings.Join(labels, "."), false
+		}
+		// Compression pointers never appear in the first question name.
+		if n&0xc0 != 0 || pos+1+n > len(buf) {
+			break
+		}
+		labels = append(labels, string(buf[pos+1:pos+1+n]))
+		pos += 1 + n
+	}
+	return strings.Join(labels, "."), true
+}
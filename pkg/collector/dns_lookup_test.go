@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDNSLookupTrackerGroupsSearchPathWalk(t *testing.T) {
+	tr := NewDNSLookupTracker(time.Second)
+	start := time.Unix(1710000000, 0)
+	walk := []struct {
+		name  string
+		rcode int
+	}{
+		{"api.openai.com.rag.svc.cluster.local", DNSRCodeNXDomain},
+		{"api.openai.com.svc.cluster.local", DNSRCodeNXDomain},
+		{"api.openai.com.cluster.local", DNSRCodeNXDomain},
+		{"api.openai.com.ec2.internal", DNSRCodeNXDomain},
+		{"api.openai.com", DNSRCodeNoError},
+	}
+
+	var closed []DNSLookup
+	for i, q := range walk {
+		ts := start.Add(time.Duration(i) * 3 * time.Millisecond)
+		closed = append(closed, tr.Observe(DNSQueryObservation{Timestamp: ts, PID: 42, QName: q.name, QType: 1, RCode: q.rcode})...)
+		// The parallel AAAA lookup resolves on its first try.
+		if i == 0 {
+			closed = append(closed, tr.Observe(DNSQueryObservation{Timestamp: ts, PID: 42, QName: "redis.rag.svc.cluster.local", QType: 28})...)
+		}
+	}
+
+	if len(closed) != 2 {
+		t.Fatalf("expected two closed lookups, got %+v", closed)
+	}
+	aaaa, a := closed[0], closed[1]
+	if aaaa.QType != 28 || aaaa.Queries != 1 || aaaa.Amplified() {
+		t.Errorf("unexpected AAAA lookup: %+v", aaaa)
+	}
+	if a.Name != "api.openai.com" || a.Queries != 5 || a.NXDomains != 4 || a.RCode != DNSRCodeNoError || !a.Amplified() {
+		t.Errorf("unexpected A lookup: %+v", a)
+	}
+}
+
+func TestDNSLookupTrackerSplitsUnrelatedAndIdleQueries(t *testing.T) {
+	tr := NewDNSLookupTracker(time.Second)
+	start := time.Unix(1710000000, 0)
+
+	if closed := tr.Observe(DNSQueryObservation{Timestamp: start, PID: 7, QName: "foo.example", QType: 1, RCode: DNSRCodeNXDomain}); len(closed) != 0 {
+		t.Fatalf("NXDOMAIN should keep the lookup open, got %+v", closed)
+	}
+	closed := tr.Observe(DNSQueryObservation{Timestamp: start.Add(10 * time.Millisecond), PID: 7, QName: "bar.example", QType: 1})
+	if len(closed) != 2 || closed[0].Name != "foo.example" || closed[1].Name != "bar.example" {
+		t.Fatalf("unrelated names should close separate lookups, got %+v", closed)
+	}
+
+	tr.Observe(DNSQueryObservation{Timestamp: start, PID: 8, QName: "gave.up.example", QType: 1, RCode: DNSRCodeNXDomain})
+	if idle := tr.Flush(start.Add(500 * time.Millisecond)); len(idle) != 0 {
+		t.Fatalf("lookup within window should stay open, got %+v", idle)
+	}
+	idle := tr.Flush(start.Add(2 * time.Second))
+	if len(idle) != 1 || idle[0].Queries != 1 || idle[0].RCode != DNSRCodeNXDomain {
+		t.Fatalf("expected one expired lookup, got %+v", idle)
+	}
+}
+
+func TestDNSNameFromWire(t *testing.T) {
+	wire := []byte("\x03api\x06openai\x03com\x00\x00\x01")
+	if name, truncated := dnsNameFromWire(wire); name != "api.openai.com" || truncated {
+		t.Fatalf("got %q truncated=%v", name, truncated)
+	}
+	if name, truncated := dnsNameFromWire(wire[:9]); name != "api" || !truncated {
+		t.Fatalf("got %q truncated=%v, want leading label", name, truncated)
+	}
+}
@@ -0,0 +1,542 @@
+package collector
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2/hpack"
+)
+
+// HTTP record kinds mirror LLM_SLO_HTTP_* in llm_slo_event.h.
+const (
+	HTTPRecordRead     = 1
+	HTTPRecordWrite    = 2
+	HTTPRecordResponse = 3
+)
+
+// HTTP protocol names reported on provider responses (ALPN identifiers).
+const (
+	HTTPProto1 = "http/1.1"
+	HTTPProto2 = "h2"
+)
+
+// DefaultHTTPConnIdle bounds how long per-connection parser state is kept
+// without traffic. Connection IDs are SSL or tls.Conn pointers, which the
+// process may reuse once a connection is freed.
+const DefaultHTTPConnIdle = 5 * time.Minute
+
+// HTTPRecord is one llm_slo_http_event from http_status.bpf.c.
+type HTTPRecord struct {
+	Timestamp time.Time
+	PID       int
+	// ConnID identifies the TLS connection (SSL* or *tls.Conn); zero for
+	// net/http response records.
+	ConnID uint64
+	Kind   int
+	// Status is set on net/http response records.
+	Status int
+	// Len is the byte count of the read or write; Data holds at most the
+	// first LLM_SLO_HTTP_DATA_LEN bytes of it. For response records Data
+	// is the request host.
+	Len  int
+	Data []byte
+}
+
+// ProviderResponse is an HTTP response status observed on a connection to
+// a provider host.
+type ProviderResponse struct {
+	Timestamp time.Time
+	PID       int
+	Host      string
+	Status    int
+	Proto     string
+	// RetryAfter is the Retry-After header as a delay; HasRetryAfter
+	// distinguishes "Retry-After: 0" from an absent header.
+	RetryAfter    time.Duration
+	HasRetryAfter bool
+}
+
+// ProviderHosts matches request hosts against configured provider hosts.
+// Entries are hostnames, optionally with a leading "*." that matches any
+// subdomain. Ports and case are ignored. An empty list matches every host.
+type ProviderHosts []string
+
+// Match reports whether host belongs to a provider.
+func (p ProviderHosts) Match(host string) bool {
+	if len(p) == 0 {
+		return true
+	}
+	host = normalizeHost(host)
+	if host == "" {
+		return false
+	}
+	for _, pattern := range p {
+		pattern = normalizeHost(pattern)
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+type httpConnKey struct {
+	pid  int
+	conn uint64
+}
+
+// httpConn is the parser state of one TLS connection.
+type httpConn struct {
+	host string
+	h2   bool
+	// out parses request bytes (writes), in parses response bytes (reads).
+	out, in h2Stream
+	last    time.Time
+}
+
+// h2Stream walks HTTP/2 frames in one direction of a connection. Frames
+// may span records; pending counts the bytes of the current frame still
+// to come, partial holds a frame header split across records.
+type h2Stream struct {
+	synced  bool
+	pending int
+	partial []byte
+	// block accumulates a header block across CONTINUATION frames;
+	// broken marks a block with bytes the probe did not capture.
+	block   []byte
+	inBlock bool
+	broken  bool
+	dec     *hpack.Decoder
+}
+
+// HTTPStatusTracker parses the plaintext records captured at the TLS
+// boundary into provider responses. HTTP/1.1 status lines and Host headers
+// are read directly; HTTP/2 HEADERS frames are decoded with HPACK, keeping
+// one dynamic table per connection and direction. Only the first bytes of
+// each read or write are captured, so a header block that did not fit
+// resets the connection's decoder and later responses may go unparsed
+// until the table is rebuilt. It is safe for concurrent use.
+type HTTPStatusTracker struct {
+	mu        sync.Mutex
+	hosts     ProviderHosts
+	idle      time.Duration
+	conns     map[httpConnKey]*httpConn
+	lastSweep time.Time
+}
+
+// NewHTTPStatusTracker creates a tracker that reports responses for hosts.
+func NewHTTPStatusTracker(hosts []string) *HTTPStatusTracker {
+	return &HTTPStatusTracker{
+		hosts: ProviderHosts(hosts),
+		idle:  DefaultHTTPConnIdle,
+		conns: make(map[httpConnKey]*httpConn),
+	}
+}
+
+// SetHosts replaces the provider host list.
+func (t *HTTPStatusTracker) SetHosts(hosts []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.hosts = ProviderHosts(hosts)
+}
+
+// Observe parses one record and returns the provider responses it
+// completed.
+func (t *HTTPStatusTracker) Observe(rec HTTPRecord) []ProviderResponse {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.sweep(rec.Timestamp)
+	if rec.Kind == HTTPRecordResponse {
+		host := string(rec.Data)
+		if !t.hosts.Match(host) {
+			return nil
+		}
+		return []ProviderResponse{{
+			Timestamp: rec.Timestamp,
+			PID:       rec.PID,
+			Host:      normalizeHost(host),
+			Status:    rec.Status,
+			Proto:     HTTPProto1,
+		}}
+	}
+
+	key := httpConnKey{pid: rec.PID, conn: rec.ConnID}
+	c := t.conns[key]
+	if rec.Kind == HTTPRecordWrite && (c == nil || bytes.HasPrefix(rec.Data, h2Preface)) {
+		// A preface starts a new connection, possibly one that reuses a
+		// freed connection ID.
+		c = &httpConn{}
+		t.conns[key] = c
+	}
+	if c == nil {
+		return nil
+	}
+	c.last = rec.Timestamp
+
+	switch rec.Kind {
+	case HTTPRecordWrite:
+		t.observeWrite(c, rec)
+		return nil
+	case HTTPRecordRead:
+		return t.observeRead(c, rec)
+	}
+	return nil
+}
+
+func (t *HTTPStatusTracker) observeWrite(c *httpConn, rec HTTPRecord) {
+	data, n := rec.Data, rec.Len
+	if bytes.HasPrefix(data, h2Preface) {
+		c.h2 = true
+		c.out = h2Stream{synced: true}
+		c.in = h2Stream{synced: true}
+		data, n = data[len(h2Preface):], n-len(h2Preface)
+	}
+	if !c.h2 {
+		if host, ok := parseHTTP1Request(data); ok {
+			c.host = host
+		}
+		return
+	}
+	c.out.walk(data, n, func(fields []hpack.HeaderField) {
+		for _, f := range fields {
+			if f.Name == ":authority" {
+				c.host = f.Value
+			}
+		}
+	}, func(size uint32) {
+		// The client's SETTINGS bound the table the server encodes with.
+		c.in.decoder().SetAllowedMaxDynamicTableSize(size)
+	})
+}
+
+func (t *HTTPStatusTracker) observeRead(c *httpConn, rec HTTPRecord) []ProviderResponse {
+	if !c.h2 {
+		status, retry, ok := parseHTTP1Response(rec.Data)
+		if !ok || !t.hosts.Match(c.host) {
+			return nil
+		}
+		return []ProviderResponse{newProviderResponse(rec, c.host, status, HTTPProto1, retry)}
+	}
+
+	var out []ProviderResponse
+	c.in.walk(rec.Data, rec.Len, func(fields []hpack.HeaderField) {
+		var (
+			status int
+			retry  string
+		)
+		for _, f := range fields {
+			switch f.Name {
+			case ":status":
+				status, _ = strconv.Atoi(f.Value)
+			case "retry-after":
+				retry = f.Value
+			}
+		}
+		// Trailers carry no :status.
+		if status == 0 || !t.hosts.Match(c.host) {
+			return
+		}
+		out = append(out, newProviderResponse(rec, c.host, status, HTTPProto2, retry))
+	}, func(size uint32) {
+		c.out.decoder().SetAllowedMaxDynamicTableSize(size)
+	})
+	return out
+}
+
+func newProviderResponse(rec HTTPRecord, host string, status int, proto, retryAfter string) ProviderResponse {
+	resp := ProviderResponse{
+		Timestamp: rec.Timestamp,
+		PID:       rec.PID,
+		Host:      normalizeHost(host),
+		Status:    status,
+		Proto:     proto,
+	}
+	if retryAfter != "" {
+		resp.RetryAfter, resp.HasRetryAfter = parseRetryAfter(retryAfter, rec.Timestamp)
+	}
+	return resp
+}
+
+// sweep drops connections idle past the idle window, at most once per
+// window.
+func (t *HTTPStatusTracker) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < t.idle {
+		return
+	}
+	t.lastSweep = now
+	for key, c := range t.conns {
+		if now.Sub(c.last) > t.idle {
+			delete(t.conns, key)
+		}
+	}
+}
+
+var h2Preface = []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")
+
+// HTTP/2 frame types and flags used by the parser (RFC 9113 section 6).
+const (
+	h2FrameHeaders      = 0x1
+	h2FrameSettings     = 0x4
+	h2FrameContinuation = 0x9
+
+	h2FlagAck        = 0x1
+	h2FlagEndHeaders = 0x4
+	h2FlagPadded     = 0x8
+	h2FlagPriority   = 0x20
+
+	h2SettingHeaderTableSize = 0x1
+	h2FrameHeaderLen         = 9
+	// h2MaxResyncFrame bounds the frame length accepted when looking for
+	// a frame boundary after losing sync; it is the protocol's default
+	// SETTINGS_MAX_FRAME_SIZE.
+	h2MaxResyncFrame = 1 << 14
+)
+
+func (s *h2Stream) decoder() *hpack.Decoder {
+	if s.dec == nil {
+		s.dec = hpack.NewDecoder(4096, nil)
+	}
+	return s.dec
+}
+
+// walk parses the frames in one record. data is the captured prefix of a
+// record n bytes long. onHeaders receives each decoded header block and
+// onTableSize each SETTINGS_HEADER_TABLE_SIZE the peer advertises.
+func (s *h2Stream) walk(data []byte, n int, onHeaders func([]hpack.HeaderField), onTableSize func(uint32)) {
+	if len(s.partial) > 0 {
+		data = append(s.partial, data...)
+		n += len(s.partial)
+		s.partial = nil
+	}
+	pos := 0
+	if s.pending > 0 {
+		if s.pending >= n {
+			s.pending -= n
+			return
+		}
+		pos, s.pending = s.pending, 0
+	} else if !s.synced {
+		if !plausibleH2Frame(data) {
+			return
+		}
+		s.synced = true
+	}
+
+	for pos < n {
+		if pos+h2FrameHeaderLen > len(data) {
+			if len(data) == n {
+				// The header continues in the next record.
+				s.partial = append([]byte(nil), data[pos:]...)
+				return
+			}
+			// The header lies past the captured bytes.
+			s.lose()
+			return
+		}
+		hdr := data[pos : pos+h2FrameHeaderLen]
+		length := int(hdr[0])<<16 | int(hdr[1])<<8 | int(hdr[2])
+		typ, flags := hdr[3], hdr[4]
+		start, end := pos+h2FrameHeaderLen, pos+h2FrameHeaderLen+length
+		if end > n {
+			s.pending = end - n
+		}
+		payload := data[start:min(end, len(data))]
+		s.frame(typ, flags, payload, len(payload) == length, onHeaders, onTableSize)
+		pos = end
+	}
+}
+
+// lose drops frame sync. The decoder's dynamic table can no longer be
+// trusted either, so it is reset.
+func (s *h2Stream) lose() {
+	s.synced = false
+	s.pending = 0
+	s.partial = nil
+	s.block, s.inBlock, s.broken = nil, false, false
+	s.dec = nil
+}
+
+func (s *h2Stream) frame(typ, flags byte, payload []byte, complete bool, onHeaders func([]hpack.HeaderField), onTableSize func(uint32)) {
+	switch typ {
+	case h2FrameHeaders:
+		frag, ok := headersFragment(flags, payload)
+		s.block = append(s.block[:0], frag...)
+		s.inBlock = true
+		s.broken = !ok || !complete
+	case h2FrameContinuation:
+		if !s.inBlock {
+			return
+		}
+		s.block = append(s.block, payload...)
+		s.broken = s.broken || !complete
+	case h2FrameSettings:
+		if flags&h2FlagAck != 0 {
+			return
+		}
+		for i := 0; i+6 <= len(payload); i += 6 {
+			if binary.BigEndian.Uint16(payload[i:]) == h2SettingHeaderTableSize {
+				onTableSize(binary.BigEndian.Uint32(payload[i+2:]))
+			}
+		}
+		return
+	default:
+		return
+	}
+	if flags&h2FlagEndHeaders == 0 {
+		return
+	}
+
+	block, broken := s.block, s.broken
+	s.block, s.inBlock, s.broken = s.block[:0], false, false
+	if broken {
+		s.dec = nil
+		return
+	}
+	fields, err := s.decoder().DecodeFull(block)
+	if err != nil {
+		s.dec = nil
+		return
+	}
+	onHeaders(fields)
+}
+
+// headersFragment strips padding and priority fields from a HEADERS
+// payload. ok is false when the payload is too short to hold them.
+func headersFragment(flags byte, payload []byte) ([]byte, bool) {
+	pad := 0
+	if flags&h2FlagPadded != 0 {
+		if len(payload) < 1 {
+			return nil, false
+		}
+		pad = int(payload[0])
+		payload = payload[1:]
+	}
+	if flags&h2FlagPriority != 0 {
+		if len(payload) < 5 {
+			return nil, false
+		}
+		payload = payload[5:]
+	}
+	if pad > len(payload) {
+		// Padding not captured yet; the fragment itself is truncated.
+		return payload, false
+	}
+	return payload[:len(payload)-pad], true
+}
+
+// plausibleH2Frame reports whether data starts with a frame header that
+// could begin a frame: a known type, a length within the default maximum
+// and the reserved bit clear.
+func plausibleH2Frame(data []byte) bool {
+	if len(data) < h2FrameHeaderLen {
+		return false
+	}
+	length := int(data[0])<<16 | int(data[1])<<8 | int(data[2])
+	return length <= h2MaxResyncFrame && data[3] <= h2FrameContinuation && data[5]&0x80 == 0
+}
+
+// parseHTTP1Request returns the request host from the Host header, or from
+// an absolute-form request target sent to a proxy.
+func parseHTTP1Request(data []byte) (string, bool) {
+	line, ok := crlfLine(data)
+	if !ok {
+		return "", false
+	}
+	parts := strings.Fields(string(line))
+	if len(parts) != 3 || !strings.HasPrefix(parts[2], "HTTP/1.") {
+		return "", false
+	}
+	if strings.Contains(parts[1], "://") {
+		if u, err := url.Parse(parts[1]); err == nil && u.Host != "" {
+			return u.Host, true
+		}
+	}
+	if host, ok := http1Header(data[len(line)+2:], "Host"); ok {
+		return host, true
+	}
+	return "", false
+}
+
+// parseHTTP1Response parses "HTTP/1.x NNN reason" and the Retry-After
+// header from the start of a response.
+func parseHTTP1Response(data []byte) (int, string, bool) {
+	line, ok := crlfLine(data)
+	if !ok || !bytes.HasPrefix(line, []byte("HTTP/1.")) {
+		return 0, "", false
+	}
+	parts := strings.SplitN(string(line), " ", 3)
+	if len(parts) < 2 || len(parts[1]) != 3 {
+		return 0, "", false
+	}
+	status, err := strconv.Atoi(parts[1])
+	if err != nil || status < 100 {
+		return 0, "", false
+	}
+	retry, _ := http1Header(data[len(line)+2:], "Retry-After")
+	return status, retry, true
+}
+
+// http1Header returns the first header named name. Only complete,
+// CRLF-terminated lines are considered.
+func http1Header(data []byte, name string) (string, bool) {
+	for {
+		line, ok := crlfLine(data)
+		if !ok || len(line) == 0 {
+			return "", false
+		}
+		data = data[len(line)+2:]
+		k, v, found := strings.Cut(string(line), ":")
+		if found && strings.EqualFold(strings.TrimSpace(k), name) {
+			return strings.TrimSpace(v), true
+		}
+	}
+}
+
+func crlfLine(data []byte) ([]byte, bool) {
+	idx := bytes.Index(data, []byte("\r\n"))
+	if idx < 0 {
+		return nil, false
+	}
+	return data[:idx], true
+}
+
+// parseRetryAfter parses a Retry-After value given in seconds or as an
+// HTTP date relative to now. Dates in the past yield zero.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	when, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if d := when.Sub(now); d > 0 {
+		return d.Round(time.Second), true
+	}
+	return 0, true
+}
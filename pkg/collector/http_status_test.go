@@ -0,0 +1,214 @@
+package collector
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2/hpack"
+)
+
+// h2Frame encodes one HTTP/2 frame.
+func h2Frame(typ, flags byte, stream uint32, payload []byte) []byte {
+	hdr := make([]byte, h2FrameHeaderLen)
+	hdr[0], hdr[1], hdr[2] = byte(len(payload)>>16), byte(len(payload)>>8), byte(len(payload))
+	hdr[3], hdr[4] = typ, flags
+	binary.BigEndian.PutUint32(hdr[5:], stream)
+	return append(hdr, payload...)
+}
+
+// h2Headers HPACK-encodes fields with enc, so successive blocks share its
+// dynamic table.
+func h2Headers(enc *hpack.Encoder, buf *bytes.Buffer, fields ...string) []byte {
+	buf.Reset()
+	for i := 0; i+1 < len(fields); i += 2 {
+		_ = enc.WriteField(hpack.HeaderField{Name: fields[i], Value: fields[i+1]})
+	}
+	return append([]byte(nil), buf.Bytes()...)
+}
+
+func httpWrite(conn uint64, data []byte) HTTPRecord {
+	return HTTPRecord{Timestamp: time.Unix(1700000000, 0), PID: 10, ConnID: conn, Kind: HTTPRecordWrite, Len: len(data), Data: data}
+}
+
+func httpRead(conn uint64, data []byte) HTTPRecord {
+	return HTTPRecord{Timestamp: time.Unix(1700000000, 0), PID: 10, ConnID: conn, Kind: HTTPRecordRead, Len: len(data), Data: data}
+}
+
+func TestProviderHostsMatch(t *testing.T) {
+	hosts := ProviderHosts{"api.openai.com", "*.openai.azure.com"}
+	for host, want := range map[string]bool{
+		"api.openai.com":                  true,
+		"API.OpenAI.com:443":              true,
+		"eastus.openai.azure.com":         true,
+		"openai.azure.com":                false,
+		"api.openai.com.evil.example":     false,
+		"vector-db.rag.svc.cluster.local": false,
+		"":                                false,
+	} {
+		if got := hosts.Match(host); got != want {
+			t.Errorf("Match(%q) = %v, expected %v", host, got, want)
+		}
+	}
+	if !(ProviderHosts{}).Match("anything.example") {
+		t.Fatal("empty host list should match every host")
+	}
+}
+
+func TestHTTPStatusTrackerHTTP1(t *testing.T) {
+	tr := NewHTTPStatusTracker([]string{"api.openai.com"})
+	if out := tr.Observe(httpWrite(1, []byte("POST /v1/chat/completions HTTP/1.1\r\nHost: api.openai.com\r\nContent-Length: 42\r\n\r\n"))); out != nil {
+		t.Fatalf("request should not report a response, got %+v", out)
+	}
+	out := tr.Observe(httpRead(1, []byte("HTTP/1.1 429 Too Many Requests\r\nContent-Type: application/json\r\nretry-after: Tue, 14 Nov 2023 22:13:50 GMT\r\n\r\n{")))
+	if len(out) != 1 {
+		t.Fatalf("expected one response, got %+v", out)
+	}
+	got := out[0]
+	if got.Host != "api.openai.com" || got.Status != 429 || got.Proto != HTTPProto1 || got.PID != 10 {
+		t.Fatalf("unexpected response %+v", got)
+	}
+	if !got.HasRetryAfter || got.RetryAfter != 30*time.Second {
+		t.Fatalf("expected Retry-After date 30s ahead, got %v (%v)", got.RetryAfter, got.HasRetryAfter)
+	}
+
+	// Body bytes of a later read are not a status line.
+	if out := tr.Observe(httpRead(1, []byte(`{"error":{"code":"rate_limit_exceeded"}}`))); out != nil {
+		t.Fatalf("body should not parse as a response, got %+v", out)
+	}
+	// A status line cut off by the capture limit is not trusted.
+	if out := tr.Observe(httpRead(1, []byte("HTTP/1.1 50"))); out != nil {
+		t.Fatalf("truncated status line should be ignored, got %+v", out)
+	}
+
+	tr.Observe(httpWrite(2, []byte("GET /healthz HTTP/1.1\r\nHost: vector-db.rag.svc:8080\r\n\r\n")))
+	if out := tr.Observe(httpRead(2, []byte("HTTP/1.1 503 Service Unavailable\r\n\r\n"))); out != nil {
+		t.Fatalf("non-provider host should be filtered, got %+v", out)
+	}
+}
+
+func TestHTTPStatusTrackerHTTP2(t *testing.T) {
+	tr := NewHTTPStatusTracker([]string{"api.anthropic.com"})
+	const conn = 0xc0001a2000
+
+	var reqBuf, respBuf bytes.Buffer
+	reqEnc, respEnc := hpack.NewEncoder(&reqBuf), hpack.NewEncoder(&respBuf)
+
+	settings := h2Frame(h2FrameSettings, 0, 0, []byte{0, 1, 0, 0, 0x10, 0}) // HEADER_TABLE_SIZE 4096
+	write := append(append([]byte(nil), h2Preface...), settings...)
+	write = append(write, h2Frame(h2FrameHeaders, h2FlagEndHeaders, 1, h2Headers(reqEnc, &reqBuf,
+		":method", "POST", ":scheme", "https", ":authority", "api.anthropic.com", ":path", "/v1/messages"))...)
+	tr.Observe(httpWrite(conn, write))
+
+	var responses []ProviderResponse
+	read := append(append([]byte(nil), settings...), h2Frame(h2FrameHeaders, h2FlagEndHeaders, 1, h2Headers(respEnc, &respBuf,
+		":status", "200", "content-type", "text/event-stream"))...)
+	read = append(read, h2Frame(0x0, 0, 1, []byte("event: message_start\n"))...)
+	responses = append(responses, tr.Observe(httpRead(conn, read))...)
+
+	// Padded, prioritised HEADERS whose :status 429 and retry-after enter
+	// the dynamic table, then a second 429 that only references it.
+	for i := 0; i < 2; i++ {
+		block := h2Headers(respEnc, &respBuf, ":status", "429", "retry-after", "7", "x-request-id", "req_01HZX")
+		payload := append([]byte{3, 0, 0, 0, 0, 16}, block...)
+		payload = append(payload, 0, 0, 0)
+		frame := h2Frame(h2FrameHeaders, h2FlagEndHeaders|h2FlagPadded|h2FlagPriority, uint32(3+2*i), payload)
+		responses = append(responses, tr.Observe(httpRead(conn, frame))...)
+	}
+
+	if len(responses) != 3 {
+		t.Fatalf("expected 3 responses, got %+v", responses)
+	}
+	if responses[0].Status != 200 || responses[0].Proto != HTTPProto2 || responses[0].Host != "api.anthropic.com" {
+		t.Fatalf("unexpected first response %+v", responses[0])
+	}
+	for _, r := range responses[1:] {
+		if r.Status != 429 || !r.HasRetryAfter || r.RetryAfter != 7*time.Second {
+			t.Fatalf("unexpected throttled response %+v", r)
+		}
+	}
+}
+
+func TestHTTPStatusTrackerHTTP2FramesSpanRecords(t *testing.T) {
+	tr := NewHTTPStatusTracker(nil)
+	const conn = 7
+
+	var reqBuf, respBuf bytes.Buffer
+	reqEnc, respEnc := hpack.NewEncoder(&reqBuf), hpack.NewEncoder(&respBuf)
+	tr.Observe(httpWrite(conn, append(append([]byte(nil), h2Preface...), h2Frame(h2FrameHeaders, h2FlagEndHeaders, 1,
+		h2Headers(reqEnc, &reqBuf, ":method", "POST", ":authority", "llm-proxy.internal:8443", ":path", "/v1/chat"))...)))
+
+	// A DATA frame whose payload continues into the next record, followed
+	// by a HEADERS frame split inside its frame header, then CONTINUATION.
+	data := h2Frame(0x0, 0, 1, bytes.Repeat([]byte("x"), 40))
+	block := h2Headers(respEnc, &respBuf, ":status", "503", "retry-after", "120")
+	headers := h2Frame(h2FrameHeaders, 0, 3, block[:2])
+	cont := h2Frame(h2FrameContinuation, h2FlagEndHeaders, 3, block[2:])
+	stream := append(append(append([]byte(nil), data...), headers...), cont...)
+
+	var out []ProviderResponse
+	for _, cut := range [][2]int{{0, 20}, {20, len(data) + 4}, {len(data) + 4, len(stream)}} {
+		out = append(out, tr.Observe(httpRead(conn, stream[cut[0]:cut[1]]))...)
+	}
+	if len(out) != 1 || out[0].Status != 503 || out[0].RetryAfter != 2*time.Minute || out[0].Host != "llm-proxy.internal" {
+		t.Fatalf("unexpected responses %+v", out)
+	}
+}
+
+func TestHTTPStatusTrackerTruncatedHeaderBlock(t *testing.T) {
+	tr := NewHTTPStatusTracker(nil)
+	const conn = 9
+	tr.Observe(httpWrite(conn, h2Preface))
+
+	var respBuf bytes.Buffer
+	respEnc := hpack.NewEncoder(&respBuf)
+	frame := h2Frame(h2FrameHeaders, h2FlagEndHeaders, 1, h2Headers(respEnc, &respBuf, ":status", "500", "x-trace", string(bytes.Repeat([]byte("a"), 1200))))
+	rec := httpRead(conn, frame[:httpDataLen])
+	rec.Len = len(frame)
+	if out := tr.Observe(rec); out != nil {
+		t.Fatalf("truncated header block should not be decoded, got %+v", out)
+	}
+
+	// The decoder was reset and the stream stays in sync.
+	respEnc = hpack.NewEncoder(&respBuf)
+	next := h2Frame(h2FrameHeaders, h2FlagEndHeaders, 3, h2Headers(respEnc, &respBuf, ":status", "502"))
+	if out := tr.Observe(httpRead(conn, next)); len(out) != 1 || out[0].Status != 502 {
+		t.Fatalf("expected 502 after reset, got %+v", out)
+	}
+}
+
+func TestHTTPStatusTrackerGoResponseRecord(t *testing.T) {
+	tr := NewHTTPStatusTracker([]string{"*.svc.cluster.local"})
+	rec := HTTPRecord{Timestamp: time.Unix(1700000000, 0), PID: 12, Kind: HTTPRecordResponse, Status: 502, Data: []byte("llm-egress.proxy.svc.cluster.local:3128")}
+	out := tr.Observe(rec)
+	if len(out) != 1 || out[0].Status != 502 || out[0].Host != "llm-egress.proxy.svc.cluster.local" || out[0].HasRetryAfter {
+		t.Fatalf("unexpected net/http response %+v", out)
+	}
+	rec.Data = []byte("api.openai.com")
+	if out := tr.Observe(rec); out != nil {
+		t.Fatalf("non-provider host should be filtered, got %+v", out)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+	cases := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"20", 20 * time.Second, true},
+		{" 0 ", 0, true},
+		{"Tue, 14 Nov 2023 22:14:20 GMT", time.Minute, true},
+		{"Tue, 14 Nov 2023 22:00:00 GMT", 0, true},
+		{"-5", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tc := range cases {
+		got, ok := parseRetryAfter(tc.in, now)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; expected %v, %v", tc.in, got, ok, tc.want, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MemoryEventCounts holds the cgroup v2 memory.events counters the toolkit
+// reports. high counts reclaim throttling above memory.high, max counts
+// allocations that hit memory.max, and oom_kill counts processes the OOM
+// killer removed from the cgroup.
+type MemoryEventCounts struct {
+	High    uint64
+	Max     uint64
+	OOMKill uint64
+}
+
+// MemoryEventsSample is one pod cgroup's counter growth since the previous
+// poll.
+type MemoryEventsSample struct {
+	Timestamp  time.Time
+	PodUID     string
+	CgroupPath string
+	Delta      MemoryEventCounts
+}
+
+// MemoryEventsPoller reads memory.events for every pod-level cgroup under a
+// cgroup v2 root and reports per-pod deltas. The first poll of a cgroup only
+// records its counters, so events from before the agent started are not
+// replayed as new.
+type MemoryEventsPoller struct {
+	root     string
+	interval time.Duration
+	last     map[string]MemoryEventCounts
+}
+
+// NewMemoryEventsPoller creates a poller rooted at a cgroup v2 mount,
+// usually /sys/fs/cgroup.
+func NewMemoryEventsPoller(root string, interval time.Duration) *MemoryEventsPoller {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	return &MemoryEventsPoller{
+		root:     root,
+		interval: interval,
+		last:     make(map[string]MemoryEventCounts),
+	}
+}
+
+// Available reports whether root is a cgroup v2 hierarchy with memory
+// accounting.
+func (p *MemoryEventsPoller) Available() error {
+	if _, err := os.Stat(filepath.Join(p.root, "cgroup.controllers")); err != nil {
+		return fmt.Errorf("cgroup v2 not mounted at %s: %w", p.root, err)
+	}
+	return nil
+}
+
+// Poll walks pod cgroups and returns samples for pods whose counters grew.
+func (p *MemoryEventsPoller) Poll(now time.Time) ([]MemoryEventsSample, error) {
+	seen := make(map[string]struct{}, len(p.last))
+	var out []MemoryEventsSample
+
+	err := filepath.WalkDir(p.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Pods come and go between readdir and open; skip vanished paths.
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		uid, ok := podUIDFromCgroupDir(d.Name())
+		if !ok {
+			return nil
+		}
+
+		counts, readErr := readMemoryEvents(filepath.Join(path, "memory.events"))
+		if readErr != nil {
+			return filepath.SkipDir
+		}
+		seen[path] = struct{}{}
+		prev, known := p.last[path]
+		p.last[path] = counts
+		if known {
+			delta := counts.since(prev)
+			if delta != (MemoryEventCounts{}) {
+				out = append(out, MemoryEventsSample{
+					Timestamp:  now.UTC(),
+					PodUID:     uid,
+					CgroupPath: path,
+					Delta:      delta,
+				})
+			}
+		}
+		// Container cgroups below the pod roll up into its counters.
+		return filepath.SkipDir
+	})
+
+	for path := range p.last {
+		if _, ok := seen[path]; !ok {
+			delete(p.last, path)
+		}
+	}
+	return out, err
+}
+
+// Start polls until context cancellation, calling emit with each non-empty
+// batch and onErr with walk errors.
+func (p *MemoryEventsPoller) Start(ctx context.Context, emit func([]MemoryEventsSample), onErr func(error)) {
+	if emit == nil {
+		return
+	}
+	poll := func(ts time.Time) {
+		samples, err := p.Poll(ts)
+		if err != nil && onErr != nil {
+			onErr(err)
+		}
+		if len(samples) > 0 {
+			emit(samples)
+		}
+	}
+
+	poll(time.Now())
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ts := <-ticker.C:
+			poll(ts)
+		}
+	}
+}
+
+// since returns counter growth from prev; a counter that went backwards
+// belongs to a recreated cgroup and is reported from zero.
+func (c MemoryEventCounts) since(prev MemoryEventCounts) MemoryEventCounts {
+	delta := func(cur, old uint64) uint64 {
+		if cur < old {
+			return cur
+		}
+		return cur - old
+	}
+	return MemoryEventCounts{
+		High:    delta(c.High, prev.High),
+		Max:     delta(c.Max, prev.Max),
+		OOMKill: delta(c.OOMKill, prev.OOMKill),
+	}
+}
+
+func readMemoryEvents(path string) (MemoryEventCounts, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return MemoryEventCounts{}, err
+	}
+	defer f.Close()
+	return parseMemoryEvents(f)
+}
+
+// parseMemoryEvents parses the flat-keyed memory.events format
+// ("high 12\nmax 0\noom_kill 1\n"). Unknown keys are ignored.
+func parseMemoryEvents(r io.Reader) (MemoryEventCounts, error) {
+	var counts MemoryEventCounts
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, raw, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if !ok {
+			continue
+		}
+		var dst *uint64
+		switch key {
+		case "high":
+			dst = &counts.High
+		case "max":
+			dst = &counts.Max
+		case "oom_kill":
+			dst = &counts.OOMKill
+		default:
+			continue
+		}
+		value, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 64)
+		if err != nil {
+			return counts, fmt.Errorf("parse memory.events %s: %w", key, err)
+		}
+		*dst = value
+	}
+	return counts, scanner.Err()
+}
+
+// podUIDFromCgroupDir recognizes pod-level cgroup directory names from the
+// systemd driver ("kubepods-burstable-pod<uid>.slice", with "_" for "-")
+// and the cgroupfs driver ("pod<uid>").
+func podUIDFromCgroupDir(name string) (string, bool) {
+	name = strings.TrimSuffix(name, ".slice")
+	idx := strings.LastIndex(name, "pod")
+	if idx < 0 || (idx > 0 && name[idx-1] != '-') {
+		return "", false
+	}
+	uid := strings.ReplaceAll(name[idx+len("pod"):], "_", "-")
+	if len(uid) < 32 {
+		return "", false
+	}
+	for _, ch := range uid {
+		if !(ch == '-' || (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'f')) {
+			return "", false
+		}
+	}
+	return uid, true
+}
@@ -0,0 +1,96 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPodUID = "0f6a3c1e-6b2d-4f7a-9c1e-2b7d5e8a9f10"
+
+func writeMemoryEvents(t *testing.T, dir string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "memory.events"), []byte(content), 0o644); err != nil {
+		t.Fatalf("write memory.events: %v", err)
+	}
+}
+
+func TestParseMemoryEvents(t *testing.T) {
+	counts, err := parseMemoryEvents(strings.NewReader("low 0\nhigh 12\nmax 3\noom 2\noom_kill 1\noom_group_kill 0\n"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if counts != (MemoryEventCounts{High: 12, Max: 3, OOMKill: 1}) {
+		t.Fatalf("unexpected counts: %+v", counts)
+	}
+	if _, err := parseMemoryEvents(strings.NewReader("high x\n")); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
+
+func TestPodUIDFromCgroupDir(t *testing.T) {
+	systemd := "kubepods-burstable-pod" + strings.ReplaceAll(testPodUID, "-", "_") + ".slice"
+	for name, want := range map[string]string{
+		systemd:                                testPodUID,
+		"kubepods-pod" + testPodUID + ".slice": testPodUID,
+		"pod" + testPodUID:                     testPodUID,
+		"kubepods.slice":                       "",
+		"kubepods-burstable.slice":             "",
+		"podman-1234":                          "",
+	} {
+		got, ok := podUIDFromCgroupDir(name)
+		if got != want || ok != (want != "") {
+			t.Errorf("podUIDFromCgroupDir(%q) = %q, %v; want %q", name, got, ok, want)
+		}
+	}
+}
+
+func TestMemoryEventsPollerReportsDeltas(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "cgroup.controllers"), []byte("cpu memory\n"), 0o644); err != nil {
+		t.Fatalf("write controllers: %v", err)
+	}
+	podDir := filepath.Join(root, "kubepods.slice", "kubepods-burstable.slice",
+		"kubepods-burstable-pod"+strings.ReplaceAll(testPodUID, "-", "_")+".slice")
+	writeMemoryEvents(t, podDir, "high 10\nmax 2\noom_kill 0\n")
+	// Container cgroups roll up into the pod and must not be double counted.
+	writeMemoryEvents(t, filepath.Join(podDir, "cri-containerd-abc.scope"), "high 10\nmax 2\noom_kill 0\n")
+
+	p := NewMemoryEventsPoller(root, time.Second)
+	if err := p.Available(); err != nil {
+		t.Fatalf("available: %v", err)
+	}
+	now := time.Unix(1710000000, 0)
+	first, err := p.Poll(now)
+	if err != nil || len(first) != 0 {
+		t.Fatalf("first poll should only prime counters, got %+v (%v)", first, err)
+	}
+
+	writeMemoryEvents(t, podDir, "high 25\nmax 2\noom_kill 1\n")
+	second, err := p.Poll(now.Add(5 * time.Second))
+	if err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if len(second) != 1 {
+		t.Fatalf("expected one pod sample, got %+v", second)
+	}
+	if second[0].PodUID != testPodUID || second[0].Delta != (MemoryEventCounts{High: 15, OOMKill: 1}) {
+		t.Fatalf("unexpected sample: %+v", second[0])
+	}
+
+	third, err := p.Poll(now.Add(10 * time.Second))
+	if err != nil || len(third) != 0 {
+		t.Fatalf("unchanged counters should not emit, got %+v (%v)", third, err)
+	}
+}
+
+func TestMemoryEventsPollerUnavailable(t *testing.T) {
+	if err := NewMemoryEventsPoller(t.TempDir(), 0).Available(); err == nil {
+		t.Fatal("expected error without cgroup.controllers")
+	}
+}
@@ -0,0 +1,164 @@
+package collector
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
+)
+
+// DefaultContentionWindow is the length of one stolen-time matrix.
+const DefaultContentionWindow = time.Minute
+
+// ContentionCell is the run-queue delay one party accrued behind another
+// within a window. Parties are pod UIDs, or "cgroup/<id>" for tasks
+// outside pod cgroups.
+type ContentionCell struct {
+	Victim  string
+	Culprit string
+	// CulpritComm is the culprit's most recently seen command.
+	CulpritComm string
+	StolenMS    float64
+	Events      int
+}
+
+// ContentionWindow is the "stolen from victim by culprit" matrix of one
+// window, as cells sorted by stolen time, largest first.
+type ContentionWindow struct {
+	Start time.Time
+	End   time.Time
+	Cells []ContentionCell
+}
+
+// Culprits returns victim's top n neighbours by stolen time; n <= 0
+// returns all. Time the victim waited behind its own tasks counts towards
+// each share but is not listed, since it points at no neighbour.
+func (w ContentionWindow) Culprits(victim string, n int) []schema.Culprit {
+	var total float64
+	var out []schema.Culprit
+	for _, cell := range w.Cells {
+		if cell.Victim != victim {
+			continue
+		}
+		total += cell.StolenMS
+		if cell.Culprit == victim {
+			continue
+		}
+		out = append(out, schema.Culprit{Pod: cell.Culprit, Comm: cell.CulpritComm, StolenMS: cell.StolenMS})
+	}
+	for i := range out {
+		out[i].Share = out[i].StolenMS / total
+	}
+	if n > 0 && len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+type contentionKey struct {
+	victim  string
+	culprit string
+}
+
+// NoisyNeighborTracker aggregates runqueue_delay_ms events that carry the
+// outgoing task (schema.SchedPrev) into per-window contention matrices.
+// Windows are aligned to multiples of their length and close when an
+// event from a later window arrives or on Flush. It is safe for
+// concurrent use.
+type NoisyNeighborTracker struct {
+	mu     sync.Mutex
+	window time.Duration
+	pods   PodResolver
+	start  time.Time
+	cells  map[contentionKey]*ContentionCell
+	last   ContentionWindow
+}
+
+// NewNoisyNeighborTracker creates a tracker; window <= 0 uses
+// DefaultContentionWindow. A nil pods labels every party by cgroup.
+func NewNoisyNeighborTracker(window time.Duration, pods PodResolver) *NoisyNeighborTracker {
+	if window <= 0 {
+		window = DefaultContentionWindow
+	}
+	return &NoisyNeighborTracker{
+		window: window,
+		pods:   pods,
+		cells:  make(map[contentionKey]*ContentionCell),
+	}
+}
+
+// Observe adds one event and returns the window it closed, if any. Events
+// other than runqueue_delay_ms, events where the CPU was idle and events
+// whose waiting task has no known cgroup are ignored.
+func (t *NoisyNeighborTracker) Observe(ev schema.ProbeEventV1Beta1) (ContentionWindow, bool) {
+	if ev.Signal != signalspec.RunqueueDelayMS || ev.Sched == nil || ev.CgroupID == 0 {
+		return ContentionWindow{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ts := time.Unix(0, ev.TSUnixNano)
+	var (
+		closed ContentionWindow
+		ok     bool
+	)
+	if !t.start.IsZero() && !ts.Before(t.start.Add(t.window)) {
+		closed, ok = t.closeLocked(), true
+	}
+	if t.start.IsZero() {
+		t.start = ts.Truncate(t.window)
+	}
+
+	key := contentionKey{victim: podLabel(t.pods, ev.CgroupID), culprit: podLabel(t.pods, ev.Sched.PrevCgroupID)}
+	cell := t.cells[key]
+	if cell == nil {
+		cell = &ContentionCell{Victim: key.victim, Culprit: key.culprit}
+		t.cells[key] = cell
+	}
+	cell.StolenMS += ev.Value
+	cell.Events++
+	if ev.Sched.PrevComm != "" {
+		cell.CulpritComm = ev.Sched.PrevComm
+	}
+	return closed, ok
+}
+
+// Flush closes the open window if now is past its end.
+func (t *NoisyNeighborTracker) Flush(now time.Time) (ContentionWindow, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.start.IsZero() || now.Before(t.start.Add(t.window)) {
+		return ContentionWindow{}, false
+	}
+	return t.closeLocked(), true
+}
+
+// Last returns the most recently closed window.
+func (t *NoisyNeighborTracker) Last() ContentionWindow {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.last
+}
+
+func (t *NoisyNeighborTracker) closeLocked() ContentionWindow {
+	w := ContentionWindow{Start: t.start, End: t.start.Add(t.window)}
+	for _, cell := range t.cells {
+		w.Cells = append(w.Cells, *cell)
+	}
+	sort.Slice(w.Cells, func(i, j int) bool {
+		a, b := w.Cells[i], w.Cells[j]
+		if a.StolenMS != b.StolenMS {
+			return a.StolenMS > b.StolenMS
+		}
+		if a.Victim != b.Victim {
+			return a.Victim < b.Victim
+		}
+		return a.Culprit < b.Culprit
+	})
+	t.last = w
+	t.start = time.Time{}
+	t.cells = make(map[contentionKey]*ContentionCell)
+	return w
+}
@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
+)
+
+type staticPods map[uint64]string
+
+func (p staticPods) PodUID(id uint64) (string, bool) {
+	uid, ok := p[id]
+	return uid, ok
+}
+
+func runqEvent(at time.Duration, victim uint64, delayMS float64, prev *schema.SchedPrev) schema.ProbeEventV1Beta1 {
+	return schema.ProbeEventV1Beta1{
+		TSUnixNano: time.Unix(1700000040, 0).Add(at).UnixNano(),
+		Signal:     signalspec.RunqueueDelayMS,
+		CgroupID:   victim,
+		Value:      delayMS,
+		Sched:      prev,
+	}
+}
+
+func TestNoisyNeighborTrackerMatrix(t *testing.T) {
+	pods := staticPods{101: "vllm-uid", 102: "vllm-uid", 201: "batch-uid", 301: "etl-uid"}
+	tr := NewNoisyNeighborTracker(time.Minute, pods)
+
+	batch := &schema.SchedPrev{PrevPID: 900, PrevComm: "spark-executor", PrevCgroupID: 201}
+	etl := &schema.SchedPrev{PrevPID: 901, PrevComm: "python3", PrevCgroupID: 301}
+	self := &schema.SchedPrev{PrevPID: 500, PrevComm: "vllm-worker", PrevCgroupID: 102}
+	host := &schema.SchedPrev{PrevPID: 1, PrevComm: "kubelet", PrevCgroupID: 77}
+
+	for _, ev := range []schema.ProbeEventV1Beta1{
+		runqEvent(0, 101, 30, batch),
+		runqEvent(time.Second, 102, 20, batch),
+		runqEvent(2*time.Second, 101, 25, etl),
+		runqEvent(3*time.Second, 101, 15, self),
+		runqEvent(4*time.Second, 101, 10, host),
+		runqEvent(5*time.Second, 301, 40, batch),
+		// Ignored: idle CPU, unknown victim cgroup, other signals.
+		runqEvent(6*time.Second, 101, 99, nil),
+		runqEvent(7*time.Second, 0, 99, batch),
+		{Signal: signalspec.DNSLatencyMS, CgroupID: 101, Value: 99, Sched: batch},
+	} {
+		if _, ok := tr.Observe(ev); ok {
+			t.Fatalf("window closed early at %+v", ev)
+		}
+	}
+
+	w, ok := tr.Observe(runqEvent(time.Minute, 101, 5, batch))
+	if !ok {
+		t.Fatal("expected the first window to close")
+	}
+	if !w.Start.Equal(time.Unix(1700000040, 0)) || w.End.Sub(w.Start) != time.Minute {
+		t.Fatalf("unexpected window bounds %v-%v", w.Start, w.End)
+	}
+	if len(w.Cells) != 5 || w.Cells[0].Victim != "vllm-uid" || w.Cells[0].Culprit != "batch-uid" || w.Cells[0].StolenMS != 50 || w.Cells[0].Events != 2 {
+		t.Fatalf("unexpected matrix %+v", w.Cells)
+	}
+
+	culprits := w.Culprits("vllm-uid", 2)
+	if len(culprits) != 2 {
+		t.Fatalf("expected two culprits, got %+v", culprits)
+	}
+	if culprits[0].Pod != "batch-uid" || culprits[0].Comm != "spark-executor" || culprits[0].StolenMS != 50 || culprits[0].Share != 0.5 {
+		t.Fatalf("unexpected top culprit %+v", culprits[0])
+	}
+	if culprits[1].Pod != "etl-uid" || culprits[1].Share != 0.25 {
+		t.Fatalf("unexpected second culprit %+v", culprits[1])
+	}
+	if all := w.Culprits("vllm-uid", 0); len(all) != 3 || all[2].Pod != "cgroup/77" {
+		t.Fatalf("expected the host task by cgroup, got %+v", all)
+	}
+	if tr.Last().Start != w.Start {
+		t.Fatal("Last should return the closed window")
+	}
+
+	if _, ok := tr.Flush(time.Unix(1700000040, 0).Add(90 * time.Second)); ok {
+		t.Fatal("second window is still open")
+	}
+	w, ok = tr.Flush(time.Unix(1700000040, 0).Add(2 * time.Minute))
+	if !ok || len(w.Cells) != 1 || w.Cells[0].StolenMS != 5 {
+		t.Fatalf("unexpected flushed window %+v", w)
+	}
+}
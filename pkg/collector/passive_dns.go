@@ -0,0 +1,188 @@
+package collector
+
+import (
+	"container/list"
+	"encoding/binary"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+)
+
+// DefaultPassiveDNSSize bounds the addresses a PassiveDNSCache holds.
+const DefaultPassiveDNSSize = 4096
+
+// passiveDNSMinTTL keeps answers with a zero or tiny TTL long enough to
+// label the connection the application opens right after resolving.
+const passiveDNSMinTTL = time.Second
+
+// DNS record types and class read from answer sections.
+const (
+	dnsTypeA    = 1
+	dnsTypeAAAA = 28
+	dnsClassIN  = 1
+)
+
+// DNSAddrAnswer is one A or AAAA record of a DNS response.
+type DNSAddrAnswer struct {
+	Addr netip.Addr
+	TTL  time.Duration
+}
+
+// parseDNSAddrAnswers reads the A and AAAA records of a wire-format answer
+// section holding count records. Owner names are skipped, not resolved:
+// behind a CNAME chain they name the chain's target, while passive DNS
+// maps addresses to the name that was asked for. Parsing stops at the
+// first record that does not fit in buf.
+func parseDNSAddrAnswers(buf []byte, count int) []DNSAddrAnswer {
+	var out []DNSAddrAnswer
+	pos := 0
+	for i := 0; i < count; i++ {
+		next, ok := skipDNSName(buf, pos)
+		if !ok || next+10 > len(buf) {
+			break
+		}
+		rtype := binary.BigEndian.Uint16(buf[next:])
+		class := binary.BigEndian.Uint16(buf[next+2:])
+		ttl := binary.BigEndian.Uint32(buf[next+4:])
+		rdlen := int(binary.BigEndian.Uint16(buf[next+8:]))
+		rdata := next + 10
+		if rdata+rdlen > len(buf) {
+			break
+		}
+		if class == dnsClassIN {
+			var addr netip.Addr
+			switch {
+			case rtype == dnsTypeA && rdlen == 4:
+				addr = netip.AddrFrom4([4]byte(buf[rdata : rdata+4]))
+			case rtype == dnsTypeAAAA && rdlen == 16:
+				addr = netip.AddrFrom16([16]byte(buf[rdata : rdata+16]))
+			}
+			if addr.IsValid() {
+				out = append(out, DNSAddrAnswer{Addr: addr, TTL: time.Duration(ttl) * time.Second})
+			}
+		}
+		pos = rdata + rdlen
+	}
+	return out
+}
+
+// skipDNSName returns the offset just past the wire-format name at pos,
+// which may end in a compression pointer.
+func skipDNSName(buf []byte, pos int) (int, bool) {
+	for pos < len(buf) {
+		n := int(buf[pos])
+		switch {
+		case n == 0:
+			return pos + 1, true
+		case n&0xc0 == 0xc0:
+			return pos + 2, pos+2 <= len(buf)
+		case n&0xc0 != 0:
+			return 0, false
+		}
+		pos += 1 + n
+	}
+	return 0, false
+}
+
+type passiveDNSEntry struct {
+	addr    netip.Addr
+	host    string
+	expires time.Time
+}
+
+// PassiveDNSCache maps destination addresses back to the host names
+// applications resolved them from, as seen in DNS responses on the node.
+// Entries expire with their record's TTL; when the cache is full the
+// least recently answered address is evicted. An address answered for
+// several names maps to the latest. It is safe for concurrent use.
+type PassiveDNSCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[netip.Addr]*list.Element
+	order   *list.List // front is the most recently answered
+}
+
+// NewPassiveDNSCache creates a cache holding at most size addresses;
+// size <= 0 uses DefaultPassiveDNSSize.
+func NewPassiveDNSCache(size int) *PassiveDNSCache {
+	if size <= 0 {
+		size = DefaultPassiveDNSSize
+	}
+	return &PassiveDNSCache{
+		size:    size,
+		entries: make(map[netip.Addr]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Observe records the addresses a response for host carried, as of now.
+func (c *PassiveDNSCache) Observe(host string, answers []DNSAddrAnswer, now time.Time) {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" || len(answers) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, ans := range answers {
+		ttl := max(ans.TTL, passiveDNSMinTTL)
+		entry := passiveDNSEntry{addr: ans.Addr.Unmap(), host: host, expires: now.Add(ttl)}
+		if el, ok := c.entries[entry.addr]; ok {
+			el.Value = entry
+			c.order.MoveToFront(el)
+			continue
+		}
+		c.entries[entry.addr] = c.order.PushFront(entry)
+		if c.order.Len() > c.size {
+			oldest := c.order.Back()
+			c.order.Remove(oldest)
+			delete(c.entries, oldest.Value.(passiveDNSEntry).addr)
+		}
+	}
+}
+
+// Lookup returns the host addr was last resolved from, unless that answer
+// has expired by now.
+func (c *PassiveDNSCache) Lookup(addr netip.Addr, now time.Time) (string, bool) {
+	addr = addr.Unmap()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[addr]
+	if !ok {
+		return "", false
+	}
+	entry := el.Value.(passiveDNSEntry)
+	if !now.Before(entry.expires) {
+		c.order.Remove(el)
+		delete(c.entries, addr)
+		return "", false
+	}
+	return entry.host, true
+}
+
+// Annotate sets tuple.DstHost from the cache and reports whether it did.
+// Tuples that already name a host are left alone.
+func (c *PassiveDNSCache) Annotate(tuple *schema.ConnTuple, now time.Time) bool {
+	if c == nil || tuple == nil || tuple.DstHost != "" {
+		return false
+	}
+	addr, err := netip.ParseAddr(tuple.DstIP)
+	if err != nil {
+		return false
+	}
+	host, ok := c.Lookup(addr, now)
+	if ok {
+		tuple.DstHost = host
+	}
+	return ok
+}
+
+// Len returns the number of cached addresses, including expired ones not
+// yet evicted.
+func (c *PassiveDNSCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,105 @@
+package collector
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+)
+
+// dnsRecord encodes one answer record whose owner name is a compression
+// pointer to the question.
+func dnsRecord(rtype uint16, ttl uint32, rdata []byte) []byte {
+	rec := []byte{0xc0, 0x0c}
+	rec = binary.BigEndian.AppendUint16(rec, rtype)
+	rec = binary.BigEndian.AppendUint16(rec, dnsClassIN)
+	rec = binary.BigEndian.AppendUint32(rec, ttl)
+	rec = binary.BigEndian.AppendUint16(rec, uint16(len(rdata)))
+	return append(rec, rdata...)
+}
+
+func TestParseDNSAddrAnswers(t *testing.T) {
+	cname := []byte{3, 'a', 'p', 'i', 0xc0, 0x10}
+	v6 := netip.MustParseAddr("2606:4700::6810:7b")
+	v6Bytes := v6.As16()
+	var section []byte
+	section = append(section, dnsRecord(5, 300, cname)...)
+	section = append(section, dnsRecord(dnsTypeA, 60, []byte{104, 18, 7, 192})...)
+	section = append(section, dnsRecord(dnsTypeAAAA, 30, v6Bytes[:])...)
+
+	got := parseDNSAddrAnswers(section, 3)
+	if len(got) != 2 {
+		t.Fatalf("expected A and AAAA answers, got %+v", got)
+	}
+	if got[0].Addr != netip.MustParseAddr("104.18.7.192") || got[0].TTL != time.Minute {
+		t.Errorf("unexpected A answer %+v", got[0])
+	}
+	if got[1].Addr != v6 || got[1].TTL != 30*time.Second {
+		t.Errorf("unexpected AAAA answer %+v", got[1])
+	}
+
+	// A record cut off by the capture limit is dropped with the rest.
+	if got := parseDNSAddrAnswers(section[:len(section)-4], 3); len(got) != 1 {
+		t.Errorf("expected only the complete A answer, got %+v", got)
+	}
+	if got := parseDNSAddrAnswers(section, 1); len(got) != 0 {
+		t.Errorf("ancount should bound parsing, got %+v", got)
+	}
+}
+
+func TestPassiveDNSCacheExpiry(t *testing.T) {
+	c := NewPassiveDNSCache(0)
+	now := time.Unix(1700000000, 0)
+	addr := netip.MustParseAddr("104.18.7.192")
+	c.Observe("API.OpenAI.com.", []DNSAddrAnswer{{Addr: addr, TTL: 30 * time.Second}}, now)
+
+	if host, ok := c.Lookup(netip.MustParseAddr("::ffff:104.18.7.192"), now.Add(29*time.Second)); !ok || host != "api.openai.com" {
+		t.Fatalf("expected api.openai.com before expiry, got %q (%v)", host, ok)
+	}
+	if _, ok := c.Lookup(addr, now.Add(30*time.Second)); ok {
+		t.Fatal("answer should expire with its TTL")
+	}
+	if c.Len() != 0 {
+		t.Fatalf("expired entry should be dropped, %d left", c.Len())
+	}
+
+	// Zero TTLs still label the connection opened right after.
+	c.Observe("api.openai.com", []DNSAddrAnswer{{Addr: addr}}, now)
+	if _, ok := c.Lookup(addr, now.Add(500*time.Millisecond)); !ok {
+		t.Fatal("zero-TTL answer should be kept briefly")
+	}
+}
+
+func TestPassiveDNSCacheBounded(t *testing.T) {
+	c := NewPassiveDNSCache(2)
+	now := time.Unix(1700000000, 0)
+	a := netip.MustParseAddr("10.0.0.1")
+	b := netip.MustParseAddr("10.0.0.2")
+	d := netip.MustParseAddr("10.0.0.3")
+	c.Observe("a.example.com", []DNSAddrAnswer{{Addr: a, TTL: time.Hour}}, now)
+	c.Observe("b.example.com", []DNSAddrAnswer{{Addr: b, TTL: time.Hour}}, now)
+	// Answering a again makes b the least recently answered.
+	c.Observe("a2.example.com", []DNSAddrAnswer{{Addr: a, TTL: time.Hour}}, now)
+	c.Observe("d.example.com", []DNSAddrAnswer{{Addr: d, TTL: time.Hour}}, now)
+
+	if c.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", c.Len())
+	}
+	if _, ok := c.Lookup(b, now); ok {
+		t.Fatal("least recently answered address should be evicted")
+	}
+	if host, _ := c.Lookup(a, now); host != "a2.example.com" {
+		t.Fatalf("latest answer should win, got %q", host)
+	}
+
+	tuple := &schema.ConnTuple{DstIP: "10.0.0.3", DstPort: 443}
+	if !c.Annotate(tuple, now) || tuple.DstHost != "d.example.com" {
+		t.Fatalf("unexpected annotation %+v", tuple)
+	}
+	var none *PassiveDNSCache
+	if none.Annotate(&schema.ConnTuple{DstIP: "10.0.0.3"}, now) {
+		t.Fatal("nil cache should not annotate")
+	}
+}
@@ -15,6 +15,7 @@ type RawSample struct {
 	Workload         string    `json:"workload"`
 	Service          string    `json:"service"`
 	Node             string    `json:"node,omitempty"`
+	Pod              string    `json:"pod,omitempty"`
 	RequestID        string    `json:"request_id"`
 	TraceID          string    `json:"trace_id"`
 	TTFTMs           float64   `json:"ttft_ms"`
@@ -22,26 +23,47 @@ type RawSample struct {
 	TokenTPS         float64   `json:"token_throughput_tps"`
 	ErrorRate        float64   `json:"error_rate"`
 	FaultLabel       string    `json:"fault_label,omitempty"`
+	// InterTokenStallMs is the longest pause between streamed tokens; zero
+	// when the source does not time individual tokens.
+	InterTokenStallMs float64 `json:"inter_token_stall_ms,omitempty"`
+	// Source names where the timings came from; empty means synthetic.
+	// Kernel samples (SourceKernel) are derived from socket timing.
+	Source string `json:"source,omitempty"`
 }
 
 // NormalizeSample converts one raw sample into first-class SLO events.
+// Kernel samples of responses written in one call have no throughput and
+// omit token_throughput_tps. inter_token_stall_ms is emitted only for
+// samples that time individual tokens.
 func NormalizeSample(sample RawSample) []schema.SLOEvent {
 	events := []schema.SLOEvent{
 		buildEvent(sample, "ttft_ms", sample.TTFTMs, "ms", thresholdStatus(sample.TTFTMs, 500, 1000)),
 		buildEvent(sample, "request_latency_ms", sample.RequestLatencyMs, "ms", thresholdStatus(sample.RequestLatencyMs, 700, 1500)),
-		buildEvent(sample, "token_throughput_tps", sample.TokenTPS, "tps", inverseThresholdStatus(sample.TokenTPS, 30, 10)),
-		buildEvent(sample, "error_rate", sample.ErrorRate, "ratio", thresholdStatus(sample.ErrorRate, 0.02, 0.05)),
 	}
+	if sample.Source != SourceKernel || sample.TokenTPS > 0 {
+		events = append(events, buildEvent(sample, "token_throughput_tps", sample.TokenTPS, "tps", inverseThresholdStatus(sample.TokenTPS, 30, 10)))
+	}
+	if sample.InterTokenStallMs > 0 {
+		events = append(events, buildEvent(sample, "inter_token_stall_ms", sample.InterTokenStallMs, "ms", thresholdStatus(sample.InterTokenStallMs, 250, 1000)))
+	}
+	events = append(events, buildEvent(sample, "error_rate", sample.ErrorRate, "ratio", thresholdStatus(sample.ErrorRate, 0.02, 0.05)))
 	return events
 }
 
 func buildEvent(sample RawSample, sli string, value float64, unit string, status string) schema.SLOEvent {
+	source := sample.Source
+	if source == "" {
+		source = "synthetic"
+	}
 	labels := map[string]string{
-		"source": "synthetic",
+		"source": source,
 	}
 	if sample.Node != "" {
 		labels["node"] = sample.Node
 	}
+	if sample.Pod != "" {
+		labels["pod"] = sample.Pod
+	}
 	if sample.FaultLabel != "" {
 		labels["fault_label"] = sample.FaultLabel
 	}
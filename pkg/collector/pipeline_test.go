@@ -3,6 +3,8 @@ package collector
 import (
 	"testing"
 	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
 )
 
 func TestNormalizeSampleProducesEvents(t *testing.T) {
@@ -40,6 +42,29 @@ func TestNormalizeSampleProducesEvents(t *testing.T) {
 	}
 }
 
+func TestNormalizeSampleInterTokenStall(t *testing.T) {
+	sample := RawSample{
+		Timestamp:         time.Now().UTC(),
+		RequestID:         "req-2",
+		TTFTMs:            300,
+		RequestLatencyMs:  4000,
+		TokenTPS:          25,
+		InterTokenStallMs: 1400,
+	}
+
+	events := NormalizeSample(sample)
+	if len(events) != 5 {
+		t.Fatalf("expected 5 events, got %d", len(events))
+	}
+	stall := events[3]
+	if stall.SLIName != "inter_token_stall_ms" || stall.SLIValue != 1400 || stall.Unit != "ms" || stall.Status != "breach" {
+		t.Fatalf("unexpected stall event %+v", stall)
+	}
+	if err := schema.SLOEventValidator().Validate(stall); err != nil {
+		t.Fatalf("stall event rejected by contract: %v", err)
+	}
+}
+
 func TestDependencyMarker(t *testing.T) {
 	if DependencyMarker() == "" {
 		t.Fatal("dependency marker should not be empty")
@@ -1,6 +1,7 @@
 package collector
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -17,6 +18,18 @@ type ProbeSpec struct {
 	Collection *ebpf.Collection
 	Links      []link.Link
 	RingBuf    *ringbuf.Reader
+	// Scope is the llm_slo_scope_cgroups map of a probe loaded with
+	// llm_slo_scoped set; nil for probes that record every task.
+	Scope ScopeMap
+	// Attach attaches a scoped probe's programs. Scoped probes stay
+	// detached until SetScope gives them a cgroup.
+	Attach func() ([]link.Link, error)
+}
+
+// ScopeMap is the part of *ebpf.Map SetScope uses.
+type ScopeMap interface {
+	Put(key, value interface{}) error
+	Delete(key interface{}) error
 }
 
 // ProbeManager loads, attaches, and controls the lifecycle of eBPF probes.
@@ -30,6 +43,7 @@ type ProbeManager struct {
 	disableOrder []string            // preferred disable order for overhead shedding
 	guard        *safety.OverheadGuard
 	limiter      *safety.RateLimiter
+	scopes       map[string]map[uint64]struct{} // cgroups in each scoped probe's map
 }
 
 // NewProbeManager creates a manager for the given capability mode. The caller
@@ -47,6 +61,7 @@ func NewProbeManager(mode string, allowed []string, disableOrder []string, guard
 		disableOrder: disableOrder,
 		guard:        guard,
 		limiter:      limiter,
+		scopes:       make(map[string]map[uint64]struct{}),
 	}
 }
 
@@ -70,12 +85,16 @@ func (pm *ProbeManager) Register(spec *ProbeSpec) error {
 	return nil
 }
 
-// AttachAll attaches all registered probes.
+// AttachAll attaches all registered probes except scoped ones, which
+// SetScope attaches.
 func (pm *ProbeManager) AttachAll() error {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
 	for sig, spec := range pm.probes {
+		if spec.Scope != nil {
+			continue
+		}
 		if len(spec.Links) > 0 {
 			log.Printf("probe %s: already attached, skipping", sig)
 			continue
@@ -94,6 +113,7 @@ func (pm *ProbeManager) DetachAll() {
 		pm.closeProbe(sig, spec)
 	}
 	pm.probes = make(map[string]*ProbeSpec)
+	pm.scopes = make(map[string]map[uint64]struct{})
 }
 
 // DisableProbe detaches and removes a single probe by signal name.
@@ -108,9 +128,73 @@ func (pm *ProbeManager) DisableProbe(signal string) bool {
 
 	pm.closeProbe(signal, spec)
 	delete(pm.probes, signal)
+	delete(pm.scopes, signal)
 	return true
 }
 
+// SetScope limits a scoped probe to tasks in cgroups, attaching it when
+// the scope becomes non-empty and detaching its links, but keeping it
+// loaded, when the scope becomes empty.
+func (pm *ProbeManager) SetScope(signal string, cgroups []uint64) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	spec, ok := pm.probes[signal]
+	if !ok {
+		return fmt.Errorf("probe %s: not registered", signal)
+	}
+	if spec.Scope == nil {
+		return fmt.Errorf("probe %s: not loaded with a scope map", signal)
+	}
+
+	want := make(map[uint64]struct{}, len(cgroups))
+	for _, id := range cgroups {
+		want[id] = struct{}{}
+	}
+	have := pm.scopes[signal]
+	for id := range have {
+		if _, keep := want[id]; keep {
+			continue
+		}
+		if err := spec.Scope.Delete(id); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+			return fmt.Errorf("probe %s: unscope cgroup %d: %w", signal, id, err)
+		}
+		delete(have, id)
+	}
+	if have == nil {
+		have = make(map[uint64]struct{}, len(want))
+		pm.scopes[signal] = have
+	}
+	for id := range want {
+		if _, ok := have[id]; ok {
+			continue
+		}
+		if err := spec.Scope.Put(id, uint8(1)); err != nil {
+			return fmt.Errorf("probe %s: scope cgroup %d: %w", signal, id, err)
+		}
+		have[id] = struct{}{}
+	}
+
+	switch {
+	case len(want) == 0 && len(spec.Links) > 0:
+		for _, l := range spec.Links {
+			if err := l.Close(); err != nil {
+				log.Printf("probe %s: link close error: %v", signal, err)
+			}
+		}
+		spec.Links = nil
+		log.Printf("probe %s: detached, scope empty", signal)
+	case len(want) > 0 && len(spec.Links) == 0 && spec.Attach != nil:
+		links, err := spec.Attach()
+		if err != nil {
+			return fmt.Errorf("probe %s: attach: %w", signal, err)
+		}
+		spec.Links = links
+		log.Printf("probe %s: attached, scoped to %d cgroups", signal, len(want))
+	}
+	return nil
+}
+
 // EnabledSignals returns the list of currently attached signal names.
 func (pm *ProbeManager) EnabledSignals() []string {
 	pm.mu.Lock()
@@ -149,6 +233,7 @@ func (pm *ProbeManager) CheckOverhead() (string, bool) {
 		if spec, ok := pm.probes[signal]; ok {
 			pm.closeProbe(signal, spec)
 			delete(pm.probes, signal)
+			delete(pm.scopes, signal)
 			return signal, true
 		}
 	}
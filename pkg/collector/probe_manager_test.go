@@ -2,6 +2,8 @@ package collector
 
 import (
 	"testing"
+
+	"github.com/cilium/ebpf/link"
 )
 
 var (
@@ -103,3 +105,57 @@ func TestProbeManagerMode(t *testing.T) {
 		t.Errorf("mode: got %q, want %q", pm.Mode(), "bcc_degraded")
 	}
 }
+
+type fakeScopeMap map[uint64]struct{}
+
+func (m fakeScopeMap) Put(key, value interface{}) error {
+	m[key.(uint64)] = struct{}{}
+	return nil
+}
+
+func (m fakeScopeMap) Delete(key interface{}) error {
+	delete(m, key.(uint64))
+	return nil
+}
+
+func TestProbeManagerSetScope(t *testing.T) {
+	pm := NewProbeManager("core_full", testCoreSignals, testDisableOrder, nil, nil)
+	scope := fakeScopeMap{}
+	attaches := 0
+	spec := &ProbeSpec{Signal: "tls_handshake_ms", Scope: scope, Attach: func() ([]link.Link, error) {
+		attaches++
+		return nil, nil
+	}}
+	if err := pm.Register(spec); err != nil {
+		t.Fatalf("register tls: %v", err)
+	}
+	if err := pm.Register(&ProbeSpec{Signal: "dns_latency_ms"}); err != nil {
+		t.Fatalf("register dns: %v", err)
+	}
+
+	if err := pm.SetScope("tls_handshake_ms", []uint64{11, 12}); err != nil {
+		t.Fatalf("set scope: %v", err)
+	}
+	if len(scope) != 2 || attaches != 1 {
+		t.Fatalf("expected 2 scoped cgroups and one attach, got %v and %d", scope, attaches)
+	}
+	if err := pm.SetScope("tls_handshake_ms", []uint64{12, 13}); err != nil {
+		t.Fatalf("set scope: %v", err)
+	}
+	if _, ok := scope[11]; ok || len(scope) != 2 {
+		t.Fatalf("scope should be replaced, got %v", scope)
+	}
+	if err := pm.SetScope("tls_handshake_ms", nil); err != nil {
+		t.Fatalf("clear scope: %v", err)
+	}
+	if len(scope) != 0 {
+		t.Fatalf("scope should be empty, got %v", scope)
+	}
+
+	if err := pm.SetScope("dns_latency_ms", []uint64{11}); err == nil {
+		t.Error("expected error scoping a probe without a scope map")
+	}
+	if err := pm.SetScope("syscall_latency_ms", []uint64{11}); err == nil {
+		t.Error("expected error scoping an unregistered probe")
+	}
+}
@@ -0,0 +1,316 @@
+package collector
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cilium/ebpf"
+)
+
+// Socket I/O record kinds mirror LLM_SLO_SOCK_* in llm_slo_event.h.
+const (
+	SockIORead  = 1
+	SockIOWrite = 2
+	SockIOClose = 3
+)
+
+// Request timing defaults.
+const (
+	// DefaultRequestIdle is how long after its last response write a
+	// request is considered complete when the client neither sends another
+	// request nor closes the connection. It must exceed the longest pause
+	// between streamed tokens.
+	DefaultRequestIdle = 5 * time.Second
+	// DefaultRequestMaxAge drops requests that never produced a response
+	// byte, e.g. a client that opened a connection and went silent.
+	DefaultRequestMaxAge = 10 * time.Minute
+)
+
+// writeGapReservoir bounds the write gaps kept per request for
+// P95WriteGap.
+const writeGapReservoir = 1024
+
+// SourceKernel labels SLO samples derived from socket timing rather than
+// application instrumentation.
+const SourceKernel = "kernel"
+
+// SockIORecord is one llm_slo_sock_io_event from request_timing.bpf.c.
+type SockIORecord struct {
+	Timestamp time.Time
+	PID       int
+	CgroupID  uint64
+	Comm      string
+	// SockID is the kernel socket address; it identifies the connection.
+	SockID     uint64
+	ServerPort int
+	Client     string
+	Kind       int
+	Bytes      int
+}
+
+// RequestTiming is one request on a server connection, timed from the
+// first read of the request to the first and last writes of the response.
+type RequestTiming struct {
+	PID        int
+	CgroupID   uint64
+	Comm       string
+	SockID     uint64
+	ServerPort int
+	Client     string
+	// Stream numbers the requests on a connection from 1, in order.
+	Stream     int
+	Start      time.Time
+	FirstWrite time.Time
+	LastWrite  time.Time
+	Writes     int
+	BytesIn    int
+	BytesOut   int
+	// Aborted is set when the connection closed before any response byte
+	// was written.
+	Aborted bool
+	// MaxWriteGap is the longest pause between successive response
+	// writes. Streaming servers write once per token or token group, so
+	// it is the worst inter-token stall the client saw.
+	MaxWriteGap time.Duration
+	writeGaps   []time.Duration
+}
+
+// TTFT is the time from the request's first read to the first response
+// write. For aborted requests it is the time until close.
+func (r RequestTiming) TTFT() time.Duration {
+	return r.FirstWrite.Sub(r.Start)
+}
+
+// Latency is the time from the request's first read to the last response
+// write, or until close for aborted requests.
+func (r RequestTiming) Latency() time.Duration {
+	return r.LastWrite.Sub(r.Start)
+}
+
+// ChunksPerSecond is the rate of response writes after the first one.
+// Streaming LLM servers write one chunk per token or small token group,
+// so it stands in for token throughput. It is zero for responses written
+// in one call.
+func (r RequestTiming) ChunksPerSecond() float64 {
+	window := r.LastWrite.Sub(r.FirstWrite).Seconds()
+	if r.Writes < 2 || window <= 0 {
+		return 0
+	}
+	return float64(r.Writes-1) / window
+}
+
+// P95WriteGap is the 95th percentile pause between successive response
+// writes. It is zero for responses written in one call.
+func (r RequestTiming) P95WriteGap() time.Duration {
+	if len(r.writeGaps) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(r.writeGaps))
+	for i, gap := range r.writeGaps {
+		sorted[i] = float64(gap)
+	}
+	sort.Float64s(sorted)
+	return time.Duration(sortedQuantile(sorted, 0.95))
+}
+
+// addWriteGap records the pause before a response write.
+func (r *RequestTiming) addWriteGap(gap time.Duration) {
+	if gap > r.MaxWriteGap {
+		r.MaxWriteGap = gap
+	}
+	// Writes still excludes this write, so this is gap number Writes.
+	if len(r.writeGaps) < writeGapReservoir {
+		r.writeGaps = append(r.writeGaps, gap)
+	} else if i := rand.IntN(r.Writes); i < writeGapReservoir {
+		r.writeGaps[i] = gap
+	}
+}
+
+// RawSample converts the timing into an SLO sample for meta's workload.
+// Kernel samples carry no trace ID; aborted requests count as errors. The
+// longest write gap becomes the sample's inter-token stall.
+func (r RequestTiming) RawSample(meta SampleMeta) RawSample {
+	sample := RawSample{
+		Timestamp:         r.Start,
+		Cluster:           meta.Cluster,
+		Namespace:         meta.Namespace,
+		Workload:          meta.Workload,
+		Service:           meta.Service,
+		Node:              meta.Node,
+		Pod:               meta.Pod,
+		RequestID:         fmt.Sprintf("kernel-%x-%d", r.SockID, r.Start.UnixNano()),
+		TTFTMs:            durationMS(r.TTFT()),
+		RequestLatencyMs:  durationMS(r.Latency()),
+		TokenTPS:          r.ChunksPerSecond(),
+		InterTokenStallMs: durationMS(r.MaxWriteGap),
+		Source:            SourceKernel,
+	}
+	if r.Aborted {
+		sample.ErrorRate = 1
+	}
+	return sample
+}
+
+func durationMS(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+type requestConn struct {
+	open    *RequestTiming
+	streams int
+	last    time.Time
+}
+
+// RequestTimingTracker segments socket I/O records into requests. Each
+// connection alternates between reading a request and writing its
+// response, as HTTP/1.1 does: a read after response bytes starts the next
+// request. A request completes when that happens, when the connection
+// closes, or when no response byte has been written for the idle window.
+// HTTP/2 connections that multiplex concurrent streams are timed as one
+// request per burst of overlapping streams. It is safe for concurrent use.
+type RequestTimingTracker struct {
+	mu     sync.Mutex
+	idle   time.Duration
+	maxAge time.Duration
+	conns  map[uint64]*requestConn
+}
+
+// NewRequestTimingTracker creates a tracker; idle <= 0 uses
+// DefaultRequestIdle.
+func NewRequestTimingTracker(idle time.Duration) *RequestTimingTracker {
+	if idle <= 0 {
+		idle = DefaultRequestIdle
+	}
+	return &RequestTimingTracker{
+		idle:   idle,
+		maxAge: DefaultRequestMaxAge,
+		conns:  make(map[uint64]*requestConn),
+	}
+}
+
+// Observe records one socket I/O record and returns the requests it
+// completed.
+func (t *RequestTimingTracker) Observe(rec SockIORecord) []RequestTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c := t.conns[rec.SockID]
+	switch rec.Kind {
+	case SockIORead:
+		if c == nil {
+			c = &requestConn{}
+			t.conns[rec.SockID] = c
+		}
+		c.last = rec.Timestamp
+		var done []RequestTiming
+		if c.open != nil && c.open.Writes > 0 {
+			done = append(done, *c.open)
+			c.open = nil
+		}
+		if c.open == nil {
+			c.streams++
+			c.open = &RequestTiming{
+				PID:        rec.PID,
+				CgroupID:   rec.CgroupID,
+				Comm:       rec.Comm,
+				SockID:     rec.SockID,
+				ServerPort: rec.ServerPort,
+				Client:     rec.Client,
+				Stream:     c.streams,
+				Start:      rec.Timestamp,
+			}
+		}
+		c.open.BytesIn += rec.Bytes
+		return done
+
+	case SockIOWrite:
+		// Writes with no request in flight (e.g. a request that started
+		// before the probe attached) cannot be timed.
+		if c == nil || c.open == nil {
+			return nil
+		}
+		c.last = rec.Timestamp
+		if c.open.Writes == 0 {
+			c.open.FirstWrite = rec.Timestamp
+		} else {
+			c.open.addWriteGap(rec.Timestamp.Sub(c.open.LastWrite))
+		}
+		c.open.LastWrite = rec.Timestamp
+		c.open.Writes++
+		c.open.BytesOut += rec.Bytes
+		return nil
+
+	case SockIOClose:
+		if c == nil {
+			return nil
+		}
+		delete(t.conns, rec.SockID)
+		if c.open == nil {
+			return nil
+		}
+		r := *c.open
+		if r.Writes == 0 {
+			r.Aborted = true
+			r.FirstWrite, r.LastWrite = rec.Timestamp, rec.Timestamp
+		}
+		return []RequestTiming{r}
+	}
+	return nil
+}
+
+// Flush returns requests whose response has been idle past the window at
+// now, and forgets requests that never got a response within the maximum
+// age.
+func (t *RequestTimingTracker) Flush(now time.Time) []RequestTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var done []RequestTiming
+	for id, c := range t.conns {
+		switch {
+		case c.open != nil && c.open.Writes > 0 && now.Sub(c.open.LastWrite) > t.idle:
+			done = append(done, *c.open)
+			c.open = nil
+		case c.open != nil && c.open.Writes == 0 && now.Sub(c.open.Start) > t.maxAge:
+			c.open = nil
+		}
+		if c.open == nil && now.Sub(c.last) > t.maxAge {
+			delete(t.conns, id)
+		}
+	}
+	return done
+}
+
+// SetServerPorts replaces the ports in request_timing.bpf.c's server_ports
+// map.
+func SetServerPorts(m *ebpf.Map, ports []int) error {
+	var (
+		key  uint16
+		val  uint8
+		keys []uint16
+	)
+	iter := m.Iterate()
+	for iter.Next(&key, &val) {
+		keys = append(keys, key)
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("iterate server ports: %w", err)
+	}
+	for _, k := range keys {
+		if err := m.Delete(k); err != nil {
+			return fmt.Errorf("delete server port %d: %w", k, err)
+		}
+	}
+	for _, p := range ports {
+		if p <= 0 || p > 65535 {
+			return fmt.Errorf("server port %d out of range", p)
+		}
+		if err := m.Put(uint16(p), uint8(1)); err != nil {
+			return fmt.Errorf("set server port %d: %w", p, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package collector
+
+import (
+	"testing"
+	"time"
+)
+
+var requestBase = time.Unix(1700000000, 0)
+
+func sockIO(sock uint64, kind int, at time.Duration, n int) SockIORecord {
+	return SockIORecord{
+		Timestamp:  requestBase.Add(at),
+		PID:        4242,
+		Comm:       "python3",
+		SockID:     sock,
+		ServerPort: 8000,
+		Client:     "10.0.3.7:51544",
+		Kind:       kind,
+		Bytes:      n,
+	}
+}
+
+func TestRequestTimingKeepAliveStreams(t *testing.T) {
+	tr := NewRequestTimingTracker(0)
+	const sock = 0xffff8880123a4000
+
+	var done []RequestTiming
+	for _, rec := range []SockIORecord{
+		// Request 1: headers and body arrive in two reads, then an SSE
+		// response streamed as one write per token.
+		sockIO(sock, SockIORead, 0, 512),
+		sockIO(sock, SockIORead, 2*time.Millisecond, 1024),
+		sockIO(sock, SockIOWrite, 180*time.Millisecond, 256),
+		sockIO(sock, SockIOWrite, 200*time.Millisecond, 64),
+		sockIO(sock, SockIOWrite, 220*time.Millisecond, 64),
+		sockIO(sock, SockIOWrite, 240*time.Millisecond, 64),
+		sockIO(sock, SockIOWrite, 260*time.Millisecond, 64),
+		// Request 2 on the same connection, answered in one write.
+		sockIO(sock, SockIORead, time.Second, 600),
+		sockIO(sock, SockIOWrite, 1400*time.Millisecond, 2048),
+		sockIO(sock, SockIOClose, 2*time.Second, 0),
+	} {
+		done = append(done, tr.Observe(rec)...)
+	}
+
+	if len(done) != 2 {
+		t.Fatalf("expected 2 requests, got %+v", done)
+	}
+	first, second := done[0], done[1]
+	if first.Stream != 1 || first.TTFT() != 180*time.Millisecond || first.Latency() != 260*time.Millisecond {
+		t.Fatalf("unexpected first request %+v", first)
+	}
+	if first.BytesIn != 1536 || first.BytesOut != 512 || first.Writes != 5 {
+		t.Fatalf("unexpected first request sizes %+v", first)
+	}
+	if got := first.ChunksPerSecond(); got != 50 {
+		t.Fatalf("expected 50 chunks/s, got %v", got)
+	}
+	if second.Stream != 2 || second.TTFT() != 400*time.Millisecond || second.Latency() != 400*time.Millisecond || second.Aborted {
+		t.Fatalf("unexpected second request %+v", second)
+	}
+	if second.ChunksPerSecond() != 0 {
+		t.Fatalf("single-write response should have no chunk rate, got %v", second.ChunksPerSecond())
+	}
+}
+
+func TestRequestTimingWriteGaps(t *testing.T) {
+	tr := NewRequestTimingTracker(0)
+	const sock = 0xffff8880123a4000
+
+	tr.Observe(sockIO(sock, SockIORead, 0, 512))
+	// Twenty tokens 20ms apart with one 900ms stall after the tenth.
+	at := 150 * time.Millisecond
+	for i := 0; i < 20; i++ {
+		tr.Observe(sockIO(sock, SockIOWrite, at, 48))
+		at += 20 * time.Millisecond
+		if i == 9 {
+			at += 880 * time.Millisecond
+		}
+	}
+	done := tr.Observe(sockIO(sock, SockIOClose, at, 0))
+	if len(done) != 1 {
+		t.Fatalf("expected one request, got %+v", done)
+	}
+	r := done[0]
+	if r.MaxWriteGap != 900*time.Millisecond {
+		t.Fatalf("expected a 900ms max gap, got %v", r.MaxWriteGap)
+	}
+	if p95 := r.P95WriteGap(); p95 <= 20*time.Millisecond || p95 >= r.MaxWriteGap {
+		t.Fatalf("expected p95 between the token cadence and the stall, got %v", p95)
+	}
+	if s := r.RawSample(SampleMeta{}); s.InterTokenStallMs != 900 {
+		t.Fatalf("expected a 900ms inter-token stall, got %+v", s)
+	}
+
+	// A response written in one call has no gaps and no stall event.
+	tr.Observe(sockIO(sock+1, SockIORead, 0, 512))
+	tr.Observe(sockIO(sock+1, SockIOWrite, 300*time.Millisecond, 4096))
+	done = tr.Observe(sockIO(sock+1, SockIOClose, time.Second, 0))
+	if len(done) != 1 || done[0].MaxWriteGap != 0 || done[0].P95WriteGap() != 0 {
+		t.Fatalf("expected no write gaps, got %+v", done)
+	}
+	for _, ev := range NormalizeSample(done[0].RawSample(SampleMeta{})) {
+		if ev.SLIName == "inter_token_stall_ms" {
+			t.Fatalf("single-write sample should omit inter-token stall, got %+v", ev)
+		}
+	}
+}
+
+func TestRequestTimingAbortAndUntracked(t *testing.T) {
+	tr := NewRequestTimingTracker(0)
+
+	// A response write with no request in flight is ignored.
+	if out := tr.Observe(sockIO(1, SockIOWrite, 0, 100)); out != nil {
+		t.Fatalf("write without request should be ignored, got %+v", out)
+	}
+	if out := tr.Observe(sockIO(1, SockIOClose, time.Millisecond, 0)); out != nil {
+		t.Fatalf("close of untracked connection should be ignored, got %+v", out)
+	}
+
+	// The server closes before writing any response byte.
+	tr.Observe(sockIO(2, SockIORead, 0, 300))
+	out := tr.Observe(sockIO(2, SockIOClose, 30*time.Second, 0))
+	if len(out) != 1 || !out[0].Aborted || out[0].TTFT() != 30*time.Second {
+		t.Fatalf("expected an aborted request, got %+v", out)
+	}
+	if s := out[0].RawSample(SampleMeta{}); s.ErrorRate != 1 {
+		t.Fatalf("aborted request should count as an error, got %+v", s)
+	}
+}
+
+func TestRequestTimingFlush(t *testing.T) {
+	tr := NewRequestTimingTracker(2 * time.Second)
+	tr.Observe(sockIO(1, SockIORead, 0, 300))
+	tr.Observe(sockIO(1, SockIOWrite, 100*time.Millisecond, 40))
+	tr.Observe(sockIO(1, SockIOWrite, 1500*time.Millisecond, 40))
+
+	if out := tr.Flush(requestBase.Add(3 * time.Second)); out != nil {
+		t.Fatalf("request still streaming within the idle window, got %+v", out)
+	}
+	out := tr.Flush(requestBase.Add(4 * time.Second))
+	if len(out) != 1 || out[0].Latency() != 1500*time.Millisecond {
+		t.Fatalf("expected the idle request to complete, got %+v", out)
+	}
+
+	// The next request on the kept-alive connection is numbered after it.
+	tr.Observe(sockIO(1, SockIORead, 10*time.Second, 300))
+	tr.Observe(sockIO(1, SockIOWrite, 10100*time.Millisecond, 40))
+	out = tr.Observe(sockIO(1, SockIOClose, 11*time.Second, 0))
+	if len(out) != 1 || out[0].Stream != 2 {
+		t.Fatalf("expected stream 2 on close, got %+v", out)
+	}
+
+	// A request that never gets a response is dropped after the max age.
+	tr.Observe(sockIO(3, SockIORead, 0, 10))
+	if out := tr.Flush(requestBase.Add(DefaultRequestMaxAge + time.Second)); out != nil {
+		t.Fatalf("unanswered request should be dropped, got %+v", out)
+	}
+	if len(tr.conns) != 0 {
+		t.Fatalf("expected idle connections to be forgotten, got %d", len(tr.conns))
+	}
+}
+
+func TestRequestTimingRawSample(t *testing.T) {
+	r := RequestTiming{
+		SockID:     0xffff8880aa00,
+		Stream:     1,
+		Start:      requestBase,
+		FirstWrite: requestBase.Add(1200 * time.Millisecond),
+		LastWrite:  requestBase.Add(3200 * time.Millisecond),
+		Writes:     41,
+	}
+	sample := r.RawSample(SampleMeta{Namespace: "llm", Workload: "vllm", Service: "vllm", Node: "node-1", Pod: "vllm-0"})
+	if sample.TTFTMs != 1200 || sample.RequestLatencyMs != 3200 || sample.TokenTPS != 20 || sample.Source != SourceKernel {
+		t.Fatalf("unexpected sample %+v", sample)
+	}
+
+	events := NormalizeSample(sample)
+	if len(events) != 4 {
+		t.Fatalf("expected 4 SLO events, got %d", len(events))
+	}
+	if events[0].Labels["source"] != SourceKernel || events[0].Labels["pod"] != "vllm-0" {
+		t.Fatalf("unexpected labels %v", events[0].Labels)
+	}
+	if events[0].Status != "breach" {
+		t.Fatalf("expected ttft breach at 1200ms, got %s", events[0].Status)
+	}
+
+	// Responses written in one call have no throughput to report.
+	r.Writes = 1
+	r.LastWrite = r.FirstWrite
+	for _, ev := range NormalizeSample(r.RawSample(SampleMeta{})) {
+		if ev.SLIName == "token_throughput_tps" {
+			t.Fatalf("single-write kernel sample should omit throughput, got %+v", ev)
+		}
+	}
+}
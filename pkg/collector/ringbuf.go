@@ -6,11 +6,14 @@ import (
 	"encoding/binary"
 	"fmt"
 	"log"
+	"net"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/cilium/ebpf/ringbuf"
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
 )
 
 // EventMetadata is the workload identity passed to the ring buffer consumer.
@@ -23,19 +26,30 @@ type EventMetadata struct {
 	Container string
 	TraceID   string
 	SpanID    string
+	// Service, Workload and NodeBootID populate the v1beta1 identity fields.
+	Service    string
+	Workload   string
+	NodeBootID string
 }
 
 // signalType mirrors the enum llm_slo_signal_type from llm_slo_event.h.
 const (
-	signalTypeDNSLatency    uint32 = 1
-	signalTypeTCPRetransmit uint32 = 2
-	signalTypeRunqueueDelay uint32 = 3
-	signalTypeConnectLat    uint32 = 4
-	signalTypeTLSHandshake  uint32 = 5
-	signalTypeCPUSteal      uint32 = 6
-	signalTypeMemReclaim    uint32 = 7
-	signalTypeDiskIOLatency uint32 = 8
-	signalTypeSyscallLat    uint32 = 9
+	signalTypeDNSLatency    = signalspec.KernelDNSLatency
+	signalTypeTCPRetransmit = signalspec.KernelTCPRetransmit
+	signalTypeRunqueueDelay = signalspec.KernelRunqueueDelay
+	signalTypeConnectLat    = signalspec.KernelConnectLatency
+	signalTypeTLSHandshake  = signalspec.KernelTLSHandshake
+	signalTypeCPUSteal      = signalspec.KernelCPUSteal
+	signalTypeMemReclaim    = signalspec.KernelMemReclaim
+	signalTypeDiskIOLatency = signalspec.KernelDiskIOLatency
+	signalTypeSyscallLat    = signalspec.KernelSyscallLatency
+	signalTypeOOMKill       = signalspec.KernelOOMKill
+	signalTypeTCPSRTT       = signalspec.KernelTCPSRTT
+	signalTypeTCPZeroWindow = signalspec.KernelTCPZeroWindow
+	signalTypeTCPReset      = signalspec.KernelTCPReset
+	signalTypeListenOverflow = signalspec.KernelListenOverflow
+	signalTypeDNSNXDomain    = signalspec.KernelDNSNXDomain
+	signalTypeConntrackDrop  = signalspec.KernelConntrackDrop
 )
 
 // bpfEvent matches the packed struct llm_slo_event from llm_slo_event.h.
@@ -49,16 +63,122 @@ type bpfEvent struct {
 	ConnDstPort  uint16
 	ConnDstIP    uint32
 	ErrnoVal     int32
+	CgroupID     uint64
+	Comm         [16]byte
+	ConnSrcIP    uint32
 }
 
+// dnsQNameLen mirrors LLM_SLO_DNS_QNAME_LEN.
+const dnsQNameLen = 128
+
+// dnsAnswersLen mirrors LLM_SLO_DNS_ANSWERS_LEN.
+const dnsAnswersLen = 256
+
+// bpfDNSTail matches the fields struct llm_slo_dns_event appends to
+// llm_slo_event for DNS signal types.
+type bpfDNSTail struct {
+	QType uint16
+	RCode uint8
+	Flags uint8
+	QName [dnsQNameLen]byte
+}
+
+// bpfDNSAnswers matches the answer section fields that follow bpfDNSTail.
+// Objects built before they were added send bpfDNSTail alone.
+type bpfDNSAnswers struct {
+	ANCount uint16
+	Len     uint16
+	Data    [dnsAnswersLen]byte
+}
+
+// bpfRunqTail matches the fields struct llm_slo_runq_event appends to
+// llm_slo_event for run-queue delay events.
+type bpfRunqTail struct {
+	PrevCgroupID uint64
+	PrevPID      uint32
+	PrevComm     [16]byte
+}
+
+// bpfBlkTail matches the fields struct llm_slo_blk_event appends to
+// llm_slo_event for disk I/O latency events.
+type bpfBlkTail struct {
+	DevMajor uint32
+	DevMinor uint32
+	Bytes    uint32
+	Op       uint8
+	Pad      [3]uint8
+}
+
+// Block request operations mirror LLM_SLO_BLK_*.
+const (
+	blkOpRead  = 1
+	blkOpWrite = 2
+)
+
+// recordTypeHTTP mirrors LLM_SLO_RECORD_HTTP. HTTP records carry captured
+// plaintext rather than a signal value and are parsed by HTTPStatusTracker.
+const recordTypeHTTP = 64
+
+// httpDataLen mirrors LLM_SLO_HTTP_DATA_LEN.
+const httpDataLen = 512
+
+// bpfHTTPTail matches the fields struct llm_slo_http_event appends to
+// llm_slo_event.
+type bpfHTTPTail struct {
+	ConnID uint64
+	Len    uint32
+	Status uint16
+	Kind   uint8
+	Pad    uint8
+	Data   [httpDataLen]byte
+}
+
+// recordTypeSockIO mirrors LLM_SLO_RECORD_SOCK_IO. Socket I/O records
+// are segmented into requests by RequestTimingTracker.
+const recordTypeSockIO = 65
+
+// bpfSockIOTail matches the fields struct llm_slo_sock_io_event appends to
+// llm_slo_event.
+type bpfSockIOTail struct {
+	SockID uint64
+	Bytes  uint32
+	Kind   uint8
+	Pad    [3]uint8
+}
+
+// DNS event flags mirror LLM_SLO_DNS_F_*.
+const (
+	dnsFlagParsed  = 0x01
+	dnsFlagAnswers = 0x02
+)
+
+// bpfEventLegacySize is the encoded size of llm_slo_event before cgroup_id
+// and comm were appended. Samples of this size come from older objects.
+const bpfEventLegacySize = 40
+
 // RingBufConsumer reads llm_slo_event entries from eBPF ring buffers
-// and converts them to schema.ProbeEventV1 on a channel.
+// and converts them to schema.ProbeEventV1Beta1 on a channel. Use
+// schema.DowngradeProbeEvent for v1alpha1 consumers.
 type RingBufConsumer struct {
 	mu      sync.Mutex
 	readers []*ringbuf.Reader
-	events  chan schema.ProbeEventV1
+	events  chan schema.ProbeEventV1Beta1
 	done    chan struct{}
 	meta    EventMetadata
+	lookups *DNSLookupTracker
+	hosts   *PassiveDNSCache
+	churn   *ConnectionChurnTracker
+	http    *HTTPStatusTracker
+	devices *BlockDeviceResolver
+	// thresholds classifies derived events; nil uses registry cutoffs.
+	thresholds *signalspec.ThresholdTable
+	// requests and samples are set by EnableRequestTiming.
+	requests *RequestTimingTracker
+	samples  chan RawSample
+	// ktimeOffset maps bpf_ktime_get_ns to wall-clock nanoseconds; it is
+	// calibrated from the first socket I/O record.
+	ktimeOnce   sync.Once
+	ktimeOffset int64
 }
 
 // NewRingBufConsumer creates a consumer. Call AddReader for each probe's
@@ -68,12 +188,45 @@ func NewRingBufConsumer(bufSize int, meta EventMetadata) *RingBufConsumer {
 		bufSize = 256
 	}
 	return &RingBufConsumer{
-		events: make(chan schema.ProbeEventV1, bufSize),
-		done:   make(chan struct{}),
-		meta:   meta,
+		events: make(chan schema.ProbeEventV1Beta1, bufSize),
+		done:    make(chan struct{}),
+		meta:    meta,
+		lookups: NewDNSLookupTracker(DefaultDNSLookupWindow),
+		hosts:   NewPassiveDNSCache(DefaultPassiveDNSSize),
+		churn:   NewConnectionChurnTracker(DefaultChurnWindow, nil),
+		http:    NewHTTPStatusTracker(nil),
+		devices: NewBlockDeviceResolver("/sys"),
 	}
 }
 
+// SetProviderHosts limits HTTP status events to responses from hosts. By
+// default responses from every host are reported.
+func (c *RingBufConsumer) SetProviderHosts(hosts []string) {
+	c.http.SetHosts(hosts)
+}
+
+// SetThresholds sets the table that classifies the status of events the
+// consumer derives itself, such as DNS lookups, provider HTTP statuses,
+// stream gaps and connection churn; nil uses registry defaults. Call it
+// before Start.
+func (c *RingBufConsumer) SetThresholds(table *signalspec.ThresholdTable) {
+	c.thresholds = table
+}
+
+// EnableRequestTiming turns socket I/O records into kernel SLO samples on
+// Samples. A request whose response has been quiet for idle is complete;
+// idle <= 0 uses DefaultRequestIdle. Call it before Start.
+func (c *RingBufConsumer) EnableRequestTiming(idle time.Duration) {
+	c.requests = NewRequestTimingTracker(idle)
+	c.samples = make(chan RawSample, cap(c.events))
+}
+
+// Samples returns the channel of kernel request timing samples, or nil if
+// request timing is not enabled.
+func (c *RingBufConsumer) Samples() <-chan RawSample {
+	return c.samples
+}
+
 // AddReader registers a ring buffer reader for consumption.
 func (c *RingBufConsumer) AddReader(r *ringbuf.Reader) {
 	c.mu.Lock()
@@ -82,7 +235,7 @@ func (c *RingBufConsumer) AddReader(r *ringbuf.Reader) {
 }
 
 // Events returns the channel of decoded probe events.
-func (c *RingBufConsumer) Events() <-chan schema.ProbeEventV1 {
+func (c *RingBufConsumer) Events() <-chan schema.ProbeEventV1Beta1 {
 	return c.events
 }
 
@@ -95,6 +248,13 @@ func (c *RingBufConsumer) Start(ctx context.Context) {
 	c.mu.Unlock()
 
 	var wg sync.WaitGroup
+	if c.requests != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.flushRequests(ctx)
+		}()
+	}
 	for _, r := range readers {
 		wg.Add(1)
 		go func(reader *ringbuf.Reader) {
@@ -109,6 +269,9 @@ func (c *RingBufConsumer) Start(ctx context.Context) {
 	}
 	wg.Wait()
 	close(c.events)
+	if c.samples != nil {
+		close(c.samples)
+	}
 	close(c.done)
 }
 
@@ -140,17 +303,541 @@ func (c *RingBufConsumer) readLoop(ctx context.Context, reader *ringbuf.Reader)
 			continue
 		}
 
-		probeEvent := c.toProbeEvent(event)
+		if event.SignalType == recordTypeSockIO {
+			if !c.completeRequests(ctx, c.requestTimings(event, record.RawSample)) {
+				return
+			}
+			continue
+		}
+
+		var outs []schema.ProbeEventV1Beta1
+		if event.SignalType == recordTypeHTTP {
+			outs = c.providerEvents(event, record.RawSample)
+		} else {
+			probeEvent := c.toProbeEventV1Beta1(event)
+			if tail, ok := decodeDNSTail(record.RawSample); ok {
+				probeEvent.DNS = dnsQuery(tail, probeEvent.ConnTuple)
+				if answers, ok := decodeDNSAnswers(record.RawSample, tail); ok {
+					c.observeAnswers(probeEvent, answers)
+				}
+			}
+			if tail, ok := decodeRunqTail(record.RawSample); ok {
+				probeEvent.Sched = schedPrev(tail)
+			}
+			if tail, ok := decodeBlkTail(record.RawSample); ok {
+				probeEvent.Disk = c.diskIO(tail)
+			}
+			c.annotateHost(&probeEvent)
+			outs = append([]schema.ProbeEventV1Beta1{probeEvent}, c.lookupEvents(probeEvent)...)
+			outs = append(outs, c.churnEvents(probeEvent)...)
+		}
+		for _, out := range outs {
+			c.annotateHost(&out)
+			select {
+			case c.events <- out:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// decodeDNSTail reads the llm_slo_dns_event fields that follow the common
+// event for DNS signal types.
+func decodeDNSTail(data []byte) (bpfDNSTail, bool) {
+	var (
+		event bpfEvent
+		tail  bpfDNSTail
+	)
+	base := binary.Size(event)
+	if len(data) < base+binary.Size(tail) {
+		return tail, false
+	}
+	st := binary.LittleEndian.Uint32(data[16:20])
+	if st != signalTypeDNSLatency && st != signalTypeDNSNXDomain {
+		return tail, false
+	}
+	if err := binary.Read(bytes.NewReader(data[base:]), binary.LittleEndian, &tail); err != nil {
+		return tail, false
+	}
+	return tail, tail.Flags&dnsFlagParsed != 0
+}
+
+// decodeDNSAnswers reads the answer section that follows a decoded DNS
+// tail.
+func decodeDNSAnswers(data []byte, tail bpfDNSTail) (bpfDNSAnswers, bool) {
+	var answers bpfDNSAnswers
+	if tail.Flags&dnsFlagAnswers == 0 {
+		return answers, false
+	}
+	off := binary.Size(bpfEvent{}) + binary.Size(tail)
+	if len(data) < off+binary.Size(answers) {
+		return answers, false
+	}
+	if err := binary.Read(bytes.NewReader(data[off:]), binary.LittleEndian, &answers); err != nil {
+		return answers, false
+	}
+	return answers, true
+}
+
+// observeAnswers adds the addresses of an answered query to the passive
+// DNS cache. NXDOMAIN events repeat their dns_latency_ms event and are
+// skipped.
+func (c *RingBufConsumer) observeAnswers(ev schema.ProbeEventV1Beta1, answers bpfDNSAnswers) {
+	if ev.Signal != signalspec.DNSLatencyMS || ev.DNS == nil || ev.DNS.QNameTruncated || c.hosts == nil {
+		return
+	}
+	n := min(int(answers.Len), dnsAnswersLen)
+	addrs := parseDNSAddrAnswers(answers.Data[:n], int(answers.ANCount))
+	c.hosts.Observe(ev.DNS.QName, addrs, time.Unix(0, ev.TSUnixNano))
+}
+
+// annotateHost names the destination of ev's connection from the passive
+// DNS cache. DNS events are skipped: their destination is the resolver.
+func (c *RingBufConsumer) annotateHost(ev *schema.ProbeEventV1Beta1) {
+	if ev.DNS != nil {
+		return
+	}
+	c.hosts.Annotate(ev.ConnTuple, time.Unix(0, ev.TSUnixNano))
+}
+
+// decodeRunqTail reads the llm_slo_runq_event fields that follow the
+// common event of a run-queue delay event. Objects built before the tail
+// was added send the common event alone.
+func decodeRunqTail(data []byte) (bpfRunqTail, bool) {
+	var (
+		event bpfEvent
+		tail  bpfRunqTail
+	)
+	base := binary.Size(event)
+	if len(data) < base+binary.Size(tail) {
+		return tail, false
+	}
+	if binary.LittleEndian.Uint32(data[16:20]) != signalTypeRunqueueDelay {
+		return tail, false
+	}
+	if err := binary.Read(bytes.NewReader(data[base:]), binary.LittleEndian, &tail); err != nil {
+		return tail, false
+	}
+	return tail, true
+}
+
+// schedPrev returns the outgoing task of a run-queue event, or nil when
+// the CPU was idle.
+func schedPrev(tail bpfRunqTail) *schema.SchedPrev {
+	if tail.PrevPID == 0 {
+		return nil
+	}
+	return &schema.SchedPrev{
+		PrevPID:      int(tail.PrevPID),
+		PrevComm:     commString(tail.PrevComm),
+		PrevCgroupID: tail.PrevCgroupID,
+	}
+}
+
+// decodeBlkTail reads the llm_slo_blk_event fields that follow the common
+// event of a disk I/O latency event.
+func decodeBlkTail(data []byte) (bpfBlkTail, bool) {
+	var (
+		event bpfEvent
+		tail  bpfBlkTail
+	)
+	base := binary.Size(event)
+	if len(data) < base+binary.Size(tail) {
+		return tail, false
+	}
+	if binary.LittleEndian.Uint32(data[16:20]) != signalTypeDiskIOLatency {
+		return tail, false
+	}
+	if err := binary.Read(bytes.NewReader(data[base:]), binary.LittleEndian, &tail); err != nil {
+		return tail, false
+	}
+	return tail, true
+}
+
+func (c *RingBufConsumer) diskIO(tail bpfBlkTail) *schema.DiskIO {
+	op := "other"
+	switch tail.Op {
+	case blkOpRead:
+		op = "read"
+	case blkOpWrite:
+		op = "write"
+	}
+	device := fmt.Sprintf("%d:%d", tail.DevMajor, tail.DevMinor)
+	if c.devices != nil {
+		device = c.devices.Name(tail.DevMajor, tail.DevMinor)
+	}
+	return &schema.DiskIO{
+		Device: device,
+		Major:  int(tail.DevMajor),
+		Minor:  int(tail.DevMinor),
+		Op:     op,
+		Bytes:  int(tail.Bytes),
+	}
+}
+
+func dnsQuery(tail bpfDNSTail, tuple *schema.ConnTuple) *schema.DNSQuery {
+	name, truncated := dnsNameFromWire(tail.QName[:])
+	q := &schema.DNSQuery{
+		QName:          name,
+		QNameTruncated: truncated,
+		QType:          int(tail.QType),
+		RCode:          int(tail.RCode),
+	}
+	if tuple != nil {
+		q.ResolverIP = tuple.DstIP
+	}
+	return q
+}
+
+// lookupEvents feeds answered DNS queries to the lookup tracker and turns
+// closed lookups into dns_queries_per_lookup events.
+func (c *RingBufConsumer) lookupEvents(ev schema.ProbeEventV1Beta1) []schema.ProbeEventV1Beta1 {
+	if ev.Signal != signalspec.DNSLatencyMS || ev.DNS == nil || c.lookups == nil {
+		return nil
+	}
+	closed := c.lookups.Observe(DNSQueryObservation{
+		Timestamp: time.Unix(0, ev.TSUnixNano),
+		PID:       ev.PID,
+		QName:     ev.DNS.QName,
+		QType:     ev.DNS.QType,
+		RCode:     ev.DNS.RCode,
+	})
+	out := make([]schema.ProbeEventV1Beta1, 0, len(closed))
+	for _, lookup := range closed {
+		out = append(out, c.lookupEvent(ev, lookup))
+	}
+	return out
+}
+
+// churnEvents feeds connects to the churn tracker and turns the windows
+// it closes into connection_churn_per_s events.
+func (c *RingBufConsumer) churnEvents(ev schema.ProbeEventV1Beta1) []schema.ProbeEventV1Beta1 {
+	if c.churn == nil {
+		return nil
+	}
+	closed, ok := c.churn.Observe(ev)
+	if !ok {
+		return nil
+	}
+	out := make([]schema.ProbeEventV1Beta1, 0, len(closed))
+	for _, s := range closed {
+		out = append(out, c.churnEvent(ev, s))
+	}
+	return out
+}
+
+// churnEvent builds a connection_churn_per_s event for one pair, with the
+// node identity of the event that closed the window and the task and
+// tuple of the pair's last connect.
+func (c *RingBufConsumer) churnEvent(closing schema.ProbeEventV1Beta1, s ChurnSummary) schema.ProbeEventV1Beta1 {
+	desc, _ := signalspec.Lookup(signalspec.ConnectionChurnPerS)
+	out := closing
+	out.Signal = desc.Name
+	out.Unit = desc.Unit
+	out.TSUnixNano = s.End.UnixNano()
+	out.PID = s.PID
+	out.TID = s.PID
+	out.Comm = s.Comm
+	out.CgroupID = s.CgroupID
+	out.Value = s.RatePerS
+	tuple := s.Tuple
+	out.ConnTuple = &tuple
+	out.Errno = nil
+	out.Dependency = ""
+	out.Status = c.thresholds.Status(desc.Name, signalspec.Workload{Namespace: out.Namespace, Service: out.Service}, out.Value)
+	out.Churn = &schema.ConnChurn{
+		Destination:  s.Destination,
+		Connects:     s.Connects,
+		WindowS:      s.Window.Seconds(),
+		BaselinePerS: s.BaselinePerS,
+		Regression:   s.Regression,
+	}
+	return out
+}
+
+// lookupEvent builds a dns_queries_per_lookup event carrying the identity
+// of the event that closed the lookup.
+func (c *RingBufConsumer) lookupEvent(closing schema.ProbeEventV1Beta1, lookup DNSLookup) schema.ProbeEventV1Beta1 {
+	desc, _ := signalspec.Lookup(signalspec.DNSQueriesPerLookup)
+	out := closing
+	out.Signal = desc.Name
+	out.Unit = desc.Unit
+	out.TSUnixNano = lookup.End.UnixNano()
+	out.PID = lookup.PID
+	out.Value = float64(lookup.Queries)
+	out.ConnTuple = nil
+	out.Errno = nil
+	out.Status = c.thresholds.Status(desc.Name, signalspec.Workload{Namespace: out.Namespace, Service: out.Service}, out.Value)
+	resolver := ""
+	if closing.DNS != nil {
+		resolver = closing.DNS.ResolverIP
+	}
+	out.DNS = &schema.DNSQuery{
+		QName:      lookup.Name,
+		QType:      lookup.QType,
+		RCode:      lookup.RCode,
+		ResolverIP: resolver,
+	}
+	return out
+}
+
+// decodeHTTPTail reads the llm_slo_http_event fields that follow the
+// common event of an HTTP record.
+func decodeHTTPTail(data []byte) (bpfHTTPTail, bool) {
+	var (
+		event bpfEvent
+		tail  bpfHTTPTail
+	)
+	base := binary.Size(event)
+	if len(data) < base+binary.Size(tail) {
+		return tail, false
+	}
+	if err := binary.Read(bytes.NewReader(data[base:]), binary.LittleEndian, &tail); err != nil {
+		return tail, false
+	}
+	return tail, true
+}
+
+// providerEvents feeds an HTTP record to the status tracker and turns the
+// provider responses it completes into events.
+func (c *RingBufConsumer) providerEvents(e bpfEvent, data []byte) []schema.ProbeEventV1Beta1 {
+	tail, ok := decodeHTTPTail(data)
+	if !ok {
+		return nil
+	}
+	responses := c.http.Observe(HTTPRecord{
+		Timestamp: time.Now(),
+		PID:       int(e.PID),
+		ConnID:    tail.ConnID,
+		Kind:      int(tail.Kind),
+		Status:    int(tail.Status),
+		Len:       int(tail.Len),
+		Data:      tail.Data[:min(int(tail.Len), httpDataLen)],
+	})
+	if len(responses) == 0 {
+		return nil
+	}
+	base := c.toProbeEventV1Beta1(e)
+	var out []schema.ProbeEventV1Beta1
+	for _, resp := range responses {
+		out = append(out, c.providerResponseEvents(base, resp)...)
+	}
+	return out
+}
+
+// providerResponseEvents maps one provider response to
+// provider_http_429_total, provider_http_5xx_total and
+// provider_retry_after_s events.
+func (c *RingBufConsumer) providerResponseEvents(base schema.ProbeEventV1Beta1, resp ProviderResponse) []schema.ProbeEventV1Beta1 {
+	details := &schema.HTTPResponse{Host: resp.Host, Status: resp.Status, Proto: resp.Proto}
+	if resp.HasRetryAfter {
+		secs := resp.RetryAfter.Seconds()
+		details.RetryAfterS = &secs
+	}
+	var out []schema.ProbeEventV1Beta1
+	emit := func(signal string, value float64) {
+		desc, _ := signalspec.Lookup(signal)
+		ev := base
+		ev.Signal = desc.Name
+		ev.Unit = desc.Unit
+		ev.TSUnixNano = resp.Timestamp.UnixNano()
+		ev.PID = resp.PID
+		ev.Value = value
+		ev.ConnTuple = nil
+		ev.Errno = nil
+		ev.Status = c.thresholds.Status(desc.Name, signalspec.Workload{Namespace: ev.Namespace, Service: ev.Service}, value)
+		ev.HTTP = details
+		out = append(out, ev)
+	}
+	switch {
+	case resp.Status == 429:
+		emit(signalspec.ProviderHTTP429s, 1)
+	case resp.Status >= 500 && resp.Status <= 599:
+		emit(signalspec.ProviderHTTP5xxs, 1)
+	}
+	if resp.HasRetryAfter && resp.Status >= 400 {
+		emit(signalspec.ProviderRetryAfterS, resp.RetryAfter.Seconds())
+	}
+	return out
+}
+
+// decodeSockIOTail reads the llm_slo_sock_io_event fields that follow the
+// common event of a socket I/O record.
+func decodeSockIOTail(data []byte) (bpfSockIOTail, bool) {
+	var (
+		event bpfEvent
+		tail  bpfSockIOTail
+	)
+	base := binary.Size(event)
+	if len(data) < base+binary.Size(tail) {
+		return tail, false
+	}
+	if err := binary.Read(bytes.NewReader(data[base:]), binary.LittleEndian, &tail); err != nil {
+		return tail, false
+	}
+	return tail, true
+}
+
+// requestTimings feeds a socket I/O record to the request timing tracker
+// and returns the requests it completes.
+func (c *RingBufConsumer) requestTimings(e bpfEvent, data []byte) []RequestTiming {
+	if c.requests == nil {
+		return nil
+	}
+	tail, ok := decodeSockIOTail(data)
+	if !ok {
+		return nil
+	}
+	done := c.requests.Observe(SockIORecord{
+		Timestamp:  c.kernelTime(e.TimestampNS),
+		PID:        int(e.PID),
+		CgroupID:   e.CgroupID,
+		Comm:       commString(e.Comm),
+		SockID:     tail.SockID,
+		ServerPort: int(e.ConnSrcPort),
+		Client:     fmt.Sprintf("%s:%d", ipFromU32(e.ConnDstIP), e.ConnDstPort),
+		Kind:       int(tail.Kind),
+		Bytes:      int(tail.Bytes),
+	})
+	return done
+}
+
+func (c *RingBufConsumer) timingSamples(done []RequestTiming) []RawSample {
+	meta := SampleMeta{
+		Namespace: c.meta.Namespace,
+		Workload:  c.meta.Workload,
+		Service:   c.meta.Service,
+		Node:      c.meta.Node,
+		Pod:       c.meta.Pod,
+	}
+	out := make([]RawSample, 0, len(done))
+	for _, r := range done {
+		out = append(out, r.RawSample(meta))
+	}
+	return out
+}
+
+// streamGapEvents maps each completed request streamed in more than one
+// write to a stream_write_gap_ms event valued at its largest write gap.
+func (c *RingBufConsumer) streamGapEvents(done []RequestTiming) []schema.ProbeEventV1Beta1 {
+	desc, _ := signalspec.Lookup(signalspec.StreamWriteGapMS)
+	var out []schema.ProbeEventV1Beta1
+	for _, r := range done {
+		if r.Writes < 2 {
+			continue
+		}
+		value := durationMS(r.MaxWriteGap)
+		ev := schema.ProbeEventV1Beta1{
+			SchemaVersion:  schema.ProbeSchemaV1Beta1,
+			TSUnixNano:     r.LastWrite.UnixNano(),
+			Signal:         desc.Name,
+			Node:           c.meta.Node,
+			NodeBootID:     c.meta.NodeBootID,
+			Namespace:      c.meta.Namespace,
+			Pod:            c.meta.Pod,
+			Container:      c.meta.Container,
+			Service:        c.meta.Service,
+			Workload:       c.meta.Workload,
+			PID:            r.PID,
+			TID:            r.PID,
+			Comm:           r.Comm,
+			CgroupID:       r.CgroupID,
+			ConnTuple:      requestConnTuple(r),
+			Value:          value,
+			Unit:           desc.Unit,
+			SamplingWeight: 1,
+			Stream: &schema.StreamWrites{
+				Request:  r.Stream,
+				Writes:   r.Writes,
+				P95GapMS: durationMS(r.P95WriteGap()),
+			},
+		}
+		ev.Status = c.thresholds.Status(desc.Name, signalspec.Workload{Namespace: ev.Namespace, Service: ev.Service}, value)
+		out = append(out, ev)
+	}
+	return out
+}
+
+// requestConnTuple returns the server side of a request's connection as
+// source and the client as destination, as request_timing.bpf.c reports
+// them.
+func requestConnTuple(r RequestTiming) *schema.ConnTuple {
+	host, port, err := net.SplitHostPort(r.Client)
+	if err != nil {
+		return nil
+	}
+	clientPort, _ := strconv.Atoi(port)
+	return &schema.ConnTuple{
+		SrcIP:    "0.0.0.0",
+		DstIP:    host,
+		SrcPort:  r.ServerPort,
+		DstPort:  clientPort,
+		Protocol: "tcp",
+	}
+}
+
+// completeRequests sends completed requests as samples and their write
+// gaps as events. It returns false once ctx is done.
+func (c *RingBufConsumer) completeRequests(ctx context.Context, done []RequestTiming) bool {
+	if !c.sendSamples(ctx, c.timingSamples(done)) {
+		return false
+	}
+	for _, ev := range c.streamGapEvents(done) {
+		select {
+		case c.events <- ev:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// flushRequests completes idle requests once a second until ctx is done.
+func (c *RingBufConsumer) flushRequests(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
 		select {
-		case c.events <- probeEvent:
 		case <-ctx.Done():
 			return
+		case now := <-ticker.C:
+			if !c.completeRequests(ctx, c.requests.Flush(now)) {
+				return
+			}
 		}
 	}
 }
 
+func (c *RingBufConsumer) sendSamples(ctx context.Context, samples []RawSample) bool {
+	for _, s := range samples {
+		select {
+		case c.samples <- s:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// kernelTime converts a bpf_ktime_get_ns timestamp to wall-clock time.
+// Request timing needs the kernel's spacing between records, not the time
+// they were read from the ring buffer.
+func (c *RingBufConsumer) kernelTime(ns uint64) time.Time {
+	c.ktimeOnce.Do(func() {
+		c.ktimeOffset = time.Now().UnixNano() - int64(ns)
+	})
+	return time.Unix(0, int64(ns)+c.ktimeOffset)
+}
+
 func decodeBPFEvent(data []byte) (bpfEvent, error) {
 	var event bpfEvent
+	if len(data) >= bpfEventLegacySize && len(data) < binary.Size(event) {
+		// Older object without identity or source-IP fields: zero-pad the tail.
+		padded := make([]byte, binary.Size(event))
+		copy(padded, data)
+		data = padded
+	}
 	reader := bytes.NewReader(data)
 	if err := binary.Read(reader, binary.LittleEndian, &event); err != nil {
 		return event, fmt.Errorf("decode bpf event: %w", err)
@@ -158,6 +845,25 @@ func decodeBPFEvent(data []byte) (bpfEvent, error) {
 	return event, nil
 }
 
+// toProbeEventV1Beta1 adds the kernel-stamped comm and cgroup ID to the
+// v1alpha1 conversion.
+func (c *RingBufConsumer) toProbeEventV1Beta1(e bpfEvent) schema.ProbeEventV1Beta1 {
+	return schema.UpgradeProbeEvent(c.toProbeEvent(e), schema.ProbeIdentity{
+		Comm:       commString(e.Comm),
+		CgroupID:   e.CgroupID,
+		Service:    c.meta.Service,
+		Workload:   c.meta.Workload,
+		NodeBootID: c.meta.NodeBootID,
+	})
+}
+
+func commString(comm [16]byte) string {
+	if idx := bytes.IndexByte(comm[:], 0); idx >= 0 {
+		return string(comm[:idx])
+	}
+	return string(comm[:])
+}
+
 func (c *RingBufConsumer) toProbeEvent(e bpfEvent) schema.ProbeEventV1 {
 	sig, unit := signalFromType(e.SignalType)
 	value := convertValue(e.SignalType, e.ValueNS)
@@ -179,8 +885,12 @@ func (c *RingBufConsumer) toProbeEvent(e bpfEvent) schema.ProbeEventV1 {
 	}
 
 	if e.ConnSrcPort != 0 || e.ConnDstPort != 0 {
+		srcIP := "0.0.0.0" // enriched by probe manager with actual IP
+		if e.ConnSrcIP != 0 {
+			srcIP = ipFromU32(e.ConnSrcIP)
+		}
 		event.ConnTuple = &schema.ConnTuple{
-			SrcIP:    "0.0.0.0", // enriched by probe manager with actual IP
+			SrcIP:    srcIP,
 			DstIP:    ipFromU32(e.ConnDstIP),
 			SrcPort:  int(e.ConnSrcPort),
 			DstPort:  int(e.ConnDstPort),
@@ -196,45 +906,25 @@ func (c *RingBufConsumer) toProbeEvent(e bpfEvent) schema.ProbeEventV1 {
 	return event
 }
 
+// signalFromType returns the signal name and ring buffer unit for a kernel
+// type. cpu_steal_pct is reported in raw ns; Go-side aggregation converts
+// it to a percentage over the sampling window.
 func signalFromType(st uint32) (string, string) {
-	switch st {
-	case signalTypeDNSLatency:
-		return "dns_latency_ms", "ms"
-	case signalTypeTCPRetransmit:
-		return "tcp_retransmits_total", "count"
-	case signalTypeRunqueueDelay:
-		return "runqueue_delay_ms", "ms"
-	case signalTypeConnectLat:
-		return "connect_latency_ms", "ms"
-	case signalTypeTLSHandshake:
-		return "tls_handshake_ms", "ms"
-	case signalTypeCPUSteal:
-		// Unit is "ns" at the kernel boundary; Go-side aggregation converts
-		// accumulated nanoseconds to a percentage over the sampling window.
-		// See convertValue() which passes the raw value through unchanged.
-		return "cpu_steal_pct", "ns"
-	case signalTypeMemReclaim:
-		return "mem_reclaim_latency_ms", "ms"
-	case signalTypeDiskIOLatency:
-		return "disk_io_latency_ms", "ms"
-	case signalTypeSyscallLat:
-		return "syscall_latency_ms", "ms"
-	default:
+	desc, ok := signalspec.ByKernelType(st)
+	if !ok {
 		return "unknown", "unknown"
 	}
+	return desc.Name, desc.RingBufUnit()
 }
 
 // convertValue converts raw nanosecond/count values from the kernel to
 // the unit expected by the signal schema.
 func convertValue(signalType uint32, valueNS uint64) float64 {
-	switch signalType {
-	case signalTypeTCPRetransmit:
-		return float64(valueNS) // already a count
-	case signalTypeCPUSteal:
-		return float64(valueNS) // raw ns; Go-side aggregates to pct
-	default:
-		return float64(valueNS) / 1e6 // ns -> ms
+	desc, ok := signalspec.ByKernelType(signalType)
+	if !ok {
+		return signalspec.ConvertNSToMS.Apply(valueNS)
 	}
+	return desc.Conversion.Apply(valueNS)
 }
 
 func ipFromU32(ip uint32) string {
@@ -3,7 +3,12 @@ package collector
 import (
 	"bytes"
 	"encoding/binary"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
 )
 
 func TestDecodeBPFEvent(t *testing.T) {
@@ -58,6 +63,13 @@ func TestSignalFromType(t *testing.T) {
 		{signalTypeMemReclaim, "mem_reclaim_latency_ms", "ms"},
 		{signalTypeDiskIOLatency, "disk_io_latency_ms", "ms"},
 		{signalTypeSyscallLat, "syscall_latency_ms", "ms"},
+		{signalTypeOOMKill, "oom_kills_total", "count"},
+		{signalTypeTCPSRTT, "tcp_srtt_ms", "ms"},
+		{signalTypeTCPZeroWindow, "tcp_zero_window_total", "count"},
+		{signalTypeTCPReset, "tcp_resets_total", "count"},
+		{signalTypeListenOverflow, "listen_overflows_total", "count"},
+		{signalTypeDNSNXDomain, "dns_nxdomain_total", "count"},
+		{signalTypeConntrackDrop, "conntrack_drops_total", "count"},
 	}
 
 	for _, tc := range tests {
@@ -148,3 +160,483 @@ func TestToProbeEventConnTuple(t *testing.T) {
 		t.Errorf("pid: got %d, want 1234", probe.PID)
 	}
 }
+
+func TestDecodeBPFEventIdentity(t *testing.T) {
+	orig := bpfEvent{
+		PID:        1234,
+		SignalType: signalTypeConnectLat,
+		ValueNS:    2000000,
+		CgroupID:   4242,
+	}
+	copy(orig.Comm[:], "python3")
+
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, orig); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if buf.Len() != bpfEventLegacySize+28 {
+		t.Fatalf("encoded size: got %d, want %d", buf.Len(), bpfEventLegacySize+28)
+	}
+
+	decoded, err := decodeBPFEvent(buf.Bytes())
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	c := &RingBufConsumer{meta: EventMetadata{Node: "node-1", Service: "rag", NodeBootID: "boot-1"}}
+	probe := c.toProbeEventV1Beta1(decoded)
+	if probe.Comm != "python3" {
+		t.Errorf("comm: got %q, want python3", probe.Comm)
+	}
+	if probe.CgroupID != 4242 {
+		t.Errorf("cgroup_id: got %d, want 4242", probe.CgroupID)
+	}
+	if probe.Service != "rag" || probe.NodeBootID != "boot-1" {
+		t.Errorf("identity: got service=%q boot_id=%q", probe.Service, probe.NodeBootID)
+	}
+	if probe.SamplingWeight != 1 {
+		t.Errorf("sampling_weight: got %f, want 1", probe.SamplingWeight)
+	}
+}
+
+func TestDecodeBPFEventLegacySample(t *testing.T) {
+	orig := bpfEvent{PID: 77, SignalType: signalTypeDNSLatency, ValueNS: 1000000}
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, orig); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	decoded, err := decodeBPFEvent(buf.Bytes()[:bpfEventLegacySize])
+	if err != nil {
+		t.Fatalf("decode legacy sample: %v", err)
+	}
+	if decoded.PID != 77 || decoded.CgroupID != 0 || decoded.Comm[0] != 0 {
+		t.Errorf("legacy decode: got pid=%d cgroup=%d comm=%q", decoded.PID, decoded.CgroupID, decoded.Comm[:])
+	}
+}
+
+func TestDecodeBPFEventWithoutSrcIP(t *testing.T) {
+	orig := bpfEvent{PID: 88, SignalType: signalTypeConnectLat, CgroupID: 7, ConnSrcIP: 0x0A00000A}
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, orig); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	// v1beta1 objects end at comm, four bytes before conn_src_ip.
+	decoded, err := decodeBPFEvent(buf.Bytes()[:buf.Len()-4])
+	if err != nil {
+		t.Fatalf("decode v1beta1 sample: %v", err)
+	}
+	if decoded.CgroupID != 7 || decoded.ConnSrcIP != 0 {
+		t.Errorf("v1beta1 decode: got cgroup=%d src_ip=%#x", decoded.CgroupID, decoded.ConnSrcIP)
+	}
+}
+
+func TestToProbeEventFullConnTuple(t *testing.T) {
+	c := &RingBufConsumer{meta: EventMetadata{Node: "node-1", Pod: "rag-service-abc"}}
+	probe := c.toProbeEvent(bpfEvent{
+		SignalType:  signalTypeTCPSRTT,
+		ValueNS:     42000000, // srtt 42ms
+		ConnSrcPort: 42424,
+		ConnDstPort: 443,
+		ConnSrcIP:   0x0A00000A, // 10.0.0.10
+		ConnDstIP:   0x0100007F,
+	})
+	if probe.Signal != "tcp_srtt_ms" || probe.Value != 42 {
+		t.Fatalf("unexpected probe: %s=%f", probe.Signal, probe.Value)
+	}
+	if probe.ConnTuple == nil || probe.ConnTuple.SrcIP != "10.0.0.10" || probe.ConnTuple.DstIP != "127.0.0.1" {
+		t.Fatalf("unexpected conn tuple: %+v", probe.ConnTuple)
+	}
+}
+
+func encodeDNSEvent(t *testing.T, st uint32, qname string, rcode uint8) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	event := bpfEvent{PID: 42, TID: 42, SignalType: st, ValueNS: 3000000, ConnSrcPort: 40000, ConnDstPort: 53, ConnDstIP: 0x0A00600A}
+	tail := bpfDNSTail{QType: 1, RCode: rcode, Flags: dnsFlagParsed}
+	pos := 0
+	for _, label := range strings.Split(qname, ".") {
+		tail.QName[pos] = byte(len(label))
+		pos += 1 + copy(tail.QName[pos+1:], label)
+	}
+	for _, v := range []any{event, tail} {
+		if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeDNSTail(t *testing.T) {
+	raw := encodeDNSEvent(t, signalTypeDNSNXDomain, "api.openai.com.svc.cluster.local", DNSRCodeNXDomain)
+	tail, ok := decodeDNSTail(raw)
+	if !ok {
+		t.Fatal("expected DNS tail")
+	}
+	q := dnsQuery(tail, &schema.ConnTuple{DstIP: "10.96.0.10"})
+	if q.QName != "api.openai.com.svc.cluster.local" || q.QType != 1 || q.RCode != DNSRCodeNXDomain || q.ResolverIP != "10.96.0.10" {
+		t.Fatalf("unexpected query: %+v", q)
+	}
+
+	event, err := decodeBPFEvent(raw)
+	if err != nil || event.SignalType != signalTypeDNSNXDomain {
+		t.Fatalf("common fields should still decode: %+v (%v)", event, err)
+	}
+	if _, ok := decodeDNSTail(raw[:binary.Size(bpfEvent{})]); ok {
+		t.Fatal("event without tail should not decode a DNS tail")
+	}
+}
+
+func TestDNSAnswersAnnotateConnections(t *testing.T) {
+	c := NewRingBufConsumer(1, EventMetadata{Node: "node-1"})
+	raw := encodeDNSEvent(t, signalTypeDNSLatency, "api.openai.com", DNSRCodeNoError)
+	if _, ok := decodeDNSAnswers(raw, bpfDNSTail{Flags: dnsFlagParsed | dnsFlagAnswers}); ok {
+		t.Fatal("event without answer fields should not decode answers")
+	}
+
+	answers := bpfDNSAnswers{ANCount: 1}
+	rec := dnsRecord(dnsTypeA, 60, []byte{104, 18, 7, 192})
+	answers.Len = uint16(copy(answers.Data[:], rec))
+	var buf bytes.Buffer
+	buf.Write(raw)
+	if err := binary.Write(&buf, binary.LittleEndian, answers); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	raw = buf.Bytes()
+	raw[binary.Size(bpfEvent{})+3] |= dnsFlagAnswers // bpfDNSTail.Flags
+
+	event, err := decodeBPFEvent(raw)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	dnsEvent := c.toProbeEventV1Beta1(event)
+	tail, ok := decodeDNSTail(raw)
+	if !ok {
+		t.Fatal("expected DNS tail")
+	}
+	dnsEvent.DNS = dnsQuery(tail, dnsEvent.ConnTuple)
+	decoded, ok := decodeDNSAnswers(raw, tail)
+	if !ok {
+		t.Fatal("expected DNS answers")
+	}
+	c.observeAnswers(dnsEvent, decoded)
+	c.annotateHost(&dnsEvent)
+	if dnsEvent.ConnTuple.DstHost != "" {
+		t.Fatalf("DNS events should keep the resolver unnamed, got %q", dnsEvent.ConnTuple.DstHost)
+	}
+
+	conn := c.toProbeEventV1Beta1(bpfEvent{
+		SignalType:  signalTypeConnectLat,
+		ValueNS:     80000000,
+		ConnSrcPort: 40100,
+		ConnDstPort: 443,
+		ConnDstIP:   0xC0071268, // 104.18.7.192
+	})
+	c.annotateHost(&conn)
+	if conn.ConnTuple.DstHost != "api.openai.com" {
+		t.Fatalf("expected dst_host from passive DNS, got %+v", conn.ConnTuple)
+	}
+}
+
+func TestLookupEventsReportQueriesPerLookup(t *testing.T) {
+	c := &RingBufConsumer{meta: EventMetadata{Node: "node-1", Pod: "rag-0"}, lookups: NewDNSLookupTracker(time.Second)}
+	var out []schema.ProbeEventV1Beta1
+	for _, q := range []struct {
+		name  string
+		rcode uint8
+	}{
+		{"api.openai.com.rag.svc.cluster.local", DNSRCodeNXDomain},
+		{"api.openai.com.svc.cluster.local", DNSRCodeNXDomain},
+		{"api.openai.com.cluster.local", DNSRCodeNXDomain},
+		{"api.openai.com", DNSRCodeNoError},
+	} {
+		raw := encodeDNSEvent(t, signalTypeDNSLatency, q.name, q.rcode)
+		event, err := decodeBPFEvent(raw)
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		probe := c.toProbeEventV1Beta1(event)
+		tail, _ := decodeDNSTail(raw)
+		probe.DNS = dnsQuery(tail, probe.ConnTuple)
+		out = append(out, c.lookupEvents(probe)...)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("expected one lookup event, got %+v", out)
+	}
+	got := out[0]
+	if got.Signal != "dns_queries_per_lookup" || got.Value != 4 || got.Status != "warning" {
+		t.Fatalf("unexpected lookup event: %s=%v (%s)", got.Signal, got.Value, got.Status)
+	}
+	if got.DNS == nil || got.DNS.QName != "api.openai.com" || got.DNS.ResolverIP != "10.96.0.10" {
+		t.Fatalf("unexpected lookup DNS details: %+v", got.DNS)
+	}
+}
+
+func TestKernelTypesDecodeFromRegistry(t *testing.T) {
+	for _, desc := range signalspec.All() {
+		if desc.KernelType == 0 {
+			continue
+		}
+		sig, unit := signalFromType(desc.KernelType)
+		if sig != desc.Name || unit != desc.RingBufUnit() {
+			t.Errorf("kernel type %d: got %s/%s, want %s/%s", desc.KernelType, sig, unit, desc.Name, desc.RingBufUnit())
+		}
+	}
+	if sig, _ := signalFromType(0); sig != "unknown" {
+		t.Errorf("type 0 should decode as unknown, got %s", sig)
+	}
+}
+
+func encodeHTTPRecord(t *testing.T, kind uint8, conn uint64, status uint16, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	event := bpfEvent{PID: 77, TID: 78, SignalType: recordTypeHTTP}
+	tail := bpfHTTPTail{ConnID: conn, Len: uint32(len(data)), Status: status, Kind: kind}
+	copy(tail.Data[:], data)
+	for _, v := range []any{event, tail} {
+		if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestProviderEventsFromHTTPRecords(t *testing.T) {
+	c := NewRingBufConsumer(8, EventMetadata{Node: "node-1", Pod: "gateway-0"})
+	c.SetProviderHosts([]string{"api.openai.com"})
+
+	var out []schema.ProbeEventV1Beta1
+	for _, raw := range [][]byte{
+		encodeHTTPRecord(t, HTTPRecordWrite, 0xc000120000, 0, "POST /v1/chat/completions HTTP/1.1\r\nHost: api.openai.com\r\nContent-Type: application/json\r\n\r\n"),
+		encodeHTTPRecord(t, HTTPRecordRead, 0xc000120000, 0, "HTTP/1.1 429 Too Many Requests\r\nRetry-After: 20\r\nContent-Length: 0\r\n\r\n"),
+		encodeHTTPRecord(t, HTTPRecordResponse, 0, 503, "vector-db.rag.svc:8080"),
+	} {
+		event, err := decodeBPFEvent(raw)
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		out = append(out, c.providerEvents(event, raw)...)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("expected 429 and retry-after events only, got %+v", out)
+	}
+	if out[0].Signal != signalspec.ProviderHTTP429s || out[0].Value != 1 || out[0].PID != 77 || out[0].Pod != "gateway-0" {
+		t.Fatalf("unexpected 429 event: %+v", out[0])
+	}
+	if out[1].Signal != signalspec.ProviderRetryAfterS || out[1].Value != 20 || out[1].Unit != "s" {
+		t.Fatalf("unexpected retry-after event: %+v", out[1])
+	}
+	http := out[0].HTTP
+	if http == nil || http.Host != "api.openai.com" || http.Status != 429 || http.Proto != HTTPProto1 || http.RetryAfterS == nil || *http.RetryAfterS != 20 {
+		t.Fatalf("unexpected HTTP details: %+v", http)
+	}
+	if err := schema.ProbeEventV1Beta1Validator().Validate(out[0]); err != nil {
+		t.Fatalf("provider event fails the v1beta1 contract: %v", err)
+	}
+}
+
+func encodeSockIORecord(t *testing.T, ts uint64, kind uint8, sock uint64, n uint32) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	event := bpfEvent{
+		PID:         4242,
+		TID:         4243,
+		TimestampNS: ts,
+		SignalType:  recordTypeSockIO,
+		ConnSrcPort: 8000,
+		ConnDstPort: 51544,
+		ConnDstIP:   0x07030a0a, // 10.10.3.7
+		ConnSrcIP:   0x0501000a,
+	}
+	copy(event.Comm[:], "python3")
+	tail := bpfSockIOTail{SockID: sock, Bytes: n, Kind: kind}
+	for _, v := range []any{event, tail} {
+		if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestRequestSamplesFromSockIORecords(t *testing.T) {
+	c := NewRingBufConsumer(8, EventMetadata{Node: "node-1", Namespace: "llm", Pod: "vllm-0", Workload: "vllm"})
+	if c.requestTimings(bpfEvent{SignalType: recordTypeSockIO}, nil) != nil {
+		t.Fatal("request timing should be off until enabled")
+	}
+	c.EnableRequestTiming(0)
+
+	const ms = uint64(time.Millisecond)
+	var out []RawSample
+	for _, raw := range [][]byte{
+		encodeSockIORecord(t, 1000*ms, SockIORead, 0xffff888001, 900),
+		encodeSockIORecord(t, 1350*ms, SockIOWrite, 0xffff888001, 128),
+		encodeSockIORecord(t, 1850*ms, SockIOWrite, 0xffff888001, 128),
+		encodeSockIORecord(t, 1900*ms, SockIOClose, 0xffff888001, 0),
+	} {
+		event, err := decodeBPFEvent(raw)
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		out = append(out, c.timingSamples(c.requestTimings(event, raw))...)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("expected one request sample, got %+v", out)
+	}
+	s := out[0]
+	if s.TTFTMs != 350 || s.RequestLatencyMs != 850 || s.TokenTPS != 2 {
+		t.Fatalf("unexpected timings %+v", s)
+	}
+	if s.Source != SourceKernel || s.Pod != "vllm-0" || s.Namespace != "llm" || s.Workload != "vllm" || s.Node != "node-1" {
+		t.Fatalf("unexpected identity %+v", s)
+	}
+	if got := c.Samples(); got == nil {
+		t.Fatal("expected a samples channel once enabled")
+	}
+}
+
+func TestStreamGapEvents(t *testing.T) {
+	c := NewRingBufConsumer(8, EventMetadata{Node: "node-1", Namespace: "llm", Pod: "vllm-0", Service: "vllm"})
+	c.EnableRequestTiming(0)
+
+	const ms = uint64(time.Millisecond)
+	var done []RequestTiming
+	for _, raw := range [][]byte{
+		encodeSockIORecord(t, 1000*ms, SockIORead, 0xffff888001, 900),
+		encodeSockIORecord(t, 1200*ms, SockIOWrite, 0xffff888001, 64),
+		encodeSockIORecord(t, 1230*ms, SockIOWrite, 0xffff888001, 64),
+		encodeSockIORecord(t, 2530*ms, SockIOWrite, 0xffff888001, 64),
+		encodeSockIORecord(t, 2560*ms, SockIOWrite, 0xffff888001, 64),
+		encodeSockIORecord(t, 2600*ms, SockIOClose, 0xffff888001, 0),
+		// Answered in one write: no gap to report.
+		encodeSockIORecord(t, 3000*ms, SockIORead, 0xffff888002, 300),
+		encodeSockIORecord(t, 3100*ms, SockIOWrite, 0xffff888002, 2048),
+		encodeSockIORecord(t, 3200*ms, SockIOClose, 0xffff888002, 0),
+	} {
+		event, err := decodeBPFEvent(raw)
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		done = append(done, c.requestTimings(event, raw)...)
+	}
+
+	events := c.streamGapEvents(done)
+	if len(done) != 2 || len(events) != 1 {
+		t.Fatalf("expected one gap event from two requests, got %d from %d", len(events), len(done))
+	}
+	ev := events[0]
+	if ev.Signal != signalspec.StreamWriteGapMS || ev.Value != 1300 || ev.Unit != "ms" || ev.Status != "error" {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+	if ev.Stream == nil || ev.Stream.Request != 1 || ev.Stream.Writes != 4 || ev.Stream.P95GapMS <= 30 {
+		t.Fatalf("unexpected stream details %+v", ev.Stream)
+	}
+	if ev.Pod != "vllm-0" || ev.ConnTuple == nil || ev.ConnTuple.SrcPort != 8000 {
+		t.Fatalf("unexpected identity %+v", ev)
+	}
+	if err := schema.ProbeEventV1Beta1Validator().Validate(ev); err != nil {
+		t.Fatalf("gap event rejected by contract: %v", err)
+	}
+}
+
+func encodeRunqEvent(t *testing.T, prevPID uint32, prevCgroup uint64, prevComm string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	event := bpfEvent{PID: 4242, TID: 900, SignalType: signalTypeRunqueueDelay, ValueNS: 3_000_000, CgroupID: 8811}
+	copy(event.Comm[:], "vllm-worker")
+	tail := bpfRunqTail{PrevCgroupID: prevCgroup, PrevPID: prevPID}
+	copy(tail.PrevComm[:], prevComm)
+	for _, v := range []any{event, tail} {
+		if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeRunqTail(t *testing.T) {
+	raw := encodeRunqEvent(t, 900, 8812, "spark-executor")
+	tail, ok := decodeRunqTail(raw)
+	if !ok {
+		t.Fatal("expected run-queue tail")
+	}
+	prev := schedPrev(tail)
+	if prev == nil || prev.PrevPID != 900 || prev.PrevComm != "spark-executor" || prev.PrevCgroupID != 8812 {
+		t.Fatalf("unexpected prev task %+v", prev)
+	}
+
+	if idle, _ := decodeRunqTail(encodeRunqEvent(t, 0, 0, "swapper/3")); schedPrev(idle) != nil {
+		t.Fatal("idle CPU should carry no prev task")
+	}
+	if _, ok := decodeRunqTail(raw[:binary.Size(bpfEvent{})]); ok {
+		t.Fatal("event from an older object should not decode a tail")
+	}
+	dns := encodeDNSEvent(t, signalTypeDNSLatency, "api.openai.com", 0)
+	if _, ok := decodeRunqTail(dns); ok {
+		t.Fatal("DNS event should not decode a run-queue tail")
+	}
+}
+
+func TestDecodeBlkTail(t *testing.T) {
+	var buf bytes.Buffer
+	event := bpfEvent{PID: 311, SignalType: signalTypeDiskIOLatency, ValueNS: 12_000_000, CgroupID: 8811}
+	tail := bpfBlkTail{DevMajor: 259, DevMinor: 2, Bytes: 131072, Op: blkOpRead}
+	for _, v := range []any{event, tail} {
+		if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+	}
+	raw := buf.Bytes()
+
+	got, ok := decodeBlkTail(raw)
+	if !ok {
+		t.Fatal("expected block tail")
+	}
+	c := &RingBufConsumer{devices: NewBlockDeviceResolver(t.TempDir())}
+	disk := c.diskIO(got)
+	if disk.Device != "259:2" || disk.Major != 259 || disk.Minor != 2 || disk.Op != "read" || disk.Bytes != 131072 {
+		t.Fatalf("unexpected disk details %+v", disk)
+	}
+	if _, ok := decodeBlkTail(raw[:binary.Size(bpfEvent{})]); ok {
+		t.Fatal("event from an older object should not decode a tail")
+	}
+	if _, ok := decodeBlkTail(encodeRunqEvent(t, 900, 8812, "spark-executor")); ok {
+		t.Fatal("run-queue event should not decode a block tail")
+	}
+}
+
+func TestDerivedEventsUseThresholdTable(t *testing.T) {
+	c := NewRingBufConsumer(8, EventMetadata{Node: "node-1", Namespace: "batch", Service: "rag"})
+	now := time.Unix(1710000000, 0)
+	closing := schema.ProbeEventV1Beta1{Node: "node-1", Namespace: "batch", Service: "rag", SamplingWeight: 1}
+	derive := func() []schema.ProbeEventV1Beta1 {
+		var out []schema.ProbeEventV1Beta1
+		out = append(out, c.lookupEvent(closing, DNSLookup{Name: "api.openai.com", Queries: 4, Start: now, End: now}))
+		out = append(out, c.churnEvent(closing, ChurnSummary{End: now, Connects: 40, Window: 10 * time.Second, RatePerS: 4}))
+		out = append(out, c.providerResponseEvents(closing, ProviderResponse{Timestamp: now, Host: "api.openai.com", Status: 429})...)
+		out = append(out, c.streamGapEvents([]RequestTiming{{PID: 9, Writes: 3, LastWrite: now, MaxWriteGap: 400 * time.Millisecond}})...)
+		return out
+	}
+
+	for _, ev := range derive() {
+		if ev.Status != "warning" {
+			t.Fatalf("%s: expected warning at registry cutoffs, got %q", ev.Signal, ev.Status)
+		}
+	}
+
+	warning, errorAt := 500.0, 1000.0
+	overrides := make(map[string]signalspec.ThresholdOverride)
+	for _, name := range []string{signalspec.DNSQueriesPerLookup, signalspec.ConnectionChurnPerS, signalspec.ProviderHTTP429s, signalspec.StreamWriteGapMS} {
+		overrides[name] = signalspec.ThresholdOverride{Warning: &warning, Error: &errorAt}
+	}
+	c.SetThresholds(&signalspec.ThresholdTable{
+		Namespaces: map[string]map[string]signalspec.ThresholdOverride{"batch": overrides},
+	})
+	for _, ev := range derive() {
+		if ev.Status != "ok" {
+			t.Fatalf("%s: expected the batch namespace override to apply, got %q", ev.Signal, ev.Status)
+		}
+	}
+}
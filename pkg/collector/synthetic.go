@@ -12,17 +12,20 @@ type SampleMeta struct {
 	Workload  string
 	Service   string
 	Node      string
+	Pod       string
 }
 
 var syntheticScenarioSequence = map[string][]string{
-	"baseline":          {"baseline"},
-	"provider_throttle": {"provider_throttle"},
-	"dns_latency":       {"dns_latency"},
-	"cpu_throttle":      {"cpu_throttle"},
-	"memory_pressure":   {"memory_pressure"},
-	"network_partition": {"network_partition"},
-	"mixed":             {"provider_throttle", "dns_latency", "cpu_throttle", "memory_pressure", "network_partition"},
-	"mixed_multi":       {"mixed_multi"},
+	"baseline":             {"baseline"},
+	"provider_throttle":    {"provider_throttle"},
+	"dns_latency":          {"dns_latency"},
+	"cpu_throttle":         {"cpu_throttle"},
+	"memory_pressure":      {"memory_pressure"},
+	"network_partition":    {"network_partition"},
+	"gateway_saturation":   {"gateway_saturation"},
+	"conntrack_exhaustion": {"conntrack_exhaustion"},
+	"mixed":                {"provider_throttle", "dns_latency", "cpu_throttle", "memory_pressure", "network_partition"},
+	"mixed_multi":          {"mixed_multi"},
 }
 
 // SupportedSyntheticScenarios returns accepted synthetic scenario names.
@@ -34,6 +37,8 @@ func SupportedSyntheticScenarios() []string {
 		"cpu_throttle",
 		"memory_pressure",
 		"network_partition",
+		"gateway_saturation",
+		"conntrack_exhaustion",
 		"mixed",
 		"mixed_multi",
 	}
@@ -87,6 +92,7 @@ func buildScenarioSample(meta SampleMeta, timestamp time.Time, idx int, faultLab
 		Workload:         meta.Workload,
 		Service:          meta.Service,
 		Node:             meta.Node,
+		Pod:              meta.Pod,
 		RequestID:        requestID,
 		TraceID:          traceID,
 		TTFTMs:           340,
@@ -122,6 +128,16 @@ func buildScenarioSample(meta SampleMeta, timestamp time.Time, idx int, faultLab
 		sample.RequestLatencyMs = 3500
 		sample.TokenTPS = 3
 		sample.ErrorRate = 0.25
+	case "gateway_saturation":
+		sample.TTFTMs = 900
+		sample.RequestLatencyMs = 2600
+		sample.TokenTPS = 15
+		sample.ErrorRate = 0.18
+	case "conntrack_exhaustion":
+		sample.TTFTMs = 1500
+		sample.RequestLatencyMs = 2900
+		sample.TokenTPS = 20
+		sample.ErrorRate = 0.12
 	case "mixed_multi":
 		sample.TTFTMs = 1450
 		sample.RequestLatencyMs = 4200
@@ -0,0 +1,699 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"debug/buildinfo"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+	"golang.org/x/arch/x86/x86asm"
+)
+
+// TLS implementations the uprobe attacher recognizes.
+const (
+	TLSLibraryGo        = "go_crypto_tls"
+	TLSLibraryOpenSSL   = "openssl"
+	TLSLibraryBoringSSL = "boringssl"
+)
+
+// Per-binary attach states reported by TLSUprobeAttacher.
+const (
+	// TLSAttachAttached: every uprobe for the binary is attached.
+	TLSAttachAttached = "attached"
+	// TLSAttachResolved: attach points were found but no programs were
+	// loaded to attach.
+	TLSAttachResolved = "resolved"
+	// TLSAttachNoSymbol: the binary has no handshake symbol, either because
+	// it does not link a TLS library or because it was stripped.
+	TLSAttachNoSymbol = "no_symbol"
+	// TLSAttachUnsupported: the binary is not an ELF object, or targets an
+	// architecture or Go ABI the probes cannot read.
+	TLSAttachUnsupported = "unsupported"
+	// TLSAttachFailed: attach points were found but the kernel refused a
+	// uprobe.
+	TLSAttachFailed = "failed"
+)
+
+const (
+	goTLSHandshakeSymbol       = "crypto/tls.(*Conn).handshakeContext"
+	goTLSClientHandshakeSymbol = "crypto/tls.(*Conn).clientHandshake"
+	goTLSServerHandshakeSymbol = "crypto/tls.(*Conn).serverHandshake"
+	sslDoHandshakeSymbol       = "SSL_do_handshake"
+
+	// Plaintext boundary functions probed for HTTP status capture.
+	goTLSReadSymbol          = "crypto/tls.(*Conn).Read"
+	goTLSWriteSymbol         = "crypto/tls.(*Conn).Write"
+	goHTTPReadResponseSymbol = "net/http.ReadResponse"
+	sslReadSymbol            = "SSL_read"
+	sslWriteSymbol           = "SSL_write"
+
+	// arm64RET is "RET" (RET X30) in little-endian encoding.
+	arm64RET uint32 = 0xd65f03c0
+)
+
+// Errors returned by ResolveTLSAttachPoints.
+var (
+	ErrNoTLSSymbol    = errors.New("no TLS handshake symbol")
+	ErrTLSUnsupported = errors.New("unsupported binary")
+)
+
+// TLSAttachPoints are the uprobe locations resolved from one ELF object.
+// Offsets are file offsets, the form link.UprobeOptions.Address expects.
+type TLSAttachPoints struct {
+	Library string
+	Symbol  string
+	Address uint64
+	// ReturnOffsets are the RET instructions of a Go handshake function,
+	// relative to Address. Go binaries are probed at each RET instead of
+	// with a uretprobe.
+	ReturnOffsets []uint64
+	// Markers are the Go clientHandshake and serverHandshake entry points,
+	// which separate real handshakes from the early return taken once a
+	// connection has completed its handshake.
+	Markers   []uint64
+	GoVersion string
+	// Plaintext are the read/write boundary functions the HTTP status
+	// probes attach to; functions the binary does not link are left out.
+	Plaintext []UprobeTarget
+}
+
+// UprobeTarget is one function resolved for uprobes. ReturnOffsets is set
+// for Go functions whose results are read at each RET.
+type UprobeTarget struct {
+	Symbol        string
+	Address       uint64
+	ReturnOffsets []uint64
+}
+
+// ResolveTLSAttachPoints finds the TLS handshake function in the ELF object
+// at path. Go binaries resolve crypto/tls.(*Conn).handshakeContext; other
+// objects resolve a defined SSL_do_handshake, which covers both libssl and
+// BoringSSL copies linked into an executable or extension module.
+func ResolveTLSAttachPoints(path string) (TLSAttachPoints, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return TLSAttachPoints{}, fmt.Errorf("%w: %v", ErrTLSUnsupported, err)
+	}
+	defer f.Close()
+
+	syms, err := elfFuncSymbols(f,
+		goTLSHandshakeSymbol, goTLSClientHandshakeSymbol, goTLSServerHandshakeSymbol, sslDoHandshakeSymbol,
+		goTLSReadSymbol, goTLSWriteSymbol, goHTTPReadResponseSymbol, sslReadSymbol, sslWriteSymbol)
+	if err != nil {
+		return TLSAttachPoints{}, err
+	}
+
+	if info, err := buildinfo.ReadFile(path); err == nil {
+		return resolveGoTLS(f, syms, info.GoVersion)
+	}
+
+	sym, ok := syms[sslDoHandshakeSymbol]
+	if !ok {
+		return TLSAttachPoints{}, ErrNoTLSSymbol
+	}
+	addr, err := elfFileOffset(f, sym.Value)
+	if err != nil {
+		return TLSAttachPoints{}, err
+	}
+	library := TLSLibraryBoringSSL
+	if strings.HasPrefix(filepath.Base(path), "libssl.so") {
+		library = TLSLibraryOpenSSL
+	}
+	pts := TLSAttachPoints{Library: library, Symbol: sslDoHandshakeSymbol, Address: addr}
+	for _, name := range []string{sslReadSymbol, sslWriteSymbol} {
+		s, ok := syms[name]
+		if !ok {
+			continue
+		}
+		off, err := elfFileOffset(f, s.Value)
+		if err != nil {
+			return TLSAttachPoints{}, err
+		}
+		pts.Plaintext = append(pts.Plaintext, UprobeTarget{Symbol: name, Address: off})
+	}
+	return pts, nil
+}
+
+func resolveGoTLS(f *elf.File, syms map[string]elf.Symbol, goVersion string) (TLSAttachPoints, error) {
+	// The probes read the goroutine pointer and return value from the
+	// register ABI: Go 1.17 on amd64, Go 1.18 on arm64.
+	minMinor := 0
+	switch f.Machine {
+	case elf.EM_X86_64:
+		minMinor = 17
+	case elf.EM_AARCH64:
+		minMinor = 18
+	default:
+		return TLSAttachPoints{}, fmt.Errorf("%w: Go binary for %s", ErrTLSUnsupported, f.Machine)
+	}
+	if minor, ok := goMinorVersion(goVersion); ok && minor < minMinor {
+		return TLSAttachPoints{}, fmt.Errorf("%w: %s predates the register ABI on %s", ErrTLSUnsupported, goVersion, f.Machine)
+	}
+
+	sym, ok := syms[goTLSHandshakeSymbol]
+	if !ok {
+		if f.Section(".symtab") == nil {
+			return TLSAttachPoints{}, fmt.Errorf("%w: Go binary is stripped", ErrNoTLSSymbol)
+		}
+		return TLSAttachPoints{}, fmt.Errorf("%w: crypto/tls is not linked", ErrNoTLSSymbol)
+	}
+	handshake, err := goFunction(f, sym, true)
+	if err != nil {
+		return TLSAttachPoints{}, err
+	}
+
+	var markers []uint64
+	for _, name := range []string{goTLSClientHandshakeSymbol, goTLSServerHandshakeSymbol} {
+		m, ok := syms[name]
+		if !ok {
+			continue
+		}
+		off, err := elfFileOffset(f, m.Value)
+		if err != nil {
+			return TLSAttachPoints{}, err
+		}
+		markers = append(markers, off)
+	}
+	if len(markers) == 0 {
+		return TLSAttachPoints{}, fmt.Errorf("%w: no client or server handshake in binary", ErrNoTLSSymbol)
+	}
+
+	pts := TLSAttachPoints{
+		Library:       TLSLibraryGo,
+		Symbol:        goTLSHandshakeSymbol,
+		Address:       handshake.Address,
+		ReturnOffsets: handshake.ReturnOffsets,
+		Markers:       markers,
+		GoVersion:     goVersion,
+	}
+	for _, name := range []string{goTLSReadSymbol, goTLSWriteSymbol, goHTTPReadResponseSymbol} {
+		s, ok := syms[name]
+		if !ok {
+			continue
+		}
+		// Write is read at entry; Read and ReadResponse at each RET.
+		target, err := goFunction(f, s, name != goTLSWriteSymbol)
+		if err != nil {
+			return TLSAttachPoints{}, err
+		}
+		pts.Plaintext = append(pts.Plaintext, target)
+	}
+	return pts, nil
+}
+
+// goFunction resolves a Go function's file offset and, when rets is set,
+// the offsets of its RET instructions.
+func goFunction(f *elf.File, sym elf.Symbol, rets bool) (UprobeTarget, error) {
+	addr, err := elfFileOffset(f, sym.Value)
+	if err != nil {
+		return UprobeTarget{}, err
+	}
+	target := UprobeTarget{Symbol: sym.Name, Address: addr}
+	if !rets {
+		return target, nil
+	}
+
+	code := make([]byte, sym.Size)
+	if int(sym.Section) >= len(f.Sections) {
+		return UprobeTarget{}, fmt.Errorf("%s: bad section index %d", sym.Name, sym.Section)
+	}
+	text := f.Sections[sym.Section]
+	if _, err := text.ReadAt(code, int64(sym.Value-text.Addr)); err != nil {
+		return UprobeTarget{}, fmt.Errorf("read %s: %w", sym.Name, err)
+	}
+	target.ReturnOffsets = goReturnOffsets(f.Machine, code)
+	if len(target.ReturnOffsets) == 0 {
+		return UprobeTarget{}, fmt.Errorf("%w: no RET instruction in %s", ErrTLSUnsupported, sym.Name)
+	}
+	return target, nil
+}
+
+// goReturnOffsets returns the offsets of RET instructions in a function's
+// machine code. amd64 is decoded instruction by instruction so 0xc3 bytes
+// inside immediates and displacements are not mistaken for RET.
+func goReturnOffsets(machine elf.Machine, code []byte) []uint64 {
+	var out []uint64
+	switch machine {
+	case elf.EM_X86_64:
+		for pc := 0; pc < len(code); {
+			inst, err := x86asm.Decode(code[pc:], 64)
+			if err != nil || inst.Len == 0 {
+				pc++
+				continue
+			}
+			if inst.Op == x86asm.RET {
+				out = append(out, uint64(pc))
+			}
+			pc += inst.Len
+		}
+	case elf.EM_AARCH64:
+		for pc := 0; pc+4 <= len(code); pc += 4 {
+			if binary.LittleEndian.Uint32(code[pc:]) == arm64RET {
+				out = append(out, uint64(pc))
+			}
+		}
+	}
+	return out
+}
+
+// elfFuncSymbols returns the defined function symbols among names from the
+// static and dynamic symbol tables.
+func elfFuncSymbols(f *elf.File, names ...string) (map[string]elf.Symbol, error) {
+	want := make(map[string]struct{}, len(names))
+	for _, n := range names {
+		want[n] = struct{}{}
+	}
+	out := make(map[string]elf.Symbol, len(names))
+	for _, load := range []func() ([]elf.Symbol, error){f.Symbols, f.DynamicSymbols} {
+		syms, err := load()
+		if err != nil && !errors.Is(err, elf.ErrNoSymbols) {
+			return nil, fmt.Errorf("read symbols: %w", err)
+		}
+		for _, s := range syms {
+			if _, ok := want[s.Name]; !ok {
+				continue
+			}
+			if elf.ST_TYPE(s.Info) != elf.STT_FUNC || s.Section == elf.SHN_UNDEF || s.Value == 0 {
+				continue
+			}
+			if _, seen := out[s.Name]; !seen {
+				out[s.Name] = s
+			}
+		}
+	}
+	return out, nil
+}
+
+// elfFileOffset converts a virtual address to its offset in the file.
+func elfFileOffset(f *elf.File, vaddr uint64) (uint64, error) {
+	for _, p := range f.Progs {
+		if p.Type != elf.PT_LOAD || p.Flags&elf.PF_X == 0 {
+			continue
+		}
+		if p.Vaddr <= vaddr && vaddr < p.Vaddr+p.Memsz {
+			return vaddr - p.Vaddr + p.Off, nil
+		}
+	}
+	return 0, fmt.Errorf("address %#x is not in an executable segment", vaddr)
+}
+
+// goMinorVersion parses the minor version from "go1.21.3" or
+// "go1.22rc1"; development builds ("devel ...") report false.
+func goMinorVersion(v string) (int, bool) {
+	rest, ok := strings.CutPrefix(v, "go1.")
+	if !ok {
+		return 0, false
+	}
+	end := 0
+	for end < len(rest) && rest[end] >= '0' && rest[end] <= '9' {
+		end++
+	}
+	minor, err := strconv.Atoi(rest[:end])
+	return minor, err == nil
+}
+
+// TLSUprobePrograms are the loaded handshake programs the attacher links
+// into discovered binaries. Nil programs leave matching binaries in the
+// resolved state.
+type TLSUprobePrograms struct {
+	// GoEnter, GoRun and GoReturn come from go_tls_handshake.bpf.c.
+	GoEnter  *ebpf.Program
+	GoRun    *ebpf.Program
+	GoReturn *ebpf.Program
+	// SSLEnter and SSLReturn come from tls_handshake.bpf.c.
+	SSLEnter  *ebpf.Program
+	SSLReturn *ebpf.Program
+
+	// HTTP status capture programs from http_status.bpf.c. They are
+	// optional; nil leaves capture off for the matching library.
+	GoTLSWrite       *ebpf.Program
+	GoTLSReadEnter   *ebpf.Program
+	GoTLSReadReturn  *ebpf.Program
+	GoResponseEnter  *ebpf.Program
+	GoResponseReturn *ebpf.Program
+	SSLWrite         *ebpf.Program
+	SSLReadEnter     *ebpf.Program
+	SSLReadReturn    *ebpf.Program
+}
+
+// TLSAttachStatus is the attach outcome for one binary.
+type TLSAttachStatus struct {
+	// Binary is the path as the process sees it (the /proc/<pid>/exe link
+	// target or the /proc/<pid>/maps path).
+	Binary    string
+	Library   string
+	Symbol    string
+	State     string
+	Reason    string
+	GoVersion string
+	// Uprobes is the number of attached uprobes.
+	Uprobes int
+	// HTTPCapture reports that HTTP status capture probes are attached.
+	HTTPCapture bool
+	// PIDs is the number of processes running the binary at the last scan.
+	PIDs int
+}
+
+type fileKey struct {
+	dev uint64
+	ino uint64
+}
+
+type tlsObject struct {
+	status TLSAttachStatus
+	links  []link.Link
+	// lib marks objects found through /proc/<pid>/maps; they are only
+	// reported when they carry a handshake symbol.
+	lib bool
+}
+
+// TLSUprobeAttacher discovers binaries of running processes through
+// /proc/<pid>/exe and their executable mappings, resolves TLS handshake
+// attach points from their ELF symbols, and attaches uprobes once per file.
+// Uprobes attach to the file's inode, so one attach covers every process
+// running the same binary.
+type TLSUprobeAttacher struct {
+	procRoot string
+	interval time.Duration
+	progs    TLSUprobePrograms
+
+	mu      sync.Mutex
+	objects map[fileKey]*tlsObject
+}
+
+// NewTLSUprobeAttacher creates an attacher scanning procRoot, usually /proc.
+func NewTLSUprobeAttacher(procRoot string, interval time.Duration, progs TLSUprobePrograms) *TLSUprobeAttacher {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &TLSUprobeAttacher{
+		procRoot: procRoot,
+		interval: interval,
+		progs:    progs,
+		objects:  make(map[fileKey]*tlsObject),
+	}
+}
+
+// Scan attaches to binaries that appeared since the previous scan, detaches
+// from binaries no process runs any more, and returns the status of every
+// running executable and TLS-carrying library, sorted by binary path.
+func (a *TLSUprobeAttacher) Scan() ([]TLSAttachStatus, error) {
+	entries, err := os.ReadDir(a.procRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pids := make(map[fileKey]int)
+	for _, e := range entries {
+		if _, err := strconv.Atoi(e.Name()); err != nil {
+			continue
+		}
+		pidDir := filepath.Join(a.procRoot, e.Name())
+		for _, obj := range processObjects(pidDir) {
+			key, ok := statFileKey(obj.path)
+			if !ok {
+				continue
+			}
+			if pids[key]++; pids[key] > 1 {
+				continue
+			}
+			if _, known := a.objects[key]; !known {
+				a.objects[key] = a.attach(obj)
+			}
+		}
+	}
+
+	out := make([]TLSAttachStatus, 0, len(pids))
+	for key, o := range a.objects {
+		n, running := pids[key]
+		if !running {
+			closeLinks(o.links)
+			delete(a.objects, key)
+			continue
+		}
+		o.status.PIDs = n
+		if o.lib && o.status.State == TLSAttachNoSymbol {
+			continue
+		}
+		out = append(out, o.status)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Binary < out[j].Binary })
+	return out, nil
+}
+
+// Start scans until context cancellation, calling report with each scan's
+// status and onErr with scan errors. Uprobes stay attached until Close.
+func (a *TLSUprobeAttacher) Start(ctx context.Context, report func([]TLSAttachStatus), onErr func(error)) {
+	scan := func() {
+		status, err := a.Scan()
+		if err != nil {
+			if onErr != nil {
+				onErr(err)
+			}
+			return
+		}
+		if report != nil {
+			report(status)
+		}
+	}
+
+	scan()
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			scan()
+		}
+	}
+}
+
+// Close detaches every uprobe.
+func (a *TLSUprobeAttacher) Close() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for key, o := range a.objects {
+		closeLinks(o.links)
+		delete(a.objects, key)
+	}
+}
+
+type processObject struct {
+	// path opens the file from the agent's mount namespace.
+	path string
+	// display is the path inside the process's mount namespace.
+	display string
+	lib     bool
+}
+
+// processObjects lists a process's executable and its file-backed
+// executable mappings.
+func processObjects(pidDir string) []processObject {
+	exe := filepath.Join(pidDir, "exe")
+	target, err := os.Readlink(exe)
+	if err != nil {
+		// Kernel threads have no exe link.
+		return nil
+	}
+	out := []processObject{{path: exe, display: target}}
+
+	f, err := os.Open(filepath.Join(pidDir, "maps"))
+	if err != nil {
+		return out
+	}
+	defer f.Close()
+	for _, lib := range parseExecutableMappings(f) {
+		if lib == target {
+			continue
+		}
+		out = append(out, processObject{
+			path:    filepath.Join(pidDir, "root", lib),
+			display: lib,
+			lib:     true,
+		})
+	}
+	return out
+}
+
+// parseExecutableMappings returns the distinct file paths mapped executable
+// in a /proc/<pid>/maps listing, skipping anonymous, pseudo and deleted
+// mappings.
+func parseExecutableMappings(r io.Reader) []string {
+	var out []string
+	seen := make(map[string]struct{})
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		// address perms offset dev inode path
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 6 || !strings.Contains(fields[1], "x") {
+			continue
+		}
+		path := strings.Join(fields[5:], " ")
+		if !strings.HasPrefix(path, "/") || strings.HasSuffix(path, " (deleted)") {
+			continue
+		}
+		if _, ok := seen[path]; ok {
+			continue
+		}
+		seen[path] = struct{}{}
+		out = append(out, path)
+	}
+	return out
+}
+
+func statFileKey(path string) (fileKey, bool) {
+	fi, err := os.Stat(path)
+	if err != nil || !fi.Mode().IsRegular() {
+		return fileKey{}, false
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileKey{}, false
+	}
+	return fileKey{dev: uint64(st.Dev), ino: st.Ino}, true
+}
+
+func (a *TLSUprobeAttacher) attach(obj processObject) *tlsObject {
+	o := &tlsObject{lib: obj.lib, status: TLSAttachStatus{Binary: obj.display}}
+	pts, err := ResolveTLSAttachPoints(obj.path)
+	switch {
+	case errors.Is(err, ErrNoTLSSymbol):
+		o.status.State, o.status.Reason = TLSAttachNoSymbol, err.Error()
+		return o
+	case err != nil:
+		o.status.State, o.status.Reason = TLSAttachUnsupported, err.Error()
+		return o
+	}
+	o.status.Library = pts.Library
+	o.status.Symbol = pts.Symbol
+	o.status.GoVersion = pts.GoVersion
+
+	links, err := a.link(obj.path, pts)
+	switch {
+	case err != nil:
+		o.status.State, o.status.Reason = TLSAttachFailed, err.Error()
+	case len(links) == 0:
+		o.status.State, o.status.Reason = TLSAttachResolved, "handshake programs not loaded"
+	default:
+		o.status.State = TLSAttachAttached
+		o.status.Uprobes = len(links)
+		o.links = links
+		o.status.HTTPCapture = a.progs.httpCapture(pts.Library) && len(pts.Plaintext) > 0
+	}
+	return o
+}
+
+// httpCapture reports whether the HTTP status programs for library are
+// loaded.
+func (p TLSUprobePrograms) httpCapture(library string) bool {
+	if library == TLSLibraryGo {
+		return p.GoTLSWrite != nil && p.GoTLSReadEnter != nil && p.GoTLSReadReturn != nil &&
+			p.GoResponseEnter != nil && p.GoResponseReturn != nil
+	}
+	return p.SSLWrite != nil && p.SSLReadEnter != nil && p.SSLReadReturn != nil
+}
+
+// link attaches the handshake programs at pts. It returns no links when the
+// programs for pts.Library are not loaded.
+func (a *TLSUprobeAttacher) link(path string, pts TLSAttachPoints) ([]link.Link, error) {
+	type probe struct {
+		prog *ebpf.Program
+		opts link.UprobeOptions
+		ret  bool
+	}
+	var probes []probe
+	if pts.Library == TLSLibraryGo {
+		if a.progs.GoEnter == nil || a.progs.GoRun == nil || a.progs.GoReturn == nil {
+			return nil, nil
+		}
+		probes = append(probes, probe{prog: a.progs.GoEnter, opts: link.UprobeOptions{Address: pts.Address}})
+		for _, m := range pts.Markers {
+			probes = append(probes, probe{prog: a.progs.GoRun, opts: link.UprobeOptions{Address: m}})
+		}
+		for _, off := range pts.ReturnOffsets {
+			probes = append(probes, probe{prog: a.progs.GoReturn, opts: link.UprobeOptions{Address: pts.Address, Offset: off}})
+		}
+		if a.progs.httpCapture(TLSLibraryGo) {
+			for _, t := range pts.Plaintext {
+				enter, ret := a.progs.GoTLSReadEnter, a.progs.GoTLSReadReturn
+				switch t.Symbol {
+				case goTLSWriteSymbol:
+					enter, ret = a.progs.GoTLSWrite, nil
+				case goHTTPReadResponseSymbol:
+					enter, ret = a.progs.GoResponseEnter, a.progs.GoResponseReturn
+				}
+				probes = append(probes, probe{prog: enter, opts: link.UprobeOptions{Address: t.Address}})
+				for _, off := range t.ReturnOffsets {
+					probes = append(probes, probe{prog: ret, opts: link.UprobeOptions{Address: t.Address, Offset: off}})
+				}
+			}
+		}
+	} else {
+		if a.progs.SSLEnter == nil || a.progs.SSLReturn == nil {
+			return nil, nil
+		}
+		probes = append(probes,
+			probe{prog: a.progs.SSLEnter, opts: link.UprobeOptions{Address: pts.Address}},
+			probe{prog: a.progs.SSLReturn, opts: link.UprobeOptions{Address: pts.Address}, ret: true},
+		)
+		if a.progs.httpCapture(pts.Library) {
+			for _, t := range pts.Plaintext {
+				if t.Symbol == sslWriteSymbol {
+					probes = append(probes, probe{prog: a.progs.SSLWrite, opts: link.UprobeOptions{Address: t.Address}})
+					continue
+				}
+				probes = append(probes,
+					probe{prog: a.progs.SSLReadEnter, opts: link.UprobeOptions{Address: t.Address}},
+					probe{prog: a.progs.SSLReadReturn, opts: link.UprobeOptions{Address: t.Address}, ret: true},
+				)
+			}
+		}
+	}
+
+	ex, err := link.OpenExecutable(path)
+	if err != nil {
+		return nil, err
+	}
+	links := make([]link.Link, 0, len(probes))
+	for _, p := range probes {
+		opts := p.opts
+		var l link.Link
+		if p.ret {
+			l, err = ex.Uretprobe(pts.Symbol, p.prog, &opts)
+		} else {
+			l, err = ex.Uprobe(pts.Symbol, p.prog, &opts)
+		}
+		if err != nil {
+			closeLinks(links)
+			return nil, fmt.Errorf("uprobe %s+%#x: %w", pts.Symbol, opts.Offset, err)
+		}
+		links = append(links, l)
+	}
+	return links, nil
+}
+
+func closeLinks(links []link.Link) {
+	for _, l := range links {
+		_ = l.Close()
+	}
+}
@@ -0,0 +1,241 @@
+package collector
+
+import (
+	"debug/elf"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const goTLSProgram = `package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"net/http"
+	"os"
+)
+
+func main() {
+	conn, err := tls.Dial("tcp", os.Args[1], nil)
+	if err == nil {
+		conn.Write([]byte("HEAD / HTTP/1.1\r\n\r\n"))
+		conn.Read(make([]byte, 512))
+		conn.Close()
+	}
+	http.ReadResponse(bufio.NewReader(os.Stdin), nil)
+}
+`
+
+// goTLSBinary builds a small unstripped Go program that performs a TLS
+// handshake and links the HTTP status capture functions. go test strips the test binary itself, so it cannot be used.
+func goTLSBinary(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS != "linux" || (runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64") {
+		t.Skip("requires linux/amd64 or linux/arm64")
+	}
+	dir := t.TempDir()
+	src := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(src, []byte(goTLSProgram), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bin := filepath.Join(dir, "gateway")
+	cmd := exec.Command(filepath.Join(runtime.GOROOT(), "bin", "go"), "build", "-o", bin, src)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=", "CGO_ENABLED=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("cannot build Go TLS target: %v: %s", err, out)
+	}
+	return bin
+}
+
+func TestGoReturnOffsetsAMD64(t *testing.T) {
+	code := []byte{
+		0xb8, 0xc3, 0x00, 0x00, 0x00, // MOVL $0xc3, AX
+		0xc3,                   // RET
+		0x48, 0x83, 0xc4, 0x10, // ADDQ $0x10, SP
+		0x5d, // POPQ BP
+		0xc3, // RET
+	}
+	got := goReturnOffsets(elf.EM_X86_64, code)
+	if want := []uint64{5, 11}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected RET offsets %v, got %v", want, got)
+	}
+}
+
+func TestGoReturnOffsetsARM64(t *testing.T) {
+	code := []byte{
+		0xfd, 0x7b, 0xbf, 0xa9, // STP (R29, R30), -16(RSP)!
+		0xc0, 0x03, 0x5f, 0xd6, // RET
+		0x1f, 0x20, 0x03, 0xd5, // NOP
+		0xc0, 0x03, 0x5f, 0xd6, // RET
+	}
+	got := goReturnOffsets(elf.EM_AARCH64, code)
+	if want := []uint64{4, 12}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected RET offsets %v, got %v", want, got)
+	}
+}
+
+func TestGoMinorVersion(t *testing.T) {
+	cases := map[string]int{"go1.17": 17, "go1.21.3": 21, "go1.22rc1": 22}
+	for in, want := range cases {
+		if got, ok := goMinorVersion(in); !ok || got != want {
+			t.Fatalf("goMinorVersion(%q) = %d, %v; expected %d", in, got, ok, want)
+		}
+	}
+	if _, ok := goMinorVersion("devel +abc"); ok {
+		t.Fatal("expected devel version to be unparsed")
+	}
+}
+
+func TestResolveTLSAttachPointsGoBinary(t *testing.T) {
+	exe := goTLSBinary(t)
+	pts, err := ResolveTLSAttachPoints(exe)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if pts.Library != TLSLibraryGo || pts.Symbol != goTLSHandshakeSymbol {
+		t.Fatalf("unexpected target %s %s", pts.Library, pts.Symbol)
+	}
+	if pts.GoVersion != runtime.Version() {
+		t.Fatalf("expected Go version %s, got %s", runtime.Version(), pts.GoVersion)
+	}
+	if pts.Address == 0 || len(pts.Markers) == 0 || len(pts.ReturnOffsets) == 0 {
+		t.Fatalf("incomplete attach points: %+v", pts)
+	}
+	plaintext := map[string]UprobeTarget{}
+	for _, target := range pts.Plaintext {
+		plaintext[target.Symbol] = target
+	}
+	for _, sym := range []string{goTLSReadSymbol, goTLSWriteSymbol, goHTTPReadResponseSymbol} {
+		target, ok := plaintext[sym]
+		if !ok || target.Address == 0 {
+			t.Fatalf("missing plaintext target %s in %+v", sym, pts.Plaintext)
+		}
+		if wantRets := sym != goTLSWriteSymbol; wantRets != (len(target.ReturnOffsets) > 0) {
+			t.Fatalf("%s: unexpected return offsets %v", sym, target.ReturnOffsets)
+		}
+	}
+
+	f, err := os.Open(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for _, off := range pts.ReturnOffsets {
+		buf := make([]byte, 4)
+		if _, err := f.ReadAt(buf, int64(pts.Address+off)); err != nil {
+			t.Fatal(err)
+		}
+		machine := elf.EM_X86_64
+		if runtime.GOARCH == "arm64" {
+			machine = elf.EM_AARCH64
+		}
+		if rets := goReturnOffsets(machine, buf); len(rets) == 0 || rets[0] != 0 {
+			t.Fatalf("offset %#x does not hold a RET: % x", off, buf)
+		}
+	}
+}
+
+func TestResolveTLSAttachPointsNotELF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ResolveTLSAttachPoints(path); !errors.Is(err, ErrTLSUnsupported) {
+		t.Fatalf("expected ErrTLSUnsupported, got %v", err)
+	}
+}
+
+func TestParseExecutableMappings(t *testing.T) {
+	maps := `55d0c0a00000-55d0c0a2c000 r--p 00000000 08:01 1234 /usr/bin/python3.11
+55d0c0a2c000-55d0c0c00000 r-xp 0002c000 08:01 1234 /usr/bin/python3.11
+7f1c2a000000-7f1c2a400000 r-xp 00100000 08:01 5678 /usr/lib/python3/site-packages/grpc/_cython/cygrpc.so
+7f1c2a400000-7f1c2a500000 r-xp 00000000 08:01 9999 /tmp/old.so (deleted)
+7f1c2b000000-7f1c2b200000 r-xp 00000000 08:01 4321 /usr/lib/x86_64-linux-gnu/libc.so.6
+7f1c2b300000-7f1c2b310000 r-xp 00010000 08:01 4321 /usr/lib/x86_64-linux-gnu/libc.so.6
+7ffd1a5f0000-7ffd1a5f2000 r-xp 00000000 00:00 0 [vdso]
+7ffd1a600000-7ffd1a700000 rw-p 00000000 00:00 0
+`
+	got := parseExecutableMappings(strings.NewReader(maps))
+	want := []string{
+		"/usr/bin/python3.11",
+		"/usr/lib/python3/site-packages/grpc/_cython/cygrpc.so",
+		"/usr/lib/x86_64-linux-gnu/libc.so.6",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestTLSUprobeAttacherScanReportsPerBinaryStatus(t *testing.T) {
+	exe := goTLSBinary(t)
+	script := filepath.Join(t.TempDir(), "entrypoint.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	proc := t.TempDir()
+	addProc := func(pid, target, maps string) {
+		dir := filepath.Join(proc, pid)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(target, filepath.Join(dir, "exe")); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink("/", filepath.Join(dir, "root")); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "maps"), []byte(maps), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addProc("100", exe, "00400000-00800000 r-xp 00000000 08:01 1 "+exe+"\n")
+	addProc("101", exe, "")
+	addProc("200", script, "")
+	if err := os.MkdirAll(filepath.Join(proc, "self"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewTLSUprobeAttacher(proc, 0, TLSUprobePrograms{})
+	defer a.Close()
+	status, err := a.Scan()
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if len(status) != 2 {
+		t.Fatalf("expected 2 binaries, got %+v", status)
+	}
+	byBinary := map[string]TLSAttachStatus{}
+	for _, s := range status {
+		byBinary[s.Binary] = s
+	}
+
+	gw := byBinary[exe]
+	if gw.State != TLSAttachResolved || gw.Library != TLSLibraryGo || gw.PIDs != 2 {
+		t.Fatalf("unexpected Go binary status %+v", gw)
+	}
+	if sh := byBinary[script]; sh.State != TLSAttachUnsupported || sh.Reason == "" {
+		t.Fatalf("unexpected script status %+v", sh)
+	}
+
+	if err := os.RemoveAll(filepath.Join(proc, "100")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(proc, "101")); err != nil {
+		t.Fatal(err)
+	}
+	status, err = a.Scan()
+	if err != nil {
+		t.Fatalf("rescan: %v", err)
+	}
+	if len(status) != 1 || status[0].Binary != script {
+		t.Fatalf("expected exited binary to be dropped, got %+v", status)
+	}
+}
@@ -46,33 +46,10 @@ type Decision struct {
 	Tier       string
 }
 
-// Match computes confidence/tier for one span-signal pair.
+// Match computes confidence/tier for one span-signal pair on the default
+// tier ladder.
 func Match(span SpanRef, signal SignalRef, window time.Duration) Decision {
-	if window <= 0 {
-		window = DefaultWindow
-	}
-	if !withinWindow(span.Timestamp, signal.Timestamp, window) {
-		return Decision{}
-	}
-
-	if span.TraceID != "" && span.TraceID == signal.TraceID {
-		return Decision{Matched: true, Confidence: 1.0, Tier: "trace_id_exact"}
-	}
-	if span.Pod != "" && span.Pod == signal.Pod && span.PID > 0 && span.PID == signal.PID &&
-		withinWindow(span.Timestamp, signal.Timestamp, 100*time.Millisecond) {
-		return Decision{Matched: true, Confidence: 0.9, Tier: "pod_pid_100ms"}
-	}
-	if span.Pod != "" && span.Pod == signal.Pod && span.ConnTuple != "" && span.ConnTuple == signal.ConnTuple &&
-		withinWindow(span.Timestamp, signal.Timestamp, 250*time.Millisecond) {
-		return Decision{Matched: true, Confidence: 0.8, Tier: "pod_conn_250ms"}
-	}
-	if span.Service != "" && span.Service == signal.Service &&
-		span.Node != "" && span.Node == signal.Node &&
-		withinWindow(span.Timestamp, signal.Timestamp, 500*time.Millisecond) {
-		return Decision{Matched: true, Confidence: 0.65, Tier: "service_node_500ms"}
-	}
-
-	return Decision{}
+	return defaultTiers.Match(span, signal, window)
 }
 
 // EnrichDNS applies DNS attributes only when confidence >= threshold.
@@ -32,22 +32,23 @@ type Prediction struct {
 
 // EvalReport summarizes precision/recall/F1 and confusion counts.
 type EvalReport struct {
-	GeneratedAt     time.Time `json:"generated_at"`
-	SampleSize      int       `json:"sample_size"`
-	TruePositive    int       `json:"true_positive"`
-	FalsePositive   int       `json:"false_positive"`
-	FalseNegative   int       `json:"false_negative"`
-	TrueNegative    int       `json:"true_negative"`
-	Precision       float64   `json:"precision"`
-	Recall          float64   `json:"recall"`
-	F1              float64   `json:"f1"`
-	TierAccuracy    float64   `json:"tier_accuracy"`
-	MeanConfidence  float64   `json:"mean_confidence"`
-	WindowMS        int       `json:"window_ms"`
-	Threshold       float64   `json:"threshold"`
-	MinPrecisionReq float64   `json:"min_precision_required,omitempty"`
-	MinRecallReq    float64   `json:"min_recall_required,omitempty"`
-	PassedGate      bool      `json:"passed_gate,omitempty"`
+	GeneratedAt     time.Time      `json:"generated_at"`
+	SampleSize      int            `json:"sample_size"`
+	TruePositive    int            `json:"true_positive"`
+	FalsePositive   int            `json:"false_positive"`
+	FalseNegative   int            `json:"false_negative"`
+	TrueNegative    int            `json:"true_negative"`
+	Precision       float64        `json:"precision"`
+	Recall          float64        `json:"recall"`
+	F1              float64        `json:"f1"`
+	TierAccuracy    float64        `json:"tier_accuracy"`
+	MeanConfidence  float64        `json:"mean_confidence"`
+	WindowMS        int            `json:"window_ms"`
+	Threshold       float64        `json:"threshold"`
+	MinPrecisionReq float64        `json:"min_precision_required,omitempty"`
+	MinRecallReq    float64        `json:"min_recall_required,omitempty"`
+	PassedGate      bool           `json:"passed_gate,omitempty"`
+	TierCounts      map[string]int `json:"tier_counts,omitempty"`
 }
 
 // GateResult captures gate verdict and details.
@@ -91,6 +92,17 @@ func EvaluateLabeledPairs(
 	pairs []LabeledPair,
 	window time.Duration,
 	threshold float64,
+) (EvalReport, []Prediction) {
+	return EvaluateLabeledPairsWithTiers(pairs, defaultTiers, window, threshold)
+}
+
+// EvaluateLabeledPairsWithTiers computes quality metrics for a tier ladder
+// at a given threshold/window.
+func EvaluateLabeledPairsWithTiers(
+	pairs []LabeledPair,
+	tiers Tiers,
+	window time.Duration,
+	threshold float64,
 ) (EvalReport, []Prediction) {
 	if window <= 0 {
 		window = DefaultWindow
@@ -104,6 +116,7 @@ func EvaluateLabeledPairs(
 		SampleSize:  len(pairs),
 		WindowMS:    int(window / time.Millisecond),
 		Threshold:   threshold,
+		TierCounts:  make(map[string]int),
 	}
 	predictions := make([]Prediction, 0, len(pairs))
 
@@ -113,7 +126,7 @@ func EvaluateLabeledPairs(
 	confCount := 0
 
 	for _, pair := range pairs {
-		decision := Match(pair.Span, pair.Signal, window)
+		decision := tiers.Match(pair.Span, pair.Signal, window)
 		predicted := decision.Matched && decision.Confidence >= threshold
 		correct := predicted == pair.ExpectedMatch
 		predictions = append(predictions, Prediction{
@@ -130,6 +143,7 @@ func EvaluateLabeledPairs(
 		if predicted {
 			confSum += decision.Confidence
 			confCount++
+			report.TierCounts[decision.Tier]++
 		}
 
 		switch {
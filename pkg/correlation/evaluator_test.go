@@ -7,9 +7,8 @@ import (
 	"time"
 )
 
-func TestEvaluateLabeledPairs(t *testing.T) {
-	now := time.Now().UTC()
-	pairs := []LabeledPair{
+func evalPairs(now time.Time) []LabeledPair {
+	return []LabeledPair{
 		{
 			CaseID:        "tp-trace",
 			ExpectedMatch: true,
@@ -56,7 +55,10 @@ func TestEvaluateLabeledPairs(t *testing.T) {
 			},
 		},
 	}
+}
 
+func TestEvaluateLabeledPairs(t *testing.T) {
+	pairs := evalPairs(time.Now().UTC())
 	report, predictions := EvaluateLabeledPairs(pairs, 2*time.Second, 0.7)
 	if len(predictions) != 3 {
 		t.Fatalf("expected 3 predictions, got %d", len(predictions))
@@ -72,6 +74,21 @@ func TestEvaluateLabeledPairs(t *testing.T) {
 	}
 }
 
+func TestEvaluateLabeledPairsWithTiers(t *testing.T) {
+	tiers := DefaultTiers()
+	tiers[3].Confidence = 0.75
+	report, predictions := EvaluateLabeledPairsWithTiers(evalPairs(time.Now().UTC()), tiers, 2*time.Second, 0.7)
+	if report.TruePositive != 2 || report.Recall != 1.0 || report.TierAccuracy != 1.0 {
+		t.Fatalf("raising the service tier should recover the low-confidence pair: %+v", report)
+	}
+	if predictions[1].Confidence != 0.75 {
+		t.Fatalf("expected the ladder's confidence, got %v", predictions[1].Confidence)
+	}
+	if report.TierCounts["trace_id_exact"] != 1 || report.TierCounts["service_node_500ms"] != 1 {
+		t.Fatalf("unexpected tier counts %v", report.TierCounts)
+	}
+}
+
 func TestEvaluateGate(t *testing.T) {
 	report := EvalReport{Precision: 0.91, Recall: 0.86}
 	gate := EvaluateGate(report, 0.9, 0.85)
@@ -0,0 +1,114 @@
+package correlation
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// JoinKey names the span and signal fields a tier joins on.
+type JoinKey string
+
+const (
+	// JoinTraceID joins on trace ID.
+	JoinTraceID JoinKey = "trace_id"
+	// JoinPodPID joins on pod and process ID.
+	JoinPodPID JoinKey = "pod_pid"
+	// JoinPodConn joins on pod and connection tuple.
+	JoinPodConn JoinKey = "pod_conn"
+	// JoinServiceNode joins on service and node.
+	JoinServiceNode JoinKey = "service_node"
+)
+
+// JoinKeys lists the supported join keys.
+func JoinKeys() []JoinKey {
+	return []JoinKey{JoinTraceID, JoinPodPID, JoinPodConn, JoinServiceNode}
+}
+
+// Equal reports whether span and signal share the key. Empty fields never
+// match.
+func (k JoinKey) Equal(span SpanRef, signal SignalRef) bool {
+	switch k {
+	case JoinTraceID:
+		return span.TraceID != "" && span.TraceID == signal.TraceID
+	case JoinPodPID:
+		return span.Pod != "" && span.Pod == signal.Pod && span.PID > 0 && span.PID == signal.PID
+	case JoinPodConn:
+		return span.Pod != "" && span.Pod == signal.Pod && span.ConnTuple != "" && span.ConnTuple == signal.ConnTuple
+	case JoinServiceNode:
+		return span.Service != "" && span.Service == signal.Service && span.Node != "" && span.Node == signal.Node
+	}
+	return false
+}
+
+// Tier is one rung of the correlation ladder: a signal sharing Key with a
+// span within Window of it matches at Confidence.
+type Tier struct {
+	Name string
+	Key  JoinKey
+	// Window bounds the span-signal distance; 0 leaves only the
+	// correlation window.
+	Window     time.Duration
+	Confidence float64
+}
+
+// Tiers is a correlation ladder. Tiers are tried in order and the first
+// that matches decides.
+type Tiers []Tier
+
+var defaultTiers = Tiers{
+	{Name: "trace_id_exact", Key: JoinTraceID, Confidence: 1.0},
+	{Name: "pod_pid_100ms", Key: JoinPodPID, Window: 100 * time.Millisecond, Confidence: 0.9},
+	{Name: "pod_conn_250ms", Key: JoinPodConn, Window: 250 * time.Millisecond, Confidence: 0.8},
+	{Name: "service_node_500ms", Key: JoinServiceNode, Window: 500 * time.Millisecond, Confidence: 0.65},
+}
+
+// DefaultTiers returns the built-in ladder that Match uses.
+func DefaultTiers() Tiers {
+	return slices.Clone(defaultTiers)
+}
+
+// Validate checks that tiers have unique names, known keys, non-negative
+// windows and confidences in (0, 1].
+func (t Tiers) Validate() error {
+	seen := make(map[string]bool, len(t))
+	for i, tier := range t {
+		if tier.Name == "" {
+			return fmt.Errorf("tier %d: name is required", i)
+		}
+		if seen[tier.Name] {
+			return fmt.Errorf("tier %s: duplicate name", tier.Name)
+		}
+		seen[tier.Name] = true
+		if !slices.Contains(JoinKeys(), tier.Key) {
+			return fmt.Errorf("tier %s: unknown join key %q", tier.Name, tier.Key)
+		}
+		if tier.Window < 0 {
+			return fmt.Errorf("tier %s: negative window %v", tier.Name, tier.Window)
+		}
+		if tier.Confidence <= 0 || tier.Confidence > 1 {
+			return fmt.Errorf("tier %s: confidence %v outside (0, 1]", tier.Name, tier.Confidence)
+		}
+	}
+	return nil
+}
+
+// Match computes confidence/tier for one span-signal pair on this ladder.
+func (t Tiers) Match(span SpanRef, signal SignalRef, window time.Duration) Decision {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	if !withinWindow(span.Timestamp, signal.Timestamp, window) {
+		return Decision{}
+	}
+	for _, tier := range t {
+		if !tier.Key.Equal(span, signal) {
+			continue
+		}
+		if tier.Window > 0 && !withinWindow(span.Timestamp, signal.Timestamp, tier.Window) {
+			continue
+		}
+		return Decision{Matched: true, Confidence: tier.Confidence, Tier: tier.Name}
+	}
+	return Decision{}
+}
@@ -0,0 +1,69 @@
+package correlation
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCustomTiersMatchInOrder(t *testing.T) {
+	baseTime := time.Unix(1700000000, 0)
+	tiers := Tiers{
+		{Name: "pod_pid_40ms", Key: JoinPodPID, Window: 40 * time.Millisecond, Confidence: 0.95},
+		{Name: "trace", Key: JoinTraceID, Confidence: 0.9},
+		{Name: "pod_pid_400ms", Key: JoinPodPID, Window: 400 * time.Millisecond, Confidence: 0.75},
+	}
+	span := SpanRef{TraceID: "trace-1", Pod: "pod-a", PID: 42, Service: "chat", Node: "node-a", Timestamp: baseTime}
+
+	tests := []struct {
+		name   string
+		signal SignalRef
+		tier   string
+	}{
+		{"first tier wins", SignalRef{TraceID: "trace-1", Pod: "pod-a", PID: 42, Timestamp: baseTime.Add(30 * time.Millisecond)}, "pod_pid_40ms"},
+		{"falls through to trace", SignalRef{TraceID: "trace-1", Pod: "pod-a", PID: 42, Timestamp: baseTime.Add(time.Second)}, "trace"},
+		{"wider window on the same key", SignalRef{Pod: "pod-a", PID: 42, Timestamp: baseTime.Add(-300 * time.Millisecond)}, "pod_pid_400ms"},
+		{"removed tier", SignalRef{Service: "chat", Node: "node-a", Timestamp: baseTime}, ""},
+		{"outside the correlation window", SignalRef{TraceID: "trace-1", Timestamp: baseTime.Add(3 * time.Second)}, ""},
+	}
+	for _, tt := range tests {
+		decision := tiers.Match(span, tt.signal, DefaultWindow)
+		if decision.Tier != tt.tier || decision.Matched != (tt.tier != "") {
+			t.Errorf("%s: got %+v, want tier %q", tt.name, decision, tt.tier)
+		}
+	}
+}
+
+func TestDefaultTiersMatchMatch(t *testing.T) {
+	baseTime := time.Unix(1700000000, 0)
+	span := SpanRef{Pod: "pod-a", PID: 42, ConnTuple: "c", Service: "chat", Node: "node-a", Timestamp: baseTime}
+	signal := SignalRef{Pod: "pod-a", ConnTuple: "c", Service: "chat", Node: "node-a"}
+	for ms := -600; ms <= 600; ms += 50 {
+		signal.Timestamp = baseTime.Add(time.Duration(ms) * time.Millisecond)
+		if got, want := DefaultTiers().Match(span, signal, 0), Match(span, signal, 0); got != want {
+			t.Fatalf("%dms: got %+v, want %+v", ms, got, want)
+		}
+	}
+}
+
+func TestTiersValidate(t *testing.T) {
+	if err := DefaultTiers().Validate(); err != nil {
+		t.Fatalf("default tiers should validate: %v", err)
+	}
+	tests := []struct {
+		tiers Tiers
+		want  string
+	}{
+		{Tiers{{Key: JoinTraceID, Confidence: 1}}, "name is required"},
+		{Tiers{{Name: "a", Key: JoinTraceID, Confidence: 1}, {Name: "a", Key: JoinPodPID, Confidence: 0.9}}, "duplicate name"},
+		{Tiers{{Name: "a", Key: "pod_ip", Confidence: 1}}, "unknown join key"},
+		{Tiers{{Name: "a", Key: JoinPodPID, Window: -time.Millisecond, Confidence: 0.9}}, "negative window"},
+		{Tiers{{Name: "a", Key: JoinPodPID, Confidence: 1.2}}, "outside (0, 1]"},
+	}
+	for _, tt := range tests {
+		err := tt.tiers.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%+v: got %v, want %q", tt.tiers, err, tt.want)
+		}
+	}
+}
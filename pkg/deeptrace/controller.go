@@ -0,0 +1,413 @@
+package deeptrace
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
+)
+
+// burnBuckets is the number of buckets a burn rate window is kept in.
+const burnBuckets = 30
+
+// maxHistory bounds the annotations kept for incident timelines.
+const maxHistory = 256
+
+// Scoper limits a probe to tasks in a set of cgroups; an empty set
+// detaches it. *collector.ProbeManager implements it.
+type Scoper interface {
+	SetScope(signal string, cgroups []uint64) error
+}
+
+// CgroupResolver lists the cgroups of a pod. *collector.CgroupPodResolver
+// implements it.
+type CgroupResolver interface {
+	CgroupIDs(podUID string) []uint64
+}
+
+// Config tunes when and for how long deep tracing runs.
+type Config struct {
+	// Signals are the probes attached while a workload burns.
+	Signals []string
+	// Objective is the SLO target: the share of SLO events expected to
+	// be ok.
+	Objective float64
+	// BurnRateThreshold is the burn rate over Window that triggers
+	// tracing. A burn rate of 1 spends the error budget exactly.
+	BurnRateThreshold float64
+	// Window is the span burn rates are measured over.
+	Window time.Duration
+	// MinEvents is the number of events of one SLI a window must hold
+	// before its burn rate counts.
+	MinEvents int
+	// Streak is the number of consecutive warning or breach events of one
+	// SLI that trigger tracing regardless of burn rate.
+	Streak int
+	// Duration bounds one activation.
+	Duration time.Duration
+	// Cooldown is how long a workload waits after an activation ends, or
+	// is skipped, before it may trigger again.
+	Cooldown time.Duration
+	// MaxActive bounds the workloads traced at once.
+	MaxActive int
+}
+
+// DefaultConfig traces TLS handshakes and syscall latency for two minutes
+// once a 99% objective burns ten times too fast over five minutes.
+func DefaultConfig() Config {
+	return Config{
+		Signals:           []string{signalspec.TLSHandshakeMS, signalspec.SyscallLatencyMS},
+		Objective:         0.99,
+		BurnRateThreshold: 10,
+		Window:            5 * time.Minute,
+		MinEvents:         20,
+		Streak:            3,
+		Duration:          2 * time.Minute,
+		Cooldown:          5 * time.Minute,
+		MaxActive:         4,
+	}
+}
+
+func (c Config) normalized() Config {
+	def := DefaultConfig()
+	if len(c.Signals) == 0 {
+		c.Signals = def.Signals
+	}
+	if c.Objective <= 0 || c.Objective >= 1 {
+		c.Objective = def.Objective
+	}
+	if c.BurnRateThreshold <= 0 {
+		c.BurnRateThreshold = def.BurnRateThreshold
+	}
+	if c.Window <= 0 {
+		c.Window = def.Window
+	}
+	if c.MinEvents <= 0 {
+		c.MinEvents = def.MinEvents
+	}
+	if c.Streak <= 0 {
+		c.Streak = def.Streak
+	}
+	if c.Duration <= 0 {
+		c.Duration = def.Duration
+	}
+	if c.Cooldown <= 0 {
+		c.Cooldown = def.Cooldown
+	}
+	if c.MaxActive <= 0 {
+		c.MaxActive = def.MaxActive
+	}
+	return c
+}
+
+type burnBucket struct {
+	start time.Time
+	total int
+	nonOK int
+}
+
+// sliState tracks one SLI of a workload.
+type sliState struct {
+	buckets []burnBucket
+	streak  int
+}
+
+type workloadState struct {
+	slis map[string]*sliState
+	pods map[string]time.Time // pod UID to when it was last seen
+	// trigger says why the workload should be traced; it is set by
+	// Observe and consumed by the next Tick.
+	trigger string
+
+	active     bool
+	until      time.Time
+	activePods []string
+	cgroups    []uint64
+	cooldown   time.Time
+}
+
+// Controller turns SLO events into deep-tracing activations. Observe
+// feeds it events; Tick, called periodically, starts and stops
+// activations and scopes the probes to the union of the active workloads'
+// cgroups. It is safe for concurrent use.
+type Controller struct {
+	mu        sync.Mutex
+	cfg       Config
+	width     time.Duration
+	scoper    Scoper
+	cgroups   CgroupResolver
+	workloads map[signalspec.Workload]*workloadState
+	applied   map[string][]uint64 // scope last set on each signal
+	history   []schema.Annotation
+}
+
+// NewController creates a controller; zero Config fields take
+// DefaultConfig values.
+func NewController(cfg Config, scoper Scoper, cgroups CgroupResolver) *Controller {
+	cfg = cfg.normalized()
+	return &Controller{
+		cfg:       cfg,
+		width:     max(cfg.Window/burnBuckets, time.Second),
+		scoper:    scoper,
+		cgroups:   cgroups,
+		workloads: make(map[signalspec.Workload]*workloadState),
+		applied:   make(map[string][]uint64),
+	}
+}
+
+// Signals returns the probes the controller attaches.
+func (c *Controller) Signals() []string {
+	return append([]string(nil), c.cfg.Signals...)
+}
+
+// Observe adds one SLO event. Events arriving out of order count in the
+// SLI's newest bucket. The pod label, a pod UID on node agents, names the
+// pods whose cgroups are traced.
+func (c *Controller) Observe(ev schema.SLOEvent) {
+	nonOK := ev.Status == "warning" || ev.Status == "breach"
+	key := signalspec.Workload{Namespace: ev.Namespace, Service: ev.Service}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := c.workloads[key]
+	if w == nil {
+		w = &workloadState{slis: make(map[string]*sliState), pods: make(map[string]time.Time)}
+		c.workloads[key] = w
+	}
+	if pod := ev.Labels["pod"]; pod != "" {
+		w.pods[pod] = ev.Timestamp
+	}
+	s := w.slis[ev.SLIName]
+	if s == nil {
+		s = &sliState{}
+		w.slis[ev.SLIName] = s
+	}
+
+	start := ev.Timestamp.Truncate(c.width)
+	if n := len(s.buckets); n == 0 || s.buckets[n-1].start.Before(start) {
+		s.buckets = append(s.buckets, burnBucket{start: start})
+	}
+	b := &s.buckets[len(s.buckets)-1]
+	b.total++
+	if nonOK {
+		b.nonOK++
+		s.streak++
+	} else {
+		s.streak = 0
+	}
+	c.prune(s, ev.Timestamp)
+
+	if w.trigger != "" || !nonOK {
+		return
+	}
+	if s.streak >= c.cfg.Streak {
+		w.trigger = fmt.Sprintf("%s %s for %d consecutive events", ev.SLIName, ev.Status, s.streak)
+		return
+	}
+	if rate, ok := c.burnRate(s); ok && rate >= c.cfg.BurnRateThreshold {
+		w.trigger = fmt.Sprintf("%s burn rate %.1f over %s", ev.SLIName, rate, c.cfg.Window)
+	}
+}
+
+// prune drops the buckets that ended before the window ending at now.
+func (c *Controller) prune(s *sliState, now time.Time) {
+	cutoff := now.Add(-c.cfg.Window)
+	drop := 0
+	for drop < len(s.buckets) && !s.buckets[drop].start.Add(c.width).After(cutoff) {
+		drop++
+	}
+	s.buckets = s.buckets[drop:]
+}
+
+// burnRate is the share of non-ok events in the window over the error
+// budget.
+func (c *Controller) burnRate(s *sliState) (float64, bool) {
+	var total, nonOK int
+	for _, b := range s.buckets {
+		total += b.total
+		nonOK += b.nonOK
+	}
+	if total < c.cfg.MinEvents {
+		return 0, false
+	}
+	return float64(nonOK) / float64(total) / (1 - c.cfg.Objective), true
+}
+
+// Tick stops activations that ran for Duration, then starts one for each
+// triggered workload outside its cooldown, up to MaxActive. While
+// overBudget is set, the agent's overhead ceiling is exceeded: every
+// activation is stopped and none start. Tick returns the annotations of
+// the changes; a scoping error leaves the previous scope in place, to be
+// retried on the next tick.
+func (c *Controller) Tick(now time.Time, overBudget bool) ([]schema.Annotation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]signalspec.Workload, 0, len(c.workloads))
+	for key := range c.workloads {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Namespace != keys[j].Namespace {
+			return keys[i].Namespace < keys[j].Namespace
+		}
+		return keys[i].Service < keys[j].Service
+	})
+
+	var notes []schema.Annotation
+	active := 0
+	for _, key := range keys {
+		w := c.workloads[key]
+		for pod, seen := range w.pods {
+			if now.Sub(seen) > c.cfg.Window {
+				delete(w.pods, pod)
+			}
+		}
+		switch {
+		case !w.active:
+		case overBudget:
+			notes = append(notes, c.stop(key, w, now, "overhead budget exceeded"))
+		case !now.Before(w.until):
+			notes = append(notes, c.stop(key, w, now, fmt.Sprintf("ran for %s", c.cfg.Duration)))
+		default:
+			// Containers restart during incidents; follow their cgroups.
+			if cgroups := c.resolve(w.activePods); len(cgroups) > 0 {
+				w.cgroups = cgroups
+			}
+			active++
+		}
+	}
+
+	for _, key := range keys {
+		w := c.workloads[key]
+		trigger := w.trigger
+		w.trigger = ""
+		if trigger == "" || w.active || overBudget || now.Before(w.cooldown) || active >= c.cfg.MaxActive {
+			continue
+		}
+		pods := make([]string, 0, len(w.pods))
+		for pod := range w.pods {
+			pods = append(pods, pod)
+		}
+		sort.Strings(pods)
+		cgroups := c.resolve(pods)
+		if len(cgroups) == 0 {
+			w.cooldown = now.Add(c.cfg.Cooldown)
+			notes = append(notes, c.annotate(schema.AnnotationDeepTracingSkipped, key, pods, now, trigger+"; no cgroups found for the workload's pods"))
+			continue
+		}
+		w.active = true
+		w.until = now.Add(c.cfg.Duration)
+		w.activePods = pods
+		w.cgroups = cgroups
+		active++
+		notes = append(notes, c.annotate(schema.AnnotationDeepTracingStarted, key, pods, now, trigger))
+	}
+
+	for key, w := range c.workloads {
+		for name, s := range w.slis {
+			c.prune(s, now)
+			if len(s.buckets) == 0 {
+				delete(w.slis, name)
+			}
+		}
+		if len(w.slis) == 0 && len(w.pods) == 0 && !w.active && !now.Before(w.cooldown) {
+			delete(c.workloads, key)
+		}
+	}
+
+	c.history = append(c.history, notes...)
+	if over := len(c.history) - maxHistory; over > 0 {
+		c.history = append(c.history[:0], c.history[over:]...)
+	}
+	return notes, c.applyScope()
+}
+
+func (c *Controller) stop(key signalspec.Workload, w *workloadState, now time.Time, reason string) schema.Annotation {
+	note := c.annotate(schema.AnnotationDeepTracingStopped, key, w.activePods, now, reason)
+	w.active = false
+	w.activePods = nil
+	w.cgroups = nil
+	w.cooldown = now.Add(c.cfg.Cooldown)
+	return note
+}
+
+func (c *Controller) annotate(kind string, key signalspec.Workload, pods []string, now time.Time, reason string) schema.Annotation {
+	return schema.Annotation{
+		Timestamp: now,
+		Kind:      kind,
+		Namespace: key.Namespace,
+		Service:   key.Service,
+		Signals:   append([]string(nil), c.cfg.Signals...),
+		Pods:      append([]string(nil), pods...),
+		Reason:    reason,
+	}
+}
+
+func (c *Controller) resolve(pods []string) []uint64 {
+	if c.cgroups == nil {
+		return nil
+	}
+	var out []uint64
+	for _, pod := range pods {
+		out = append(out, c.cgroups.CgroupIDs(pod)...)
+	}
+	return out
+}
+
+// applyScope scopes every signal to the active workloads' cgroups.
+func (c *Controller) applyScope() error {
+	var scope []uint64
+	for _, w := range c.workloads {
+		if w.active {
+			scope = append(scope, w.cgroups...)
+		}
+	}
+	slices.Sort(scope)
+	scope = slices.Compact(scope)
+
+	var errs []error
+	for _, signal := range c.cfg.Signals {
+		if slices.Equal(c.applied[signal], scope) {
+			continue
+		}
+		if err := c.scoper.SetScope(signal, scope); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		c.applied[signal] = scope
+	}
+	return errors.Join(errs...)
+}
+
+// Active returns the number of workloads being traced.
+func (c *Controller) Active() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, w := range c.workloads {
+		if w.active {
+			n++
+		}
+	}
+	return n
+}
+
+// Timeline returns the workload's annotations at or after since, oldest
+// first, for attaching to an incident.
+func (c *Controller) Timeline(workload signalspec.Workload, since time.Time) []schema.Annotation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []schema.Annotation
+	for _, note := range c.history {
+		if note.Namespace == workload.Namespace && note.Service == workload.Service && !note.Timestamp.Before(since) {
+			out = append(out, note)
+		}
+	}
+	return out
+}
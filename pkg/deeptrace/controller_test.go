@@ -0,0 +1,175 @@
+package deeptrace
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
+)
+
+type fakeScoper struct {
+	scopes map[string][]uint64
+	calls  int
+	err    error
+}
+
+func (f *fakeScoper) SetScope(signal string, cgroups []uint64) error {
+	f.calls++
+	if f.err != nil {
+		return f.err
+	}
+	if f.scopes == nil {
+		f.scopes = make(map[string][]uint64)
+	}
+	f.scopes[signal] = cgroups
+	return nil
+}
+
+type staticCgroups map[string][]uint64
+
+func (s staticCgroups) CgroupIDs(podUID string) []uint64 { return s[podUID] }
+
+var chat = signalspec.Workload{Namespace: "prod", Service: "chat"}
+
+func sloEvent(ts time.Time, service, pod, status string) schema.SLOEvent {
+	return schema.SLOEvent{
+		Timestamp: ts,
+		Namespace: "prod",
+		Service:   service,
+		SLIName:   "ttft_ms",
+		Status:    status,
+		Labels:    map[string]string{"pod": pod},
+	}
+}
+
+func TestControllerTracesBreachingWorkload(t *testing.T) {
+	scoper := &fakeScoper{}
+	c := NewController(Config{Duration: time.Minute, Cooldown: 5 * time.Minute}, scoper, staticCgroups{"pod-a": {11, 12}, "pod-b": {21}})
+	start := time.Unix(1700000000, 0)
+
+	c.Observe(sloEvent(start, "chat", "pod-a", "warning"))
+	c.Observe(sloEvent(start.Add(time.Second), "chat", "pod-a", "breach"))
+	c.Observe(sloEvent(start.Add(2*time.Second), "search", "pod-b", "ok"))
+	if notes, _ := c.Tick(start.Add(3*time.Second), false); len(notes) != 0 || c.Active() != 0 {
+		t.Fatalf("two non-ok events should not trigger yet, got %+v", notes)
+	}
+
+	c.Observe(sloEvent(start.Add(4*time.Second), "chat", "pod-a", "breach"))
+	notes, err := c.Tick(start.Add(5*time.Second), false)
+	if err != nil || len(notes) != 1 || notes[0].Kind != schema.AnnotationDeepTracingStarted {
+		t.Fatalf("expected a start annotation, got %+v (%v)", notes, err)
+	}
+	if notes[0].Reason != "ttft_ms breach for 3 consecutive events" || !slices.Equal(notes[0].Pods, []string{"pod-a"}) {
+		t.Fatalf("unexpected start annotation %+v", notes[0])
+	}
+	for _, signal := range []string{signalspec.TLSHandshakeMS, signalspec.SyscallLatencyMS} {
+		if !slices.Equal(scoper.scopes[signal], []uint64{11, 12}) {
+			t.Fatalf("%s should be scoped to pod-a's cgroups, got %v", signal, scoper.scopes[signal])
+		}
+	}
+
+	calls := scoper.calls
+	if notes, _ := c.Tick(start.Add(30*time.Second), false); len(notes) != 0 || scoper.calls != calls {
+		t.Fatalf("an unchanged scope should not be reapplied, got %+v", notes)
+	}
+
+	notes, _ = c.Tick(start.Add(65*time.Second), false)
+	if len(notes) != 1 || notes[0].Kind != schema.AnnotationDeepTracingStopped || notes[0].Reason != "ran for 1m0s" {
+		t.Fatalf("expected the activation to end after Duration, got %+v", notes)
+	}
+	if len(scoper.scopes[signalspec.TLSHandshakeMS]) != 0 || c.Active() != 0 {
+		t.Fatalf("probes should be unscoped, got %v", scoper.scopes)
+	}
+
+	// Still burning, but cooling down.
+	c.Observe(sloEvent(start.Add(70*time.Second), "chat", "pod-a", "breach"))
+	if notes, _ := c.Tick(start.Add(71*time.Second), false); len(notes) != 0 {
+		t.Fatalf("cooldown should hold back a restart, got %+v", notes)
+	}
+
+	timeline := c.Timeline(chat, start)
+	if len(timeline) != 2 || timeline[0].Kind != schema.AnnotationDeepTracingStarted || timeline[1].Kind != schema.AnnotationDeepTracingStopped {
+		t.Fatalf("unexpected timeline %+v", timeline)
+	}
+	if got := c.Timeline(chat, start.Add(time.Minute)); len(got) != 1 {
+		t.Fatalf("since should bound the timeline, got %+v", got)
+	}
+}
+
+func TestControllerBurnRateTrigger(t *testing.T) {
+	scoper := &fakeScoper{}
+	c := NewController(Config{Objective: 0.99, BurnRateThreshold: 10, MinEvents: 20}, scoper, staticCgroups{"pod-a": {11}})
+	start := time.Unix(1700000000, 0)
+
+	// Every fifth event warns: never a streak, but a burn rate of 20.
+	for i := 0; i < 19; i++ {
+		status := "ok"
+		if i%5 == 4 {
+			status = "warning"
+		}
+		c.Observe(sloEvent(start.Add(time.Duration(i)*time.Second), "chat", "pod-a", status))
+	}
+	if notes, _ := c.Tick(start.Add(20*time.Second), false); len(notes) != 0 {
+		t.Fatalf("burn rate needs MinEvents, got %+v", notes)
+	}
+	c.Observe(sloEvent(start.Add(20*time.Second), "chat", "pod-a", "warning"))
+	notes, _ := c.Tick(start.Add(21*time.Second), false)
+	if len(notes) != 1 || notes[0].Reason != "ttft_ms burn rate 20.0 over 5m0s" {
+		t.Fatalf("expected a burn rate trigger, got %+v", notes)
+	}
+}
+
+func TestControllerRespectsOverheadCeiling(t *testing.T) {
+	scoper := &fakeScoper{}
+	c := NewController(Config{MaxActive: 1}, scoper, staticCgroups{"pod-a": {11}, "pod-b": {21}})
+	start := time.Unix(1700000000, 0)
+	burn := func(ts time.Time) {
+		for i := 0; i < 3; i++ {
+			c.Observe(sloEvent(ts, "chat", "pod-a", "breach"))
+			c.Observe(sloEvent(ts, "search", "pod-b", "breach"))
+		}
+	}
+
+	burn(start)
+	if notes, _ := c.Tick(start, true); len(notes) != 0 {
+		t.Fatalf("nothing should start over budget, got %+v", notes)
+	}
+
+	burn(start.Add(time.Second))
+	notes, _ := c.Tick(start.Add(time.Second), false)
+	if len(notes) != 1 || notes[0].Service != "chat" || c.Active() != 1 {
+		t.Fatalf("MaxActive should admit one workload, got %+v", notes)
+	}
+
+	notes, _ = c.Tick(start.Add(2*time.Second), true)
+	if len(notes) != 1 || notes[0].Kind != schema.AnnotationDeepTracingStopped || notes[0].Reason != "overhead budget exceeded" {
+		t.Fatalf("expected the activation to be shed, got %+v", notes)
+	}
+	if len(scoper.scopes[signalspec.SyscallLatencyMS]) != 0 {
+		t.Fatalf("shed probes should be unscoped, got %v", scoper.scopes)
+	}
+}
+
+func TestControllerSkipsWorkloadWithoutCgroups(t *testing.T) {
+	scoper := &fakeScoper{err: errors.New("unused")}
+	c := NewController(Config{}, scoper, staticCgroups{})
+	start := time.Unix(1700000000, 0)
+	for i := 0; i < 3; i++ {
+		c.Observe(sloEvent(start, "chat", "pod-gone", "breach"))
+	}
+	notes, err := c.Tick(start, false)
+	if err != nil || len(notes) != 1 || notes[0].Kind != schema.AnnotationDeepTracingSkipped {
+		t.Fatalf("expected a skip annotation, got %+v (%v)", notes, err)
+	}
+	if scoper.calls != 0 {
+		t.Fatalf("a skipped workload should not touch probes, got %d calls", scoper.calls)
+	}
+
+	c.Observe(sloEvent(start.Add(time.Second), "chat", "pod-gone", "breach"))
+	if notes, _ := c.Tick(start.Add(time.Second), false); len(notes) != 0 {
+		t.Fatalf("a skip should start the cooldown, got %+v", notes)
+	}
+}
@@ -0,0 +1,4 @@
+// Package deeptrace attaches expensive probes just in time: while a
+// workload's SLO burns, they are scoped to its cgroups for a bounded
+// duration, and every activation is recorded for the incident timeline.
+package deeptrace
@@ -0,0 +1,271 @@
+// Package dependency maps connection destinations to the logical
+// dependencies of an LLM service (model providers, vector databases, DNS)
+// so kernel evidence can be credited to the matching fault domain.
+package dependency
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
+)
+
+// DomainProvider is the domain hint of hosted model APIs. It covers both
+// provider_throttle and provider_error, which the destination alone cannot
+// tell apart.
+const DomainProvider = "provider"
+
+// hintDomains lists the fault domains each domain hint stands for.
+var hintDomains = map[string][]string{
+	DomainProvider:                     {signalspec.DomainProviderThrottle, signalspec.DomainProviderError},
+	signalspec.DomainProviderThrottle:  {signalspec.DomainProviderThrottle},
+	signalspec.DomainProviderError:     {signalspec.DomainProviderError},
+	signalspec.DomainRetrievalBackend:  {signalspec.DomainRetrievalBackend},
+	signalspec.DomainNetworkDNS:        {signalspec.DomainNetworkDNS},
+	signalspec.DomainNetworkEgress:     {signalspec.DomainNetworkEgress},
+	signalspec.DomainGatewaySaturation: {signalspec.DomainGatewaySaturation},
+}
+
+// destinationDomains are the fault domains that name a destination rather
+// than the path to it or the local node. Evidence credited to one of them
+// is evidence against the others.
+var destinationDomains = []string{
+	signalspec.DomainProviderThrottle,
+	signalspec.DomainProviderError,
+	signalspec.DomainRetrievalBackend,
+	signalspec.DomainNetworkDNS,
+}
+
+// Domains returns the fault domains a domain hint stands for, or nil for
+// an unknown hint.
+func Domains(hint string) []string {
+	return hintDomains[hint]
+}
+
+// DestinationDomains returns the fault domains that name a destination.
+func DestinationDomains() []string {
+	return append([]string(nil), destinationDomains...)
+}
+
+// Dependency is one logical destination. A connection matches when its
+// destination IP is in one of CIDRs and its port is one of Ports; an empty
+// list matches anything, but an entry needs CIDRs or Ports to match
+// connections at all. Hosts match DNS query names and connections whose
+// destination host (schema.ConnTuple.DstHost) is known, subject to Ports;
+// a leading "*." matches any subdomain.
+type Dependency struct {
+	Name string
+	// Domain is the domain hint: DomainProvider or a fault domain name.
+	Domain string
+	CIDRs  []string
+	Ports  []int
+	Hosts  []string
+}
+
+type entry struct {
+	Dependency
+	prefixes []netip.Prefix
+	ports    map[int]bool
+	hosts    []string
+}
+
+// Catalog matches connection tuples and host names against dependencies
+// in order; the first match wins. A nil *Catalog matches nothing.
+type Catalog struct {
+	entries []entry
+	byName  map[string]int
+}
+
+// NewCatalog validates deps and builds a catalog.
+func NewCatalog(deps []Dependency) (*Catalog, error) {
+	c := &Catalog{byName: make(map[string]int, len(deps))}
+	for i, dep := range deps {
+		if dep.Name == "" {
+			return nil, fmt.Errorf("dependency %d: name is required", i)
+		}
+		if _, dup := c.byName[dep.Name]; dup {
+			return nil, fmt.Errorf("dependency %q: duplicate name", dep.Name)
+		}
+		if Domains(dep.Domain) == nil {
+			return nil, fmt.Errorf("dependency %q: unknown domain %q", dep.Name, dep.Domain)
+		}
+		e := entry{Dependency: dep, ports: make(map[int]bool, len(dep.Ports))}
+		for _, cidr := range dep.CIDRs {
+			prefix, err := netip.ParsePrefix(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("dependency %q: invalid cidr %q", dep.Name, cidr)
+			}
+			e.prefixes = append(e.prefixes, prefix.Masked())
+		}
+		for _, port := range dep.Ports {
+			if port < 1 || port > 65535 {
+				return nil, fmt.Errorf("dependency %q: invalid port %d", dep.Name, port)
+			}
+			e.ports[port] = true
+		}
+		for _, host := range dep.Hosts {
+			host = normalizeHost(host)
+			name := strings.TrimPrefix(host, "*.")
+			if name == "" || strings.ContainsAny(name, "*/: ") {
+				return nil, fmt.Errorf("dependency %q: invalid host %q", dep.Name, host)
+			}
+			e.hosts = append(e.hosts, host)
+		}
+		c.byName[dep.Name] = len(c.entries)
+		c.entries = append(c.entries, e)
+	}
+	return c, nil
+}
+
+// Get returns the dependency called name.
+func (c *Catalog) Get(name string) (Dependency, bool) {
+	if c == nil {
+		return Dependency{}, false
+	}
+	idx, ok := c.byName[name]
+	if !ok {
+		return Dependency{}, false
+	}
+	return c.entries[idx].Dependency, true
+}
+
+// Match returns the dependency a connection's destination belongs to, by
+// address or by destination host.
+func (c *Catalog) Match(tuple *schema.ConnTuple) (Dependency, bool) {
+	if c == nil || tuple == nil {
+		return Dependency{}, false
+	}
+	addr, err := netip.ParseAddr(tuple.DstIP)
+	addr = addr.Unmap()
+	host := normalizeHost(tuple.DstHost)
+	for _, e := range c.entries {
+		if err == nil && e.matchAddr(addr, tuple.DstPort) {
+			return e.Dependency, true
+		}
+		if host != "" && (len(e.ports) == 0 || e.ports[tuple.DstPort]) && e.matchHost(host) {
+			return e.Dependency, true
+		}
+	}
+	return Dependency{}, false
+}
+
+// MatchHost returns the dependency a host name belongs to.
+func (c *Catalog) MatchHost(host string) (Dependency, bool) {
+	if c == nil {
+		return Dependency{}, false
+	}
+	host = normalizeHost(host)
+	if host == "" {
+		return Dependency{}, false
+	}
+	for _, e := range c.entries {
+		if e.matchHost(host) {
+			return e.Dependency, true
+		}
+	}
+	return Dependency{}, false
+}
+
+// matchHost reports whether a normalized host matches one of e's hosts.
+func (e entry) matchHost(host string) bool {
+	for _, pattern := range e.hosts {
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func (e entry) matchAddr(addr netip.Addr, port int) bool {
+	if len(e.prefixes) == 0 && len(e.ports) == 0 {
+		return false
+	}
+	if len(e.ports) > 0 && !e.ports[port] {
+		return false
+	}
+	if len(e.prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range e.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Label sets ev.Dependency from its connection tuple.
+func (c *Catalog) Label(ev *schema.ProbeEventV1) {
+	if dep, ok := c.Match(ev.ConnTuple); ok {
+		ev.Dependency = dep.Name
+	}
+}
+
+// LabelV1Beta1 sets ev.Dependency from its connection tuple or, for DNS
+// events whose tuple names no dependency, from the query name.
+func (c *Catalog) LabelV1Beta1(ev *schema.ProbeEventV1Beta1) {
+	if dep, ok := c.Match(ev.ConnTuple); ok {
+		ev.Dependency = dep.Name
+		return
+	}
+	if ev.DNS != nil {
+		if dep, ok := c.MatchHost(ev.DNS.QName); ok {
+			ev.Dependency = dep.Name
+		}
+	}
+}
+
+// SignalDependencies returns, per signal, the dependency of the
+// highest-valued labeled event, as attribution.FaultSample.Dependencies
+// expects.
+func SignalDependencies(events []schema.ProbeEventV1) map[string]string {
+	return topBySignal(events, func(ev schema.ProbeEventV1) string {
+		return ev.Dependency
+	})
+}
+
+// SignalDstHosts returns, per signal, the destination host of the
+// highest-valued event whose connection names one, as
+// attribution.FaultSample.DstHosts expects.
+func SignalDstHosts(events []schema.ProbeEventV1) map[string]string {
+	return topBySignal(events, func(ev schema.ProbeEventV1) string {
+		if ev.ConnTuple == nil {
+			return ""
+		}
+		return ev.ConnTuple.DstHost
+	})
+}
+
+// topBySignal returns, per signal, the label of the highest-valued event
+// with a non-empty label.
+func topBySignal(events []schema.ProbeEventV1, label func(schema.ProbeEventV1) string) map[string]string {
+	var out map[string]string
+	top := make(map[string]float64)
+	for _, ev := range events {
+		name := label(ev)
+		if name == "" {
+			continue
+		}
+		if v, seen := top[ev.Signal]; seen && ev.Value <= v {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		top[ev.Signal] = ev.Value
+		out[ev.Signal] = name
+	}
+	return out
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
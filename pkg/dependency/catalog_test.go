@@ -0,0 +1,138 @@
+package dependency
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
+)
+
+func testCatalog(t *testing.T) *Catalog {
+	t.Helper()
+	c, err := NewCatalog([]Dependency{
+		{Name: "kube-dns", Domain: signalspec.DomainNetworkDNS, CIDRs: []string{"10.96.0.10/32"}, Ports: []int{53}},
+		{Name: "qdrant", Domain: signalspec.DomainRetrievalBackend, CIDRs: []string{"10.244.3.0/24"}, Ports: []int{6333, 6334}},
+		{Name: "openai", Domain: DomainProvider, CIDRs: []string{"2606:4700::/32"}, Hosts: []string{"api.openai.com", "*.openai.azure.com"}},
+		{Name: "egress-https", Domain: signalspec.DomainNetworkEgress, Ports: []int{443}},
+	})
+	if err != nil {
+		t.Fatalf("catalog: %v", err)
+	}
+	return c
+}
+
+func TestCatalogMatch(t *testing.T) {
+	c := testCatalog(t)
+	for _, tc := range []struct {
+		ip   string
+		port int
+		want string
+	}{
+		{"10.96.0.10", 53, "kube-dns"},
+		{"10.96.0.10", 5353, ""},
+		{"10.244.3.17", 6334, "qdrant"},
+		{"10.244.4.17", 6334, ""},
+		{"2606:4700:3033::6815:1234", 443, "openai"},
+		{"::ffff:10.244.3.9", 6333, "qdrant"},
+		// Earlier entries win; port-only entries match any address.
+		{"10.244.3.17", 443, "egress-https"},
+		{"not-an-ip", 443, ""},
+	} {
+		dep, ok := c.Match(&schema.ConnTuple{DstIP: tc.ip, DstPort: tc.port})
+		if dep.Name != tc.want || ok != (tc.want != "") {
+			t.Errorf("%s:%d: got %q (%v), want %q", tc.ip, tc.port, dep.Name, ok, tc.want)
+		}
+	}
+	// Hosts from passive DNS match when the address does not, subject to
+	// the entry's ports.
+	for _, tc := range []struct {
+		tuple schema.ConnTuple
+		want  string
+	}{
+		{schema.ConnTuple{DstIP: "104.18.7.192", DstPort: 443, DstHost: "api.openai.com"}, "openai"},
+		{schema.ConnTuple{DstIP: "104.18.7.192", DstPort: 8443, DstHost: "eastus.openai.azure.com"}, "openai"},
+		{schema.ConnTuple{DstIP: "104.18.7.192", DstPort: 8443, DstHost: "api.anthropic.com"}, ""},
+	} {
+		if dep, _ := c.Match(&tc.tuple); dep.Name != tc.want {
+			t.Errorf("%+v: got %q, want %q", tc.tuple, dep.Name, tc.want)
+		}
+	}
+	if _, ok := c.Match(nil); ok {
+		t.Error("nil tuple should not match")
+	}
+	var none *Catalog
+	if _, ok := none.Match(&schema.ConnTuple{DstIP: "10.96.0.10", DstPort: 53}); ok {
+		t.Error("nil catalog should not match")
+	}
+}
+
+func TestCatalogMatchHost(t *testing.T) {
+	c := testCatalog(t)
+	for host, want := range map[string]string{
+		"api.openai.com":          "openai",
+		"API.OpenAI.com.":         "openai",
+		"eastus.openai.azure.com": "openai",
+		"openai.azure.com":        "",
+		"api.anthropic.com":       "",
+		"":                        "",
+	} {
+		if dep, _ := c.MatchHost(host); dep.Name != want {
+			t.Errorf("%q: got %q, want %q", host, dep.Name, want)
+		}
+	}
+}
+
+func TestLabelAndSignalDependencies(t *testing.T) {
+	c := testCatalog(t)
+	events := []schema.ProbeEventV1{
+		{Signal: signalspec.ConnectLatencyMS, Value: 40, ConnTuple: &schema.ConnTuple{DstIP: "10.244.3.17", DstPort: 6333}},
+		{Signal: signalspec.ConnectLatencyMS, Value: 310, ConnTuple: &schema.ConnTuple{DstIP: "2606:4700::1", DstPort: 443}},
+		{Signal: signalspec.TCPRetransmits, Value: 4, ConnTuple: &schema.ConnTuple{DstIP: "10.244.3.17", DstPort: 6333}},
+		{Signal: signalspec.RunqueueDelayMS, Value: 30},
+	}
+	for i := range events {
+		c.Label(&events[i])
+	}
+	if events[0].Dependency != "qdrant" || events[1].Dependency != "openai" || events[3].Dependency != "" {
+		t.Fatalf("unexpected labels %+v", events)
+	}
+	events[2].ConnTuple.DstHost = "qdrant.rag.svc"
+	if hosts := SignalDstHosts(events); len(hosts) != 1 || hosts[signalspec.TCPRetransmits] != "qdrant.rag.svc" {
+		t.Fatalf("unexpected signal hosts %v", hosts)
+	}
+	deps := SignalDependencies(events)
+	if len(deps) != 2 || deps[signalspec.ConnectLatencyMS] != "openai" || deps[signalspec.TCPRetransmits] != "qdrant" {
+		t.Fatalf("unexpected signal dependencies %v", deps)
+	}
+
+	// DNS events fall back to the query name when the resolver is not in
+	// the catalog.
+	ev := schema.ProbeEventV1Beta1{
+		Signal:    signalspec.DNSLatencyMS,
+		ConnTuple: &schema.ConnTuple{DstIP: "169.254.20.10", DstPort: 53},
+		DNS:       &schema.DNSQuery{QName: "api.openai.com"},
+	}
+	c.LabelV1Beta1(&ev)
+	if ev.Dependency != "openai" {
+		t.Fatalf("expected openai from qname, got %q", ev.Dependency)
+	}
+}
+
+func TestNewCatalogValidation(t *testing.T) {
+	for _, tc := range []struct {
+		deps []Dependency
+		want string
+	}{
+		{[]Dependency{{Domain: DomainProvider}}, "name is required"},
+		{[]Dependency{{Name: "a", Domain: DomainProvider}, {Name: "a", Domain: DomainProvider}}, "duplicate name"},
+		{[]Dependency{{Name: "a", Domain: signalspec.DomainCPUThrottle}}, "unknown domain"},
+		{[]Dependency{{Name: "a", Domain: DomainProvider, CIDRs: []string{"10.0.0.0/33"}}}, "invalid cidr"},
+		{[]Dependency{{Name: "a", Domain: DomainProvider, Ports: []int{0}}}, "invalid port"},
+		{[]Dependency{{Name: "a", Domain: DomainProvider, Hosts: []string{"*.*.example.com"}}}, "invalid host"},
+	} {
+		if _, err := NewCatalog(tc.deps); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%+v: expected %q, got %v", tc.deps, tc.want, err)
+		}
+	}
+}
@@ -8,12 +8,14 @@ import (
 )
 
 var scenarioFaultLabels = map[string][]string{
-	"provider_throttle": {"provider_throttle"},
-	"dns_latency":       {"dns_latency"},
-	"cpu_throttle":      {"cpu_throttle"},
-	"memory_pressure":   {"memory_pressure"},
-	"network_partition": {"network_partition"},
-	"mixed":             {"provider_throttle", "dns_latency", "cpu_throttle", "memory_pressure", "network_partition"},
+	"provider_throttle":    {"provider_throttle"},
+	"dns_latency":          {"dns_latency"},
+	"cpu_throttle":         {"cpu_throttle"},
+	"memory_pressure":      {"memory_pressure"},
+	"network_partition":    {"network_partition"},
+	"gateway_saturation":   {"gateway_saturation"},
+	"conntrack_exhaustion": {"conntrack_exhaustion"},
+	"mixed":                {"provider_throttle", "dns_latency", "cpu_throttle", "memory_pressure", "network_partition"},
 }
 
 // GenerateFaultSamples creates deterministic synthetic fault samples for replay.
@@ -123,6 +125,8 @@ func SupportedScenarios() []string {
 		"cpu_throttle",
 		"memory_pressure",
 		"network_partition",
+		"gateway_saturation",
+		"conntrack_exhaustion",
 		"mixed",
 		"mixed_multi",
 	}
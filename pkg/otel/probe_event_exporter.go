@@ -48,11 +48,32 @@ func (e *ProbeEventExporter) ExportBatch(events []schema.ProbeEventV1) error {
 	if len(events) == 0 {
 		return nil
 	}
+	records := make([]logRecord, 0, len(events))
+	for _, event := range events {
+		records = append(records, toProbeLogRecord(event))
+	}
+	return e.post(records)
+}
+
+// ExportBatchV1Beta1 posts v1beta1 probe events, adding the process and
+// cgroup identity attributes to the v1alpha1 record layout.
+func (e *ProbeEventExporter) ExportBatchV1Beta1(events []schema.ProbeEventV1Beta1) error {
+	if len(events) == 0 {
+		return nil
+	}
+	records := make([]logRecord, 0, len(events))
+	for _, event := range events {
+		records = append(records, toProbeLogRecordV1Beta1(event))
+	}
+	return e.post(records)
+}
+
+func (e *ProbeEventExporter) post(records []logRecord) error {
 	if e.endpoint == "" {
 		return fmt.Errorf("otlp endpoint is required")
 	}
 
-	payload := buildProbeLogsPayload(e.serviceName, e.scopeName, events)
+	payload := probeLogsPayload(e.serviceName, e.scopeName, records)
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("marshal otlp payload: %w", err)
@@ -71,7 +92,7 @@ func (e *ProbeEventExporter) ExportBatch(events []schema.ProbeEventV1) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("otlp endpoint returned status %d", resp.StatusCode)
+		return &StatusError{Code: resp.StatusCode}
 	}
 	return nil
 }
@@ -81,7 +102,10 @@ func buildProbeLogsPayload(serviceName string, scopeName string, events []schema
 	for _, event := range events {
 		records = append(records, toProbeLogRecord(event))
 	}
+	return probeLogsPayload(serviceName, scopeName, records)
+}
 
+func probeLogsPayload(serviceName string, scopeName string, records []logRecord) logsPayload {
 	return logsPayload{
 		ResourceLogs: []resourceLogs{
 			{
@@ -135,6 +159,12 @@ func toProbeLogRecord(event schema.ProbeEventV1) logRecord {
 			strAttribute("net.transport", event.ConnTuple.Protocol),
 		)
 	}
+	if event.ConnTuple != nil && event.ConnTuple.DstHost != "" {
+		attrs = append(attrs, strAttribute("net.dst.host", event.ConnTuple.DstHost))
+	}
+	if event.Dependency != "" {
+		attrs = append(attrs, strAttribute("dependency", event.Dependency))
+	}
 	if event.Errno != nil {
 		attrs = append(attrs, doubleAttribute("errno", float64(*event.Errno)))
 	}
@@ -158,3 +188,30 @@ func toProbeLogRecord(event schema.ProbeEventV1) logRecord {
 		Attributes: attrs,
 	}
 }
+
+func toProbeLogRecordV1Beta1(event schema.ProbeEventV1Beta1) logRecord {
+	record := toProbeLogRecord(schema.DowngradeProbeEvent(event))
+	record.Attributes = append(record.Attributes,
+		strAttribute("schema.version", event.SchemaVersion),
+		doubleAttribute("sampling.weight", event.SamplingWeight),
+	)
+	if event.Comm != "" {
+		record.Attributes = append(record.Attributes, strAttribute("process.comm", event.Comm))
+	}
+	if event.CgroupID != 0 {
+		record.Attributes = append(record.Attributes, strAttribute("cgroup.id", strconv.FormatUint(event.CgroupID, 10)))
+	}
+	if event.NetNS != 0 {
+		record.Attributes = append(record.Attributes, strAttribute("netns", strconv.FormatUint(event.NetNS, 10)))
+	}
+	if event.Service != "" {
+		record.Attributes = append(record.Attributes, strAttribute("service", event.Service))
+	}
+	if event.Workload != "" {
+		record.Attributes = append(record.Attributes, strAttribute("workload", event.Workload))
+	}
+	if event.NodeBootID != "" {
+		record.Attributes = append(record.Attributes, strAttribute("node.boot_id", event.NodeBootID))
+	}
+	return record
+}
@@ -54,3 +54,34 @@ func TestProbeEventExporterExportBatch(t *testing.T) {
 		t.Fatalf("expected WARN severity, got %s", records[0].SeverityText)
 	}
 }
+
+func TestProbeLogRecordV1Beta1AddsIdentity(t *testing.T) {
+	record := toProbeLogRecordV1Beta1(schema.ProbeEventV1Beta1{
+		SchemaVersion:  schema.ProbeSchemaV1Beta1,
+		Signal:         "dns_latency_ms",
+		Value:          12,
+		Unit:           "ms",
+		Status:         "ok",
+		Comm:           "rag-service",
+		CgroupID:       8812,
+		Service:        "rag",
+		SamplingWeight: 2,
+	})
+
+	attrs := map[string]anyValue{}
+	for _, kv := range record.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if attrs["process.comm"].StringValue != "rag-service" {
+		t.Fatalf("expected process.comm attribute, got %+v", attrs["process.comm"])
+	}
+	if attrs["cgroup.id"].StringValue != "8812" {
+		t.Fatalf("expected cgroup.id attribute, got %+v", attrs["cgroup.id"])
+	}
+	if attrs["schema.version"].StringValue != schema.ProbeSchemaV1Beta1 {
+		t.Fatalf("expected schema.version attribute, got %+v", attrs["schema.version"])
+	}
+	if _, ok := attrs["netns"]; ok {
+		t.Fatal("zero netns should be omitted")
+	}
+}
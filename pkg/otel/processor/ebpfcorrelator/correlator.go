@@ -7,6 +7,7 @@ import (
 
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/correlation"
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/semconv"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
 )
 
 // Correlator is a lightweight helper for DNS signal enrichment.
@@ -14,6 +15,8 @@ type Correlator struct {
 	WindowMS            int
 	EnrichmentThreshold float64
 	MaxJoinFanout       int
+	// Tiers is the correlation ladder; empty uses correlation.DefaultTiers.
+	Tiers correlation.Tiers
 }
 
 // DebugStats captures non-enriched correlation outcomes for diagnostics.
@@ -55,18 +58,10 @@ func (c Correlator) EnrichAttributes(
 	if base == nil {
 		base = map[string]float64{}
 	}
-	window := time.Duration(c.WindowMS) * time.Millisecond
-	threshold := c.EnrichmentThreshold
-	if threshold <= 0 {
-		threshold = correlation.DefaultEnrichmentThreshold
-	}
-	fanout := c.MaxJoinFanout
-	if fanout <= 0 {
-		fanout = 3
-	}
+	window := c.window()
+	threshold := c.threshold()
 
 	candidates := make([]Candidate, 0, len(signals))
-	out := cloneMap(base)
 	debug := DebugStats{}
 
 	for _, signal := range signals {
@@ -75,7 +70,7 @@ func (c Correlator) EnrichAttributes(
 			debug.UnsupportedType++
 			continue
 		}
-		decision := correlation.Match(span, signal, window)
+		decision := c.tiers().Match(span, signal, window)
 		if !decision.Matched {
 			debug.Unmatched++
 			continue
@@ -89,6 +84,20 @@ func (c Correlator) EnrichAttributes(
 		_ = attr
 	}
 
+	return c.enrich(base, span, candidates, debug)
+}
+
+// enrich ranks candidates by confidence, then temporal proximity, keeps
+// the top MaxJoinFanout and sets their attributes on a copy of base.
+func (c Correlator) enrich(
+	base map[string]float64,
+	span correlation.SpanRef,
+	candidates []Candidate,
+	debug DebugStats,
+) EnrichmentResult {
+	fanout := c.fanout()
+	out := cloneMap(base)
+
 	sort.SliceStable(candidates, func(i, j int) bool {
 		if candidates[i].Decision.Confidence != candidates[j].Decision.Confidence {
 			return candidates[i].Decision.Confidence > candidates[j].Decision.Confidence
@@ -140,35 +149,40 @@ func (c Correlator) EnrichDNSAttributes(
 	return result.Attributes, result.Candidates[0].Decision
 }
 
+func (c Correlator) window() time.Duration {
+	if c.WindowMS <= 0 {
+		return correlation.DefaultWindow
+	}
+	return time.Duration(c.WindowMS) * time.Millisecond
+}
+
+func (c Correlator) tiers() correlation.Tiers {
+	if len(c.Tiers) == 0 {
+		return correlation.DefaultTiers()
+	}
+	return c.Tiers
+}
+
+func (c Correlator) threshold() float64 {
+	if c.EnrichmentThreshold <= 0 {
+		return correlation.DefaultEnrichmentThreshold
+	}
+	return c.EnrichmentThreshold
+}
+
+func (c Correlator) fanout() int {
+	if c.MaxJoinFanout <= 0 {
+		return 3
+	}
+	return c.MaxJoinFanout
+}
+
 func signalAttrKey(signal string) (string, bool) {
-	switch signal {
-	case "dns_latency_ms":
-		return semconv.AttrDNSLatencyMS, true
-	case "tcp_retransmits_total":
-		return semconv.AttrTCPRetransmits, true
-	case "runqueue_delay_ms":
-		return semconv.AttrRunqueueDelayMS, true
-	case "connect_latency_ms":
-		return semconv.AttrConnectLatencyMS, true
-	case "tls_handshake_ms":
-		return semconv.AttrTLSHandshakeMS, true
-	case "cpu_steal_pct":
-		return semconv.AttrCPUStealPct, true
-	case "cfs_throttled_ms":
-		return semconv.AttrCFSThrottledMS, true
-	case "mem_reclaim_latency_ms":
-		return semconv.AttrMemReclaimLatencyMS, true
-	case "disk_io_latency_ms":
-		return semconv.AttrDiskIOLatencyMS, true
-	case "syscall_latency_ms":
-		return semconv.AttrSyscallLatencyMS, true
-	case "connect_errors_total":
-		return semconv.AttrConnectErrors, true
-	case "tls_handshake_fail_total":
-		return semconv.AttrTLSHandshakeFails, true
-	default:
+	desc, ok := signalspec.Lookup(signal)
+	if !ok || desc.Attr == "" {
 		return "", false
 	}
+	return desc.Attr, true
 }
 
 // DecomposeRetrieval sums kernel-attributed retrieval latency components
@@ -5,7 +5,9 @@ import (
 	"time"
 
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/correlation"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/semconv"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
 )
 
 func TestEnrichDNSAttributes(t *testing.T) {
@@ -111,6 +113,54 @@ func TestEnrichAttributesMultiSignalFanout(t *testing.T) {
 	}
 }
 
+func TestEnrichAttributesTCPSignalsJoinOnConnTuple(t *testing.T) {
+	c := New()
+	now := time.Now().UTC()
+	tuple := schema.ConnTuple{SrcIP: "10.0.0.2", SrcPort: 42424, DstIP: "104.18.6.192", DstPort: 443, Protocol: "tcp"}
+	span := correlation.SpanRef{
+		Service:   "rag",
+		Node:      "node-a",
+		Pod:       "pod-a",
+		PID:       101,
+		ConnTuple: tuple.String(),
+		Timestamp: now,
+	}
+
+	// tcp_probe runs in softirq context, so the events carry no PID and
+	// can only join on the connection tuple.
+	result := c.EnrichAttributes(nil, span, []correlation.SignalRef{
+		{
+			Signal:    signalspec.TCPSRTTMS,
+			Pod:       "pod-a",
+			ConnTuple: tuple.String(),
+			Timestamp: now.Add(120 * time.Millisecond),
+			Value:     240,
+		},
+		{
+			Signal:    signalspec.TCPZeroWindows,
+			Pod:       "pod-a",
+			ConnTuple: tuple.String(),
+			Timestamp: now.Add(-80 * time.Millisecond),
+			Value:     1,
+		},
+	})
+
+	if len(result.Candidates) != 2 {
+		t.Fatalf("expected two candidates, got %d (%+v)", len(result.Candidates), result.Debug)
+	}
+	for _, candidate := range result.Candidates {
+		if candidate.Decision.Tier != "pod_conn_250ms" {
+			t.Fatalf("%s: expected pod_conn_250ms match, got %+v", candidate.Signal.Signal, candidate.Decision)
+		}
+	}
+	if result.Attributes[semconv.AttrTCPSRTTMS] != 240 {
+		t.Fatalf("expected srtt attribute, got %v", result.Attributes)
+	}
+	if result.Attributes[semconv.AttrTCPZeroWindows] != 1 {
+		t.Fatalf("expected zero-window attribute, got %v", result.Attributes)
+	}
+}
+
 func TestEnrichAttributesThresholdAndDebug(t *testing.T) {
 	c := New()
 	now := time.Now().UTC()
@@ -322,3 +372,15 @@ func TestProcessBatchAggregatesFanoutDrops(t *testing.T) {
 		t.Fatalf("expected one fanout drop, got %d", out.Debug.FanoutDropped)
 	}
 }
+
+func TestSignalAttrKeyCoversRegistry(t *testing.T) {
+	for _, desc := range signalspec.All() {
+		key, ok := signalAttrKey(desc.Name)
+		if !ok || key != desc.Attr {
+			t.Errorf("%s: attr key %q (ok=%v), want %q", desc.Name, key, ok, desc.Attr)
+		}
+	}
+	if _, ok := signalAttrKey("unknown"); ok {
+		t.Error("unregistered signals must not map to an attribute")
+	}
+}
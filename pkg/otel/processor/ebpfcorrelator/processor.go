@@ -1,6 +1,7 @@
 package ebpfcorrelator
 
 import (
+	"sort"
 	"time"
 
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/correlation"
@@ -25,29 +26,70 @@ type ProcessedBatch struct {
 	Debug DebugStats
 }
 
-// ProcessBatch applies correlation enrichment over a span batch.
+// ProcessBatch applies correlation enrichment over a span batch. Spans
+// are joined in timestamp order through a Stream that is fed the signals
+// up to the end of each span's window, so each span visits only the
+// signals indexed under its keys. Results match EnrichAttributes per
+// span, except that unmatched and low-confidence signals are not counted
+// and an unsupported signal is counted once per batch.
 func (c Correlator) ProcessBatch(spans []SpanRecord, signals []correlation.SignalRef) ProcessedBatch {
-	result := ProcessedBatch{Spans: make([]SpanRecord, 0, len(spans))}
-	for _, item := range spans {
-		spanRef := correlation.SpanRef{
-			TraceID:   item.TraceID,
-			Service:   item.Service,
-			Node:      item.Node,
-			Pod:       item.Pod,
-			PID:       item.PID,
-			ConnTuple: item.ConnTuple,
-			Timestamp: item.Timestamp,
+	window := c.window()
+	stream := c.NewStream(StreamConfig{
+		Lateness:   -1,
+		Retention:  2*window + DefaultStreamConfig().BucketWidth,
+		MaxSignals: len(signals) + 1,
+	})
+
+	bySignal := make([]int, len(signals))
+	for i := range bySignal {
+		bySignal[i] = i
+	}
+	sort.SliceStable(bySignal, func(i, j int) bool {
+		return signals[bySignal[i]].Timestamp.Before(signals[bySignal[j]].Timestamp)
+	})
+	bySpan := make([]int, len(spans))
+	for i := range bySpan {
+		bySpan[i] = i
+	}
+	sort.SliceStable(bySpan, func(i, j int) bool {
+		return spans[bySpan[i]].Timestamp.Before(spans[bySpan[j]].Timestamp)
+	})
+
+	result := ProcessedBatch{Spans: make([]SpanRecord, len(spans))}
+	next := 0
+	for _, k := range bySpan {
+		item := spans[k]
+		end := item.Timestamp.Add(window)
+		for ; next < len(bySignal) && !signals[bySignal[next]].Timestamp.After(end); next++ {
+			stream.add(signals[bySignal[next]], uint64(bySignal[next])+1)
 		}
 
-		enriched := c.EnrichAttributes(item.Attributes, spanRef, signals)
+		enriched := stream.Enrich(item.Attributes, item.spanRef())
 		DecomposeRetrieval(enriched.Attributes)
 		item.Attributes = enriched.Attributes
-		result.Spans = append(result.Spans, item)
+		result.Spans[k] = item
 		result.Debug = mergeDebug(result.Debug, enriched.Debug)
 	}
+	for _, signal := range signals {
+		if _, supported := signalAttrKey(signal.Signal); !supported {
+			result.Debug.UnsupportedType++
+		}
+	}
 	return result
 }
 
+func (s SpanRecord) spanRef() correlation.SpanRef {
+	return correlation.SpanRef{
+		TraceID:   s.TraceID,
+		Service:   s.Service,
+		Node:      s.Node,
+		Pod:       s.Pod,
+		PID:       s.PID,
+		ConnTuple: s.ConnTuple,
+		Timestamp: s.Timestamp,
+	}
+}
+
 func mergeDebug(left DebugStats, right DebugStats) DebugStats {
 	return DebugStats{
 		Unmatched:       left.Unmatched + right.Unmatched,
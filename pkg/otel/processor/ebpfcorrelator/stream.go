@@ -0,0 +1,475 @@
+package ebpfcorrelator
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/correlation"
+)
+
+// StreamConfig bounds a Stream's memory and lateness.
+type StreamConfig struct {
+	// BucketWidth is the time span of one index bucket. Lookups visit the
+	// buckets a tier's window overlaps, so narrower buckets mean fewer
+	// entries per visit and more visits.
+	BucketWidth time.Duration
+	// Lateness is how far behind the newest signal a signal may arrive
+	// and still join the spans released after it. The watermark trails
+	// the newest signal timestamp by Lateness.
+	Lateness time.Duration
+	// Retention is how far behind the watermark signals stay indexed, so
+	// spans arriving late still find them. Signals older than that are
+	// evicted, and dropped as late when they arrive.
+	Retention time.Duration
+	// MaxSignals bounds the indexed signals; beyond it the oldest buckets
+	// are evicted early.
+	MaxSignals int
+	// MaxPending bounds the spans queued by Submit; beyond it the oldest
+	// are released before they are complete.
+	MaxPending int
+}
+
+// DefaultStreamConfig keeps ten seconds of signals at 100k signals/s.
+func DefaultStreamConfig() StreamConfig {
+	return StreamConfig{
+		BucketWidth: 100 * time.Millisecond,
+		Lateness:    2 * time.Second,
+		MaxSignals:  1 << 20,
+		MaxPending:  1 << 16,
+	}
+}
+
+// StreamStats counts a Stream's signals by outcome.
+type StreamStats struct {
+	Added       int
+	Unsupported int
+	// Late signals arrived after the watermark had passed their
+	// retention and were dropped.
+	Late int
+	// Evicted signals aged out behind the watermark.
+	Evicted int
+	// Overflow signals were evicted early to stay within MaxSignals.
+	Overflow int
+	// EarlyReleased spans left the pending queue incomplete to stay
+	// within MaxPending.
+	EarlyReleased int
+}
+
+// joinKeyCount is the number of correlation.JoinKeys the stream indexes.
+const joinKeyCount = 4
+
+// keyIndex maps a join key to its index slot.
+func keyIndex(key correlation.JoinKey) int {
+	switch key {
+	case correlation.JoinTraceID:
+		return 0
+	case correlation.JoinPodPID:
+		return 1
+	case correlation.JoinPodConn:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// streamTier is a ladder tier the stream looks up, with its index slot.
+type streamTier struct {
+	correlation.Tier
+	key int
+}
+
+// indexKey is the value of one join key: trace ID, (pod, pid),
+// (pod, conn tuple) or (service, node).
+type indexKey struct {
+	a, b string
+	pid  int
+}
+
+// joinKeys returns the value of each join key, and whether it is set.
+func joinKeys(traceID, service, node, pod string, pid int, conn string) (keys [joinKeyCount]indexKey, ok [joinKeyCount]bool) {
+	keys[0], ok[0] = indexKey{a: traceID}, traceID != ""
+	keys[1], ok[1] = indexKey{a: pod, pid: pid}, pod != "" && pid > 0
+	keys[2], ok[2] = indexKey{a: pod, b: conn}, pod != "" && conn != ""
+	keys[3], ok[3] = indexKey{a: service, b: node}, service != "" && node != ""
+	return keys, ok
+}
+
+type streamEntry struct {
+	signal correlation.SignalRef
+	seq    uint64
+	// next chains the entries of a bucket sharing a join key value, as
+	// index+1; 0 ends the chain.
+	next [joinKeyCount]int32
+}
+
+// streamBucket holds the signals of one BucketWidth of time. Chains are
+// threaded through entries from per-key heads, so a bucket is reset, not
+// reallocated, when its ring slot is reused.
+type streamBucket struct {
+	n       int64 // bucket number: timestamp / BucketWidth
+	live    bool
+	entries []streamEntry
+	heads   [joinKeyCount]map[indexKey]int32
+}
+
+func (b *streamBucket) reset(n int64) {
+	b.n = n
+	b.live = true
+	b.entries = b.entries[:0]
+	for i := range b.heads {
+		if b.heads[i] == nil {
+			b.heads[i] = make(map[indexKey]int32)
+		} else {
+			clear(b.heads[i])
+		}
+	}
+}
+
+// Stream is a streaming join of signals to spans. Signals are indexed by
+// each tier's key in a ring of time buckets that is evicted as the
+// watermark advances, so a span lookup visits only the buckets within a
+// tier's window and the chain for its key, instead of every signal.
+// Decisions match Correlator.EnrichAttributes over the indexed signals,
+// except that Debug counts no Unmatched, LowConfidence or UnsupportedType
+// signals: unsupported ones are counted in Stats when added, and tiers
+// below the enrichment threshold are not looked up. It is safe for
+// concurrent use.
+type Stream struct {
+	mu        sync.Mutex
+	c         Correlator
+	cfg       StreamConfig
+	window    time.Duration
+	threshold float64
+	// tiers are the ladder tiers at or above the threshold; indexed marks
+	// the join keys they use.
+	ladder  correlation.Tiers
+	tiers   []streamTier
+	indexed [joinKeyCount]bool
+	ring    []streamBucket
+	// high is the newest bucket number; floor is the oldest one still
+	// indexed.
+	high, floor int64
+	newest      time.Time
+	started     bool
+	count       int
+	seq         uint64
+	pending     pendingSpans
+	stats       StreamStats
+}
+
+// NewStream creates a stream join with the correlator's window,
+// threshold and fanout. Zero StreamConfig fields take
+// DefaultStreamConfig values and Retention defaults to twice the window;
+// a negative Lateness admits no late signals.
+func (c Correlator) NewStream(cfg StreamConfig) *Stream {
+	def := DefaultStreamConfig()
+	if cfg.BucketWidth <= 0 {
+		cfg.BucketWidth = def.BucketWidth
+	}
+	if cfg.Lateness == 0 {
+		cfg.Lateness = def.Lateness
+	} else if cfg.Lateness < 0 {
+		cfg.Lateness = 0
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = 2 * c.window()
+	}
+	if cfg.MaxSignals <= 0 {
+		cfg.MaxSignals = def.MaxSignals
+	}
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = def.MaxPending
+	}
+	slots := int((cfg.Lateness+cfg.Retention)/cfg.BucketWidth) + 2
+	s := &Stream{
+		c:         c,
+		cfg:       cfg,
+		window:    c.window(),
+		threshold: c.threshold(),
+		ring:      make([]streamBucket, slots),
+	}
+	s.ladder = c.tiers()
+	for _, tier := range s.ladder {
+		if tier.Confidence < s.threshold {
+			continue
+		}
+		key := keyIndex(tier.Key)
+		s.tiers = append(s.tiers, streamTier{Tier: tier, key: key})
+		s.indexed[key] = true
+	}
+	return s
+}
+
+func (s *Stream) bucketNo(ts time.Time) int64 {
+	return ts.UnixNano() / int64(s.cfg.BucketWidth)
+}
+
+func (s *Stream) slot(n int64) *streamBucket {
+	i := n % int64(len(s.ring))
+	if i < 0 {
+		i += int64(len(s.ring))
+	}
+	return &s.ring[i]
+}
+
+// bucket returns the live bucket numbered n, or nil.
+func (s *Stream) bucket(n int64) *streamBucket {
+	if !s.started || n < s.floor || n > s.high {
+		return nil
+	}
+	b := s.slot(n)
+	if !b.live || b.n != n {
+		return nil
+	}
+	return b
+}
+
+// Add indexes one signal. It returns false for signals with no span
+// attribute or no timestamp, and for signals older than the retention
+// behind the watermark.
+func (s *Stream) Add(signal correlation.SignalRef) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	return s.add(signal, s.seq)
+}
+
+// add indexes a signal under an arrival sequence number, which orders
+// equally ranked candidates.
+func (s *Stream) add(signal correlation.SignalRef, seq uint64) bool {
+	if _, ok := signalAttrKey(signal.Signal); !ok || signal.Timestamp.IsZero() {
+		s.stats.Unsupported++
+		return false
+	}
+	n := s.bucketNo(signal.Timestamp)
+	if !s.started {
+		s.started = true
+		s.high = n
+		s.floor = s.bucketNo(signal.Timestamp.Add(-s.cfg.Lateness - s.cfg.Retention))
+		s.newest = signal.Timestamp
+	}
+	if signal.Timestamp.After(s.newest) {
+		s.advance(signal.Timestamp)
+	}
+	if n < s.floor {
+		s.stats.Late++
+		return false
+	}
+
+	b := s.slot(n)
+	if !b.live || b.n != n {
+		s.evict(b, &s.stats.Evicted)
+		b.reset(n)
+	}
+	idx := int32(len(b.entries))
+	entry := streamEntry{signal: signal, seq: seq}
+	keys, ok := joinKeys(signal.TraceID, signal.Service, signal.Node, signal.Pod, signal.PID, signal.ConnTuple)
+	for k := range keys {
+		if !ok[k] || !s.indexed[k] {
+			continue
+		}
+		entry.next[k] = b.heads[k][keys[k]]
+		b.heads[k][keys[k]] = idx + 1
+	}
+	b.entries = append(b.entries, entry)
+	s.count++
+	s.stats.Added++
+
+	// The bucket just added to is kept even if it alone exceeds the bound.
+	for s.count > s.cfg.MaxSignals && s.floor < n {
+		if old := s.bucket(s.floor); old != nil {
+			s.evict(old, &s.stats.Overflow)
+		}
+		s.floor++
+	}
+	return true
+}
+
+// advance moves the newest timestamp to ts and evicts the buckets that
+// fall behind the watermark's retention.
+func (s *Stream) advance(ts time.Time) {
+	s.newest = ts
+	s.high = s.bucketNo(ts)
+	floor := s.bucketNo(ts.Add(-s.cfg.Lateness - s.cfg.Retention))
+	if floor <= s.floor {
+		return
+	}
+	if floor-s.floor >= int64(len(s.ring)) {
+		for i := range s.ring {
+			s.evict(&s.ring[i], &s.stats.Evicted)
+		}
+	} else {
+		for n := s.floor; n < floor; n++ {
+			if b := s.slot(n); b.live && b.n == n {
+				s.evict(b, &s.stats.Evicted)
+			}
+		}
+	}
+	s.floor = floor
+}
+
+func (s *Stream) evict(b *streamBucket, counter *int) {
+	if !b.live {
+		return
+	}
+	*counter += len(b.entries)
+	s.count -= len(b.entries)
+	b.live = false
+	b.entries = b.entries[:0]
+}
+
+// Watermark is the newest signal timestamp less Lateness; signals older
+// than it are assumed to have arrived.
+func (s *Stream) Watermark() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.watermark()
+}
+
+func (s *Stream) watermark() time.Time {
+	if !s.started {
+		return time.Time{}
+	}
+	return s.newest.Add(-s.cfg.Lateness)
+}
+
+// Len returns the number of indexed signals.
+func (s *Stream) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// Stats returns the signal and span counters.
+func (s *Stream) Stats() StreamStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// Enrich joins one span against the indexed signals now, without waiting
+// for late signals.
+func (s *Stream) Enrich(base map[string]float64, span correlation.SpanRef) EnrichmentResult {
+	s.mu.Lock()
+	candidates := s.lookup(span)
+	s.mu.Unlock()
+	return s.c.enrich(base, span, candidates, DebugStats{})
+}
+
+// lookup collects the span's candidates from the buckets each tier's
+// window overlaps, following only the chain for the span's key. Tiers
+// below the enrichment threshold are skipped. Candidates are returned in
+// arrival order, so ranking breaks ties as EnrichAttributes does.
+func (s *Stream) lookup(span correlation.SpanRef) []Candidate {
+	if span.Timestamp.IsZero() || !s.started {
+		return nil
+	}
+	keys, ok := joinKeys(span.TraceID, span.Service, span.Node, span.Pod, span.PID, span.ConnTuple)
+
+	var found []streamEntry
+	var decisions []correlation.Decision
+	for _, tier := range s.tiers {
+		t := tier.key
+		if !ok[t] {
+			continue
+		}
+		window := s.window
+		if tier.Window > 0 && tier.Window < window {
+			window = tier.Window
+		}
+		lo, hi := s.bucketNo(span.Timestamp.Add(-window)), s.bucketNo(span.Timestamp.Add(window))
+		for n := lo; n <= hi; n++ {
+			b := s.bucket(n)
+			if b == nil {
+				continue
+			}
+			for i := b.heads[t][keys[t]]; i != 0; i = b.entries[i-1].next[t] {
+				entry := &b.entries[i-1]
+				// A signal is chained under every key it carries but joins
+				// at the first tier it qualifies for, so it is only taken
+				// when looking up that tier.
+				decision := s.ladder.Match(span, entry.signal, s.window)
+				if decision.Tier != tier.Name {
+					continue
+				}
+				found = append(found, *entry)
+				decisions = append(decisions, decision)
+			}
+		}
+	}
+
+	order := make([]int, len(found))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return found[order[i]].seq < found[order[j]].seq })
+	out := make([]Candidate, len(found))
+	for i, k := range order {
+		out[i] = Candidate{Signal: found[k].signal, Decision: decisions[k]}
+	}
+	return out
+}
+
+// pendingSpans is a min-heap of queued spans by timestamp.
+type pendingSpans []SpanRecord
+
+func (p pendingSpans) Len() int           { return len(p) }
+func (p pendingSpans) Less(i, j int) bool { return p[i].Timestamp.Before(p[j].Timestamp) }
+func (p pendingSpans) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p *pendingSpans) Push(x any)        { *p = append(*p, x.(SpanRecord)) }
+func (p *pendingSpans) Pop() any {
+	old := *p
+	item := old[len(old)-1]
+	*p = old[:len(old)-1]
+	return item
+}
+
+// Submit queues a span until the watermark passes the end of its window,
+// so signals arriving up to Lateness late still join it. Drain releases
+// it.
+func (s *Stream) Submit(span SpanRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	heap.Push(&s.pending, span)
+}
+
+// Drain enriches and returns the queued spans whose window the watermark
+// has passed, oldest first, and the oldest spans beyond MaxPending.
+func (s *Stream) Drain() ProcessedBatch {
+	return s.release(false)
+}
+
+// Flush enriches and returns every queued span, oldest first.
+func (s *Stream) Flush() ProcessedBatch {
+	return s.release(true)
+}
+
+func (s *Stream) release(all bool) ProcessedBatch {
+	s.mu.Lock()
+	watermark := s.watermark()
+	var ready []SpanRecord
+	for s.pending.Len() > 0 {
+		next := s.pending[0]
+		complete := s.started && !next.Timestamp.Add(s.window).After(watermark)
+		if !all && !complete {
+			if s.pending.Len() <= s.cfg.MaxPending {
+				break
+			}
+			s.stats.EarlyReleased++
+		}
+		ready = append(ready, heap.Pop(&s.pending).(SpanRecord))
+	}
+	s.mu.Unlock()
+
+	result := ProcessedBatch{Spans: make([]SpanRecord, 0, len(ready))}
+	for _, item := range ready {
+		enriched := s.Enrich(item.Attributes, item.spanRef())
+		DecomposeRetrieval(enriched.Attributes)
+		item.Attributes = enriched.Attributes
+		result.Spans = append(result.Spans, item)
+		result.Debug = mergeDebug(result.Debug, enriched.Debug)
+	}
+	return result
+}
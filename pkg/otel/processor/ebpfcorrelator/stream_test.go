@@ -0,0 +1,332 @@
+package ebpfcorrelator
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/correlation"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/semconv"
+)
+
+var streamSignals = []string{"dns_latency_ms", "connect_latency_ms", "tls_handshake_ms", "runqueue_delay_ms"}
+
+// randomRef draws join keys from small pools, so tiers overlap.
+func randomRef(rng *rand.Rand, start time.Time, spread time.Duration) correlation.SpanRef {
+	ref := correlation.SpanRef{
+		Service:   fmt.Sprintf("svc-%d", rng.Intn(3)),
+		Node:      fmt.Sprintf("node-%d", rng.Intn(2)),
+		Pod:       fmt.Sprintf("pod-%d", rng.Intn(4)),
+		PID:       100 + rng.Intn(3),
+		ConnTuple: fmt.Sprintf("conn-%d", rng.Intn(4)),
+		Timestamp: start.Add(time.Duration(rng.Int63n(int64(spread)))),
+	}
+	if rng.Intn(3) == 0 {
+		ref.TraceID = fmt.Sprintf("trace-%d", rng.Intn(8))
+	}
+	if rng.Intn(4) == 0 {
+		ref.PID = 0
+	}
+	return ref
+}
+
+func randomSignal(rng *rand.Rand, start time.Time, spread time.Duration) correlation.SignalRef {
+	ref := randomRef(rng, start, spread)
+	return correlation.SignalRef{
+		Signal:    streamSignals[rng.Intn(len(streamSignals))],
+		TraceID:   ref.TraceID,
+		Service:   ref.Service,
+		Node:      ref.Node,
+		Pod:       ref.Pod,
+		PID:       ref.PID,
+		ConnTuple: ref.ConnTuple,
+		Timestamp: ref.Timestamp,
+		Value:     float64(rng.Intn(50)),
+	}
+}
+
+func TestStreamMatchesPairwiseScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	start := time.Unix(1700000000, 0)
+	custom := New()
+	custom.Tiers = correlation.Tiers{
+		{Name: "conn_50ms", Key: correlation.JoinPodConn, Window: 50 * time.Millisecond, Confidence: 0.95},
+		{Name: "trace", Key: correlation.JoinTraceID, Confidence: 0.85},
+		{Name: "pod_pid_300ms", Key: correlation.JoinPodPID, Window: 300 * time.Millisecond, Confidence: 0.75},
+		{Name: "conn_400ms", Key: correlation.JoinPodConn, Window: 400 * time.Millisecond, Confidence: 0.7},
+	}
+	for _, c := range []Correlator{New(), {WindowMS: 300, EnrichmentThreshold: 0.6, MaxJoinFanout: 2}, custom} {
+		signals := make([]correlation.SignalRef, 2000)
+		for i := range signals {
+			signals[i] = randomSignal(rng, start, 5*time.Second)
+		}
+		stream := c.NewStream(StreamConfig{Retention: 10 * time.Second})
+		for _, signal := range signals {
+			stream.Add(signal)
+		}
+		for i := 0; i < 500; i++ {
+			span := randomRef(rng, start, 5*time.Second)
+			want := c.EnrichAttributes(nil, span, signals)
+			got := stream.Enrich(nil, span)
+			if !reflect.DeepEqual(got.Attributes, want.Attributes) || !reflect.DeepEqual(got.Candidates, want.Candidates) {
+				t.Fatalf("span %+v: stream %+v, scan %+v", span, got.Candidates, want.Candidates)
+			}
+			if got.Debug.FanoutDropped != want.Debug.FanoutDropped {
+				t.Fatalf("span %+v: fanout drops %d, want %d", span, got.Debug.FanoutDropped, want.Debug.FanoutDropped)
+			}
+		}
+	}
+}
+
+func TestProcessBatchMatchesPairwiseScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	start := time.Unix(1700000000, 0)
+	c := New()
+	signals := make([]correlation.SignalRef, 3000)
+	for i := range signals {
+		signals[i] = randomSignal(rng, start, 20*time.Second)
+	}
+	spans := make([]SpanRecord, 300)
+	for i := range spans {
+		ref := randomRef(rng, start, 20*time.Second)
+		spans[i] = SpanRecord{
+			SpanID:     fmt.Sprintf("span-%d", i),
+			TraceID:    ref.TraceID,
+			Service:    ref.Service,
+			Node:       ref.Node,
+			Pod:        ref.Pod,
+			PID:        ref.PID,
+			ConnTuple:  ref.ConnTuple,
+			Timestamp:  ref.Timestamp,
+			Attributes: map[string]float64{"base": 1},
+		}
+	}
+
+	out := c.ProcessBatch(spans, signals)
+	for i, span := range out.Spans {
+		if span.SpanID != spans[i].SpanID {
+			t.Fatalf("span %d: got %s, spans should keep their order", i, span.SpanID)
+		}
+		want := c.EnrichAttributes(spans[i].Attributes, spans[i].spanRef(), signals)
+		DecomposeRetrieval(want.Attributes)
+		if !reflect.DeepEqual(span.Attributes, want.Attributes) {
+			t.Fatalf("span %d: attributes %v, want %v", i, span.Attributes, want.Attributes)
+		}
+	}
+}
+
+func TestStreamEvictsBehindWatermark(t *testing.T) {
+	c := New()
+	stream := c.NewStream(StreamConfig{BucketWidth: 100 * time.Millisecond, Lateness: time.Second, Retention: 4 * time.Second})
+	start := time.Unix(1700000000, 0)
+	signal := func(ts time.Time) correlation.SignalRef {
+		return correlation.SignalRef{Signal: "dns_latency_ms", TraceID: "trace-1", Timestamp: ts, Value: 42}
+	}
+
+	if !stream.Add(signal(start)) || !stream.Add(signal(start.Add(3*time.Second))) {
+		t.Fatal("in-order signals should be indexed")
+	}
+	// 1.5s late, within Lateness plus Retention.
+	if !stream.Add(signal(start.Add(1500 * time.Millisecond))) {
+		t.Fatal("a late signal within retention should be indexed")
+	}
+	if got := stream.Watermark(); !got.Equal(start.Add(2 * time.Second)) {
+		t.Fatalf("watermark %v, want newest less lateness", got)
+	}
+
+	stream.Add(signal(start.Add(6 * time.Second)))
+	stats := stream.Stats()
+	if stats.Evicted != 1 || stream.Len() != 3 {
+		t.Fatalf("the first signal should age out, got %+v with %d indexed", stats, stream.Len())
+	}
+	if stream.Add(signal(start)) || stream.Stats().Late != 1 {
+		t.Fatalf("a signal behind retention should be dropped as late, got %+v", stream.Stats())
+	}
+	if stream.Add(correlation.SignalRef{Signal: "unknown", TraceID: "trace-1", Timestamp: start.Add(6 * time.Second)}) {
+		t.Fatal("an unsupported signal should not be indexed")
+	}
+
+	result := stream.Enrich(nil, correlation.SpanRef{TraceID: "trace-1", Timestamp: start.Add(-time.Second)})
+	if len(result.Candidates) != 0 {
+		t.Fatalf("evicted signals should not join, got %+v", result.Candidates)
+	}
+	result = stream.Enrich(nil, correlation.SpanRef{TraceID: "trace-1", Timestamp: start.Add(2 * time.Second)})
+	if len(result.Candidates) != 2 || result.Attributes[semconv.AttrDNSLatencyMS] != 42 {
+		t.Fatalf("expected two indexed candidates, got %+v", result.Candidates)
+	}
+
+	// A jump past the whole ring evicts everything behind it.
+	stream.Add(signal(start.Add(time.Minute)))
+	if stream.Len() != 1 {
+		t.Fatalf("expected only the newest signal indexed, got %d", stream.Len())
+	}
+}
+
+func TestStreamBoundsSignals(t *testing.T) {
+	c := New()
+	stream := c.NewStream(StreamConfig{BucketWidth: 100 * time.Millisecond, MaxSignals: 10})
+	start := time.Unix(1700000000, 0)
+	for i := 0; i < 30; i++ {
+		stream.Add(correlation.SignalRef{
+			Signal:    "dns_latency_ms",
+			TraceID:   "trace-1",
+			Timestamp: start.Add(time.Duration(i/5) * 100 * time.Millisecond),
+		})
+	}
+	stats := stream.Stats()
+	if stream.Len() > 10 || stats.Overflow != 20 || stats.Added != 30 {
+		t.Fatalf("expected the oldest buckets to overflow, got %+v with %d indexed", stats, stream.Len())
+	}
+	if stream.Add(correlation.SignalRef{Signal: "dns_latency_ms", TraceID: "trace-1", Timestamp: start}) {
+		t.Fatal("a signal in an overflowed bucket should be dropped as late")
+	}
+}
+
+func TestStreamHoldsSpansForLateSignals(t *testing.T) {
+	c := New()
+	stream := c.NewStream(StreamConfig{Lateness: time.Second})
+	start := time.Unix(1700000000, 0)
+	span := SpanRecord{SpanID: "s1", TraceID: "trace-1", Timestamp: start}
+	stream.Submit(span)
+	stream.Submit(SpanRecord{SpanID: "s2", TraceID: "trace-2", Timestamp: start.Add(time.Second)})
+
+	stream.Add(correlation.SignalRef{Signal: "dns_latency_ms", TraceID: "trace-1", Timestamp: start.Add(2 * time.Second), Value: 5})
+	if out := stream.Drain(); len(out.Spans) != 0 {
+		t.Fatalf("spans should wait for the watermark, got %+v", out.Spans)
+	}
+	// Arrives after newer signals, still within Lateness.
+	stream.Add(correlation.SignalRef{Signal: "connect_latency_ms", TraceID: "trace-1", Timestamp: start.Add(3500 * time.Millisecond)})
+	stream.Add(correlation.SignalRef{Signal: "tls_handshake_ms", TraceID: "trace-1", Timestamp: start.Add(time.Second), Value: 7})
+
+	out := stream.Drain()
+	if len(out.Spans) != 1 || out.Spans[0].SpanID != "s1" {
+		t.Fatalf("expected s1 released once the watermark passed its window, got %+v", out.Spans)
+	}
+	attrs := out.Spans[0].Attributes
+	if attrs[semconv.AttrDNSLatencyMS] != 5 || attrs[semconv.AttrTLSHandshakeMS] != 7 || attrs[semconv.AttrRetrievalKernelMS] != 12 {
+		t.Fatalf("expected the late signal to join, got %v", attrs)
+	}
+
+	if out := stream.Flush(); len(out.Spans) != 1 || out.Spans[0].SpanID != "s2" {
+		t.Fatalf("flush should release the remaining span, got %+v", out.Spans)
+	}
+}
+
+func TestStreamReleasesSpansBeyondMaxPending(t *testing.T) {
+	stream := New().NewStream(StreamConfig{MaxPending: 2})
+	start := time.Unix(1700000000, 0)
+	for i := 0; i < 3; i++ {
+		stream.Submit(SpanRecord{SpanID: fmt.Sprint(i), Timestamp: start.Add(time.Duration(i) * time.Second)})
+	}
+	out := stream.Drain()
+	if len(out.Spans) != 1 || out.Spans[0].SpanID != "0" || stream.Stats().EarlyReleased != 1 {
+		t.Fatalf("expected the oldest span released early, got %+v", out.Spans)
+	}
+}
+
+// benchRate is the signal rate the stream benchmarks model.
+const benchRate = 100_000
+
+// benchSignals returns n signals at benchRate across 500 pods on 20
+// nodes, a third of them carrying a trace ID.
+func benchSignals(rng *rand.Rand, start time.Time, n int) []correlation.SignalRef {
+	signals := make([]correlation.SignalRef, n)
+	step := time.Second / benchRate
+	for i := range signals {
+		pod := rng.Intn(500)
+		signals[i] = correlation.SignalRef{
+			Signal:    streamSignals[rng.Intn(len(streamSignals))],
+			Service:   fmt.Sprintf("svc-%d", pod%25),
+			Node:      fmt.Sprintf("node-%d", pod%20),
+			Pod:       fmt.Sprintf("pod-%d", pod),
+			PID:       1000 + rng.Intn(4),
+			ConnTuple: fmt.Sprintf("conn-%d", rng.Intn(64)),
+			Timestamp: start.Add(time.Duration(i) * step),
+			Value:     float64(rng.Intn(100)),
+		}
+		if i%3 == 0 {
+			signals[i].TraceID = fmt.Sprintf("trace-%d", rng.Intn(50_000))
+		}
+	}
+	return signals
+}
+
+func benchSpan(signal correlation.SignalRef) correlation.SpanRef {
+	return correlation.SpanRef{
+		TraceID:   signal.TraceID,
+		Service:   signal.Service,
+		Node:      signal.Node,
+		Pod:       signal.Pod,
+		PID:       signal.PID,
+		ConnTuple: signal.ConnTuple,
+		Timestamp: signal.Timestamp,
+	}
+}
+
+func BenchmarkStreamAdd(b *testing.B) {
+	start := time.Unix(1700000000, 0)
+	signals := benchSignals(rand.New(rand.NewSource(1)), start, 10*benchRate)
+	stream := New().NewStream(DefaultStreamConfig())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		signal := signals[i%len(signals)]
+		// Keep time moving forward across passes over the fixture.
+		signal.Timestamp = signal.Timestamp.Add(time.Duration(i/len(signals)) * 10 * time.Second)
+		stream.Add(signal)
+	}
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "signals/s")
+}
+
+// BenchmarkStreamEnrich joins spans against a steady state of four
+// seconds of signals at 100k/s.
+func BenchmarkStreamEnrich(b *testing.B) {
+	start := time.Unix(1700000000, 0)
+	rng := rand.New(rand.NewSource(2))
+	signals := benchSignals(rng, start, 4*benchRate)
+	stream := New().NewStream(DefaultStreamConfig())
+	for _, signal := range signals {
+		stream.Add(signal)
+	}
+	spans := make([]correlation.SpanRef, 1024)
+	for i := range spans {
+		spans[i] = benchSpan(signals[benchRate+rng.Intn(2*benchRate)])
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stream.Enrich(nil, spans[i%len(spans)])
+	}
+}
+
+// BenchmarkEnrichAttributesScan is the pairwise baseline for
+// BenchmarkStreamEnrich: one span against one second of signals.
+func BenchmarkEnrichAttributesScan(b *testing.B) {
+	start := time.Unix(1700000000, 0)
+	rng := rand.New(rand.NewSource(2))
+	signals := benchSignals(rng, start, benchRate)
+	c := New()
+	span := benchSpan(signals[benchRate/2])
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.EnrichAttributes(nil, span, signals)
+	}
+}
+
+// BenchmarkStreamSustained feeds signals at 100k/s of event time with
+// one span enriched per hundred signals, and reports the sustained rate.
+func BenchmarkStreamSustained(b *testing.B) {
+	start := time.Unix(1700000000, 0)
+	signals := benchSignals(rand.New(rand.NewSource(3)), start, 10*benchRate)
+	stream := New().NewStream(DefaultStreamConfig())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		signal := signals[i%len(signals)]
+		signal.Timestamp = signal.Timestamp.Add(time.Duration(i/len(signals)) * 10 * time.Second)
+		stream.Add(signal)
+		if i%100 == 0 {
+			stream.Enrich(nil, benchSpan(signal))
+		}
+	}
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "signals/s")
+}
@@ -11,6 +11,22 @@ import (
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
 )
 
+// StatusError is a non-2xx response from an OTLP/HTTP endpoint.
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("otlp endpoint returned status %d", e.Code)
+}
+
+// Retryable reports whether resending the same payload may succeed.
+// Timeouts, throttling and server errors may clear; other client errors
+// reject the payload itself.
+func (e *StatusError) Retryable() bool {
+	return e.Code == http.StatusRequestTimeout || e.Code == http.StatusTooManyRequests || e.Code >= 500
+}
+
 // SLOEventExporter sends normalized SLO events to an OTLP/HTTP logs endpoint.
 type SLOEventExporter struct {
 	endpoint    string
@@ -71,7 +87,7 @@ func (e *SLOEventExporter) ExportBatch(events []schema.SLOEvent) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("otlp endpoint returned status %d", resp.StatusCode)
+		return &StatusError{Code: resp.StatusCode}
 	}
 	return nil
 }
@@ -2,6 +2,7 @@ package otel
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -68,7 +69,11 @@ func TestSLOEventExporterNon2xx(t *testing.T) {
 
 	exporter := NewSLOEventExporter(server.URL, "", "", 2*time.Second)
 	err := exporter.ExportBatch([]schema.SLOEvent{{EventID: "ev-1", SLIName: "ttft_ms"}})
-	if err == nil {
-		t.Fatal("expected non-2xx error")
+	var status *StatusError
+	if !errors.As(err, &status) || status.Code != http.StatusBadGateway || !status.Retryable() {
+		t.Fatalf("expected a retryable 502, got %v", err)
+	}
+	if (&StatusError{Code: http.StatusBadRequest}).Retryable() {
+		t.Fatal("a 400 rejects the payload and should not be retried")
 	}
 }
@@ -0,0 +1,128 @@
+package output
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/spool"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/toolkitcfg"
+)
+
+// BuildOptions carries process-level settings applied to configured sinks.
+type BuildOptions struct {
+	// ScopeName is the OTLP instrumentation scope.
+	ScopeName string
+	// Envelope wraps JSON lines as {"kind", "payload"}.
+	Envelope bool
+	// Blocking applies backpressure instead of dropping on full queues.
+	Blocking bool
+	// Spool, when enabled, gives each otlp sink its own spool under
+	// Spool.Dir/<sink name>. jsonl and stdout sinks are not spooled.
+	Spool toolkitcfg.SpoolConfig
+}
+
+// Build opens every configured sink and attaches it to a new fan-out. On
+// error any sinks already opened are closed.
+func Build(configs []toolkitcfg.OutputConfig, opts BuildOptions) (*FanOut, error) {
+	f := NewFanOut()
+	f.SetBlocking(opts.Blocking)
+	for i, cfg := range configs {
+		if cfg.Name == "" {
+			cfg.Name = fmt.Sprintf("%s-%d", cfg.Type, i)
+		}
+		sink, sinkOpts, err := open(cfg, opts)
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("open output %s: %w", cfg.Name, err)
+		}
+		f.Add(sink, sinkOpts)
+	}
+	return f, nil
+}
+
+func open(cfg toolkitcfg.OutputConfig, opts BuildOptions) (Sink, SinkOptions, error) {
+	sinkOpts := SinkOptions{
+		Name:          cfg.Name,
+		BatchSize:     cfg.BatchSize,
+		FlushInterval: time.Duration(cfg.FlushIntervalMS) * time.Millisecond,
+		QueueSize:     cfg.QueueSize,
+	}
+	for _, raw := range cfg.Kinds {
+		kind, err := ParseKind(raw)
+		if err != nil {
+			return nil, sinkOpts, err
+		}
+		sinkOpts.Kinds = append(sinkOpts.Kinds, kind)
+	}
+	if len(sinkOpts.Kinds) == 0 {
+		// Incidents go to the webhook unless a sink asks for them.
+		sinkOpts.Kinds = []Kind{KindSLO, KindProbe}
+	}
+
+	var sink Sink
+	switch cfg.Type {
+	case "stdout":
+		sink = NewJSONSink(os.Stdout, opts.Envelope)
+	case "jsonl":
+		if cfg.Path == "" {
+			return nil, sinkOpts, fmt.Errorf("jsonl output requires a path")
+		}
+		rotate := RotateOptions{
+			Path:        cfg.Path,
+			Interval:    time.Duration(cfg.RotateIntervalSeconds) * time.Second,
+			Compression: cfg.Compression,
+			Truncate:    cfg.Truncate,
+		}
+		if cfg.MaxBytes != nil {
+			rotate.MaxBytes = *cfg.MaxBytes
+		}
+		if cfg.MaxFiles != nil {
+			rotate.MaxFiles = *cfg.MaxFiles
+		}
+		jsonl, err := OpenJSONLSink(rotate, opts.Envelope)
+		if err != nil {
+			return nil, sinkOpts, err
+		}
+		sink = jsonl
+	case "otlp":
+		if cfg.Endpoint == "" {
+			return nil, sinkOpts, fmt.Errorf("otlp output requires an endpoint")
+		}
+		if slices.Contains(sinkOpts.Kinds, KindIncident) {
+			return nil, sinkOpts, fmt.Errorf("otlp output does not support %s batches", KindIncident)
+		}
+		timeout := time.Duration(cfg.TimeoutMS) * time.Millisecond
+		sink = NewOTLPSink(cfg.Endpoint, opts.ScopeName, timeout)
+		sp, err := OpenSpool(opts.Spool, cfg.Name)
+		if err != nil {
+			log.Printf("output %s: spool disabled: %v", cfg.Name, err)
+		}
+		sinkOpts.Spool = sp
+		sinkOpts.ReplayInterval = time.Duration(opts.Spool.ReplayIntervalMS) * time.Millisecond
+	default:
+		return nil, sinkOpts, fmt.Errorf("unsupported output type %q (expected stdout|jsonl|otlp)", cfg.Type)
+	}
+	return sink, sinkOpts, nil
+}
+
+// OpenSpool opens the per-sink spool directory, or returns nil when
+// spooling is disabled.
+func OpenSpool(cfg toolkitcfg.SpoolConfig, sinkName string) (*spool.Spool, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	sp, err := spool.Open(spool.Options{
+		Dir:          filepath.Join(cfg.Dir, sinkName),
+		MaxBytes:     cfg.MaxBytes,
+		SegmentBytes: cfg.SegmentBytes,
+		MaxAge:       time.Duration(cfg.MaxAgeSeconds) * time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open spool: %w", err)
+	}
+	return sp, nil
+}
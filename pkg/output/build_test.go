@@ -0,0 +1,59 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/spool"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/toolkitcfg"
+)
+
+func TestBuildRejectsIncidentsOnOTLP(t *testing.T) {
+	_, err := Build([]toolkitcfg.OutputConfig{{
+		Name:     "central",
+		Type:     "otlp",
+		Endpoint: "http://127.0.0.1:1/v1/logs",
+		Kinds:    []string{"slo", "incident"},
+	}}, BuildOptions{})
+	if err == nil || !strings.Contains(err.Error(), "does not support incident") {
+		t.Fatalf("expected otlp with incidents to fail, got %v", err)
+	}
+}
+
+func TestUnsupportedBatchIsNotSpooled(t *testing.T) {
+	incident := Batch{Kind: KindIncident, Incidents: []schema.IncidentAttribution{{IncidentID: "inc-1"}}}
+	if err := NewOTLPSink("http://127.0.0.1:1/v1/logs", "test", time.Second).Write(incident); err == nil || retryable(err) {
+		t.Fatalf("expected a permanent otlp error, got %v", err)
+	}
+	if err := NewWebhookSink(nil).Write(sloBatch("a")); err == nil || retryable(err) {
+		t.Fatalf("expected a permanent webhook error, got %v", err)
+	}
+
+	sp, err := spool.Open(spool.Options{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("open spool: %v", err)
+	}
+	f := NewFanOut()
+	f.Add(NewOTLPSink("http://127.0.0.1:1/v1/logs", "test", time.Second), SinkOptions{
+		Name:          "central",
+		BatchSize:     1,
+		FlushInterval: 10 * time.Millisecond,
+		Spool:         sp,
+	})
+	if err := f.Emit(incident); err != nil {
+		t.Fatalf("emit: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for f.Stats()[0].Failed < 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the incident batch to fail, stats=%+v", f.Stats()[0])
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if stats := f.Stats()[0]; stats.Spooled != 0 || sp.Pending() {
+		t.Fatalf("an unsupported batch must not block the spool, stats=%+v", stats)
+	}
+	_ = f.Close()
+}
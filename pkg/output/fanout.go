@@ -0,0 +1,378 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/spool"
+)
+
+const (
+	defaultBatchSize      = 64
+	defaultFlushInterval  = time.Second
+	defaultQueueSize      = 1024
+	defaultReplayInterval = 2 * time.Second
+)
+
+// ErrQueueFull reports that at least one sink dropped a batch because its
+// queue was full.
+var ErrQueueFull = errors.New("output queue full")
+
+// SinkOptions controls routing and delivery for one sink.
+type SinkOptions struct {
+	Name           string
+	Kinds          []Kind
+	BatchSize      int
+	FlushInterval  time.Duration
+	QueueSize      int
+	Spool          *spool.Spool
+	ReplayInterval time.Duration
+}
+
+// SinkStats are lifetime event counters for one sink.
+type SinkStats struct {
+	Name    string
+	Sent    uint64
+	Failed  uint64
+	Dropped uint64
+	Spooled uint64
+	Spool   *spool.Stats
+}
+
+// FanOut routes batches to every sink whose kind filter matches. Each sink
+// runs on its own goroutine behind a bounded queue, so a slow or failing
+// sink does not block emitters or other sinks.
+type FanOut struct {
+	mu       sync.RWMutex
+	closed   bool
+	blocking bool
+	workers  []*worker
+}
+
+// NewFanOut returns an empty fan-out; attach sinks with Add.
+func NewFanOut() *FanOut {
+	return &FanOut{}
+}
+
+// SetBlocking makes Emit wait for queue space instead of dropping. Batch
+// tools use this to apply backpressure; long-running agents should not.
+func (f *FanOut) SetBlocking(blocking bool) {
+	f.mu.Lock()
+	f.blocking = blocking
+	f.mu.Unlock()
+}
+
+// Add attaches a sink and starts its delivery goroutine. The fan-out owns
+// the sink and its spool from this point on.
+func (f *FanOut) Add(sink Sink, opts SinkOptions) {
+	w := newWorker(sink, opts)
+	f.mu.Lock()
+	f.workers = append(f.workers, w)
+	f.mu.Unlock()
+	go w.run()
+}
+
+// Len returns the number of attached sinks.
+func (f *FanOut) Len() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return len(f.workers)
+}
+
+// Accepts reports whether any sink routes the given kind.
+func (f *FanOut) Accepts(kind Kind) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for _, w := range f.workers {
+		if w.accepts(kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// Emit enqueues a batch on every matching sink. Unless blocking is set, a
+// full queue drops the batch for that sink only.
+func (f *FanOut) Emit(b Batch) error {
+	if b.Len() == 0 {
+		return nil
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.closed {
+		return errors.New("output closed")
+	}
+
+	var dropped []string
+	for _, w := range f.workers {
+		if !w.accepts(b.Kind) {
+			continue
+		}
+		if f.blocking {
+			w.queue <- b
+			continue
+		}
+		select {
+		case w.queue <- b:
+		default:
+			w.dropped.Add(uint64(b.Len()))
+			dropped = append(dropped, w.name)
+		}
+	}
+	if len(dropped) > 0 {
+		return fmt.Errorf("%w: %s", ErrQueueFull, strings.Join(dropped, ","))
+	}
+	return nil
+}
+
+// Stats returns per-sink counters in attach order.
+func (f *FanOut) Stats() []SinkStats {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	out := make([]SinkStats, 0, len(f.workers))
+	for _, w := range f.workers {
+		out = append(out, w.stats())
+	}
+	return out
+}
+
+// Close drains every queue, flushes pending batches and closes all sinks.
+// It returns an error when any sink failed to deliver events that were
+// not spooled.
+func (f *FanOut) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+	f.closed = true
+	workers := f.workers
+	f.mu.Unlock()
+
+	for _, w := range workers {
+		close(w.queue)
+	}
+	var errs []error
+	for _, w := range workers {
+		<-w.done
+		if err := w.sink.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close sink %s: %w", w.name, err))
+		}
+		if w.spool != nil {
+			if err := w.spool.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("close spool %s: %w", w.name, err))
+			}
+		}
+		if failed := w.failed.Load(); failed > 0 {
+			errs = append(errs, fmt.Errorf("sink %s: %d events failed delivery", w.name, failed))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type worker struct {
+	name           string
+	sink           Sink
+	kinds          map[Kind]struct{}
+	queue          chan Batch
+	batchSize      int
+	flushInterval  time.Duration
+	spool          *spool.Spool
+	replayInterval time.Duration
+	lastReplay     time.Time
+	pending        map[Kind]*Batch
+	done           chan struct{}
+
+	sent    atomic.Uint64
+	failed  atomic.Uint64
+	dropped atomic.Uint64
+	spooled atomic.Uint64
+}
+
+func newWorker(sink Sink, opts SinkOptions) *worker {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultFlushInterval
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultQueueSize
+	}
+	if opts.ReplayInterval <= 0 {
+		opts.ReplayInterval = defaultReplayInterval
+	}
+	kinds := make(map[Kind]struct{}, len(opts.Kinds))
+	for _, k := range opts.Kinds {
+		kinds[k] = struct{}{}
+	}
+	return &worker{
+		name:           opts.Name,
+		sink:           sink,
+		kinds:          kinds,
+		queue:          make(chan Batch, opts.QueueSize),
+		batchSize:      opts.BatchSize,
+		flushInterval:  opts.FlushInterval,
+		spool:          opts.Spool,
+		replayInterval: opts.ReplayInterval,
+		pending:        make(map[Kind]*Batch),
+		done:           make(chan struct{}),
+	}
+}
+
+// accepts treats an empty filter as "all kinds".
+func (w *worker) accepts(kind Kind) bool {
+	if len(w.kinds) == 0 {
+		return true
+	}
+	_, ok := w.kinds[kind]
+	return ok
+}
+
+func (w *worker) stats() SinkStats {
+	s := SinkStats{
+		Name:    w.name,
+		Sent:    w.sent.Load(),
+		Failed:  w.failed.Load(),
+		Dropped: w.dropped.Load(),
+		Spooled: w.spooled.Load(),
+	}
+	if w.spool != nil {
+		st := w.spool.Stats()
+		s.Spool = &st
+	}
+	return s
+}
+
+func (w *worker) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case b, ok := <-w.queue:
+			if !ok {
+				w.replay()
+				w.flushAll()
+				return
+			}
+			w.buffer(b)
+		case now := <-ticker.C:
+			if now.Sub(w.lastReplay) >= w.replayInterval {
+				w.lastReplay = now
+				w.replay()
+			}
+			w.flushAll()
+		}
+	}
+}
+
+func (w *worker) buffer(b Batch) {
+	pending, ok := w.pending[b.Kind]
+	if !ok {
+		pending = &Batch{Kind: b.Kind}
+		w.pending[b.Kind] = pending
+	}
+	pending.merge(b)
+	if pending.Len() >= w.batchSize {
+		w.flush(b.Kind)
+	}
+}
+
+func (w *worker) flushAll() {
+	for _, kind := range []Kind{KindSLO, KindProbe, KindIncident} {
+		w.flush(kind)
+	}
+}
+
+func (w *worker) flush(kind Kind) {
+	pending, ok := w.pending[kind]
+	if !ok || pending.Len() == 0 {
+		return
+	}
+	delete(w.pending, kind)
+	for _, b := range pending.byContract() {
+		w.deliver(b)
+	}
+}
+
+// deliver writes one batch. While a spool backlog exists new batches queue
+// behind it so replay preserves emit order. Batches the sink rejects
+// permanently are counted as failed rather than spooled.
+func (w *worker) deliver(b Batch) {
+	if w.spool != nil && w.spool.Pending() {
+		w.appendSpool(b)
+		return
+	}
+	err := w.sink.Write(b)
+	if err == nil {
+		w.sent.Add(uint64(b.Len()))
+		return
+	}
+	if w.spool == nil || !retryable(err) {
+		w.failed.Add(uint64(b.Len()))
+		log.Printf("output %s: %s batch failed: %v", w.name, b.Kind, err)
+		return
+	}
+	log.Printf("output %s: %s batch failed, spooling: %v", w.name, b.Kind, err)
+	w.appendSpool(b)
+}
+
+func (w *worker) appendSpool(b Batch) {
+	payload, err := json.Marshal(b)
+	if err == nil {
+		err = w.spool.Append(payload)
+	}
+	if err != nil {
+		w.failed.Add(uint64(b.Len()))
+		log.Printf("output %s: spool %s batch: %v", w.name, b.Kind, err)
+		return
+	}
+	w.spooled.Add(uint64(b.Len()))
+}
+
+func (w *worker) replay() {
+	if w.spool == nil || !w.spool.Pending() {
+		return
+	}
+	result, err := w.spool.Replay(func(payload []byte) error {
+		var b Batch
+		if err := json.Unmarshal(payload, &b); err != nil {
+			return fmt.Errorf("%w: %v", spool.ErrUndeliverable, err)
+		}
+		if err := w.sink.Write(b); err != nil {
+			if retryable(err) {
+				return err
+			}
+			w.failed.Add(uint64(b.Len()))
+			log.Printf("output %s: spooled %s batch rejected, dropping: %v", w.name, b.Kind, err)
+			return fmt.Errorf("%w: %v", spool.ErrUndeliverable, err)
+		}
+		w.sent.Add(uint64(b.Len()))
+		return nil
+	})
+	if result.Replayed > 0 || result.Expired > 0 || result.Corrupt > 0 || result.Dropped > 0 {
+		log.Printf("output %s: spool replay: replayed=%d expired=%d corrupt=%d dropped=%d", w.name, result.Replayed, result.Expired, result.Corrupt, result.Dropped)
+	}
+	if err != nil {
+		log.Printf("output %s: spool replay paused: %v", w.name, err)
+	}
+}
+
+// retryable reports whether a sink error may clear on a later attempt.
+// Sinks mark permanent failures, such as an endpoint rejecting the payload,
+// with a Retryable method; anything else is assumed transient.
+func retryable(err error) bool {
+	var r interface{ Retryable() bool }
+	if errors.As(err, &r) {
+		return r.Retryable()
+	}
+	return true
+}
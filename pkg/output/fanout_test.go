@@ -0,0 +1,398 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/spool"
+)
+
+type recordingSink struct {
+	mu      sync.Mutex
+	batches []Batch
+	fail    bool
+	// reject names an SLO request ID the sink refuses permanently.
+	reject string
+}
+
+// rejectedError is a permanent sink failure, like an HTTP 400.
+type rejectedError struct{}
+
+func (rejectedError) Error() string   { return "payload rejected" }
+func (rejectedError) Retryable() bool { return false }
+
+func (s *recordingSink) Write(b Batch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return errors.New("endpoint down")
+	}
+	for _, ev := range b.SLO {
+		if s.reject != "" && ev.RequestID == s.reject {
+			return rejectedError{}
+		}
+	}
+	s.batches = append(s.batches, b)
+	return nil
+}
+
+func (s *recordingSink) Close() error { return nil }
+
+func (s *recordingSink) setFail(fail bool) {
+	s.mu.Lock()
+	s.fail = fail
+	s.mu.Unlock()
+}
+
+func (s *recordingSink) events() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	total := 0
+	for _, b := range s.batches {
+		total += b.Len()
+	}
+	return total
+}
+
+func sloBatch(requestID string) Batch {
+	return Batch{Kind: KindSLO, SLO: []schema.SLOEvent{{RequestID: requestID}}}
+}
+
+func probeBatch(signal string) Batch {
+	return Batch{Kind: KindProbe, Probe: []schema.ProbeEventV1{{Signal: signal}}}
+}
+
+func TestFanOutRoutesByKind(t *testing.T) {
+	sloSink := &recordingSink{}
+	probeSink := &recordingSink{}
+	f := NewFanOut()
+	f.Add(sloSink, SinkOptions{Name: "slo", Kinds: []Kind{KindSLO}})
+	f.Add(probeSink, SinkOptions{Name: "probe", Kinds: []Kind{KindProbe}})
+
+	for i := 0; i < 3; i++ {
+		if err := f.Emit(sloBatch("r")); err != nil {
+			t.Fatalf("emit slo: %v", err)
+		}
+	}
+	if err := f.Emit(probeBatch("dns_latency_ms")); err != nil {
+		t.Fatalf("emit probe: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if sloSink.events() != 3 || probeSink.events() != 1 {
+		t.Fatalf("unexpected routing: slo=%d probe=%d", sloSink.events(), probeSink.events())
+	}
+	for _, b := range sloSink.batches {
+		if b.Kind != KindSLO {
+			t.Fatalf("slo sink received %s batch", b.Kind)
+		}
+	}
+}
+
+func TestFanOutBatchesBySize(t *testing.T) {
+	sink := &recordingSink{}
+	f := NewFanOut()
+	f.Add(sink, SinkOptions{Name: "batched", BatchSize: 4, FlushInterval: time.Hour})
+
+	for i := 0; i < 10; i++ {
+		if err := f.Emit(sloBatch("r")); err != nil {
+			t.Fatalf("emit: %v", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	sizes := make([]int, 0, len(sink.batches))
+	for _, b := range sink.batches {
+		sizes = append(sizes, b.Len())
+	}
+	if len(sizes) != 3 || sizes[0] != 4 || sizes[1] != 4 || sizes[2] != 2 {
+		t.Fatalf("expected batches [4 4 2], got %v", sizes)
+	}
+}
+
+func TestFanOutIsolatesFailingSink(t *testing.T) {
+	healthy := &recordingSink{}
+	broken := &recordingSink{fail: true}
+	f := NewFanOut()
+	f.Add(broken, SinkOptions{Name: "broken", BatchSize: 1})
+	f.Add(healthy, SinkOptions{Name: "healthy", BatchSize: 1})
+
+	for i := 0; i < 5; i++ {
+		if err := f.Emit(sloBatch("r")); err != nil {
+			t.Fatalf("emit: %v", err)
+		}
+	}
+	err := f.Close()
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("expected close to report broken sink, got %v", err)
+	}
+	if healthy.events() != 5 {
+		t.Fatalf("healthy sink expected 5 events, got %d", healthy.events())
+	}
+
+	stats := f.Stats()
+	if stats[0].Failed != 5 || stats[1].Sent != 5 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestFanOutDropsWhenQueueFull(t *testing.T) {
+	block := make(chan struct{})
+	sink := &blockingSink{release: block}
+	f := NewFanOut()
+	f.Add(sink, SinkOptions{Name: "slow", BatchSize: 1, QueueSize: 1})
+
+	var dropErr error
+	for i := 0; i < 5 && dropErr == nil; i++ {
+		dropErr = f.Emit(sloBatch("r"))
+	}
+	close(block)
+	if !errors.Is(dropErr, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", dropErr)
+	}
+	_ = f.Close()
+	if f.Stats()[0].Dropped == 0 {
+		t.Fatal("expected dropped events to be counted")
+	}
+}
+
+type blockingSink struct {
+	release chan struct{}
+}
+
+func (s *blockingSink) Write(Batch) error {
+	<-s.release
+	return nil
+}
+
+func (s *blockingSink) Close() error { return nil }
+
+func TestFanOutSpoolsAndReplays(t *testing.T) {
+	dir := t.TempDir()
+	sp, err := spool.Open(spool.Options{Dir: dir})
+	if err != nil {
+		t.Fatalf("open spool: %v", err)
+	}
+	sink := &recordingSink{fail: true}
+	f := NewFanOut()
+	f.Add(sink, SinkOptions{
+		Name:           "otlp",
+		BatchSize:      1,
+		FlushInterval:  10 * time.Millisecond,
+		ReplayInterval: 10 * time.Millisecond,
+		Spool:          sp,
+	})
+
+	for _, id := range []string{"a", "b", "c"} {
+		if err := f.Emit(sloBatch(id)); err != nil {
+			t.Fatalf("emit: %v", err)
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for f.Stats()[0].Spooled < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 3 spooled events, stats=%+v", f.Stats()[0])
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	sink.setFail(false)
+	deadline = time.Now().Add(2 * time.Second)
+	for sink.events() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected replay after recovery, got %d events", sink.events())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	var order []string
+	for _, b := range sink.batches {
+		for _, ev := range b.SLO {
+			order = append(order, ev.RequestID)
+		}
+	}
+	if strings.Join(order, ",") != "a,b,c" {
+		t.Fatalf("expected replay in emit order, got %v", order)
+	}
+}
+
+func TestFanOutReplayDropsRejectedBatch(t *testing.T) {
+	sp, err := spool.Open(spool.Options{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("open spool: %v", err)
+	}
+	sink := &recordingSink{fail: true, reject: "b"}
+	f := NewFanOut()
+	f.Add(sink, SinkOptions{
+		Name:           "otlp",
+		BatchSize:      1,
+		FlushInterval:  10 * time.Millisecond,
+		ReplayInterval: 10 * time.Millisecond,
+		Spool:          sp,
+	})
+
+	for _, id := range []string{"a", "b", "c"} {
+		if err := f.Emit(sloBatch(id)); err != nil {
+			t.Fatalf("emit: %v", err)
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for f.Stats()[0].Spooled < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 3 spooled events, stats=%+v", f.Stats()[0])
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	sink.setFail(false)
+	deadline = time.Now().Add(2 * time.Second)
+	for sink.events() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected c to replay past the rejected batch, got %d events", sink.events())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := f.Close(); err == nil || !strings.Contains(err.Error(), "1 events failed") {
+		t.Fatalf("expected close to report the rejected batch, got %v", err)
+	}
+
+	stats := f.Stats()[0]
+	if stats.Failed != 1 || stats.Spool == nil || stats.Spool.Dropped != 1 {
+		t.Fatalf("expected one dropped spool record, stats=%+v spool=%+v", stats, stats.Spool)
+	}
+	var order []string
+	for _, b := range sink.batches {
+		for _, ev := range b.SLO {
+			order = append(order, ev.RequestID)
+		}
+	}
+	if strings.Join(order, ",") != "a,c" {
+		t.Fatalf("expected a,c delivered, got %v", order)
+	}
+}
+
+func TestJSONSinkEnvelope(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewJSONSink(&buf, true).Write(probeBatch("dns_latency_ms")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"kind":"probe"`) || !strings.Contains(buf.String(), `"payload"`) {
+		t.Fatalf("expected envelope, got %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := NewJSONSink(&buf, false).Write(sloBatch("req-1")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if strings.Contains(buf.String(), `"payload"`) {
+		t.Fatalf("expected bare event, got %s", buf.String())
+	}
+}
+
+func TestBatchCarriesV1Beta1Probes(t *testing.T) {
+	b := probeBatch("dns_latency_ms")
+	b.merge(Batch{Kind: KindProbe, ProbeV1Beta1: []schema.ProbeEventV1Beta1{
+		schema.UpgradeProbeEvent(schema.ProbeEventV1{Signal: "connect_latency_ms"}, schema.ProbeIdentity{Comm: "rag"}),
+	}})
+	if b.Len() != 2 {
+		t.Fatalf("expected 2 events, got %d", b.Len())
+	}
+
+	var buf bytes.Buffer
+	if err := NewJSONSink(&buf, false).Write(b); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"schema_version":"v1beta1"`) {
+		t.Fatalf("expected v1alpha1 then v1beta1 lines, got %s", buf.String())
+	}
+}
+
+// betaFailingSink exports v1alpha1 and then v1beta1 probes in separate
+// requests, like OTLPSink, and fails the second while failBeta is set.
+type betaFailingSink struct {
+	recordingSink
+	failBeta bool
+}
+
+func (s *betaFailingSink) Write(b Batch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(b.Probe) > 0 {
+		s.batches = append(s.batches, Batch{Kind: b.Kind, Probe: b.Probe})
+	}
+	if len(b.ProbeV1Beta1) == 0 {
+		return nil
+	}
+	if s.failBeta {
+		return errors.New("endpoint down")
+	}
+	s.batches = append(s.batches, Batch{Kind: b.Kind, ProbeV1Beta1: b.ProbeV1Beta1})
+	return nil
+}
+
+func (s *betaFailingSink) setFailBeta(fail bool) {
+	s.mu.Lock()
+	s.failBeta = fail
+	s.mu.Unlock()
+}
+
+func TestFanOutDeliversProbeContractsSeparately(t *testing.T) {
+	sp, err := spool.Open(spool.Options{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("open spool: %v", err)
+	}
+	sink := &betaFailingSink{failBeta: true}
+	f := NewFanOut()
+	f.Add(sink, SinkOptions{
+		Name:           "otlp",
+		FlushInterval:  10 * time.Millisecond,
+		ReplayInterval: 10 * time.Millisecond,
+		Spool:          sp,
+	})
+
+	mixed := probeBatch("dns_latency_ms")
+	mixed.merge(Batch{Kind: KindProbe, ProbeV1Beta1: []schema.ProbeEventV1Beta1{
+		schema.UpgradeProbeEvent(schema.ProbeEventV1{Signal: "connect_latency_ms"}, schema.ProbeIdentity{}),
+	}})
+	if err := f.Emit(mixed); err != nil {
+		t.Fatalf("emit: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for f.Stats()[0].Spooled < 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the v1beta1 half to spool, stats=%+v", f.Stats()[0])
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	sink.setFailBeta(false)
+	deadline = time.Now().Add(2 * time.Second)
+	for sink.events() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the v1beta1 half to replay, got %d events", sink.events())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if stats := f.Stats()[0]; stats.Sent != 2 || stats.Spooled != 1 {
+		t.Fatalf("expected v1alpha1 sent once and v1beta1 spooled once, stats=%+v", stats)
+	}
+	if sink.events() != 2 {
+		t.Fatalf("expected each probe exported once, got %+v", sink.batches)
+	}
+}
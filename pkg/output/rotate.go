@@ -0,0 +1,321 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression names accepted by RotateOptions.
+const (
+	CompressionNone = ""
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// RotateOptions bounds a rotating JSONL file. Zero values disable the
+// corresponding limit.
+type RotateOptions struct {
+	Path        string
+	MaxBytes    int64
+	Interval    time.Duration
+	MaxFiles    int
+	Compression string
+	// Truncate discards the active file on open instead of appending.
+	Truncate bool
+}
+
+// Manifest lists rotated segments of one output path, oldest first, so
+// offline tooling can select files by time range without scanning them.
+type Manifest struct {
+	Path     string            `json:"path"`
+	Active   ManifestSegment   `json:"active"`
+	Segments []ManifestSegment `json:"segments"`
+}
+
+// ManifestSegment describes one file and the wall-clock range of its writes.
+type ManifestSegment struct {
+	File        string    `json:"file"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Bytes       int64     `json:"bytes"`
+	Lines       int64     `json:"lines"`
+	Compression string    `json:"compression,omitempty"`
+}
+
+// RotatingFile is an append-only writer that rotates by size and age.
+// Each Write must carry whole lines; rotation happens between writes.
+type RotatingFile struct {
+	opts     RotateOptions
+	mu       sync.Mutex
+	file     *os.File
+	active   ManifestSegment
+	manifest Manifest
+	now      func() time.Time
+	rename   func(oldpath, newpath string) error
+	compress func(path, compression string) (string, error)
+}
+
+// ManifestPath returns the manifest location for an output path.
+func ManifestPath(path string) string {
+	return path + ".manifest.json"
+}
+
+// OpenRotatingFile opens path for append, resuming the active segment
+// recorded in the manifest when one exists.
+func OpenRotatingFile(opts RotateOptions) (*RotatingFile, error) {
+	switch opts.Compression {
+	case CompressionNone, CompressionGzip, CompressionZstd:
+	default:
+		return nil, fmt.Errorf("unsupported compression %q (expected gzip|zstd)", opts.Compression)
+	}
+	if err := os.MkdirAll(filepath.Dir(opts.Path), 0o755); err != nil {
+		return nil, err
+	}
+	r := &RotatingFile{
+		opts:     opts,
+		manifest: Manifest{Path: filepath.Base(opts.Path)},
+		now:      time.Now,
+		rename:   os.Rename,
+		compress: compressFile,
+	}
+	if data, err := os.ReadFile(ManifestPath(opts.Path)); err == nil {
+		if err := json.Unmarshal(data, &r.manifest); err != nil {
+			return nil, fmt.Errorf("parse manifest: %w", err)
+		}
+	}
+	if opts.Truncate {
+		r.manifest.Active = ManifestSegment{}
+		if err := os.Truncate(opts.Path, 0); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if err := r.openActive(); err != nil {
+		return nil, err
+	}
+	if err := r.writeManifest(); err != nil {
+		_ = r.file.Close()
+		return nil, fmt.Errorf("write manifest: %w", err)
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) openActive() error {
+	file, err := os.OpenFile(r.opts.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	r.file = file
+	r.active = ManifestSegment{File: filepath.Base(r.opts.Path), Bytes: info.Size()}
+	if info.Size() > 0 {
+		// Resume: keep the recorded start, or fall back to the file mtime.
+		r.active.Start = info.ModTime().UTC()
+		r.active.End = info.ModTime().UTC()
+		if !r.manifest.Active.Start.IsZero() && r.manifest.Active.Bytes <= info.Size() {
+			r.active.Start = r.manifest.Active.Start
+			r.active.Lines = r.manifest.Active.Lines
+		}
+	}
+	return nil
+}
+
+// Write appends p, rotating first when it would exceed a limit.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now().UTC()
+	if r.shouldRotate(now, int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			return 0, fmt.Errorf("rotate %s: %w", r.opts.Path, err)
+		}
+	}
+	n, err := r.file.Write(p)
+	if r.active.Start.IsZero() {
+		r.active.Start = now
+	}
+	r.active.End = now
+	r.active.Bytes += int64(n)
+	r.active.Lines += int64(bytes.Count(p[:n], []byte{'\n'}))
+	return n, err
+}
+
+func (r *RotatingFile) shouldRotate(now time.Time, incoming int64) bool {
+	if r.active.Bytes == 0 {
+		return false
+	}
+	if r.opts.MaxBytes > 0 && r.active.Bytes+incoming > r.opts.MaxBytes {
+		return true
+	}
+	return r.opts.Interval > 0 && now.Sub(r.active.Start) >= r.opts.Interval
+}
+
+// rotate seals the active file into a timestamped segment, compresses it,
+// prunes old segments and reopens an empty active file. The old file is
+// closed only once its replacement is open, so a failed rotation leaves a
+// writable active file: the old one when the rename or reopen fails, the
+// new one when closing or compressing the sealed segment fails. A segment
+// that failed to compress stays in the manifest uncompressed.
+func (r *RotatingFile) rotate() error {
+	sealed := r.active
+	sealed.File = r.segmentName(sealed.Start)
+	dir := filepath.Dir(r.opts.Path)
+	segment := filepath.Join(dir, sealed.File)
+	if err := r.rename(r.opts.Path, segment); err != nil {
+		return err
+	}
+	old := r.file
+	if err := r.openActive(); err != nil {
+		// Keep appending to the old file under its own name.
+		if undo := r.rename(segment, r.opts.Path); undo != nil {
+			return errors.Join(err, undo)
+		}
+		return err
+	}
+	var errs []error
+	if err := old.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	if r.opts.Compression != CompressionNone {
+		if name, err := r.compress(segment, r.opts.Compression); err != nil {
+			errs = append(errs, err)
+		} else {
+			sealed.File = name
+			sealed.Compression = r.opts.Compression
+		}
+	}
+	r.manifest.Segments = append(r.manifest.Segments, sealed)
+	r.prune()
+	if err := r.writeManifest(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// segmentName derives "<stem>-<start>.<ext>" and adds a counter on collision.
+func (r *RotatingFile) segmentName(start time.Time) string {
+	base := filepath.Base(r.opts.Path)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	stamp := start.UTC().Format("20060102T150405Z")
+	name := fmt.Sprintf("%s-%s%s", stem, stamp, ext)
+	for i := 1; r.segmentExists(name); i++ {
+		name = fmt.Sprintf("%s-%s.%d%s", stem, stamp, i, ext)
+	}
+	return name
+}
+
+func (r *RotatingFile) segmentExists(name string) bool {
+	dir := filepath.Dir(r.opts.Path)
+	for _, candidate := range []string{name, name + ".gz", name + ".zst"} {
+		if _, err := os.Stat(filepath.Join(dir, candidate)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RotatingFile) prune() {
+	if r.opts.MaxFiles <= 0 || len(r.manifest.Segments) <= r.opts.MaxFiles {
+		return
+	}
+	excess := len(r.manifest.Segments) - r.opts.MaxFiles
+	dir := filepath.Dir(r.opts.Path)
+	for _, seg := range r.manifest.Segments[:excess] {
+		_ = os.Remove(filepath.Join(dir, seg.File))
+	}
+	r.manifest.Segments = append([]ManifestSegment(nil), r.manifest.Segments[excess:]...)
+}
+
+func (r *RotatingFile) writeManifest() error {
+	r.manifest.Active = r.active
+	data, err := json.MarshalIndent(r.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := ManifestPath(r.opts.Path)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Manifest returns a copy of the current manifest, including the active file.
+func (r *RotatingFile) Manifest() Manifest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := r.manifest
+	m.Active = r.active
+	m.Segments = append([]ManifestSegment(nil), r.manifest.Segments...)
+	return m
+}
+
+// Close closes the active file and records it in the manifest.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	return r.writeManifest()
+}
+
+// compressFile replaces path with a compressed copy and returns its base name.
+func compressFile(path string, compression string) (string, error) {
+	suffix := ".gz"
+	if compression == CompressionZstd {
+		suffix = ".zst"
+	}
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	dst, err := os.Create(path + suffix)
+	if err != nil {
+		return "", err
+	}
+
+	var enc io.WriteCloser
+	if compression == CompressionZstd {
+		enc, err = zstd.NewWriter(dst)
+		if err != nil {
+			_ = dst.Close()
+			return "", err
+		}
+	} else {
+		enc = gzip.NewWriter(dst)
+	}
+	if _, err := io.Copy(enc, src); err != nil {
+		_ = enc.Close()
+		_ = dst.Close()
+		return "", fmt.Errorf("compress %s: %w", path, err)
+	}
+	if err := enc.Close(); err != nil {
+		_ = dst.Close()
+		return "", err
+	}
+	if err := dst.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Remove(path); err != nil {
+		return "", err
+	}
+	return filepath.Base(path) + suffix, nil
+}
@@ -0,0 +1,265 @@
+package output
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func writeLines(t *testing.T, r *RotatingFile, n int, line string) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if _, err := r.Write([]byte(line + "\n")); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+}
+
+func TestRotatingFileRotatesBySizeAndPrunes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	r, err := OpenRotatingFile(RotateOptions{Path: path, MaxBytes: 20, MaxFiles: 2})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tick := 0
+	r.now = func() time.Time { tick++; return base.Add(time.Duration(tick) * time.Second) }
+
+	// 10-byte lines: two per segment.
+	writeLines(t, r, 9, "123456789")
+	if err := r.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	m := r.Manifest()
+	if len(m.Segments) != 2 {
+		t.Fatalf("expected 2 retained segments, got %d", len(m.Segments))
+	}
+	for _, seg := range m.Segments {
+		if seg.Lines != 2 || seg.Bytes != 20 {
+			t.Fatalf("unexpected segment: %+v", seg)
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(path), seg.File)); err != nil {
+			t.Fatalf("segment file missing: %v", err)
+		}
+		if !seg.End.After(seg.Start) {
+			t.Fatalf("expected increasing time range: %+v", seg)
+		}
+	}
+	if !m.Segments[0].End.Before(m.Segments[1].Start) {
+		t.Fatalf("segments out of order: %+v", m.Segments)
+	}
+	if m.Active.Lines != 1 {
+		t.Fatalf("expected 1 line in active file, got %+v", m.Active)
+	}
+	entries, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "events-*.jsonl"))
+	if len(entries) != 2 {
+		t.Fatalf("expected pruned directory with 2 segments, got %v", entries)
+	}
+}
+
+func TestRotatingFileRotatesByInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	r, err := OpenRotatingFile(RotateOptions{Path: path, Interval: time.Minute})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	writeLines(t, r, 3, "a")
+	now = now.Add(61 * time.Second)
+	writeLines(t, r, 1, "b")
+	defer r.Close()
+
+	m := r.Manifest()
+	if len(m.Segments) != 1 || m.Segments[0].Lines != 3 {
+		t.Fatalf("expected one sealed segment of 3 lines, got %+v", m.Segments)
+	}
+	if m.Segments[0].File != "events-20260301T120000Z.jsonl" {
+		t.Fatalf("unexpected segment name %q", m.Segments[0].File)
+	}
+}
+
+func TestRotatingFileAppendsOnRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	r, err := OpenRotatingFile(RotateOptions{Path: path})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	writeLines(t, r, 2, "first")
+	if err := r.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	start := r.Manifest().Active.Start
+
+	reopened, err := OpenRotatingFile(RotateOptions{Path: path})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	writeLines(t, reopened, 1, "second")
+	if err := reopened.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(data) != "first\nfirst\nsecond\n" {
+		t.Fatalf("expected appended output, got %q", data)
+	}
+	active := reopened.Manifest().Active
+	if active.Lines != 3 || !active.Start.Equal(start) {
+		t.Fatalf("expected resumed active segment, got %+v", active)
+	}
+
+	truncated, err := OpenRotatingFile(RotateOptions{Path: path, Truncate: true})
+	if err != nil {
+		t.Fatalf("open truncate: %v", err)
+	}
+	_ = truncated.Close()
+	if info, _ := os.Stat(path); info.Size() != 0 {
+		t.Fatalf("expected truncated file, got %d bytes", info.Size())
+	}
+}
+
+func TestRotatingFileCompressesSegments(t *testing.T) {
+	for _, compression := range []string{CompressionGzip, CompressionZstd} {
+		t.Run(compression, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "events.jsonl")
+			r, err := OpenRotatingFile(RotateOptions{Path: path, MaxBytes: 30, Compression: compression})
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			writeLines(t, r, 4, "payload-line-1")
+			if err := r.Close(); err != nil {
+				t.Fatalf("close: %v", err)
+			}
+
+			m := r.Manifest()
+			if len(m.Segments) != 1 || m.Segments[0].Compression != compression {
+				t.Fatalf("expected one %s segment, got %+v", compression, m.Segments)
+			}
+			file, err := os.Open(filepath.Join(dir, m.Segments[0].File))
+			if err != nil {
+				t.Fatalf("open segment: %v", err)
+			}
+			defer file.Close()
+
+			var lines []string
+			if compression == CompressionGzip {
+				zr, err := gzip.NewReader(file)
+				if err != nil {
+					t.Fatalf("gzip reader: %v", err)
+				}
+				lines = scanLines(t, zr)
+			} else {
+				zr, err := zstd.NewReader(file)
+				if err != nil {
+					t.Fatalf("zstd reader: %v", err)
+				}
+				defer zr.Close()
+				lines = scanLines(t, zr)
+			}
+			if len(lines) != 2 || lines[0] != "payload-line-1" {
+				t.Fatalf("unexpected decompressed content: %v", lines)
+			}
+			if _, err := os.Stat(strings.TrimSuffix(filepath.Join(dir, m.Segments[0].File), filepath.Ext(m.Segments[0].File))); !os.IsNotExist(err) {
+				t.Fatal("expected uncompressed segment to be removed")
+			}
+		})
+	}
+}
+
+func TestRotatingFileStaysWritableWhenRotationFails(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.jsonl")
+	r, err := OpenRotatingFile(RotateOptions{Path: path, MaxBytes: 20, Compression: CompressionGzip})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tick := 0
+	r.now = func() time.Time { tick++; return base.Add(time.Duration(tick) * time.Second) }
+
+	failRename, failCompress := true, true
+	r.rename = func(oldpath, newpath string) error {
+		if failRename {
+			failRename = false
+			return errors.New("injected rename failure")
+		}
+		return os.Rename(oldpath, newpath)
+	}
+	r.compress = func(path, compression string) (string, error) {
+		if failCompress {
+			failCompress = false
+			return "", errors.New("injected compress failure")
+		}
+		return compressFile(path, compression)
+	}
+
+	writeLines(t, r, 2, "123456789")
+	if _, err := r.Write([]byte("rename-01\n")); err == nil || !strings.Contains(err.Error(), "injected rename failure") {
+		t.Fatalf("expected rename failure, got %v", err)
+	}
+	// The old file is still active; this write rotates it, but the sealed
+	// segment fails to compress.
+	if _, err := r.Write([]byte("compress1\n")); err == nil || !strings.Contains(err.Error(), "injected compress failure") {
+		t.Fatalf("expected compress failure, got %v", err)
+	}
+	writeLines(t, r, 4, "after-err")
+	if err := r.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	m := r.Manifest()
+	if len(m.Segments) != 2 {
+		t.Fatalf("expected 2 segments, got %+v", m.Segments)
+	}
+	if m.Segments[0].Compression != CompressionNone || m.Segments[1].Compression != CompressionGzip {
+		t.Fatalf("expected an uncompressed then a gzip segment, got %+v", m.Segments)
+	}
+	plain, err := os.Open(filepath.Join(dir, m.Segments[0].File))
+	if err != nil {
+		t.Fatalf("open uncompressed segment: %v", err)
+	}
+	defer plain.Close()
+	if lines := scanLines(t, plain); strings.Join(lines, ",") != "123456789,123456789" {
+		t.Fatalf("unexpected uncompressed segment: %v", lines)
+	}
+	active, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open active file: %v", err)
+	}
+	defer active.Close()
+	if lines := scanLines(t, active); strings.Join(lines, ",") != "after-err,after-err" {
+		t.Fatalf("unexpected active file: %v", lines)
+	}
+}
+
+func TestRotatingFileRejectsUnknownCompression(t *testing.T) {
+	if _, err := OpenRotatingFile(RotateOptions{Path: filepath.Join(t.TempDir(), "x.jsonl"), Compression: "lz4"}); err == nil {
+		t.Fatal("expected error for unsupported compression")
+	}
+}
+
+func scanLines(t *testing.T, r interface{ Read([]byte) (int, error) }) []string {
+	t.Helper()
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	return lines
+}
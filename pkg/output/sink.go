@@ -0,0 +1,229 @@
+// Package output delivers normalized events to one or more sinks with
+// per-sink kind routing, batching, spooling and failure isolation.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/otel"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/webhook"
+)
+
+// Kind identifies the event family carried by a batch.
+type Kind string
+
+const (
+	KindSLO      Kind = "slo"
+	KindProbe    Kind = "probe"
+	KindIncident Kind = "incident"
+)
+
+// ParseKind parses one configured kind name.
+func ParseKind(value string) (Kind, error) {
+	switch Kind(strings.ToLower(strings.TrimSpace(value))) {
+	case KindSLO:
+		return KindSLO, nil
+	case KindProbe:
+		return KindProbe, nil
+	case KindIncident:
+		return KindIncident, nil
+	default:
+		return "", fmt.Errorf("unsupported output kind %q (expected slo|probe|incident)", value)
+	}
+}
+
+// Batch is one homogeneous group of events. It is also the spool record
+// format, so field names are part of the on-disk layout.
+type Batch struct {
+	Kind      Kind                         `json:"kind"`
+	SLO       []schema.SLOEvent            `json:"slo,omitempty"`
+	Probe     []schema.ProbeEventV1        `json:"probe,omitempty"`
+	Incidents []schema.IncidentAttribution `json:"incidents,omitempty"`
+	// ProbeV1Beta1 carries probe events emitted under the v1beta1 contract.
+	// A probe batch may hold both versions.
+	ProbeV1Beta1 []schema.ProbeEventV1Beta1 `json:"probe_v1beta1,omitempty"`
+}
+
+// Len returns the number of events in the batch.
+func (b Batch) Len() int {
+	switch b.Kind {
+	case KindSLO:
+		return len(b.SLO)
+	case KindProbe:
+		return len(b.Probe) + len(b.ProbeV1Beta1)
+	case KindIncident:
+		return len(b.Incidents)
+	default:
+		return 0
+	}
+}
+
+// byContract returns a probe batch as one batch per probe contract, so a
+// sink that exports the contracts in separate requests never resends one
+// because the other failed. Other batches are returned unchanged.
+func (b Batch) byContract() []Batch {
+	if b.Kind != KindProbe || len(b.Probe) == 0 || len(b.ProbeV1Beta1) == 0 {
+		return []Batch{b}
+	}
+	return []Batch{
+		{Kind: KindProbe, Probe: b.Probe},
+		{Kind: KindProbe, ProbeV1Beta1: b.ProbeV1Beta1},
+	}
+}
+
+func (b *Batch) merge(other Batch) {
+	b.SLO = append(b.SLO, other.SLO...)
+	b.Probe = append(b.Probe, other.Probe...)
+	b.ProbeV1Beta1 = append(b.ProbeV1Beta1, other.ProbeV1Beta1...)
+	b.Incidents = append(b.Incidents, other.Incidents...)
+}
+
+// Sink writes batches to one destination.
+type Sink interface {
+	Write(Batch) error
+	Close() error
+}
+
+// unsupportedKindError reports a batch a sink cannot encode. Retrying or
+// spooling the batch cannot help.
+type unsupportedKindError struct {
+	sink string
+	kind Kind
+}
+
+func (e *unsupportedKindError) Error() string {
+	return fmt.Sprintf("%s sink does not support %s batches", e.sink, e.kind)
+}
+
+// Retryable is always false.
+func (e *unsupportedKindError) Retryable() bool { return false }
+
+// JSONSink writes one JSON document per event. With envelope enabled each
+// line is {"kind": ..., "payload": ...}; otherwise the bare event is written.
+type JSONSink struct {
+	encoder  *json.Encoder
+	closer   io.Closer
+	envelope bool
+}
+
+// NewJSONSink wraps a writer. The writer is not closed by the sink.
+func NewJSONSink(w io.Writer, envelope bool) *JSONSink {
+	return &JSONSink{encoder: json.NewEncoder(w), envelope: envelope}
+}
+
+// OpenJSONLSink opens a rotating JSONL file sink, appending to any file
+// left by a previous run.
+func OpenJSONLSink(opts RotateOptions, envelope bool) (*JSONSink, error) {
+	file, err := OpenRotatingFile(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONSink{encoder: json.NewEncoder(file), closer: file, envelope: envelope}, nil
+}
+
+// Write encodes every event in the batch.
+func (s *JSONSink) Write(b Batch) error {
+	encode := func(payload any) error {
+		if s.envelope {
+			return s.encoder.Encode(map[string]any{"kind": string(b.Kind), "payload": payload})
+		}
+		return s.encoder.Encode(payload)
+	}
+	for _, ev := range b.SLO {
+		if err := encode(ev); err != nil {
+			return err
+		}
+	}
+	for _, ev := range b.Probe {
+		if err := encode(ev); err != nil {
+			return err
+		}
+	}
+	for _, ev := range b.ProbeV1Beta1 {
+		if err := encode(ev); err != nil {
+			return err
+		}
+	}
+	for _, attr := range b.Incidents {
+		if err := encode(attr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the underlying file, if the sink owns one.
+func (s *JSONSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// OTLPSink exports SLO and probe batches as OTLP/HTTP logs.
+type OTLPSink struct {
+	slo   *otel.SLOEventExporter
+	probe *otel.ProbeEventExporter
+}
+
+// NewOTLPSink constructs an OTLP sink for one logs endpoint.
+func NewOTLPSink(endpoint string, scopeName string, timeout time.Duration) *OTLPSink {
+	return &OTLPSink{
+		slo:   otel.NewSLOEventExporter(endpoint, "llm-slo-ebpf-toolkit", scopeName, timeout),
+		probe: otel.NewProbeEventExporter(endpoint, "llm-slo-ebpf-toolkit", scopeName, timeout),
+	}
+}
+
+// Write exports one batch in a single OTLP request. The fan-out hands it
+// probe batches of one contract at a time.
+func (s *OTLPSink) Write(b Batch) error {
+	switch b.Kind {
+	case KindSLO:
+		return s.slo.ExportBatch(b.SLO)
+	case KindProbe:
+		if err := s.probe.ExportBatch(b.Probe); err != nil {
+			return err
+		}
+		return s.probe.ExportBatchV1Beta1(b.ProbeV1Beta1)
+	default:
+		return &unsupportedKindError{sink: "otlp", kind: b.Kind}
+	}
+}
+
+// Close is a no-op; exporters hold no persistent connections.
+func (s *OTLPSink) Close() error {
+	return nil
+}
+
+// WebhookSink delivers incident attributions through a webhook exporter.
+type WebhookSink struct {
+	exporter *webhook.Exporter
+}
+
+// NewWebhookSink wraps a configured webhook exporter.
+func NewWebhookSink(exporter *webhook.Exporter) *WebhookSink {
+	return &WebhookSink{exporter: exporter}
+}
+
+// Write sends each incident in order and stops at the first failure.
+func (s *WebhookSink) Write(b Batch) error {
+	if b.Kind != KindIncident {
+		return &unsupportedKindError{sink: "webhook", kind: b.Kind}
+	}
+	for _, attr := range b.Incidents {
+		if err := s.exporter.Send(attr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close is a no-op.
+func (s *WebhookSink) Close() error {
+	return nil
+}
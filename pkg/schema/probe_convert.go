@@ -0,0 +1,76 @@
+package schema
+
+// UpgradeProbeEvent converts a v1alpha1 probe event to v1beta1, taking the
+// new fields from id. A zero sampling weight means the event was not
+// sampled and is written as 1.
+func UpgradeProbeEvent(ev ProbeEventV1, id ProbeIdentity) ProbeEventV1Beta1 {
+	weight := id.SamplingWeight
+	if weight <= 0 {
+		weight = 1
+	}
+	return ProbeEventV1Beta1{
+		SchemaVersion:  ProbeSchemaV1Beta1,
+		TSUnixNano:     ev.TSUnixNano,
+		Signal:         ev.Signal,
+		Node:           ev.Node,
+		NodeBootID:     id.NodeBootID,
+		Namespace:      ev.Namespace,
+		Pod:            ev.Pod,
+		Container:      ev.Container,
+		Service:        id.Service,
+		Workload:       id.Workload,
+		PID:            ev.PID,
+		TID:            ev.TID,
+		Comm:           id.Comm,
+		CgroupID:       id.CgroupID,
+		NetNS:          id.NetNS,
+		ConnTuple:      ev.ConnTuple,
+		Value:          ev.Value,
+		Unit:           ev.Unit,
+		Status:         ev.Status,
+		SamplingWeight: weight,
+		TraceID:        ev.TraceID,
+		SpanID:         ev.SpanID,
+		Errno:          ev.Errno,
+		Confidence:     ev.Confidence,
+		Dependency:     ev.Dependency,
+	}
+}
+
+// DowngradeProbeEvent converts a v1beta1 probe event to v1alpha1, dropping
+// the identity fields and DNS, HTTP, sched, disk, stream and churn
+// details. Use Identity to keep the former.
+func DowngradeProbeEvent(ev ProbeEventV1Beta1) ProbeEventV1 {
+	return ProbeEventV1{
+		TSUnixNano: ev.TSUnixNano,
+		Signal:     ev.Signal,
+		Node:       ev.Node,
+		Namespace:  ev.Namespace,
+		Pod:        ev.Pod,
+		Container:  ev.Container,
+		PID:        ev.PID,
+		TID:        ev.TID,
+		ConnTuple:  ev.ConnTuple,
+		Value:      ev.Value,
+		Unit:       ev.Unit,
+		Status:     ev.Status,
+		TraceID:    ev.TraceID,
+		SpanID:     ev.SpanID,
+		Errno:      ev.Errno,
+		Confidence: ev.Confidence,
+		Dependency: ev.Dependency,
+	}
+}
+
+// Identity returns the v1beta1-only fields of ev.
+func (ev ProbeEventV1Beta1) Identity() ProbeIdentity {
+	return ProbeIdentity{
+		Comm:           ev.Comm,
+		CgroupID:       ev.CgroupID,
+		NetNS:          ev.NetNS,
+		Service:        ev.Service,
+		Workload:       ev.Workload,
+		SamplingWeight: ev.SamplingWeight,
+		NodeBootID:     ev.NodeBootID,
+	}
+}
@@ -0,0 +1,134 @@
+package schema
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func sampleProbeIdentity() ProbeIdentity {
+	return ProbeIdentity{
+		Comm:           "rag-service",
+		CgroupID:       8812,
+		NetNS:          4026531840,
+		Service:        "rag",
+		Workload:       "rag-service",
+		SamplingWeight: 4,
+		NodeBootID:     "2f1c2b3e-8d1a-4a7e-9c55-5f3f6a0e1d2c",
+	}
+}
+
+func TestProbeEventUpgradeDowngradeRoundTrip(t *testing.T) {
+	original := sampleProbeEvent()
+	errno := 110
+	original.Errno = &errno
+	original.Dependency = "openai"
+	original.ConnTuple.DstHost = "api.openai.com"
+
+	upgraded := UpgradeProbeEvent(original, sampleProbeIdentity())
+	if upgraded.SchemaVersion != ProbeSchemaV1Beta1 {
+		t.Fatalf("expected schema_version %s, got %q", ProbeSchemaV1Beta1, upgraded.SchemaVersion)
+	}
+	if err := ProbeEventV1Beta1Validator().Validate(upgraded); err != nil {
+		t.Fatalf("upgraded event should validate: %v", err)
+	}
+	if got := upgraded.Identity(); !reflect.DeepEqual(got, sampleProbeIdentity()) {
+		t.Fatalf("identity mismatch: got %+v", got)
+	}
+	if got := DowngradeProbeEvent(upgraded); !reflect.DeepEqual(got, original) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", got, original)
+	}
+	if err := ProbeEventValidator().Validate(DowngradeProbeEvent(upgraded)); err != nil {
+		t.Fatalf("downgraded event should validate against v1alpha1: %v", err)
+	}
+}
+
+func TestUpgradeProbeEventDefaultsSamplingWeight(t *testing.T) {
+	upgraded := UpgradeProbeEvent(sampleProbeEvent(), ProbeIdentity{})
+	if upgraded.SamplingWeight != 1 {
+		t.Fatalf("expected sampling_weight 1 for unsampled events, got %f", upgraded.SamplingWeight)
+	}
+	if err := ProbeEventV1Beta1Validator().Validate(upgraded); err != nil {
+		t.Fatalf("event without identity should validate: %v", err)
+	}
+}
+
+func TestProbeEventV1Beta1ValidatorRejectsBadIdentity(t *testing.T) {
+	event := UpgradeProbeEvent(sampleProbeEvent(), sampleProbeIdentity())
+	event.Comm = "a-command-name-longer-than-task-comm"
+	event.SchemaVersion = "v1alpha1"
+
+	err := ProbeEventV1Beta1Validator().Validate(event)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+	fields := map[string]bool{}
+	for _, f := range verr.Fields {
+		fields[f.Field] = true
+	}
+	for _, want := range []string{"comm", "schema_version"} {
+		if !fields[want] {
+			t.Fatalf("expected violation on %s, got %+v", want, verr.Fields)
+		}
+	}
+}
+
+func TestProbeEventV1Beta1ValidatorDNSDetails(t *testing.T) {
+	event := UpgradeProbeEvent(sampleProbeEvent(), sampleProbeIdentity())
+	event.DNS = &DNSQuery{QName: "api.openai.com.svc.cluster.local", QType: 1, RCode: 3, ResolverIP: "10.96.0.10"}
+	if err := ProbeEventV1Beta1Validator().Validate(event); err != nil {
+		t.Fatalf("valid DNS details rejected: %v", err)
+	}
+
+	event.DNS.RCode = 16
+	if err := ProbeEventV1Beta1Validator().Validate(event); err == nil {
+		t.Fatal("expected rcode outside 0-15 to be rejected")
+	}
+	if down := DowngradeProbeEvent(event); down.Signal != event.Signal {
+		t.Fatalf("downgrade lost signal: %+v", down)
+	}
+}
+
+func TestProbeEventV1Beta1ValidatorHTTPDetails(t *testing.T) {
+	event := UpgradeProbeEvent(sampleProbeEvent(), sampleProbeIdentity())
+	event.Signal = "provider_retry_after_s"
+	retry := 20.0
+	event.HTTP = &HTTPResponse{Host: "api.openai.com", Status: 429, Proto: "h2", RetryAfterS: &retry}
+	if err := ProbeEventV1Beta1Validator().Validate(event); err != nil {
+		t.Fatalf("valid HTTP details rejected: %v", err)
+	}
+
+	event.HTTP.Proto = "spdy/3"
+	if err := ProbeEventV1Beta1Validator().Validate(event); err == nil {
+		t.Fatal("expected unknown proto to be rejected")
+	}
+}
+
+func TestProbeEventV1Beta1ValidatorSchedDetails(t *testing.T) {
+	event := UpgradeProbeEvent(sampleProbeEvent(), sampleProbeIdentity())
+	event.Signal = "runqueue_delay_ms"
+	event.Sched = &SchedPrev{PrevPID: 900, PrevComm: "spark-executor", PrevCgroupID: 8812}
+	if err := ProbeEventV1Beta1Validator().Validate(event); err != nil {
+		t.Fatalf("valid sched details rejected: %v", err)
+	}
+
+	event.Sched.PrevPID = 0
+	if err := ProbeEventV1Beta1Validator().Validate(event); err == nil {
+		t.Fatal("expected an idle prev task to be rejected")
+	}
+}
+
+func TestProbeEventV1Beta1ValidatorDiskDetails(t *testing.T) {
+	event := UpgradeProbeEvent(sampleProbeEvent(), sampleProbeIdentity())
+	event.Signal = "disk_io_latency_ms"
+	event.Disk = &DiskIO{Device: "nvme0n1p2", Major: 259, Minor: 2, Op: "read", Bytes: 131072}
+	if err := ProbeEventV1Beta1Validator().Validate(event); err != nil {
+		t.Fatalf("valid disk details rejected: %v", err)
+	}
+
+	event.Disk.Op = "trim"
+	if err := ProbeEventV1Beta1Validator().Validate(event); err == nil {
+		t.Fatal("expected unknown op to be rejected")
+	}
+}
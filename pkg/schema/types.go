@@ -1,6 +1,9 @@
 package schema
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // SLOEvent is the normalized event envelope emitted by the collector.
 type SLOEvent struct {
@@ -38,6 +41,21 @@ type FaultHypothesis struct {
 	Domain    string   `json:"domain"`
 	Posterior float64  `json:"posterior"`
 	Evidence  []string `json:"evidence"`
+	SubCause  string   `json:"sub_cause,omitempty"`
+	// Culprits lists the neighbours that held the CPU while the workload
+	// waited to run; set on cpu_throttle hypotheses.
+	Culprits []Culprit `json:"culprits,omitempty"`
+}
+
+// Culprit is a pod or host process that CPU time was stolen by.
+type Culprit struct {
+	// Pod is the pod UID, or "cgroup/<id>" for a task outside pod cgroups.
+	Pod  string `json:"pod"`
+	Comm string `json:"comm,omitempty"`
+	// StolenMS is the run-queue delay the victim accrued behind it.
+	StolenMS float64 `json:"stolen_ms"`
+	// Share is StolenMS over the victim's total attributed delay.
+	Share float64 `json:"share"`
 }
 
 // IncidentAttribution is the normalized attribution envelope.
@@ -54,6 +72,29 @@ type IncidentAttribution struct {
 	TraceIDs             []string          `json:"trace_ids,omitempty"`
 	RequestIDs           []string          `json:"request_ids,omitempty"`
 	FaultHypotheses      []FaultHypothesis `json:"fault_hypotheses,omitempty"`
+	// Annotations are agent actions on the workload's timeline around the
+	// incident, such as deep tracing being attached.
+	Annotations []Annotation `json:"annotations,omitempty"`
+}
+
+// Annotation kinds recorded for the incident timeline.
+const (
+	AnnotationDeepTracingStarted = "deep_tracing_started"
+	AnnotationDeepTracingStopped = "deep_tracing_stopped"
+	AnnotationDeepTracingSkipped = "deep_tracing_skipped"
+)
+
+// Annotation marks one agent action on a workload's incident timeline.
+type Annotation struct {
+	Timestamp time.Time `json:"timestamp"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace,omitempty"`
+	Service   string    `json:"service"`
+	// Signals are the probes the action applied to.
+	Signals []string `json:"signals,omitempty"`
+	// Pods are the UIDs of the pods the probes were scoped to.
+	Pods   []string `json:"pods,omitempty"`
+	Reason string   `json:"reason"`
 }
 
 // ConnTuple identifies one network flow tuple observed by probes.
@@ -63,6 +104,15 @@ type ConnTuple struct {
 	SrcPort  int    `json:"src_port"`
 	DstPort  int    `json:"dst_port"`
 	Protocol string `json:"protocol"`
+	// DstHost is the name DstIP was resolved from, as seen by the passive
+	// DNS cache; empty when no answer for DstIP was observed.
+	DstHost string `json:"dst_host,omitempty"`
+}
+
+// String renders the tuple as "src:port->dst:port/proto", the key spans and
+// signals use to join at the pod_conn correlation tier.
+func (t ConnTuple) String() string {
+	return fmt.Sprintf("%s:%d->%s:%d/%s", t.SrcIP, t.SrcPort, t.DstIP, t.DstPort, t.Protocol)
 }
 
 // ProbeEventV1 is the normalized probe envelope emitted by the node agent.
@@ -83,4 +133,133 @@ type ProbeEventV1 struct {
 	SpanID     string     `json:"span_id,omitempty"`
 	Errno      *int       `json:"errno,omitempty"`
 	Confidence *float64   `json:"confidence,omitempty"`
+	// Dependency is the logical destination from the dependency catalog,
+	// e.g. "openai" or "qdrant".
+	Dependency string `json:"dependency,omitempty"`
+}
+
+// Probe contract versions. v1beta1 events carry the version in
+// schema_version; v1alpha1 events have no version field.
+const (
+	ProbeSchemaV1Alpha1 = "v1alpha1"
+	ProbeSchemaV1Beta1  = "v1beta1"
+)
+
+// ProbeIdentity holds the process, cgroup and node identity fields that
+// v1beta1 adds on top of the v1alpha1 probe envelope.
+type ProbeIdentity struct {
+	Comm           string
+	CgroupID       uint64
+	NetNS          uint64
+	Service        string
+	Workload       string
+	SamplingWeight float64
+	NodeBootID     string
+}
+
+// ProbeEventV1Beta1 is the v1beta1 probe envelope. It is a superset of
+// ProbeEventV1 (v1alpha1) with identity fields for attribution debugging
+// and joins across restarts.
+type ProbeEventV1Beta1 struct {
+	SchemaVersion  string        `json:"schema_version"`
+	TSUnixNano     int64         `json:"ts_unix_nano"`
+	Signal         string        `json:"signal"`
+	Node           string        `json:"node"`
+	NodeBootID     string        `json:"node_boot_id,omitempty"`
+	Namespace      string        `json:"namespace"`
+	Pod            string        `json:"pod"`
+	Container      string        `json:"container"`
+	Service        string        `json:"service,omitempty"`
+	Workload       string        `json:"workload,omitempty"`
+	PID            int           `json:"pid"`
+	TID            int           `json:"tid"`
+	Comm           string        `json:"comm,omitempty"`
+	CgroupID       uint64        `json:"cgroup_id,omitempty"`
+	NetNS          uint64        `json:"netns,omitempty"`
+	ConnTuple      *ConnTuple    `json:"conn_tuple,omitempty"`
+	Value          float64       `json:"value"`
+	Unit           string        `json:"unit"`
+	Status         string        `json:"status"`
+	SamplingWeight float64       `json:"sampling_weight"`
+	TraceID        string        `json:"trace_id,omitempty"`
+	SpanID         string        `json:"span_id,omitempty"`
+	Errno          *int          `json:"errno,omitempty"`
+	Confidence     *float64      `json:"confidence,omitempty"`
+	Dependency     string        `json:"dependency,omitempty"`
+	DNS            *DNSQuery     `json:"dns,omitempty"`
+	HTTP           *HTTPResponse `json:"http,omitempty"`
+	Sched          *SchedPrev    `json:"sched,omitempty"`
+	Disk           *DiskIO       `json:"disk,omitempty"`
+	Stream         *StreamWrites `json:"stream,omitempty"`
+	Churn          *ConnChurn    `json:"churn,omitempty"`
+}
+
+// DNSQuery holds the fields the DNS probe parses from a response. For
+// dns_queries_per_lookup events QName is the name shared by every query
+// of the logical lookup and RCode is the final query's response code.
+type DNSQuery struct {
+	QName string `json:"qname"`
+	// QNameTruncated is set when the name did not fit the probe's buffer;
+	// QName then holds its leading labels.
+	QNameTruncated bool   `json:"qname_truncated,omitempty"`
+	QType          int    `json:"qtype"`
+	RCode          int    `json:"rcode"`
+	ResolverIP     string `json:"resolver_ip,omitempty"`
+}
+
+// SchedPrev identifies the task that was on the CPU until the task behind
+// a runqueue_delay_ms event was switched in.
+type SchedPrev struct {
+	PrevPID      int    `json:"prev_pid"`
+	PrevComm     string `json:"prev_comm,omitempty"`
+	PrevCgroupID uint64 `json:"prev_cgroup_id,omitempty"`
+}
+
+// DiskIO identifies the block request behind a disk_io_latency_ms event.
+// The event's pid, comm and cgroup_id are those of the issuing task.
+type DiskIO struct {
+	// Device is the kernel name from /sys/dev/block, e.g. "nvme0n1p2", or
+	// "major:minor" when it cannot be resolved.
+	Device string `json:"device"`
+	Major  int    `json:"major"`
+	Minor  int    `json:"minor"`
+	// Op is "read", "write" or "other" (flush, discard).
+	Op    string `json:"op"`
+	Bytes int    `json:"bytes"`
+}
+
+// StreamWrites summarizes the response writes of the streamed request
+// behind a stream_write_gap_ms event, whose value is the largest gap.
+type StreamWrites struct {
+	// Request numbers the request on its connection from 1.
+	Request  int     `json:"request"`
+	Writes   int     `json:"writes"`
+	P95GapMS float64 `json:"p95_gap_ms"`
+}
+
+// ConnChurn summarizes the new connections from one pod to one
+// destination behind a connection_churn_per_s event, whose value is their
+// rate over the window.
+type ConnChurn struct {
+	// Destination is the host (or IP) and port connected to.
+	Destination string  `json:"destination"`
+	Connects    int     `json:"connects"`
+	WindowS     float64 `json:"window_s"`
+	// BaselinePerS is the pair's usual rate; 0 while it is warming up.
+	BaselinePerS float64 `json:"baseline_per_s"`
+	// Regression is set when the rate is far above the baseline, the mark
+	// of a client that stopped reusing connections.
+	Regression bool `json:"regression,omitempty"`
+}
+
+// HTTPResponse identifies the provider response behind a
+// provider_http_429_total, provider_http_5xx_total or
+// provider_retry_after_s event.
+type HTTPResponse struct {
+	Host   string `json:"host,omitempty"`
+	Status int    `json:"status"`
+	// Proto is "http/1.1" or "h2".
+	Proto string `json:"proto"`
+	// RetryAfterS is the Retry-After header in seconds, when present.
+	RetryAfterS *float64 `json:"retry_after_s,omitempty"`
 }
@@ -5,37 +5,190 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/docs/contracts"
 	"github.com/xeipuuv/gojsonschema"
 )
 
-// ValidateAgainstSchema validates an arbitrary payload against a JSON schema file.
-func ValidateAgainstSchema(schemaPath string, payload interface{}) error {
-	schemaBytes, err := os.ReadFile(schemaPath)
+// Embedded contract schema names, relative to docs/contracts.
+const (
+	ContractSLOEvent            = "v1/slo-event.schema.json"
+	ContractIncidentAttribution = "v1/incident-attribution.schema.json"
+	ContractProbeEvent          = "v1alpha1/probe-event.schema.json"
+	ContractProbeEventV1Beta1   = "v1beta1/probe-event.schema.json"
+)
+
+// FieldError is one schema violation located by its JSON field path, e.g.
+// "conn_tuple.dst_port". Root-level violations have an empty Field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) String() string {
+	field := e.Field
+	if field == "" {
+		field = "(root)"
+	}
+	return fmt.Sprintf("%s: %s", field, e.Message)
+}
+
+// ValidationError reports every violation found in one payload.
+type ValidationError struct {
+	Contract string
+	Fields   []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, f.String())
+	}
+	return fmt.Sprintf("payload failed schema validation (%s): %s", e.Contract, strings.Join(parts, "; "))
+}
+
+// Validator checks payloads of type T against one compiled schema.
+type Validator[T any] struct {
+	contract string
+	schema   *gojsonschema.Schema
+}
+
+// CompileValidator compiles schema bytes once for repeated validation.
+func CompileValidator[T any](contract string, schemaBytes []byte) (*Validator[T], error) {
+	compiled, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schemaBytes))
 	if err != nil {
-		return fmt.Errorf("read schema %s: %w", schemaPath, err)
+		return nil, fmt.Errorf("compile schema %s: %w", contract, err)
 	}
+	return &Validator[T]{contract: contract, schema: compiled}, nil
+}
+
+// LoadValidator compiles a schema file from disk.
+func LoadValidator[T any](path string) (*Validator[T], error) {
+	schemaBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read schema %s: %w", path, err)
+	}
+	return CompileValidator[T](path, schemaBytes)
+}
 
+// Contract returns the schema name the validator was compiled from.
+func (v *Validator[T]) Contract() string {
+	return v.contract
+}
+
+// Validate returns nil or a *ValidationError describing each violation.
+func (v *Validator[T]) Validate(payload T) error {
+	return validateCompiled(v.contract, v.schema, payload)
+}
+
+func validateCompiled(contract string, compiled *gojsonschema.Schema, payload interface{}) error {
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("marshal payload: %w", err)
 	}
-
-	result, err := gojsonschema.Validate(
-		gojsonschema.NewBytesLoader(schemaBytes),
-		gojsonschema.NewBytesLoader(payloadBytes),
-	)
+	result, err := compiled.Validate(gojsonschema.NewBytesLoader(payloadBytes))
 	if err != nil {
 		return fmt.Errorf("schema validation error: %w", err)
 	}
-
 	if result.Valid() {
 		return nil
 	}
-
-	errors := make([]string, 0, len(result.Errors()))
+	fields := make([]FieldError, 0, len(result.Errors()))
 	for _, issue := range result.Errors() {
-		errors = append(errors, issue.String())
+		fields = append(fields, FieldError{
+			Field:   fieldPath(issue),
+			Rule:    issue.Type(),
+			Message: issue.Description(),
+		})
+	}
+	return &ValidationError{Contract: contract, Fields: fields}
+}
+
+// fieldPath points required/additional-property errors at the offending
+// property rather than its parent object.
+func fieldPath(issue gojsonschema.ResultError) string {
+	field := issue.Field()
+	if field == gojsonschema.STRING_ROOT_SCHEMA_PROPERTY {
+		field = ""
+	}
+	switch issue.Type() {
+	case "required", "additional_property_not_allowed":
+		if property, ok := issue.Details()["property"].(string); ok && property != "" {
+			if field == "" {
+				return property
+			}
+			return field + "." + property
+		}
+	}
+	return field
+}
+
+func mustCompileEmbedded[T any](contract string) *Validator[T] {
+	schemaBytes, err := contracts.FS.ReadFile(contract)
+	if err != nil {
+		panic(fmt.Sprintf("embedded contract %s: %v", contract, err))
+	}
+	v, err := CompileValidator[T](contract, schemaBytes)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+var (
+	sloEventValidator = sync.OnceValue(func() *Validator[SLOEvent] {
+		return mustCompileEmbedded[SLOEvent](ContractSLOEvent)
+	})
+	incidentValidator = sync.OnceValue(func() *Validator[IncidentAttribution] {
+		return mustCompileEmbedded[IncidentAttribution](ContractIncidentAttribution)
+	})
+	probeEventValidator = sync.OnceValue(func() *Validator[ProbeEventV1] {
+		return mustCompileEmbedded[ProbeEventV1](ContractProbeEvent)
+	})
+	probeEventV1Beta1Validator = sync.OnceValue(func() *Validator[ProbeEventV1Beta1] {
+		return mustCompileEmbedded[ProbeEventV1Beta1](ContractProbeEventV1Beta1)
+	})
+)
+
+// SLOEventValidator returns the shared validator for the embedded v1 SLO event contract.
+func SLOEventValidator() *Validator[SLOEvent] {
+	return sloEventValidator()
+}
+
+// IncidentAttributionValidator returns the shared validator for the embedded v1 incident contract.
+func IncidentAttributionValidator() *Validator[IncidentAttribution] {
+	return incidentValidator()
+}
+
+// ProbeEventValidator returns the shared validator for the embedded v1alpha1 probe contract.
+func ProbeEventValidator() *Validator[ProbeEventV1] {
+	return probeEventValidator()
+}
+
+// ProbeEventV1Beta1Validator returns the shared validator for the embedded v1beta1 probe contract.
+func ProbeEventV1Beta1Validator() *Validator[ProbeEventV1Beta1] {
+	return probeEventV1Beta1Validator()
+}
+
+var compiledByPath sync.Map // path -> *gojsonschema.Schema
+
+// ValidateAgainstSchema validates an arbitrary payload against a JSON schema
+// file. Each path is compiled once per process; prefer the typed validators
+// for the published contracts.
+func ValidateAgainstSchema(schemaPath string, payload interface{}) error {
+	compiled, ok := compiledByPath.Load(schemaPath)
+	if !ok {
+		schemaBytes, err := os.ReadFile(schemaPath)
+		if err != nil {
+			return fmt.Errorf("read schema %s: %w", schemaPath, err)
+		}
+		s, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schemaBytes))
+		if err != nil {
+			return fmt.Errorf("schema validation error: %w", err)
+		}
+		compiled, _ = compiledByPath.LoadOrStore(schemaPath, s)
 	}
-	return fmt.Errorf("payload failed schema validation: %s", strings.Join(errors, "; "))
+	return validateCompiled(schemaPath, compiled.(*gojsonschema.Schema), payload)
 }
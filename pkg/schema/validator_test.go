@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -50,6 +51,17 @@ func TestValidateIncidentSchema(t *testing.T) {
 		Confidence:           0.9,
 		Evidence:             []Evidence{{Signal: "fault_label", Value: "provider_throttle", Source: "application"}},
 		SLOImpact:            SLOImpact{SLI: "ttft_ms", BurnRate: 2.1, WindowMinutes: 5},
+		FaultHypotheses: []FaultHypothesis{
+			{Domain: "network_dns", Posterior: 0.8, Evidence: []string{"dns_nxdomain_total"}, SubCause: "search-path amplification"},
+		},
+		Annotations: []Annotation{{
+			Timestamp: time.Now().UTC(),
+			Kind:      AnnotationDeepTracingStarted,
+			Service:   "chat",
+			Signals:   []string{"tls_handshake_ms"},
+			Pods:      []string{"0b6f7c2e-1d2a-4c55-9a61-2f3e4d5c6b7a"},
+			Reason:    "slo status breach",
+		}},
 	}
 	if err := ValidateAgainstSchema(schemaPath(t, "docs/contracts/v1/incident-attribution.schema.json"), incident); err != nil {
 		t.Fatalf("schema validation failed: %v", err)
@@ -128,3 +140,213 @@ func TestValidateToolkitConfigSchemaRejectsUnknownAndUnsupportedSignal(t *testin
 		t.Fatalf("expected unknown key or signal validation errors, got: %v", err)
 	}
 }
+
+func TestValidateToolkitConfigSchemaThresholds(t *testing.T) {
+	base := func(thresholds map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"signal_set":  []interface{}{"dns_latency_ms"},
+			"sampling":    map[string]interface{}{"events_per_second_limit": 1000, "burst_limit": 2000},
+			"correlation": map[string]interface{}{"window_ms": 2000},
+			"otlp":        map[string]interface{}{"endpoint": "http://otel-collector:4317"},
+			"safety":      map[string]interface{}{"max_overhead_pct": 5},
+			"thresholds":  thresholds,
+		}
+	}
+	valid := base(map[string]interface{}{
+		"defaults":   map[string]interface{}{"dns_latency_ms": map[string]interface{}{"warning": 60, "error": 180}},
+		"namespaces": map[string]interface{}{"apac": map[string]interface{}{"dns_latency_ms": map[string]interface{}{"warning": 90}}},
+		"services":   map[string]interface{}{"apac/rag": map[string]interface{}{"dns_latency_ms": map[string]interface{}{"error": 400}}},
+	})
+	if err := ValidateAgainstSchema(schemaPath(t, "config/toolkit.schema.json"), valid); err != nil {
+		t.Fatalf("thresholds config should validate: %v", err)
+	}
+
+	invalid := base(map[string]interface{}{
+		"services": map[string]interface{}{"rag": map[string]interface{}{"dns_latency_ms": map[string]interface{}{"warn": 90}}},
+	})
+	if err := ValidateAgainstSchema(schemaPath(t, "config/toolkit.schema.json"), invalid); err == nil {
+		t.Fatal("expected unknown threshold field to fail validation")
+	}
+}
+
+func TestValidateToolkitConfigSchemaOutputs(t *testing.T) {
+	base := func(output map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"signal_set":  []interface{}{"dns_latency_ms"},
+			"sampling":    map[string]interface{}{"events_per_second_limit": 1000, "burst_limit": 2000},
+			"correlation": map[string]interface{}{"window_ms": 2000},
+			"otlp":        map[string]interface{}{"endpoint": "http://otel-collector:4317"},
+			"safety":      map[string]interface{}{"max_overhead_pct": 5},
+			"outputs":     []interface{}{output},
+		}
+	}
+	valid := []map[string]interface{}{
+		{"type": "otlp", "endpoint": "http://collector:4318/v1/logs", "kinds": []interface{}{"slo", "probe"}},
+		{"type": "jsonl", "path": "/tmp/incidents.jsonl", "kinds": []interface{}{"incident"}},
+	}
+	for _, output := range valid {
+		if err := ValidateAgainstSchema(schemaPath(t, "config/toolkit.schema.json"), base(output)); err != nil {
+			t.Fatalf("output %v should validate: %v", output, err)
+		}
+	}
+
+	invalid := base(map[string]interface{}{"type": "otlp", "endpoint": "http://collector:4318/v1/logs", "kinds": []interface{}{"incident"}})
+	if err := ValidateAgainstSchema(schemaPath(t, "config/toolkit.schema.json"), invalid); err == nil {
+		t.Fatal("expected otlp output with incidents to fail validation")
+	}
+
+	reserved := base(map[string]interface{}{"name": "webhook", "type": "stdout"})
+	if err := ValidateAgainstSchema(schemaPath(t, "config/toolkit.schema.json"), reserved); err == nil {
+		t.Fatal("expected the reserved webhook name to fail validation")
+	}
+}
+
+func sampleSLOEvent() SLOEvent {
+	return SLOEvent{
+		EventID:   "evt-1",
+		Timestamp: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Cluster:   "local",
+		Namespace: "default",
+		Workload:  "demo",
+		Service:   "chat",
+		RequestID: "req-1",
+		SLIName:   "ttft_ms",
+		SLIValue:  210,
+		Unit:      "ms",
+		Status:    "ok",
+	}
+}
+
+func sampleProbeEvent() ProbeEventV1 {
+	return ProbeEventV1{
+		TSUnixNano: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC).UnixNano(),
+		Signal:     "dns_latency_ms",
+		Node:       "kind-worker",
+		Namespace:  "default",
+		Pod:        "rag-service-0",
+		Container:  "rag-service",
+		PID:        1234,
+		TID:        1234,
+		ConnTuple: &ConnTuple{
+			SrcIP:    "10.0.0.2",
+			DstIP:    "10.0.0.53",
+			SrcPort:  42424,
+			DstPort:  53,
+			Protocol: "udp",
+		},
+		Value:  23.5,
+		Unit:   "ms",
+		Status: "ok",
+	}
+}
+
+func TestEmbeddedValidatorsAcceptValidPayloads(t *testing.T) {
+	if err := SLOEventValidator().Validate(sampleSLOEvent()); err != nil {
+		t.Fatalf("slo event should validate: %v", err)
+	}
+	if err := ProbeEventValidator().Validate(sampleProbeEvent()); err != nil {
+		t.Fatalf("probe event should validate: %v", err)
+	}
+	incident := IncidentAttribution{
+		IncidentID:           "inc-1",
+		Timestamp:            time.Now().UTC(),
+		Cluster:              "local",
+		Service:              "chat",
+		PredictedFaultDomain: "provider_throttle",
+		Confidence:           0.9,
+		Evidence:             []Evidence{{Signal: "fault_label", Value: "provider_throttle", Source: "application"}},
+		SLOImpact:            SLOImpact{SLI: "ttft_ms", BurnRate: 2.1, WindowMinutes: 5},
+	}
+	if err := IncidentAttributionValidator().Validate(incident); err != nil {
+		t.Fatalf("incident should validate: %v", err)
+	}
+}
+
+func TestValidatorReportsFieldPaths(t *testing.T) {
+	event := sampleProbeEvent()
+	event.PID = -1
+	event.ConnTuple.DstPort = 70000
+
+	err := ProbeEventValidator().Validate(event)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got %T: %v", err, err)
+	}
+	if verr.Contract != ContractProbeEvent {
+		t.Fatalf("unexpected contract %q", verr.Contract)
+	}
+	fields := map[string]string{}
+	for _, f := range verr.Fields {
+		fields[f.Field] = f.Rule
+	}
+	if fields["conn_tuple.dst_port"] == "" || fields["pid"] == "" {
+		t.Fatalf("expected conn_tuple.dst_port and pid violations, got %+v", verr.Fields)
+	}
+}
+
+func TestValidatorReportsMissingRequiredProperty(t *testing.T) {
+	v, err := CompileValidator[map[string]interface{}]("inline", []byte(`{
+		"type": "object",
+		"required": ["name"],
+		"additionalProperties": false,
+		"properties": {"name": {"type": "string"}}
+	}`))
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	err = v.Validate(map[string]interface{}{"extra": 1})
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+	got := map[string]string{}
+	for _, f := range verr.Fields {
+		got[f.Field] = f.Rule
+	}
+	if got["name"] != "required" || got["extra"] != "additional_property_not_allowed" {
+		t.Fatalf("unexpected field errors: %+v", verr.Fields)
+	}
+}
+
+func BenchmarkSLOEventValidator(b *testing.B) {
+	v := SLOEventValidator()
+	event := sampleSLOEvent()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := v.Validate(event); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProbeEventValidator(b *testing.B) {
+	v := ProbeEventValidator()
+	event := sampleProbeEvent()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := v.Validate(event); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkProbeEventCompilePerEvent is the pre-embedding baseline: read
+// and compile the schema for every event.
+func BenchmarkProbeEventCompilePerEvent(b *testing.B) {
+	_, filename, _, _ := runtime.Caller(0)
+	path := filepath.Join(filepath.Dir(filename), "..", "..", "docs", "contracts", "v1alpha1", "probe-event.schema.json")
+	event := sampleProbeEvent()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v, err := LoadValidator[ProbeEventV1](path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := v.Validate(event); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -22,6 +22,37 @@ const (
 	AttrDiskIOLatencyMS     = "llm.ebpf.blk.io_latency_ms"
 	AttrSyscallLatencyMS    = "llm.ebpf.syscall.latency_ms"
 
+	AttrOOMKills           = "llm.ebpf.mm.oom_kills_total"
+	AttrMemcgHighEvents    = "llm.ebpf.memcg.high_events_total"
+	AttrMemcgMaxEvents     = "llm.ebpf.memcg.max_events_total"
+	AttrMemcgOOMKillEvents = "llm.ebpf.memcg.oom_kill_events_total"
+
+	AttrTCPSRTTMS      = "llm.ebpf.tcp.srtt_ms"
+	AttrTCPZeroWindows = "llm.ebpf.tcp.zero_window_total"
+
+	AttrTCPResets       = "llm.ebpf.tcp.resets_total"
+	AttrListenOverflows = "llm.ebpf.tcp.listen_overflows_total"
+
+	AttrDNSNXDomains        = "llm.ebpf.dns.nxdomain_total"
+	AttrDNSQueriesPerLookup = "llm.ebpf.dns.queries_per_lookup"
+
+	AttrProviderHTTP429s    = "llm.ebpf.provider.http_429_total"
+	AttrProviderHTTP5xxs    = "llm.ebpf.provider.http_5xx_total"
+	AttrProviderRetryAfterS = "llm.ebpf.provider.retry_after_s"
+
+	AttrNoisyNeighborPod = "llm.ebpf.sched.noisy_neighbor_pod"
+
+	AttrStreamWriteGapMS = "llm.ebpf.net.stream_write_gap_ms"
+
+	AttrConnectionChurnPerS = "llm.ebpf.net.connection_churn_per_s"
+	AttrConnReuseRegression = "llm.ebpf.net.connection_reuse_regression"
+
+	AttrConntrackUtilizationPct = "llm.ebpf.net.conntrack_utilization_pct"
+	AttrConntrackDrops          = "llm.ebpf.net.conntrack_drops_total"
+
+	AttrDependency = "llm.ebpf.net.dependency"
+	AttrDstHost    = "llm.ebpf.net.dst_host"
+
 	AttrConnectErrors      = "llm.ebpf.net.connect_errors_total"
 	AttrTLSHandshakeFails  = "llm.ebpf.tls.handshake_fail_total"
 )
@@ -1,93 +1,63 @@
 package signals
 
-// Signal keys exposed by the agent.
+import "github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
+
+// Signal keys exposed by the agent. Per-signal facts live in the
+// signalspec registry.
 const (
-	SignalDNSLatencyMS      = "dns_latency_ms"
-	SignalTCPRetransmits    = "tcp_retransmits_total"
-	SignalRunqueueDelayMS   = "runqueue_delay_ms"
-	SignalConnectLatencyMS  = "connect_latency_ms"
-	SignalConnectErrors     = "connect_errors_total"
-	SignalTLSHandshakeMS    = "tls_handshake_ms"
-	SignalTLSHandshakeFails = "tls_handshake_fail_total"
-	SignalCPUStealPct       = "cpu_steal_pct"
-	SignalCFSThrottledMS       = "cfs_throttled_ms"
-	SignalMemReclaimLatencyMS  = "mem_reclaim_latency_ms"
-	SignalDiskIOLatencyMS      = "disk_io_latency_ms"
-	SignalSyscallLatencyMS     = "syscall_latency_ms"
+	SignalDNSLatencyMS        = signalspec.DNSLatencyMS
+	SignalTCPRetransmits      = signalspec.TCPRetransmits
+	SignalRunqueueDelayMS     = signalspec.RunqueueDelayMS
+	SignalConnectLatencyMS    = signalspec.ConnectLatencyMS
+	SignalConnectErrors       = signalspec.ConnectErrors
+	SignalTLSHandshakeMS      = signalspec.TLSHandshakeMS
+	SignalTLSHandshakeFails   = signalspec.TLSHandshakeFails
+	SignalCPUStealPct         = signalspec.CPUStealPct
+	SignalCFSThrottledMS      = signalspec.CFSThrottledMS
+	SignalMemReclaimLatencyMS = signalspec.MemReclaimLatencyMS
+	SignalDiskIOLatencyMS     = signalspec.DiskIOLatencyMS
+	SignalSyscallLatencyMS    = signalspec.SyscallLatencyMS
+	SignalOOMKills            = signalspec.OOMKills
+	SignalMemcgHighEvents     = signalspec.MemcgHighEvents
+	SignalMemcgMaxEvents      = signalspec.MemcgMaxEvents
+	SignalMemcgOOMKillEvents  = signalspec.MemcgOOMKillEvents
+	SignalTCPSRTTMS           = signalspec.TCPSRTTMS
+	SignalTCPZeroWindows      = signalspec.TCPZeroWindows
+	SignalTCPResets           = signalspec.TCPResets
+	SignalListenOverflows     = signalspec.ListenOverflows
+	SignalDNSNXDomains        = signalspec.DNSNXDomains
+	SignalDNSQueriesPerLookup = signalspec.DNSQueriesPerLookup
+	SignalProviderHTTP429s    = signalspec.ProviderHTTP429s
+	SignalProviderHTTP5xxs    = signalspec.ProviderHTTP5xxs
+	SignalProviderRetryAfterS = signalspec.ProviderRetryAfterS
+	SignalStreamWriteGapMS    = signalspec.StreamWriteGapMS
+	SignalConnectionChurnPerS = signalspec.ConnectionChurnPerS
+	SignalConntrackUtilPct    = signalspec.ConntrackUtilizationPct
+	SignalConntrackDrops      = signalspec.ConntrackDrops
 )
 
 // CapabilityMode defines probe coverage level.
 type CapabilityMode string
 
 const (
-	CapabilityCoreFull    CapabilityMode = "core_full"
-	CapabilityBCCDegraded CapabilityMode = "bcc_degraded"
-)
-
-var (
-	coreSignalSet = []string{
-		SignalDNSLatencyMS,
-		SignalTCPRetransmits,
-		SignalRunqueueDelayMS,
-		SignalConnectLatencyMS,
-		SignalConnectErrors,
-		SignalTLSHandshakeMS,
-		SignalTLSHandshakeFails,
-		SignalCPUStealPct,
-		SignalCFSThrottledMS,
-		SignalMemReclaimLatencyMS,
-		SignalDiskIOLatencyMS,
-		SignalSyscallLatencyMS,
-	}
-	bccSignalSet = []string{
-		SignalDNSLatencyMS,
-		SignalTCPRetransmits,
-	}
-	highCostDisableOrder = []string{
-		SignalTLSHandshakeMS,
-		SignalSyscallLatencyMS,
-		SignalRunqueueDelayMS,
-		SignalDiskIOLatencyMS,
-		SignalConnectLatencyMS,
-		SignalMemReclaimLatencyMS,
-		SignalCPUStealPct,
-		SignalDNSLatencyMS,
-		SignalTCPRetransmits,
-		SignalCFSThrottledMS,
-		SignalConnectErrors,
-		SignalTLSHandshakeFails,
-	}
+	CapabilityCoreFull    CapabilityMode = signalspec.ModeCoreFull
+	CapabilityBCCDegraded CapabilityMode = signalspec.ModeBCCDegraded
 )
 
 // RequiredMinimumSignals returns the six required v0.2 signal names.
 func RequiredMinimumSignals() []string {
-	return []string{
-		SignalDNSLatencyMS,
-		SignalTCPRetransmits,
-		SignalRunqueueDelayMS,
-		SignalConnectLatencyMS,
-		SignalTLSHandshakeMS,
-		SignalCPUStealPct,
-	}
+	return signalspec.Required()
 }
 
 // SupportedSignalsForMode returns the supported signal set for a capability mode.
 func SupportedSignalsForMode(mode CapabilityMode) []string {
-	switch mode {
-	case CapabilityBCCDegraded:
-		return cloneStrings(bccSignalSet)
-	default:
-		return cloneStrings(coreSignalSet)
+	if mode == CapabilityBCCDegraded {
+		return signalspec.ForMode(signalspec.ModeBCCDegraded)
 	}
+	return signalspec.ForMode(signalspec.ModeCoreFull)
 }
 
 // DisableOrder returns preferred signal disable order when overhead exceeds budget.
 func DisableOrder() []string {
-	return cloneStrings(highCostDisableOrder)
-}
-
-func cloneStrings(src []string) []string {
-	out := make([]string, len(src))
-	copy(out, src)
-	return out
+	return signalspec.DisableOrder()
 }
@@ -7,30 +7,22 @@ import (
 
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/collector"
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
 )
 
+// signalProfile holds synthetic values for one fault label, keyed by signal.
 type signalProfile struct {
-	dnsLatencyMS        float64
-	tcpRetransmits      float64
-	runqueueDelayMS     float64
-	connectLatencyMS    float64
-	connectErrors       float64
-	connectErrno        int
-	tlsHandshakeMS      float64
-	tlsHandshakeFails   float64
-	cpuStealPct         float64
-	cfsThrottledMS      float64
-	memReclaimLatencyMS float64
-	diskIOLatencyMS     float64
-	syscallLatencyMS    float64
+	values map[string]float64
+	errnos map[string]int
 }
 
 // Generator emits normalized probe events for the configured signal set.
 type Generator struct {
-	mu       sync.RWMutex
-	mode     CapabilityMode
-	enabled  map[string]struct{}
-	enricher MetadataEnricher
+	mu         sync.RWMutex
+	mode       CapabilityMode
+	enabled    map[string]struct{}
+	enricher   MetadataEnricher
+	thresholds *signalspec.ThresholdTable
 }
 
 // NewGenerator builds a probe generator with capability filtering.
@@ -69,6 +61,13 @@ func (g *Generator) SetSignals(signalSet []string) {
 	}
 }
 
+// SetThresholds replaces the status thresholds; nil uses registry defaults.
+func (g *Generator) SetThresholds(table *signalspec.ThresholdTable) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.thresholds = table
+}
+
 // EnabledSignals returns a stable list of enabled signal names.
 func (g *Generator) EnabledSignals() []string {
 	g.mu.RLock()
@@ -88,6 +87,23 @@ func (g *Generator) Mode() CapabilityMode {
 	return g.mode
 }
 
+// Enable enables one signal if the capability mode supports it and it is
+// not already enabled.
+func (g *Generator) Enable(signal string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.enabled[signal]; ok {
+		return false
+	}
+	for _, supported := range SupportedSignalsForMode(g.mode) {
+		if supported == signal {
+			g.enabled[signal] = struct{}{}
+			return true
+		}
+	}
+	return false
+}
+
 // Disable disables one signal if it is currently enabled.
 func (g *Generator) Disable(signal string) bool {
 	g.mu.Lock()
@@ -119,6 +135,7 @@ func (g *Generator) Generate(sample collector.RawSample, meta Metadata) []schema
 	for k := range g.enabled {
 		enabled[k] = struct{}{}
 	}
+	thresholds := g.thresholds
 	g.mu.RUnlock()
 
 	if len(enabled) == 0 {
@@ -130,30 +147,80 @@ func (g *Generator) Generate(sample collector.RawSample, meta Metadata) []schema
 	}
 	profile := profileForFault(sample.FaultLabel)
 	tuple := defaultConnTuple(sample)
+	workload := signalspec.Workload{Namespace: meta.Namespace, Service: meta.Service}
 
 	out := make([]schema.ProbeEventV1, 0, len(enabled))
-	appendIfEnabled := func(signal string, event schema.ProbeEventV1) {
-		if _, ok := enabled[signal]; ok {
-			out = append(out, event)
+	for _, desc := range signalspec.All() {
+		if _, ok := enabled[desc.Name]; !ok {
+			continue
+		}
+		var eventTuple *schema.ConnTuple
+		if desc.ConnScoped {
+			eventTuple = &tuple
 		}
+		errno := profile.errnos[desc.Name]
+		value := profile.values[desc.Name]
+		status := thresholds.Status(desc.Name, workload, value)
+		out = append(out, newEvent(sample.Timestamp, desc.Name, value, desc.Unit, status, meta, eventTuple, errno, 0))
 	}
 
-	appendIfEnabled(SignalDNSLatencyMS, newEvent(sample.Timestamp, SignalDNSLatencyMS, profile.dnsLatencyMS, "ms", meta, &tuple, 0, 0))
-	appendIfEnabled(SignalTCPRetransmits, newEvent(sample.Timestamp, SignalTCPRetransmits, profile.tcpRetransmits, "count", meta, &tuple, 0, 0))
-	appendIfEnabled(SignalRunqueueDelayMS, newEvent(sample.Timestamp, SignalRunqueueDelayMS, profile.runqueueDelayMS, "ms", meta, nil, 0, 0))
-	appendIfEnabled(SignalConnectLatencyMS, newEvent(sample.Timestamp, SignalConnectLatencyMS, profile.connectLatencyMS, "ms", meta, &tuple, profile.connectErrno, 0))
-	appendIfEnabled(SignalConnectErrors, newEvent(sample.Timestamp, SignalConnectErrors, profile.connectErrors, "count", meta, &tuple, profile.connectErrno, 0))
-	appendIfEnabled(SignalTLSHandshakeMS, newEvent(sample.Timestamp, SignalTLSHandshakeMS, profile.tlsHandshakeMS, "ms", meta, &tuple, 0, 0))
-	appendIfEnabled(SignalTLSHandshakeFails, newEvent(sample.Timestamp, SignalTLSHandshakeFails, profile.tlsHandshakeFails, "count", meta, &tuple, 0, 0))
-	appendIfEnabled(SignalCPUStealPct, newEvent(sample.Timestamp, SignalCPUStealPct, profile.cpuStealPct, "pct", meta, nil, 0, 0))
-	appendIfEnabled(SignalCFSThrottledMS, newEvent(sample.Timestamp, SignalCFSThrottledMS, profile.cfsThrottledMS, "ms", meta, nil, 0, 0))
-	appendIfEnabled(SignalMemReclaimLatencyMS, newEvent(sample.Timestamp, SignalMemReclaimLatencyMS, profile.memReclaimLatencyMS, "ms", meta, nil, 0, 0))
-	appendIfEnabled(SignalDiskIOLatencyMS, newEvent(sample.Timestamp, SignalDiskIOLatencyMS, profile.diskIOLatencyMS, "ms", meta, nil, 0, 0))
-	appendIfEnabled(SignalSyscallLatencyMS, newEvent(sample.Timestamp, SignalSyscallLatencyMS, profile.syscallLatencyMS, "ms", meta, nil, 0, 0))
+	return out
+}
 
+// MemoryEvents turns one pod's memory.events growth into probe events for
+// the enabled memcg signals. Counters that did not grow are skipped.
+func (g *Generator) MemoryEvents(sample collector.MemoryEventsSample, meta Metadata) []schema.ProbeEventV1 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if meta.Pod == "" {
+		meta.Pod = sample.PodUID
+	}
+	if g.enricher != nil {
+		meta = g.enricher.Enrich(meta)
+	}
+	workload := signalspec.Workload{Namespace: meta.Namespace, Service: meta.Service}
+
+	out := make([]schema.ProbeEventV1, 0, 3)
+	for _, c := range []struct {
+		signal string
+		delta  uint64
+	}{
+		{SignalMemcgHighEvents, sample.Delta.High},
+		{SignalMemcgMaxEvents, sample.Delta.Max},
+		{SignalMemcgOOMKillEvents, sample.Delta.OOMKill},
+	} {
+		if _, ok := g.enabled[c.signal]; !ok || c.delta == 0 {
+			continue
+		}
+		desc, _ := signalspec.Lookup(c.signal)
+		value := float64(c.delta)
+		status := g.thresholds.Status(c.signal, workload, value)
+		out = append(out, newEvent(sample.Timestamp, c.signal, value, desc.Unit, status, meta, nil, 0, 0))
+	}
 	return out
 }
 
+// Conntrack turns one reading of the node's conntrack table into a
+// conntrack_utilization_pct event, if that signal is enabled. The event is
+// node-level: it names no pod, and global thresholds apply.
+func (g *Generator) Conntrack(sample collector.ConntrackSample, meta Metadata) []schema.ProbeEventV1 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if _, ok := g.enabled[SignalConntrackUtilPct]; !ok || sample.Max == 0 {
+		return nil
+	}
+	if g.enricher != nil {
+		meta = g.enricher.Enrich(meta)
+	}
+	meta = Metadata{Node: meta.Node}
+	desc, _ := signalspec.Lookup(SignalConntrackUtilPct)
+	value := sample.UtilizationPct()
+	status := g.thresholds.Status(SignalConntrackUtilPct, signalspec.Workload{}, value)
+	return []schema.ProbeEventV1{newEvent(sample.Timestamp, SignalConntrackUtilPct, value, desc.Unit, status, meta, nil, 0, 0)}
+}
+
 func defaultConnTuple(sample collector.RawSample) schema.ConnTuple {
 	return schema.ConnTuple{
 		SrcIP:    "10.244.0.10",
@@ -169,6 +236,7 @@ func newEvent(
 	signal string,
 	value float64,
 	unit string,
+	status string,
 	meta Metadata,
 	tuple *schema.ConnTuple,
 	errno int,
@@ -186,7 +254,7 @@ func newEvent(
 		ConnTuple:  tuple,
 		Value:      value,
 		Unit:       unit,
-		Status:     signalStatus(signal, value),
+		Status:     status,
 		TraceID:    meta.TraceID,
 		SpanID:     meta.SpanID,
 	}
@@ -200,90 +268,82 @@ func newEvent(
 	return event
 }
 
-func signalStatus(signal string, value float64) string {
-	switch signal {
-	case SignalDNSLatencyMS:
-		return threshold(value, 40, 120)
-	case SignalTCPRetransmits:
-		return threshold(value, 2, 5)
-	case SignalRunqueueDelayMS:
-		return threshold(value, 10, 25)
-	case SignalConnectLatencyMS:
-		return threshold(value, 80, 180)
-	case SignalConnectErrors:
-		return threshold(value, 1, 3)
-	case SignalTLSHandshakeMS:
-		return threshold(value, 60, 160)
-	case SignalTLSHandshakeFails:
-		return threshold(value, 1, 3)
-	case SignalCPUStealPct:
-		return threshold(value, 2, 8)
-	case SignalCFSThrottledMS:
-		return threshold(value, 40, 120)
-	case SignalMemReclaimLatencyMS:
-		return threshold(value, 5, 20)
-	case SignalDiskIOLatencyMS:
-		return threshold(value, 10, 50)
-	case SignalSyscallLatencyMS:
-		return threshold(value, 50, 200)
-	default:
-		return "ok"
-	}
-}
-
-func threshold(value float64, warning float64, errorCutoff float64) string {
-	if value >= errorCutoff {
-		return "error"
-	}
-	if value >= warning {
-		return "warning"
-	}
-	return "ok"
-}
-
 func profileForFault(faultLabel string) signalProfile {
-	base := signalProfile{
-		dnsLatencyMS:        12,
-		tcpRetransmits:      0.2,
-		runqueueDelayMS:     4,
-		connectLatencyMS:    18,
-		connectErrors:       0,
-		connectErrno:        0,
-		tlsHandshakeMS:      22,
-		tlsHandshakeFails:   0,
-		cpuStealPct:         0.6,
-		cfsThrottledMS:      5,
-		memReclaimLatencyMS: 0.5,
-		diskIOLatencyMS:     2,
-		syscallLatencyMS:    5,
+	base := signalProfile{values: make(map[string]float64), errnos: make(map[string]int)}
+	for _, desc := range signalspec.All() {
+		base.values[desc.Name] = desc.Baseline
 	}
+	v := base.values
 
 	switch faultLabel {
 	case "dns_latency":
-		base.dnsLatencyMS = 220
-		base.connectLatencyMS = 130
+		v[SignalDNSLatencyMS] = 220
+		v[SignalConnectLatencyMS] = 130
+	case "dns_search_amplification":
+		v[SignalDNSLatencyMS] = 55
+		v[SignalDNSNXDomains] = 24
+		v[SignalDNSQueriesPerLookup] = 5
 	case "cpu_throttle":
-		base.runqueueDelayMS = 28
-		base.cpuStealPct = 9
-		base.cfsThrottledMS = 170
+		v[SignalRunqueueDelayMS] = 28
+		v[SignalCPUStealPct] = 9
+		v[SignalCFSThrottledMS] = 170
+		v[SignalStreamWriteGapMS] = 320
 	case "memory_pressure":
-		base.runqueueDelayMS = 14
-		base.cfsThrottledMS = 90
-		base.memReclaimLatencyMS = 25
-		base.diskIOLatencyMS = 60
+		v[SignalRunqueueDelayMS] = 14
+		v[SignalCFSThrottledMS] = 90
+		v[SignalMemReclaimLatencyMS] = 25
+		v[SignalDiskIOLatencyMS] = 60
+		v[SignalOOMKills] = 1
+		v[SignalMemcgHighEvents] = 40
+		v[SignalMemcgMaxEvents] = 6
+		v[SignalMemcgOOMKillEvents] = 1
+		v[SignalStreamWriteGapMS] = 450
 	case "provider_throttle":
-		base.connectLatencyMS = 45
-		base.tlsHandshakeMS = 55
-		base.connectErrors = 1
-		base.connectErrno = 110
-		base.syscallLatencyMS = 250
+		v[SignalConnectLatencyMS] = 45
+		v[SignalTLSHandshakeMS] = 55
+		v[SignalConnectErrors] = 1
+		base.setConnectErrno(110)
+		v[SignalSyscallLatencyMS] = 250
+		v[SignalTCPSRTTMS] = 140
+		v[SignalTCPZeroWindows] = 3
+		v[SignalProviderHTTP429s] = 6
+		v[SignalProviderRetryAfterS] = 20
 	case "network_partition":
-		base.connectLatencyMS = 350
-		base.connectErrors = 3
-		base.connectErrno = 113
-		base.tcpRetransmits = 12
-		base.dnsLatencyMS = 180
-		base.tlsHandshakeFails = 2
+		v[SignalConnectLatencyMS] = 350
+		v[SignalConnectErrors] = 3
+		base.setConnectErrno(113)
+		v[SignalTCPRetransmits] = 12
+		v[SignalDNSLatencyMS] = 180
+		v[SignalTLSHandshakeFails] = 2
+		v[SignalTCPSRTTMS] = 420
+	case "provider_error":
+		v[SignalTCPResets] = 9
+		base.errnos[SignalTCPResets] = 104 // ECONNRESET: provider tore down the stream
+		v[SignalTLSHandshakeFails] = 1
+		v[SignalProviderHTTP5xxs] = 8
+		v[SignalConnectionChurnPerS] = 4 // clients reconnect after each reset
+	case "conntrack_exhaustion":
+		v[SignalConntrackUtilPct] = 100
+		v[SignalConntrackDrops] = 40
+		base.errnos[SignalConntrackDrops] = 12 // ENOMEM: table full
+		// Dropped SYNs are retransmitted after 1s.
+		v[SignalConnectLatencyMS] = 1100
+		v[SignalConnectErrors] = 2
+		base.setConnectErrno(110)
+	case "gateway_saturation":
+		v[SignalListenOverflows] = 14
+		base.errnos[SignalListenOverflows] = 105 // ENOBUFS: accept queue full
+		v[SignalTCPResets] = 6
+		base.errnos[SignalTCPResets] = 103 // ECONNABORTED: gateway reset clients
+		v[SignalRunqueueDelayMS] = 12
+		v[SignalSyscallLatencyMS] = 90
+		v[SignalConnectLatencyMS] = 65
+		v[SignalConnectionChurnPerS] = 3
 	}
 	return base
 }
+
+func (p signalProfile) setConnectErrno(errno int) {
+	p.errnos[SignalConnectLatencyMS] = errno
+	p.errnos[SignalConnectErrors] = errno
+}
@@ -1,10 +1,13 @@
 package signals
 
 import (
+	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/collector"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
 )
 
 func TestGeneratorCoreFullEmitsRequiredSignals(t *testing.T) {
@@ -120,6 +123,35 @@ func TestGeneratorMemoryPressureProfile(t *testing.T) {
 		if event.Signal == SignalDiskIOLatencyMS && event.Value < 50 {
 			t.Errorf("disk_io_latency_ms under memory_pressure should be elevated, got %f", event.Value)
 		}
+		if (event.Signal == SignalOOMKills || event.Signal == SignalMemcgOOMKillEvents) && event.Status == "ok" {
+			t.Errorf("%s under memory_pressure should not be ok, got %f", event.Signal, event.Value)
+		}
+	}
+}
+
+func TestGeneratorResetErrnos(t *testing.T) {
+	g := NewGenerator(CapabilityCoreFull, []string{SignalTCPResets, SignalListenOverflows, SignalConnectErrors}, StaticMetadataEnricher{
+		Defaults: Metadata{Node: "n", Namespace: "ns", Pod: "p", Container: "c", PID: 1, TID: 1},
+	})
+
+	events := g.Generate(collector.RawSample{Timestamp: time.Unix(1710000000, 0).UTC(), FaultLabel: "gateway_saturation"}, Metadata{})
+	errnos := make(map[string]int)
+	for _, event := range events {
+		if event.ConnTuple == nil {
+			t.Errorf("%s: missing conn tuple", event.Signal)
+		}
+		if event.Errno != nil {
+			errnos[event.Signal] = *event.Errno
+		}
+		if event.Signal != SignalConnectErrors && event.Status == "ok" {
+			t.Errorf("%s under gateway_saturation should not be ok, got %f", event.Signal, event.Value)
+		}
+	}
+	if errnos[SignalListenOverflows] != 105 || errnos[SignalTCPResets] != 103 {
+		t.Fatalf("unexpected errnos: %v", errnos)
+	}
+	if _, ok := errnos[SignalConnectErrors]; ok {
+		t.Fatalf("connect errors should carry no errno under gateway_saturation: %v", errnos)
 	}
 }
 
@@ -133,3 +165,131 @@ func TestGeneratorDisableHighestCost(t *testing.T) {
 		t.Fatalf("expected highest-cost tls signal, got %s", first)
 	}
 }
+
+func TestGeneratorEnable(t *testing.T) {
+	g := NewGenerator(CapabilityCoreFull, []string{SignalDNSLatencyMS}, nil)
+	if !g.Enable(SignalTLSHandshakeMS) || g.Enable(SignalTLSHandshakeMS) {
+		t.Fatal("expected tls signal to be enabled once")
+	}
+	if g.Enable("not_a_signal") {
+		t.Fatal("unsupported signal should not be enabled")
+	}
+	if got := g.EnabledSignals(); len(got) != 2 {
+		t.Fatalf("expected two enabled signals, got %v", got)
+	}
+}
+
+func TestParseNSInode(t *testing.T) {
+	if got := parseNSInode("net:[4026531840]"); got != 4026531840 {
+		t.Fatalf("expected 4026531840, got %d", got)
+	}
+	for _, bad := range []string{"", "net:[]", "net:4026531840", "net:[abc]"} {
+		if got := parseNSInode(bad); got != 0 {
+			t.Fatalf("expected 0 for %q, got %d", bad, got)
+		}
+	}
+}
+
+func TestProcessIdentityUsesEventPID(t *testing.T) {
+	base := NodeIdentity(Metadata{Service: "rag", Workload: "rag-api"})
+	comm, err := os.ReadFile("/proc/self/comm")
+	if err != nil {
+		t.Skipf("no procfs: %v", err)
+	}
+	self := ProcessIdentity(base, os.Getpid())
+	if self.Comm != strings.TrimSpace(string(comm)) || self.NetNS == 0 {
+		t.Fatalf("expected comm and netns of pid %d, got %+v", os.Getpid(), self)
+	}
+	if self.Service != "rag" || self.Workload != "rag-api" || self.NodeBootID != base.NodeBootID {
+		t.Fatalf("expected node identity to carry over, got %+v", self)
+	}
+
+	// An unknown or vanished PID must not inherit another process's identity.
+	for _, pid := range []int{0, -1, 1 << 30} {
+		id := ProcessIdentity(self, pid)
+		if id.Comm != "" || id.NetNS != 0 {
+			t.Fatalf("expected empty process identity for pid %d, got %+v", pid, id)
+		}
+		if id.Service != "rag" {
+			t.Fatalf("expected node identity for pid %d, got %+v", pid, id)
+		}
+	}
+}
+
+func TestGeneratorCoversRegistry(t *testing.T) {
+	g := NewGenerator(CapabilityCoreFull, nil, nil)
+	events := g.Generate(collector.RawSample{Timestamp: time.Unix(1710000000, 0).UTC()}, Metadata{PID: 1, TID: 1})
+	names := signalspec.Names()
+	if len(events) != len(names) {
+		t.Fatalf("expected one event per registry signal (%d), got %d", len(names), len(events))
+	}
+	for idx, ev := range events {
+		desc, ok := signalspec.Lookup(ev.Signal)
+		if !ok || ev.Signal != names[idx] {
+			t.Fatalf("event %d: signal %q out of registry order", idx, ev.Signal)
+		}
+		if ev.Unit != desc.Unit {
+			t.Errorf("%s: unit %q, want %q", ev.Signal, ev.Unit, desc.Unit)
+		}
+		if (ev.ConnTuple != nil) != desc.ConnScoped {
+			t.Errorf("%s: conn tuple presence does not match ConnScoped", ev.Signal)
+		}
+	}
+
+	for _, fault := range []string{"dns_latency", "cpu_throttle", "memory_pressure", "provider_throttle", "network_partition", "conntrack_exhaustion"} {
+		for signal := range profileForFault(fault).values {
+			if _, ok := signalspec.Lookup(signal); !ok {
+				t.Errorf("fault %s overrides unregistered signal %s", fault, signal)
+			}
+		}
+	}
+}
+
+func TestGeneratorMemoryEvents(t *testing.T) {
+	g := NewGenerator(CapabilityCoreFull, []string{SignalMemcgHighEvents, SignalMemcgOOMKillEvents}, StaticMetadataEnricher{
+		Defaults: Metadata{Node: "n", Namespace: "ns", Container: "c", PID: 1, TID: 1},
+	})
+	sample := collector.MemoryEventsSample{
+		Timestamp: time.Unix(1710000000, 0).UTC(),
+		PodUID:    "0f6a3c1e-6b2d-4f7a-9c1e-2b7d5e8a9f10",
+		Delta:     collector.MemoryEventCounts{High: 40, Max: 2, OOMKill: 1},
+	}
+
+	events := g.MemoryEvents(sample, Metadata{})
+	if len(events) != 2 {
+		t.Fatalf("expected high and oom_kill events (max disabled), got %+v", events)
+	}
+	for _, ev := range events {
+		if ev.Pod != sample.PodUID || ev.Unit != "count" {
+			t.Fatalf("unexpected event identity: %+v", ev)
+		}
+	}
+	if events[0].Signal != SignalMemcgHighEvents || events[0].Value != 40 || events[0].Status != "warning" {
+		t.Fatalf("unexpected high event: %+v", events[0])
+	}
+	if events[1].Signal != SignalMemcgOOMKillEvents || events[1].Status != "warning" {
+		t.Fatalf("unexpected oom_kill event: %+v", events[1])
+	}
+}
+
+func TestGeneratorConntrack(t *testing.T) {
+	g := NewGenerator(CapabilityCoreFull, []string{SignalConntrackUtilPct}, StaticMetadataEnricher{
+		Defaults: Metadata{Node: "n", Namespace: "ns", Pod: "agent", Container: "c", PID: 1, TID: 1},
+	})
+	sample := collector.ConntrackSample{Timestamp: time.Unix(1710000000, 0).UTC(), Count: 255000, Max: 262144}
+
+	events := g.Conntrack(sample, Metadata{})
+	if len(events) != 1 {
+		t.Fatalf("expected one utilization event, got %+v", events)
+	}
+	ev := events[0]
+	if ev.Signal != SignalConntrackUtilPct || ev.Unit != "pct" || ev.Status != "error" || ev.Value < 97 || ev.Value > 98 {
+		t.Fatalf("unexpected utilization event: %+v", ev)
+	}
+	if ev.Node != "n" || ev.Pod != "" || ev.Namespace != "" || ev.PID != 0 {
+		t.Fatalf("conntrack event should be node-level: %+v", ev)
+	}
+	if got := g.Conntrack(collector.ConntrackSample{Count: 10}, Metadata{}); len(got) != 0 {
+		t.Fatalf("unknown table size should emit nothing, got %+v", got)
+	}
+}
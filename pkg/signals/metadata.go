@@ -3,7 +3,10 @@ package signals
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
 )
 
 // Metadata is the canonical workload identity attached to probe events.
@@ -117,6 +120,57 @@ func deriveFromCgroup(pid int) (string, string) {
 	return pod, container
 }
 
+// NodeIdentity returns the v1beta1 identity fields shared by every event
+// this agent emits: service, workload and the node boot ID.
+func NodeIdentity(meta Metadata) schema.ProbeIdentity {
+	return schema.ProbeIdentity{
+		Service:    meta.Service,
+		Workload:   meta.Workload,
+		NodeBootID: readBootID(),
+	}
+}
+
+// ProcessIdentity adds the comm and network namespace of pid, read from
+// /proc, to base. Fields that cannot be read, or any pid <= 0, leave them
+// empty; cgroup_id is only known to kernel probes and stays 0 here.
+func ProcessIdentity(base schema.ProbeIdentity, pid int) schema.ProbeIdentity {
+	id := base
+	id.Comm = ""
+	id.NetNS = 0
+	if pid <= 0 {
+		return id
+	}
+	if data, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid)); err == nil {
+		id.Comm = strings.TrimSpace(string(data))
+	}
+	if link, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/net", pid)); err == nil {
+		id.NetNS = parseNSInode(link)
+	}
+	return id
+}
+
+func readBootID() string {
+	data, err := os.ReadFile("/proc/sys/kernel/random/boot_id")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// parseNSInode extracts N from a namespace link such as "net:[4026531840]".
+func parseNSInode(link string) uint64 {
+	start := strings.IndexByte(link, '[')
+	end := strings.IndexByte(link, ']')
+	if start < 0 || end <= start+1 {
+		return 0
+	}
+	inode, err := strconv.ParseUint(link[start+1:end], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return inode
+}
+
 func normalizePodLabel(raw string) string {
 	if raw == "" {
 		return raw
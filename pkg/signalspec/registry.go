@@ -0,0 +1,917 @@
+// Package signalspec is the single registry of probe signals. Packages that
+// need per-signal facts (kernel type IDs, units, thresholds, semconv keys,
+// overhead cost, capability modes, attribution likelihoods) derive them from
+// here instead of keeping their own switch statements.
+package signalspec
+
+import (
+	"sort"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/semconv"
+)
+
+// Signal names.
+const (
+	DNSLatencyMS            = "dns_latency_ms"
+	TCPRetransmits          = "tcp_retransmits_total"
+	RunqueueDelayMS         = "runqueue_delay_ms"
+	ConnectLatencyMS        = "connect_latency_ms"
+	ConnectErrors           = "connect_errors_total"
+	TLSHandshakeMS          = "tls_handshake_ms"
+	TLSHandshakeFails       = "tls_handshake_fail_total"
+	CPUStealPct             = "cpu_steal_pct"
+	CFSThrottledMS          = "cfs_throttled_ms"
+	MemReclaimLatencyMS     = "mem_reclaim_latency_ms"
+	DiskIOLatencyMS         = "disk_io_latency_ms"
+	SyscallLatencyMS        = "syscall_latency_ms"
+	OOMKills                = "oom_kills_total"
+	MemcgHighEvents         = "memcg_high_events_total"
+	MemcgMaxEvents          = "memcg_max_events_total"
+	MemcgOOMKillEvents      = "memcg_oom_kill_events_total"
+	TCPSRTTMS               = "tcp_srtt_ms"
+	TCPZeroWindows          = "tcp_zero_window_total"
+	TCPResets               = "tcp_resets_total"
+	ListenOverflows         = "listen_overflows_total"
+	DNSNXDomains            = "dns_nxdomain_total"
+	DNSQueriesPerLookup     = "dns_queries_per_lookup"
+	ProviderHTTP429s        = "provider_http_429_total"
+	ProviderHTTP5xxs        = "provider_http_5xx_total"
+	ProviderRetryAfterS     = "provider_retry_after_s"
+	StreamWriteGapMS        = "stream_write_gap_ms"
+	ConnectionChurnPerS     = "connection_churn_per_s"
+	ConntrackUtilizationPct = "conntrack_utilization_pct"
+	ConntrackDrops          = "conntrack_drops_total"
+)
+
+// Kernel type IDs mirror enum llm_slo_signal_type in ebpf/c/llm_slo_event.h.
+const (
+	KernelDNSLatency     uint32 = 1
+	KernelTCPRetransmit  uint32 = 2
+	KernelRunqueueDelay  uint32 = 3
+	KernelConnectLatency uint32 = 4
+	KernelTLSHandshake   uint32 = 5
+	KernelCPUSteal       uint32 = 6
+	KernelMemReclaim     uint32 = 7
+	KernelDiskIOLatency  uint32 = 8
+	KernelSyscallLatency uint32 = 9
+	KernelOOMKill        uint32 = 10
+	KernelTCPSRTT        uint32 = 11
+	KernelTCPZeroWindow  uint32 = 12
+	KernelTCPReset       uint32 = 13
+	KernelListenOverflow uint32 = 14
+	KernelDNSNXDomain    uint32 = 15
+	KernelConntrackDrop  uint32 = 16
+)
+
+// Capability mode names.
+const (
+	ModeCoreFull    = "core_full"
+	ModeBCCDegraded = "bcc_degraded"
+)
+
+// Fault domains that likelihood rows are keyed by.
+const (
+	DomainNetworkDNS        = "network_dns"
+	DomainNetworkEgress     = "network_egress"
+	DomainCPUThrottle       = "cpu_throttle"
+	DomainMemoryPressure    = "memory_pressure"
+	DomainProviderThrottle  = "provider_throttle"
+	DomainProviderError     = "provider_error"
+	DomainRetrievalBackend  = "retrieval_backend"
+	DomainGatewaySaturation = "gateway_saturation"
+	DomainUnknown           = "unknown"
+)
+
+// Conversion turns a raw ring buffer value into the signal unit.
+type Conversion int
+
+const (
+	// ConvertNone passes the raw value through (counts, raw ns aggregated later).
+	ConvertNone Conversion = iota
+	// ConvertNSToMS converts nanoseconds to milliseconds.
+	ConvertNSToMS
+)
+
+// Apply converts one raw kernel value.
+func (c Conversion) Apply(raw uint64) float64 {
+	if c == ConvertNSToMS {
+		return float64(raw) / 1e6
+	}
+	return float64(raw)
+}
+
+// SignalDescriptor holds everything the toolkit knows about one signal.
+type SignalDescriptor struct {
+	Name string
+	// KernelType is the llm_slo_signal_type ID; 0 for signals derived in
+	// userspace or from other kernel events.
+	KernelType uint32
+	Unit       string
+	// KernelUnit is the unit at the ring buffer boundary when it differs
+	// from Unit (cpu_steal_pct arrives as ns and is aggregated later).
+	KernelUnit string
+	Conversion Conversion
+	// Warning and Error are inclusive status cutoffs; Warning is also the
+	// level at which attribution treats the signal as elevated.
+	Warning float64
+	Error   float64
+	// Attr is the semconv span attribute the correlator writes.
+	Attr string
+	// DisableCost ranks overhead; higher-cost signals are disabled first.
+	DisableCost int
+	Modes       []string
+	// Required marks the v0.2 minimum signal set.
+	Required bool
+	// ConnScoped signals carry a connection tuple.
+	ConnScoped bool
+	// Baseline is the healthy value used by the synthetic generator.
+	Baseline float64
+	// Likelihoods is P(signal elevated | fault domain).
+	Likelihoods map[string]float64
+}
+
+// RingBufUnit returns the unit reported for raw ring buffer events.
+func (d SignalDescriptor) RingBufUnit() string {
+	if d.KernelUnit != "" {
+		return d.KernelUnit
+	}
+	return d.Unit
+}
+
+// SupportsMode reports whether the signal is available in a capability mode.
+func (d SignalDescriptor) SupportsMode(mode string) bool {
+	for _, m := range d.Modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	coreOnly   = []string{ModeCoreFull}
+	coreAndBCC = []string{ModeCoreFull, ModeBCCDegraded}
+)
+
+// registry is ordered by emission order; keep new signals at the end.
+var registry = []SignalDescriptor{
+	{
+		Name:        DNSLatencyMS,
+		KernelType:  KernelDNSLatency,
+		Unit:        "ms",
+		Conversion:  ConvertNSToMS,
+		Warning:     40,
+		Error:       120,
+		Attr:        semconv.AttrDNSLatencyMS,
+		DisableCost: 50,
+		Modes:       coreAndBCC,
+		Required:    true,
+		ConnScoped:  true,
+		Baseline:    12,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.95,
+			DomainNetworkEgress:     0.40,
+			DomainCPUThrottle:       0.10,
+			DomainMemoryPressure:    0.10,
+			DomainProviderThrottle:  0.10,
+			DomainProviderError:     0.10,
+			DomainRetrievalBackend:  0.15,
+			DomainGatewaySaturation: 0.05,
+			DomainUnknown:           0.10,
+		},
+	},
+	{
+		Name:        TCPRetransmits,
+		KernelType:  KernelTCPRetransmit,
+		Unit:        "count",
+		Warning:     2,
+		Error:       5,
+		Attr:        semconv.AttrTCPRetransmits,
+		DisableCost: 40,
+		Modes:       coreAndBCC,
+		Required:    true,
+		ConnScoped:  true,
+		Baseline:    0.2,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.15,
+			DomainNetworkEgress:     0.90,
+			DomainCPUThrottle:       0.10,
+			DomainMemoryPressure:    0.10,
+			DomainProviderThrottle:  0.10,
+			DomainProviderError:     0.15,
+			DomainRetrievalBackend:  0.10,
+			DomainGatewaySaturation: 0.20,
+			DomainUnknown:           0.10,
+		},
+	},
+	{
+		Name:        RunqueueDelayMS,
+		KernelType:  KernelRunqueueDelay,
+		Unit:        "ms",
+		Conversion:  ConvertNSToMS,
+		Warning:     10,
+		Error:       25,
+		Attr:        semconv.AttrRunqueueDelayMS,
+		DisableCost: 100,
+		Modes:       coreOnly,
+		Required:    true,
+		Baseline:    4,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.10,
+			DomainNetworkEgress:     0.10,
+			DomainCPUThrottle:       0.90,
+			DomainMemoryPressure:    0.60,
+			DomainProviderThrottle:  0.10,
+			DomainProviderError:     0.10,
+			DomainRetrievalBackend:  0.10,
+			DomainGatewaySaturation: 0.40,
+			DomainUnknown:           0.10,
+		},
+	},
+	{
+		Name:        ConnectLatencyMS,
+		KernelType:  KernelConnectLatency,
+		Unit:        "ms",
+		Conversion:  ConvertNSToMS,
+		Warning:     80,
+		Error:       180,
+		Attr:        semconv.AttrConnectLatencyMS,
+		DisableCost: 80,
+		Modes:       coreOnly,
+		Required:    true,
+		ConnScoped:  true,
+		Baseline:    18,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.50,
+			DomainNetworkEgress:     0.85,
+			DomainCPUThrottle:       0.10,
+			DomainMemoryPressure:    0.10,
+			DomainProviderThrottle:  0.75,
+			DomainProviderError:     0.40,
+			DomainRetrievalBackend:  0.30,
+			DomainGatewaySaturation: 0.45,
+			DomainUnknown:           0.10,
+		},
+	},
+	{
+		Name:        ConnectErrors,
+		Unit:        "count",
+		Warning:     1,
+		Error:       3,
+		Attr:        semconv.AttrConnectErrors,
+		DisableCost: 20,
+		Modes:       coreOnly,
+		ConnScoped:  true,
+		Baseline:    0,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.10,
+			DomainNetworkEgress:     0.80,
+			DomainCPUThrottle:       0.05,
+			DomainMemoryPressure:    0.05,
+			DomainProviderThrottle:  0.60,
+			DomainProviderError:     0.85,
+			DomainRetrievalBackend:  0.15,
+			DomainGatewaySaturation: 0.30,
+			DomainUnknown:           0.10,
+		},
+	},
+	{
+		Name:        TLSHandshakeMS,
+		KernelType:  KernelTLSHandshake,
+		Unit:        "ms",
+		Conversion:  ConvertNSToMS,
+		Warning:     60,
+		Error:       160,
+		Attr:        semconv.AttrTLSHandshakeMS,
+		DisableCost: 120,
+		Modes:       coreOnly,
+		Required:    true,
+		ConnScoped:  true,
+		Baseline:    22,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.10,
+			DomainNetworkEgress:     0.30,
+			DomainCPUThrottle:       0.10,
+			DomainMemoryPressure:    0.10,
+			DomainProviderThrottle:  0.80,
+			DomainProviderError:     0.50,
+			DomainRetrievalBackend:  0.20,
+			DomainGatewaySaturation: 0.30,
+			DomainUnknown:           0.10,
+		},
+	},
+	{
+		Name:        TLSHandshakeFails,
+		Unit:        "count",
+		Warning:     1,
+		Error:       3,
+		Attr:        semconv.AttrTLSHandshakeFails,
+		DisableCost: 10,
+		Modes:       coreOnly,
+		ConnScoped:  true,
+		Baseline:    0,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.05,
+			DomainNetworkEgress:     0.70,
+			DomainCPUThrottle:       0.05,
+			DomainMemoryPressure:    0.05,
+			DomainProviderThrottle:  0.30,
+			DomainProviderError:     0.60,
+			DomainRetrievalBackend:  0.10,
+			DomainGatewaySaturation: 0.10,
+			DomainUnknown:           0.05,
+		},
+	},
+	{
+		Name:        CPUStealPct,
+		KernelType:  KernelCPUSteal,
+		Unit:        "pct",
+		KernelUnit:  "ns",
+		Warning:     2,
+		Error:       8,
+		Attr:        semconv.AttrCPUStealPct,
+		DisableCost: 60,
+		Modes:       coreOnly,
+		Required:    true,
+		Baseline:    0.6,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.10,
+			DomainNetworkEgress:     0.10,
+			DomainCPUThrottle:       0.90,
+			DomainMemoryPressure:    0.20,
+			DomainProviderThrottle:  0.10,
+			DomainProviderError:     0.10,
+			DomainRetrievalBackend:  0.10,
+			DomainGatewaySaturation: 0.10,
+			DomainUnknown:           0.10,
+		},
+	},
+	{
+		Name:        CFSThrottledMS,
+		Unit:        "ms",
+		Warning:     40,
+		Error:       120,
+		Attr:        semconv.AttrCFSThrottledMS,
+		DisableCost: 30,
+		Modes:       coreOnly,
+		Baseline:    5,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.10,
+			DomainNetworkEgress:     0.10,
+			DomainCPUThrottle:       0.85,
+			DomainMemoryPressure:    0.75,
+			DomainProviderThrottle:  0.10,
+			DomainProviderError:     0.10,
+			DomainRetrievalBackend:  0.10,
+			DomainGatewaySaturation: 0.35,
+			DomainUnknown:           0.10,
+		},
+	},
+	{
+		Name:        MemReclaimLatencyMS,
+		KernelType:  KernelMemReclaim,
+		Unit:        "ms",
+		Conversion:  ConvertNSToMS,
+		Warning:     5,
+		Error:       20,
+		Attr:        semconv.AttrMemReclaimLatencyMS,
+		DisableCost: 70,
+		Modes:       coreOnly,
+		Baseline:    0.5,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.05,
+			DomainNetworkEgress:     0.05,
+			DomainCPUThrottle:       0.15,
+			DomainMemoryPressure:    0.95,
+			DomainProviderThrottle:  0.05,
+			DomainProviderError:     0.05,
+			DomainRetrievalBackend:  0.05,
+			DomainGatewaySaturation: 0.10,
+			DomainUnknown:           0.05,
+		},
+	},
+	{
+		Name:        DiskIOLatencyMS,
+		KernelType:  KernelDiskIOLatency,
+		Unit:        "ms",
+		Conversion:  ConvertNSToMS,
+		Warning:     10,
+		Error:       50,
+		Attr:        semconv.AttrDiskIOLatencyMS,
+		DisableCost: 90,
+		Modes:       coreOnly,
+		Baseline:    2,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.05,
+			DomainNetworkEgress:     0.05,
+			DomainCPUThrottle:       0.10,
+			DomainMemoryPressure:    0.85,
+			DomainProviderThrottle:  0.05,
+			DomainProviderError:     0.05,
+			DomainRetrievalBackend:  0.30,
+			DomainGatewaySaturation: 0.05,
+			DomainUnknown:           0.05,
+		},
+	},
+	{
+		Name:        SyscallLatencyMS,
+		KernelType:  KernelSyscallLatency,
+		Unit:        "ms",
+		Conversion:  ConvertNSToMS,
+		Warning:     50,
+		Error:       200,
+		Attr:        semconv.AttrSyscallLatencyMS,
+		DisableCost: 110,
+		Modes:       coreOnly,
+		Baseline:    5,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.10,
+			DomainNetworkEgress:     0.20,
+			DomainCPUThrottle:       0.15,
+			DomainMemoryPressure:    0.10,
+			DomainProviderThrottle:  0.90,
+			DomainProviderError:     0.60,
+			DomainRetrievalBackend:  0.40,
+			DomainGatewaySaturation: 0.40,
+			DomainUnknown:           0.10,
+		},
+	},
+	// OOM and memory.events likelihoods sit at the evidence cutoff for
+	// memory_pressure rather than near 1: they are decisive when present but
+	// absent from many memory-pressure incidents, and unobserved signals
+	// count against a domain.
+	{
+		Name:        OOMKills,
+		KernelType:  KernelOOMKill,
+		Unit:        "count",
+		Warning:     1,
+		Error:       2,
+		Attr:        semconv.AttrOOMKills,
+		DisableCost: 15,
+		Modes:       coreOnly,
+		Baseline:    0,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.02,
+			DomainNetworkEgress:     0.02,
+			DomainCPUThrottle:       0.03,
+			DomainMemoryPressure:    0.75,
+			DomainProviderThrottle:  0.02,
+			DomainProviderError:     0.05,
+			DomainRetrievalBackend:  0.05,
+			DomainGatewaySaturation: 0.05,
+			DomainUnknown:           0.02,
+		},
+	},
+	{
+		Name:        MemcgHighEvents,
+		Unit:        "count",
+		Warning:     1,
+		Error:       100,
+		Attr:        semconv.AttrMemcgHighEvents,
+		DisableCost: 25,
+		Modes:       coreOnly,
+		Baseline:    0,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.03,
+			DomainNetworkEgress:     0.03,
+			DomainCPUThrottle:       0.10,
+			DomainMemoryPressure:    0.55,
+			DomainProviderThrottle:  0.03,
+			DomainProviderError:     0.03,
+			DomainRetrievalBackend:  0.05,
+			DomainGatewaySaturation: 0.10,
+			DomainUnknown:           0.05,
+		},
+	},
+	{
+		Name:        MemcgMaxEvents,
+		Unit:        "count",
+		Warning:     1,
+		Error:       10,
+		Attr:        semconv.AttrMemcgMaxEvents,
+		DisableCost: 35,
+		Modes:       coreOnly,
+		Baseline:    0,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.02,
+			DomainNetworkEgress:     0.02,
+			DomainCPUThrottle:       0.05,
+			DomainMemoryPressure:    0.50,
+			DomainProviderThrottle:  0.02,
+			DomainProviderError:     0.03,
+			DomainRetrievalBackend:  0.05,
+			DomainGatewaySaturation: 0.05,
+			DomainUnknown:           0.03,
+		},
+	},
+	{
+		Name:        MemcgOOMKillEvents,
+		Unit:        "count",
+		Warning:     1,
+		Error:       2,
+		Attr:        semconv.AttrMemcgOOMKillEvents,
+		DisableCost: 45,
+		Modes:       coreOnly,
+		Baseline:    0,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.02,
+			DomainNetworkEgress:     0.02,
+			DomainCPUThrottle:       0.03,
+			DomainMemoryPressure:    0.70,
+			DomainProviderThrottle:  0.02,
+			DomainProviderError:     0.05,
+			DomainRetrievalBackend:  0.05,
+			DomainGatewaySaturation: 0.05,
+			DomainUnknown:           0.02,
+		},
+	},
+	// Smoothed RTT and zero-window stalls are sampled per connection from
+	// tcp_probe and carry the full tuple, so they join spans at the
+	// pod_conn tier. A slow path raises srtt; a provider that stops reading
+	// or a client that stops draining shows up as a zero window.
+	{
+		Name:        TCPSRTTMS,
+		KernelType:  KernelTCPSRTT,
+		Unit:        "ms",
+		Conversion:  ConvertNSToMS,
+		Warning:     100,
+		Error:       300,
+		Attr:        semconv.AttrTCPSRTTMS,
+		DisableCost: 65,
+		Modes:       coreOnly,
+		ConnScoped:  true,
+		Baseline:    2,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.10,
+			DomainNetworkEgress:     0.70,
+			DomainCPUThrottle:       0.05,
+			DomainMemoryPressure:    0.05,
+			DomainProviderThrottle:  0.50,
+			DomainProviderError:     0.15,
+			DomainRetrievalBackend:  0.10,
+			DomainGatewaySaturation: 0.05,
+			DomainUnknown:           0.05,
+		},
+	},
+	{
+		Name:        TCPZeroWindows,
+		KernelType:  KernelTCPZeroWindow,
+		Unit:        "count",
+		Warning:     1,
+		Error:       5,
+		Attr:        semconv.AttrTCPZeroWindows,
+		DisableCost: 55,
+		Modes:       coreOnly,
+		ConnScoped:  true,
+		Baseline:    0,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.02,
+			DomainNetworkEgress:     0.60,
+			DomainCPUThrottle:       0.10,
+			DomainMemoryPressure:    0.05,
+			DomainProviderThrottle:  0.55,
+			DomainProviderError:     0.10,
+			DomainRetrievalBackend:  0.05,
+			DomainGatewaySaturation: 0.10,
+			DomainUnknown:           0.03,
+		},
+	},
+	// Resets and accept-queue overflows carry a tuple and errno. A burst of
+	// resets points at the provider (or a proxy) killing streams; listen
+	// overflows with resets point at our own gateway failing to accept.
+	{
+		Name:        TCPResets,
+		KernelType:  KernelTCPReset,
+		Unit:        "count",
+		Warning:     3,
+		Error:       20,
+		Attr:        semconv.AttrTCPResets,
+		DisableCost: 85,
+		Modes:       coreOnly,
+		ConnScoped:  true,
+		Baseline:    0,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.03,
+			DomainNetworkEgress:     0.30,
+			DomainCPUThrottle:       0.05,
+			DomainMemoryPressure:    0.05,
+			DomainProviderThrottle:  0.20,
+			DomainProviderError:     0.55,
+			DomainRetrievalBackend:  0.10,
+			DomainGatewaySaturation: 0.55,
+			DomainUnknown:           0.05,
+		},
+	},
+	{
+		Name:        ListenOverflows,
+		KernelType:  KernelListenOverflow,
+		Unit:        "count",
+		Warning:     1,
+		Error:       50,
+		Attr:        semconv.AttrListenOverflows,
+		DisableCost: 75,
+		Modes:       coreOnly,
+		ConnScoped:  true,
+		Baseline:    0,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.02,
+			DomainNetworkEgress:     0.03,
+			DomainCPUThrottle:       0.15,
+			DomainMemoryPressure:    0.05,
+			DomainProviderThrottle:  0.03,
+			DomainProviderError:     0.03,
+			DomainRetrievalBackend:  0.03,
+			DomainGatewaySaturation: 0.60,
+			DomainUnknown:           0.03,
+		},
+	},
+	// NXDOMAIN counts and queries per logical lookup separate search-path
+	// amplification (ndots:5 walking cluster suffixes) from a slow resolver,
+	// which only raises dns_latency_ms.
+	{
+		Name:        DNSNXDomains,
+		KernelType:  KernelDNSNXDomain,
+		Unit:        "count",
+		Warning:     5,
+		Error:       50,
+		Attr:        semconv.AttrDNSNXDomains,
+		DisableCost: 95,
+		Modes:       coreOnly,
+		ConnScoped:  true,
+		Baseline:    0,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.55,
+			DomainNetworkEgress:     0.05,
+			DomainCPUThrottle:       0.02,
+			DomainMemoryPressure:    0.02,
+			DomainProviderThrottle:  0.03,
+			DomainProviderError:     0.05,
+			DomainRetrievalBackend:  0.10,
+			DomainGatewaySaturation: 0.02,
+			DomainUnknown:           0.05,
+		},
+	},
+	{
+		Name:        DNSQueriesPerLookup,
+		Unit:        "count",
+		Warning:     3,
+		Error:       6,
+		Attr:        semconv.AttrDNSQueriesPerLookup,
+		DisableCost: 105,
+		Modes:       coreOnly,
+		Baseline:    1,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.50,
+			DomainNetworkEgress:     0.03,
+			DomainCPUThrottle:       0.02,
+			DomainMemoryPressure:    0.02,
+			DomainProviderThrottle:  0.02,
+			DomainProviderError:     0.03,
+			DomainRetrievalBackend:  0.10,
+			DomainGatewaySaturation: 0.02,
+			DomainUnknown:           0.05,
+		},
+	},
+	// Provider response statuses parsed from TLS plaintext and Go net/http
+	// (http_status.bpf.c) tell provider_throttle and provider_error apart
+	// directly instead of inferring them from connection behaviour.
+	{
+		Name:        ProviderHTTP429s,
+		Unit:        "count",
+		Warning:     1,
+		Error:       10,
+		Attr:        semconv.AttrProviderHTTP429s,
+		DisableCost: 115,
+		Modes:       coreOnly,
+		Baseline:    0,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.02,
+			DomainNetworkEgress:     0.03,
+			DomainCPUThrottle:       0.02,
+			DomainMemoryPressure:    0.02,
+			DomainProviderThrottle:  0.75,
+			DomainProviderError:     0.05,
+			DomainRetrievalBackend:  0.03,
+			DomainGatewaySaturation: 0.03,
+			DomainUnknown:           0.02,
+		},
+	},
+	{
+		Name:        ProviderHTTP5xxs,
+		Unit:        "count",
+		Warning:     1,
+		Error:       5,
+		Attr:        semconv.AttrProviderHTTP5xxs,
+		DisableCost: 114,
+		Modes:       coreOnly,
+		Baseline:    0,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.02,
+			DomainNetworkEgress:     0.05,
+			DomainCPUThrottle:       0.02,
+			DomainMemoryPressure:    0.02,
+			DomainProviderThrottle:  0.08,
+			DomainProviderError:     0.75,
+			DomainRetrievalBackend:  0.03,
+			DomainGatewaySaturation: 0.05,
+			DomainUnknown:           0.02,
+		},
+	},
+	{
+		Name:        ProviderRetryAfterS,
+		Unit:        "s",
+		Warning:     1,
+		Error:       30,
+		Attr:        semconv.AttrProviderRetryAfterS,
+		DisableCost: 113,
+		Modes:       coreOnly,
+		Baseline:    0,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.02,
+			DomainNetworkEgress:     0.02,
+			DomainCPUThrottle:       0.02,
+			DomainMemoryPressure:    0.02,
+			DomainProviderThrottle:  0.55,
+			DomainProviderError:     0.10,
+			DomainRetrievalBackend:  0.02,
+			DomainGatewaySaturation: 0.02,
+			DomainUnknown:           0.02,
+		},
+	},
+	{
+		Name:        StreamWriteGapMS,
+		Unit:        "ms",
+		Warning:     250,
+		Error:       1000,
+		Attr:        semconv.AttrStreamWriteGapMS,
+		DisableCost: 112,
+		Modes:       coreOnly,
+		ConnScoped:  true,
+		Baseline:    40,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.02,
+			DomainNetworkEgress:     0.10,
+			DomainCPUThrottle:       0.70,
+			DomainMemoryPressure:    0.60,
+			DomainProviderThrottle:  0.10,
+			DomainProviderError:     0.05,
+			DomainRetrievalBackend:  0.05,
+			DomainGatewaySaturation: 0.20,
+			DomainUnknown:           0.10,
+		},
+	},
+	{
+		Name:        ConnectionChurnPerS,
+		Unit:        "per_s",
+		Warning:     2,
+		Error:       10,
+		Attr:        semconv.AttrConnectionChurnPerS,
+		DisableCost: 111,
+		Modes:       coreOnly,
+		ConnScoped:  true,
+		Baseline:    0.2,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.10,
+			DomainNetworkEgress:     0.25,
+			DomainCPUThrottle:       0.05,
+			DomainMemoryPressure:    0.05,
+			DomainProviderThrottle:  0.20,
+			DomainProviderError:     0.30,
+			DomainRetrievalBackend:  0.15,
+			DomainGatewaySaturation: 0.35,
+			DomainUnknown:           0.40,
+		},
+	},
+	// A full conntrack table drops new connections on the node before they
+	// leave it. Utilization comes from polling nf_conntrack_count against
+	// nf_conntrack_max; drops from conntrack_drop.bpf.c carry the tuple of
+	// the connection that could not be tracked.
+	{
+		Name:        ConntrackUtilizationPct,
+		Unit:        "pct",
+		Warning:     80,
+		Error:       95,
+		Attr:        semconv.AttrConntrackUtilizationPct,
+		DisableCost: 12,
+		Modes:       coreOnly,
+		Baseline:    20,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.05,
+			DomainNetworkEgress:     0.70,
+			DomainCPUThrottle:       0.03,
+			DomainMemoryPressure:    0.03,
+			DomainProviderThrottle:  0.03,
+			DomainProviderError:     0.03,
+			DomainRetrievalBackend:  0.05,
+			DomainGatewaySaturation: 0.05,
+			DomainUnknown:           0.02,
+		},
+	},
+	{
+		Name:        ConntrackDrops,
+		KernelType:  KernelConntrackDrop,
+		Unit:        "count",
+		Warning:     1,
+		Error:       10,
+		Attr:        semconv.AttrConntrackDrops,
+		DisableCost: 116,
+		Modes:       coreOnly,
+		ConnScoped:  true,
+		Baseline:    0,
+		Likelihoods: map[string]float64{
+			DomainNetworkDNS:        0.10,
+			DomainNetworkEgress:     0.70,
+			DomainCPUThrottle:       0.02,
+			DomainMemoryPressure:    0.02,
+			DomainProviderThrottle:  0.02,
+			DomainProviderError:     0.05,
+			DomainRetrievalBackend:  0.05,
+			DomainGatewaySaturation: 0.05,
+			DomainUnknown:           0.02,
+		},
+	},
+}
+
+var (
+	byName       = make(map[string]int, len(registry))
+	byKernelType = make(map[uint32]int, len(registry))
+)
+
+func init() {
+	for idx, d := range registry {
+		byName[d.Name] = idx
+		if d.KernelType != 0 {
+			byKernelType[d.KernelType] = idx
+		}
+	}
+}
+
+// All returns every descriptor in registry order. Modes and Likelihoods
+// are shared with the registry; treat them as read-only.
+func All() []SignalDescriptor {
+	out := make([]SignalDescriptor, len(registry))
+	copy(out, registry)
+	return out
+}
+
+// Names returns every signal name in registry order.
+func Names() []string {
+	out := make([]string, 0, len(registry))
+	for _, d := range registry {
+		out = append(out, d.Name)
+	}
+	return out
+}
+
+// Lookup returns the descriptor for a signal name.
+func Lookup(name string) (SignalDescriptor, bool) {
+	idx, ok := byName[name]
+	if !ok {
+		return SignalDescriptor{}, false
+	}
+	return registry[idx], true
+}
+
+// ByKernelType returns the descriptor for a ring buffer signal type.
+func ByKernelType(kernelType uint32) (SignalDescriptor, bool) {
+	idx, ok := byKernelType[kernelType]
+	if !ok {
+		return SignalDescriptor{}, false
+	}
+	return registry[idx], true
+}
+
+// ForMode returns the names supported in a capability mode, in registry order.
+func ForMode(mode string) []string {
+	out := make([]string, 0, len(registry))
+	for _, d := range registry {
+		if d.SupportsMode(mode) {
+			out = append(out, d.Name)
+		}
+	}
+	return out
+}
+
+// Required returns the v0.2 minimum signal set in registry order.
+func Required() []string {
+	out := make([]string, 0, len(registry))
+	for _, d := range registry {
+		if d.Required {
+			out = append(out, d.Name)
+		}
+	}
+	return out
+}
+
+// DisableOrder returns signal names from highest to lowest DisableCost.
+func DisableOrder() []string {
+	ordered := All()
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].DisableCost > ordered[j].DisableCost
+	})
+	out := make([]string, 0, len(ordered))
+	for _, d := range ordered {
+		out = append(out, d.Name)
+	}
+	return out
+}
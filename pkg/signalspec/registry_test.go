@@ -0,0 +1,147 @@
+package signalspec
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var allDomains = []string{
+	DomainNetworkDNS,
+	DomainNetworkEgress,
+	DomainCPUThrottle,
+	DomainMemoryPressure,
+	DomainProviderThrottle,
+	DomainProviderError,
+	DomainRetrievalBackend,
+	DomainGatewaySaturation,
+	DomainUnknown,
+}
+
+func TestRegistryDescriptorsAreComplete(t *testing.T) {
+	attrs := make(map[string]string)
+	for _, d := range All() {
+		if d.Name == "" || d.Unit == "" {
+			t.Fatalf("descriptor missing name or unit: %+v", d)
+		}
+		if d.Attr == "" {
+			t.Errorf("%s: missing semconv attribute", d.Name)
+		} else if other, dup := attrs[d.Attr]; dup {
+			t.Errorf("%s: semconv attribute %s already used by %s", d.Name, d.Attr, other)
+		}
+		attrs[d.Attr] = d.Name
+		if d.Warning <= 0 || d.Error <= d.Warning {
+			t.Errorf("%s: thresholds must satisfy 0 < warning < error, got %v/%v", d.Name, d.Warning, d.Error)
+		}
+		if d.DisableCost <= 0 {
+			t.Errorf("%s: missing disable cost", d.Name)
+		}
+		if !d.SupportsMode(ModeCoreFull) {
+			t.Errorf("%s: every signal must be available in %s", d.Name, ModeCoreFull)
+		}
+		for _, mode := range d.Modes {
+			if mode != ModeCoreFull && mode != ModeBCCDegraded {
+				t.Errorf("%s: unknown capability mode %q", d.Name, mode)
+			}
+		}
+		if len(d.Likelihoods) != len(allDomains) {
+			t.Errorf("%s: likelihood row has %d domains, want %d", d.Name, len(d.Likelihoods), len(allDomains))
+		}
+		for _, domain := range allDomains {
+			p, ok := d.Likelihoods[domain]
+			if !ok || p <= 0 || p >= 1 {
+				t.Errorf("%s: likelihood for %s must be in (0,1), got %v (present=%v)", d.Name, domain, p, ok)
+			}
+		}
+		if got, ok := Lookup(d.Name); !ok || got.Name != d.Name {
+			t.Errorf("%s: Lookup failed", d.Name)
+		}
+	}
+}
+
+func TestRegistryUniqueCosts(t *testing.T) {
+	seen := make(map[int]string)
+	for _, d := range All() {
+		if other, dup := seen[d.DisableCost]; dup {
+			t.Errorf("%s and %s share disable cost %d; disable order would be ambiguous", d.Name, other, d.DisableCost)
+		}
+		seen[d.DisableCost] = d.Name
+	}
+	order := DisableOrder()
+	if len(order) != len(All()) || order[0] != TLSHandshakeMS || order[len(order)-1] != TLSHandshakeFails {
+		t.Fatalf("unexpected disable order %v", order)
+	}
+}
+
+// TestKernelTypesMatchHeader keeps the registry and enum llm_slo_signal_type
+// in sync in both directions.
+func TestKernelTypesMatchHeader(t *testing.T) {
+	header, err := os.ReadFile(filepath.Join("..", "..", "ebpf", "c", "llm_slo_event.h"))
+	if err != nil {
+		t.Fatalf("read header: %v", err)
+	}
+	matches := regexp.MustCompile(`(?m)^\s*(LLM_SLO_\w+)\s*=\s*(\d+),`).FindAllStringSubmatch(string(header), -1)
+	if len(matches) == 0 {
+		t.Fatal("no enum llm_slo_signal_type values found")
+	}
+	inHeader := make(map[uint32]string, len(matches))
+	for _, m := range matches {
+		id, _ := strconv.ParseUint(m[2], 10, 32)
+		inHeader[uint32(id)] = m[1]
+		if _, ok := ByKernelType(uint32(id)); !ok {
+			t.Errorf("%s = %d has no registry descriptor", m[1], id)
+		}
+	}
+	for _, d := range All() {
+		if d.KernelType == 0 {
+			continue
+		}
+		if _, ok := inHeader[d.KernelType]; !ok {
+			t.Errorf("%s: kernel type %d missing from llm_slo_event.h", d.Name, d.KernelType)
+		}
+	}
+}
+
+// TestSignalSetEnumMatchesRegistry keeps the signal_set enum in
+// config/toolkit.schema.json equal to the registry, in registry order.
+func TestSignalSetEnumMatchesRegistry(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "config", "toolkit.schema.json"))
+	if err != nil {
+		t.Fatalf("read schema: %v", err)
+	}
+	var schema struct {
+		Properties struct {
+			SignalSet struct {
+				Items struct {
+					Enum []string `json:"enum"`
+				} `json:"items"`
+			} `json:"signal_set"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+	got := strings.Join(schema.Properties.SignalSet.Items.Enum, ",")
+	if want := strings.Join(Names(), ","); got != want {
+		t.Fatalf("signal_set enum drifted from the registry:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestConversionAndStatus(t *testing.T) {
+	if v := ConvertNSToMS.Apply(2500000); v != 2.5 {
+		t.Fatalf("ns->ms: got %v, want 2.5", v)
+	}
+	if v := ConvertNone.Apply(7); v != 7 {
+		t.Fatalf("pass-through: got %v, want 7", v)
+	}
+	var registryOnly *ThresholdTable
+	for value, want := range map[float64]string{12: "ok", 40: "warning", 120: "error"} {
+		if got := registryOnly.Status(DNSLatencyMS, Workload{}, value); got != want {
+			t.Errorf("status(%v): got %s, want %s", value, got, want)
+		}
+	}
+}
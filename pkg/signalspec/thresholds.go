@@ -0,0 +1,167 @@
+package signalspec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Threshold sources, from least to most specific.
+const (
+	SourceRegistry         = "registry"
+	SourceDefaults         = "defaults"
+	SourceNamespace        = "namespace"
+	SourceService          = "service"
+	SourceNamespaceService = "namespace/service"
+)
+
+// ThresholdOverride replaces one or both cutoffs of a signal. Nil fields
+// inherit from the less specific level.
+type ThresholdOverride struct {
+	Warning *float64
+	Error   *float64
+}
+
+// Workload identifies the scope thresholds are resolved for.
+type Workload struct {
+	Namespace string
+	Service   string
+}
+
+// EffectiveThreshold is the resolved pair of cutoffs for one signal and
+// workload, with the level each cutoff came from.
+type EffectiveThreshold struct {
+	Signal        string  `json:"signal"`
+	Unit          string  `json:"unit"`
+	Warning       float64 `json:"warning"`
+	Error         float64 `json:"error"`
+	WarningSource string  `json:"warning_source"`
+	ErrorSource   string  `json:"error_source"`
+}
+
+// Status classifies value against the resolved cutoffs.
+func (t EffectiveThreshold) Status(value float64) string {
+	if value >= t.Error {
+		return "error"
+	}
+	if value >= t.Warning {
+		return "warning"
+	}
+	return "ok"
+}
+
+// Elevated reports whether value counts as attribution evidence.
+func (t EffectiveThreshold) Elevated(value float64) bool {
+	return value >= t.Warning
+}
+
+// ThresholdTable layers threshold overrides on top of the registry.
+// Resolution order is registry < Defaults < Namespaces[ns] < Services[svc]
+// < Services["ns/svc"]. A nil table resolves to registry values.
+type ThresholdTable struct {
+	Defaults   map[string]ThresholdOverride
+	Namespaces map[string]map[string]ThresholdOverride
+	// Services is keyed by service name, or "namespace/service" to scope
+	// an override to one namespace.
+	Services map[string]map[string]ThresholdOverride
+}
+
+// Resolve returns the effective thresholds for signal in workload w.
+func (t *ThresholdTable) Resolve(signal string, w Workload) (EffectiveThreshold, bool) {
+	desc, ok := Lookup(signal)
+	if !ok {
+		return EffectiveThreshold{}, false
+	}
+	eff := EffectiveThreshold{
+		Signal:        desc.Name,
+		Unit:          desc.Unit,
+		Warning:       desc.Warning,
+		Error:         desc.Error,
+		WarningSource: SourceRegistry,
+		ErrorSource:   SourceRegistry,
+	}
+	if t == nil {
+		return eff, true
+	}
+	apply := func(source string, overrides map[string]ThresholdOverride) {
+		o, ok := overrides[signal]
+		if !ok {
+			return
+		}
+		if o.Warning != nil {
+			eff.Warning = *o.Warning
+			eff.WarningSource = source
+		}
+		if o.Error != nil {
+			eff.Error = *o.Error
+			eff.ErrorSource = source
+		}
+	}
+	apply(SourceDefaults, t.Defaults)
+	if w.Namespace != "" {
+		apply(SourceNamespace, t.Namespaces[w.Namespace])
+	}
+	if w.Service != "" {
+		apply(SourceService, t.Services[w.Service])
+		if w.Namespace != "" {
+			apply(SourceNamespaceService, t.Services[w.Namespace+"/"+w.Service])
+		}
+	}
+	return eff, true
+}
+
+// Status classifies value for signal in workload w. Unknown signals are "ok".
+func (t *ThresholdTable) Status(signal string, w Workload, value float64) string {
+	eff, ok := t.Resolve(signal, w)
+	if !ok {
+		return "ok"
+	}
+	return eff.Status(value)
+}
+
+// Explain resolves every registered signal for w, in registry order.
+func (t *ThresholdTable) Explain(w Workload) []EffectiveThreshold {
+	out := make([]EffectiveThreshold, 0, len(registry))
+	for _, d := range registry {
+		eff, _ := t.Resolve(d.Name, w)
+		out = append(out, eff)
+	}
+	return out
+}
+
+// Validate rejects overrides for unknown signals and any scope whose
+// resolved warning cutoff is not below its error cutoff.
+func (t *ThresholdTable) Validate() error {
+	if t == nil {
+		return nil
+	}
+	var problems []string
+	check := func(scope string, overrides map[string]ThresholdOverride, w Workload) {
+		for signal := range overrides {
+			eff, ok := t.Resolve(signal, w)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: unknown signal %q", scope, signal))
+				continue
+			}
+			if eff.Warning >= eff.Error {
+				problems = append(problems, fmt.Sprintf("%s: %s warning %g must be below error %g", scope, signal, eff.Warning, eff.Error))
+			}
+		}
+	}
+	check("defaults", t.Defaults, Workload{})
+	for ns, overrides := range t.Namespaces {
+		check("namespaces."+ns, overrides, Workload{Namespace: ns})
+	}
+	for key, overrides := range t.Services {
+		w := Workload{Service: key}
+		if ns, svc, ok := strings.Cut(key, "/"); ok {
+			w = Workload{Namespace: ns, Service: svc}
+		}
+		check("services."+key, overrides, w)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("invalid thresholds: %s", strings.Join(problems, "; "))
+}
@@ -0,0 +1,84 @@
+package signalspec
+
+import (
+	"strings"
+	"testing"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func TestThresholdTableResolvePrecedence(t *testing.T) {
+	table := &ThresholdTable{
+		Defaults: map[string]ThresholdOverride{
+			DNSLatencyMS: {Warning: ptr(50), Error: ptr(150)},
+		},
+		Namespaces: map[string]map[string]ThresholdOverride{
+			"apac": {DNSLatencyMS: {Warning: ptr(80), Error: ptr(250)}},
+		},
+		Services: map[string]map[string]ThresholdOverride{
+			"rag":      {DNSLatencyMS: {Error: ptr(300)}},
+			"apac/rag": {DNSLatencyMS: {Warning: ptr(120)}},
+		},
+	}
+
+	cases := []struct {
+		name       string
+		workload   Workload
+		warn, err  float64
+		warnSource string
+		errSource  string
+	}{
+		{"defaults", Workload{Namespace: "default", Service: "chat"}, 50, 150, SourceDefaults, SourceDefaults},
+		{"namespace", Workload{Namespace: "apac", Service: "chat"}, 80, 250, SourceNamespace, SourceNamespace},
+		{"service keeps namespace warning", Workload{Namespace: "apac", Service: "rag"}, 120, 300, SourceNamespaceService, SourceService},
+		{"service outside namespace", Workload{Namespace: "emea", Service: "rag"}, 50, 300, SourceDefaults, SourceService},
+	}
+	for _, tc := range cases {
+		eff, ok := table.Resolve(DNSLatencyMS, tc.workload)
+		if !ok {
+			t.Fatalf("%s: dns not resolved", tc.name)
+		}
+		if eff.Warning != tc.warn || eff.Error != tc.err || eff.WarningSource != tc.warnSource || eff.ErrorSource != tc.errSource {
+			t.Errorf("%s: got %+v", tc.name, eff)
+		}
+	}
+
+	eff, _ := table.Resolve(TCPRetransmits, Workload{Namespace: "apac", Service: "rag"})
+	if eff.Warning != 2 || eff.WarningSource != SourceRegistry {
+		t.Errorf("untouched signal should keep registry values, got %+v", eff)
+	}
+	if _, ok := table.Resolve("not_a_signal", Workload{}); ok {
+		t.Error("unknown signal should not resolve")
+	}
+	if got := table.Status(DNSLatencyMS, Workload{Namespace: "apac"}, 100); got != "warning" {
+		t.Errorf("status: got %s, want warning", got)
+	}
+	if got := len(table.Explain(Workload{})); got != len(All()) {
+		t.Errorf("explain: got %d entries, want %d", got, len(All()))
+	}
+}
+
+func TestThresholdTableValidate(t *testing.T) {
+	table := &ThresholdTable{
+		Defaults: map[string]ThresholdOverride{
+			"dns_latency": {Warning: ptr(10)},
+		},
+		Services: map[string]map[string]ThresholdOverride{
+			"prod/rag": {DNSLatencyMS: {Warning: ptr(500)}},
+		},
+	}
+	err := table.Validate()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{`unknown signal "dns_latency"`, "services.prod/rag: dns_latency_ms warning 500 must be below error 120"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+
+	var none *ThresholdTable
+	if err := none.Validate(); err != nil {
+		t.Fatalf("nil table should validate: %v", err)
+	}
+}
@@ -0,0 +1,484 @@
+// Package spool implements a bounded, segmented on-disk write-ahead buffer
+// for output batches that could not be delivered.
+package spool
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxBytes bounds total on-disk spool size.
+	DefaultMaxBytes int64 = 256 << 20
+
+	// DefaultSegmentBytes is the size at which the active segment is sealed.
+	DefaultSegmentBytes int64 = 8 << 20
+
+	// DefaultMaxAge is how long spooled records remain eligible for replay.
+	DefaultMaxAge = time.Hour
+
+	segmentSuffix = ".seg"
+	cursorFile    = "cursor.json"
+
+	// recordHeaderSize is length(4) + crc32c(4) + unix nanos(8).
+	recordHeaderSize = 16
+
+	// maxRecordBytes rejects corrupted length prefixes before allocation.
+	maxRecordBytes = 64 << 20
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrRecordTooLarge is returned when one payload cannot fit the spool bounds.
+var ErrRecordTooLarge = errors.New("spool record exceeds size bounds")
+
+// ErrUndeliverable marks a Replay callback error as permanent. The record
+// is dropped instead of blocking every record behind it.
+var ErrUndeliverable = errors.New("spool record undeliverable")
+
+// Options configures spool bounds and location.
+type Options struct {
+	Dir          string
+	MaxBytes     int64
+	MaxAge       time.Duration
+	SegmentBytes int64
+}
+
+// Stats reports current spool occupancy and lifetime counters.
+type Stats struct {
+	Bytes           int64
+	Segments        int
+	Appended        uint64
+	Replayed        uint64
+	Expired         uint64
+	Corrupt         uint64
+	Dropped         uint64
+	EvictedSegments uint64
+}
+
+// ReplayResult summarizes one Replay pass.
+type ReplayResult struct {
+	Replayed int
+	Expired  int
+	Corrupt  int
+	Dropped  int
+}
+
+// Spool is a directory of append-only segment files. Each record carries a
+// CRC32C checksum and its append timestamp. Records are replayed oldest
+// first, and a persisted cursor makes partially replayed segments resume at
+// the first undelivered record after a restart.
+type Spool struct {
+	mu       sync.Mutex
+	replayMu sync.Mutex
+
+	opts Options
+	now  func() time.Time
+
+	segments   []segment // sealed segments, oldest first
+	active     *os.File
+	activeSeq  uint64
+	activeSize int64
+	cursor     cursor
+
+	stats Stats
+}
+
+type segment struct {
+	seq  uint64
+	size int64
+	mod  time.Time
+}
+
+type cursor struct {
+	Segment uint64 `json:"segment"`
+	Offset  int64  `json:"offset"`
+}
+
+// Open creates or reopens a spool directory. Existing segments from a
+// previous process are sealed and queued for replay; new appends always
+// go to a fresh segment so a torn tail never receives more data.
+func Open(opts Options) (*Spool, error) {
+	opts = normalizeOptions(opts)
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("spool dir is required")
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create spool dir: %w", err)
+	}
+
+	s := &Spool{opts: opts, now: time.Now}
+	if err := s.loadSegments(); err != nil {
+		return nil, err
+	}
+	s.loadCursor()
+
+	nextSeq := uint64(1)
+	if n := len(s.segments); n > 0 {
+		nextSeq = s.segments[n-1].seq + 1
+	}
+	if err := s.openActive(nextSeq); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.enforceLimitsLocked()
+	s.mu.Unlock()
+	return s, nil
+}
+
+func normalizeOptions(opts Options) Options {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultMaxBytes
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = DefaultMaxAge
+	}
+	if opts.SegmentBytes <= 0 {
+		opts.SegmentBytes = DefaultSegmentBytes
+	}
+	// Keep several segments within the byte budget so eviction stays granular.
+	if limit := opts.MaxBytes / 4; opts.SegmentBytes > limit && limit > 0 {
+		opts.SegmentBytes = limit
+	}
+	return opts
+}
+
+// Append durably writes one payload to the active segment.
+func (s *Spool) Append(payload []byte) error {
+	size := int64(recordHeaderSize + len(payload))
+	if size > s.opts.SegmentBytes || len(payload) > maxRecordBytes {
+		return ErrRecordTooLarge
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.active == nil {
+		return fmt.Errorf("spool is closed")
+	}
+	if s.activeSize > 0 && s.activeSize+size > s.opts.SegmentBytes {
+		if err := s.sealActiveLocked(); err != nil {
+			return err
+		}
+	}
+
+	buf := encodeRecord(s.now().UTC().UnixNano(), payload)
+	if _, err := s.active.Write(buf); err != nil {
+		return fmt.Errorf("write spool record: %w", err)
+	}
+	if err := s.active.Sync(); err != nil {
+		return fmt.Errorf("sync spool segment: %w", err)
+	}
+	s.activeSize += size
+	s.stats.Appended++
+	s.enforceLimitsLocked()
+	return nil
+}
+
+// Pending reports whether any undelivered records remain on disk.
+func (s *Spool) Pending() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.activeSize > 0 || len(s.segments) > 0
+}
+
+// Stats returns a snapshot of spool occupancy and counters.
+func (s *Spool) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := s.stats
+	out.Bytes = s.activeSize
+	out.Segments = len(s.segments)
+	for _, seg := range s.segments {
+		out.Bytes += seg.size
+	}
+	if s.activeSize > 0 {
+		out.Segments++
+	}
+	return out
+}
+
+// Replay delivers spooled records oldest first. Delivery stops at the first
+// error from fn; that record and everything after it stays on disk for the
+// next pass. An error wrapping ErrUndeliverable drops the record and
+// delivery continues. Records older than MaxAge are dropped without
+// delivery.
+func (s *Spool) Replay(fn func(payload []byte) error) (ReplayResult, error) {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	s.mu.Lock()
+	if s.activeSize > 0 {
+		if err := s.sealActiveLocked(); err != nil {
+			s.mu.Unlock()
+			return ReplayResult{}, err
+		}
+	}
+	s.enforceLimitsLocked()
+	pending := make([]segment, len(s.segments))
+	copy(pending, s.segments)
+	s.mu.Unlock()
+
+	var result ReplayResult
+	for _, seg := range pending {
+		done, err := s.replaySegment(seg, fn, &result)
+		if err != nil {
+			return result, err
+		}
+		if !done {
+			// Segment was evicted underneath us; continue with the next one.
+			continue
+		}
+		s.mu.Lock()
+		s.removeSegmentLocked(seg.seq)
+		s.mu.Unlock()
+	}
+	return result, nil
+}
+
+func (s *Spool) replaySegment(seg segment, fn func([]byte) error, result *ReplayResult) (bool, error) {
+	file, err := os.Open(s.segmentPath(seg.seq))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("open spool segment: %w", err)
+	}
+	defer file.Close()
+
+	s.mu.Lock()
+	offset := int64(0)
+	if s.cursor.Segment == seg.seq {
+		offset = s.cursor.Offset
+	}
+	s.mu.Unlock()
+	if offset > 0 {
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			return false, fmt.Errorf("seek spool segment: %w", err)
+		}
+	}
+
+	reader := bufio.NewReader(file)
+	cutoff := s.now().Add(-s.opts.MaxAge).UnixNano()
+	for {
+		ts, payload, n, err := decodeRecord(reader)
+		if err == io.EOF {
+			return true, nil
+		}
+		if err != nil {
+			// A torn or corrupted tail ends the segment; the rest is unreadable.
+			result.Corrupt++
+			s.mu.Lock()
+			s.stats.Corrupt++
+			s.mu.Unlock()
+			return true, nil
+		}
+
+		if ts < cutoff {
+			result.Expired++
+			s.mu.Lock()
+			s.stats.Expired++
+			s.mu.Unlock()
+		} else if err := fn(payload); errors.Is(err, ErrUndeliverable) {
+			result.Dropped++
+			s.mu.Lock()
+			s.stats.Dropped++
+			s.mu.Unlock()
+		} else if err != nil {
+			s.mu.Lock()
+			s.cursor = cursor{Segment: seg.seq, Offset: offset}
+			s.persistCursorLocked()
+			s.mu.Unlock()
+			return false, err
+		} else {
+			result.Replayed++
+			s.mu.Lock()
+			s.stats.Replayed++
+			s.mu.Unlock()
+		}
+		offset += n
+	}
+}
+
+// Close flushes and closes the active segment. An empty active segment is
+// removed so restarts do not accumulate empty files.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active == nil {
+		return nil
+	}
+	path := s.active.Name()
+	err := s.active.Close()
+	s.active = nil
+	if s.activeSize == 0 {
+		_ = os.Remove(path)
+	}
+	return err
+}
+
+func (s *Spool) openActive(seq uint64) error {
+	file, err := os.OpenFile(s.segmentPath(seq), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("open spool segment: %w", err)
+	}
+	s.active = file
+	s.activeSeq = seq
+	s.activeSize = 0
+	return nil
+}
+
+func (s *Spool) sealActiveLocked() error {
+	if err := s.active.Close(); err != nil {
+		return fmt.Errorf("close spool segment: %w", err)
+	}
+	s.segments = append(s.segments, segment{
+		seq:  s.activeSeq,
+		size: s.activeSize,
+		mod:  s.now(),
+	})
+	return s.openActive(s.activeSeq + 1)
+}
+
+// enforceLimitsLocked evicts whole sealed segments, oldest first, until the
+// spool fits both the byte and age budgets.
+func (s *Spool) enforceLimitsLocked() {
+	cutoff := s.now().Add(-s.opts.MaxAge)
+	for len(s.segments) > 0 {
+		total := s.activeSize
+		for _, seg := range s.segments {
+			total += seg.size
+		}
+		oldest := s.segments[0]
+		if total <= s.opts.MaxBytes && !oldest.mod.Before(cutoff) {
+			return
+		}
+		s.removeSegmentLocked(oldest.seq)
+		s.stats.EvictedSegments++
+	}
+}
+
+func (s *Spool) removeSegmentLocked(seq uint64) {
+	for idx, seg := range s.segments {
+		if seg.seq != seq {
+			continue
+		}
+		_ = os.Remove(s.segmentPath(seq))
+		s.segments = append(s.segments[:idx], s.segments[idx+1:]...)
+		break
+	}
+	if s.cursor.Segment == seq {
+		s.cursor = cursor{}
+		s.persistCursorLocked()
+	}
+}
+
+func (s *Spool) loadSegments() error {
+	entries, err := os.ReadDir(s.opts.Dir)
+	if err != nil {
+		return fmt.Errorf("read spool dir: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.Size() == 0 {
+			_ = os.Remove(filepath.Join(s.opts.Dir, name))
+			continue
+		}
+		s.segments = append(s.segments, segment{seq: seq, size: info.Size(), mod: info.ModTime()})
+	}
+	sort.Slice(s.segments, func(i, j int) bool {
+		return s.segments[i].seq < s.segments[j].seq
+	})
+	return nil
+}
+
+func (s *Spool) loadCursor() {
+	data, err := os.ReadFile(filepath.Join(s.opts.Dir, cursorFile))
+	if err != nil {
+		return
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return
+	}
+	s.cursor = c
+}
+
+func (s *Spool) persistCursorLocked() {
+	path := filepath.Join(s.opts.Dir, cursorFile)
+	if s.cursor == (cursor{}) {
+		_ = os.Remove(path)
+		return
+	}
+	data, err := json.Marshal(s.cursor)
+	if err != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, path)
+}
+
+func (s *Spool) segmentPath(seq uint64) string {
+	return filepath.Join(s.opts.Dir, fmt.Sprintf("%020d%s", seq, segmentSuffix))
+}
+
+func encodeRecord(tsUnixNano int64, payload []byte) []byte {
+	buf := make([]byte, recordHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint64(buf[8:16], uint64(tsUnixNano))
+	copy(buf[recordHeaderSize:], payload)
+	binary.LittleEndian.PutUint32(buf[4:8], crc32.Checksum(buf[8:], castagnoli))
+	return buf
+}
+
+// decodeRecord reads one record and returns its timestamp, payload and
+// encoded length. io.EOF is returned only on a clean record boundary.
+func decodeRecord(r io.Reader) (int64, []byte, int64, error) {
+	var header [recordHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.EOF {
+			return 0, nil, 0, io.EOF
+		}
+		return 0, nil, 0, fmt.Errorf("read spool record header: %w", err)
+	}
+	length := binary.LittleEndian.Uint32(header[0:4])
+	if length > maxRecordBytes {
+		return 0, nil, 0, fmt.Errorf("spool record length %d out of range", length)
+	}
+	body := make([]byte, 8+int(length))
+	copy(body[0:8], header[8:16])
+	if _, err := io.ReadFull(r, body[8:]); err != nil {
+		return 0, nil, 0, fmt.Errorf("read spool record payload: %w", err)
+	}
+	if crc32.Checksum(body, castagnoli) != binary.LittleEndian.Uint32(header[4:8]) {
+		return 0, nil, 0, fmt.Errorf("spool record checksum mismatch")
+	}
+	ts := int64(binary.LittleEndian.Uint64(header[8:16]))
+	return ts, body[8:], int64(recordHeaderSize + int(length)), nil
+}
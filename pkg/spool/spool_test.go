@@ -0,0 +1,290 @@
+package spool
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func collect(t *testing.T, s *Spool) []string {
+	t.Helper()
+	var out []string
+	if _, err := s.Replay(func(payload []byte) error {
+		out = append(out, string(payload))
+		return nil
+	}); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	return out
+}
+
+func TestAppendReplayInOrder(t *testing.T) {
+	s, err := Open(Options{Dir: t.TempDir(), SegmentBytes: 64, MaxBytes: 1 << 20})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer s.Close()
+
+	for i := 0; i < 10; i++ {
+		if err := s.Append([]byte(fmt.Sprintf("batch-%02d", i))); err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+	}
+	if !s.Pending() {
+		t.Fatal("expected pending records")
+	}
+
+	got := collect(t, s)
+	if len(got) != 10 {
+		t.Fatalf("expected 10 replayed records, got %d", len(got))
+	}
+	for i, payload := range got {
+		if want := fmt.Sprintf("batch-%02d", i); payload != want {
+			t.Fatalf("record %d: got %q want %q", i, payload, want)
+		}
+	}
+	if s.Pending() {
+		t.Fatal("expected spool to be drained")
+	}
+}
+
+func TestReplayStopsAtFailureAndResumes(t *testing.T) {
+	s, err := Open(Options{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer s.Close()
+
+	for _, p := range []string{"a", "b", "c"} {
+		if err := s.Append([]byte(p)); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	var delivered []string
+	_, err = s.Replay(func(payload []byte) error {
+		if string(payload) == "b" {
+			return errors.New("endpoint down")
+		}
+		delivered = append(delivered, string(payload))
+		return nil
+	})
+	if err == nil {
+		t.Fatal("expected replay error")
+	}
+	if len(delivered) != 1 || delivered[0] != "a" {
+		t.Fatalf("unexpected first pass delivery: %v", delivered)
+	}
+
+	rest := collect(t, s)
+	if len(rest) != 2 || rest[0] != "b" || rest[1] != "c" {
+		t.Fatalf("expected resume at b, got %v", rest)
+	}
+}
+
+func TestUndeliverableRecordDoesNotBlockReplay(t *testing.T) {
+	s, err := Open(Options{Dir: t.TempDir(), SegmentBytes: 64, MaxBytes: 1 << 20})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer s.Close()
+
+	for _, p := range []string{"a", "poison", "b", "c"} {
+		if err := s.Append([]byte(p)); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	var delivered []string
+	result, err := s.Replay(func(payload []byte) error {
+		if string(payload) == "poison" {
+			return fmt.Errorf("%w: HTTP 400", ErrUndeliverable)
+		}
+		delivered = append(delivered, string(payload))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(delivered) != 3 || delivered[0] != "a" || delivered[1] != "b" || delivered[2] != "c" {
+		t.Fatalf("expected a, b, c past the poison record, got %v", delivered)
+	}
+	if result.Replayed != 3 || result.Dropped != 1 || s.Stats().Dropped != 1 {
+		t.Fatalf("unexpected result %+v, stats %+v", result, s.Stats())
+	}
+	if s.Pending() {
+		t.Fatal("expected spool to be drained")
+	}
+}
+
+func TestSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(Options{Dir: dir})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for _, p := range []string{"one", "two", "three"} {
+		if err := s.Append([]byte(p)); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	// Deliver the first record, then fail so a cursor is persisted.
+	_, _ = s.Replay(func(payload []byte) error {
+		if string(payload) == "one" {
+			return nil
+		}
+		return errors.New("down")
+	})
+	if err := s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	reopened, err := Open(Options{Dir: dir})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	if err := reopened.Append([]byte("four")); err != nil {
+		t.Fatalf("append after restart: %v", err)
+	}
+
+	got := collect(t, reopened)
+	want := []string{"two", "three", "four"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v want %v", got, want)
+		}
+	}
+}
+
+func TestCorruptTailIsSkipped(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(Options{Dir: dir})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for _, p := range []string{"good-1", "good-2"} {
+		if err := s.Append([]byte(p)); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	path := s.active.Name()
+	_ = s.Close()
+
+	// Simulate a torn write: a header promising more bytes than exist.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open segment: %v", err)
+	}
+	if _, err := f.Write([]byte{0xff, 0x00, 0x00, 0x00, 0x01, 0x02}); err != nil {
+		t.Fatalf("write garbage: %v", err)
+	}
+	_ = f.Close()
+
+	reopened, err := Open(Options{Dir: dir})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+
+	var got []string
+	result, err := reopened.Replay(func(payload []byte) error {
+		got = append(got, string(payload))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(got) != 2 || result.Corrupt != 1 {
+		t.Fatalf("expected 2 good records and 1 corrupt tail, got %v corrupt=%d", got, result.Corrupt)
+	}
+	if reopened.Pending() {
+		t.Fatal("expected corrupt segment to be removed")
+	}
+}
+
+func TestMaxBytesEvictsOldestSegments(t *testing.T) {
+	s, err := Open(Options{Dir: t.TempDir(), MaxBytes: 400, SegmentBytes: 100})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer s.Close()
+
+	payload := make([]byte, 60)
+	for i := 0; i < 20; i++ {
+		payload[0] = byte(i)
+		if err := s.Append(payload); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	stats := s.Stats()
+	if stats.Bytes > 400 {
+		t.Fatalf("spool exceeds byte budget: %d", stats.Bytes)
+	}
+	if stats.EvictedSegments == 0 {
+		t.Fatal("expected eviction of oldest segments")
+	}
+
+	var first byte = 0xff
+	_, _ = s.Replay(func(p []byte) error {
+		if first == 0xff {
+			first = p[0]
+		}
+		return nil
+	})
+	if first == 0 {
+		t.Fatal("expected oldest record to be evicted")
+	}
+}
+
+func TestMaxAgeExpiresRecords(t *testing.T) {
+	s, err := Open(Options{Dir: t.TempDir(), MaxAge: time.Minute})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer s.Close()
+
+	base := time.Unix(1710000000, 0)
+	s.now = func() time.Time { return base }
+	if err := s.Append([]byte("stale")); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	s.now = func() time.Time { return base.Add(50 * time.Second) }
+	if err := s.Append([]byte("fresh")); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	s.now = func() time.Time { return base.Add(90 * time.Second) }
+	var got []string
+	result, err := s.Replay(func(p []byte) error {
+		got = append(got, string(p))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(got) != 1 || got[0] != "fresh" || result.Expired != 1 {
+		t.Fatalf("expected stale record expired, got %v expired=%d", got, result.Expired)
+	}
+}
+
+func TestRejectsOversizedRecord(t *testing.T) {
+	s, err := Open(Options{Dir: t.TempDir(), MaxBytes: 400, SegmentBytes: 100})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer s.Close()
+	if err := s.Append(make([]byte, 200)); !errors.Is(err, ErrRecordTooLarge) {
+		t.Fatalf("expected ErrRecordTooLarge, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(s.opts.Dir, cursorFile)); !os.IsNotExist(err) {
+		t.Fatalf("unexpected cursor file: %v", err)
+	}
+}
@@ -3,21 +3,38 @@ package toolkitcfg
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/baseline"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/correlation"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/deeptrace"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/dependency"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/otel/processor/ebpfcorrelator"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
 	"gopkg.in/yaml.v3"
 )
 
 // ToolkitConfig mirrors config/toolkit.yaml.
 type ToolkitConfig struct {
-	APIVersion  string            `yaml:"apiVersion"`
-	Kind        string            `yaml:"kind"`
-	SignalSet   []string          `yaml:"signal_set"`
-	Sampling    SamplingConfig    `yaml:"sampling"`
-	Correlation CorrelationConfig `yaml:"correlation"`
-	OTLP        OTLPConfig        `yaml:"otlp"`
-	Safety      SafetyConfig      `yaml:"safety"`
-	Webhook     WebhookConfig     `yaml:"webhook"`
-	CDGate      CDGateConfig      `yaml:"cdgate"`
+	APIVersion    string              `yaml:"apiVersion"`
+	Kind          string              `yaml:"kind"`
+	SignalSet     []string            `yaml:"signal_set"`
+	Sampling      SamplingConfig      `yaml:"sampling"`
+	Correlation   CorrelationConfig   `yaml:"correlation"`
+	OTLP          OTLPConfig          `yaml:"otlp"`
+	Safety        SafetyConfig        `yaml:"safety"`
+	Webhook       WebhookConfig       `yaml:"webhook"`
+	CDGate        CDGateConfig        `yaml:"cdgate"`
+	Spool         SpoolConfig         `yaml:"spool"`
+	Outputs       []OutputConfig      `yaml:"outputs"`
+	Thresholds    ThresholdsConfig    `yaml:"thresholds"`
+	Attribution   AttributionConfig   `yaml:"attribution"`
+	ProviderHTTP  ProviderHTTPConfig  `yaml:"provider_http"`
+	RequestTiming RequestTimingConfig `yaml:"request_timing"`
+	DeepTracing   DeepTracingConfig   `yaml:"deep_tracing"`
+	Dependencies  []DependencyConfig  `yaml:"dependencies"`
 }
 
 // SamplingConfig controls event-rate limiting.
@@ -26,9 +43,67 @@ type SamplingConfig struct {
 	BurstLimit           int `yaml:"burst_limit"`
 }
 
-// CorrelationConfig contains join-window tuning.
+// CorrelationConfig contains join-window tuning and the tier ladder. A
+// signal must be within WindowMS of a span to join it at any tier.
 type CorrelationConfig struct {
-	WindowMS int `yaml:"window_ms"`
+	WindowMS int                     `yaml:"window_ms"`
+	Tiers    []CorrelationTierConfig `yaml:"tiers"`
+}
+
+// CorrelationTierConfig is one rung of the correlation ladder. Tiers are
+// tried in order; a signal sharing Key with a span within WindowMS of it
+// (0 for only the correlation window) joins at Confidence. Enabled
+// defaults to true.
+type CorrelationTierConfig struct {
+	Name       string  `yaml:"name"`
+	Key        string  `yaml:"key"`
+	WindowMS   int     `yaml:"window_ms"`
+	Confidence float64 `yaml:"confidence"`
+	Enabled    *bool   `yaml:"enabled"`
+}
+
+func (c CorrelationTierConfig) enabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// TierTable returns the enabled tiers, in order.
+func (c CorrelationConfig) TierTable() correlation.Tiers {
+	tiers := make(correlation.Tiers, 0, len(c.Tiers))
+	for _, t := range c.Tiers {
+		if !t.enabled() {
+			continue
+		}
+		tiers = append(tiers, correlation.Tier{
+			Name:       t.Name,
+			Key:        correlation.JoinKey(t.Key),
+			Window:     time.Duration(t.WindowMS) * time.Millisecond,
+			Confidence: t.Confidence,
+		})
+	}
+	return tiers
+}
+
+// Correlator returns a span correlator with this window and tier ladder,
+// and default threshold and fanout.
+func (c CorrelationConfig) Correlator() ebpfcorrelator.Correlator {
+	correlator := ebpfcorrelator.New()
+	correlator.WindowMS = c.WindowMS
+	correlator.Tiers = c.TierTable()
+	return correlator
+}
+
+func defaultCorrelationTiers() []CorrelationTierConfig {
+	defaults := correlation.DefaultTiers()
+	tiers := make([]CorrelationTierConfig, 0, len(defaults))
+	for _, t := range defaults {
+		tiers = append(tiers, CorrelationTierConfig{
+			Name:       t.Name,
+			Key:        string(t.Key),
+			WindowMS:   int(t.Window / time.Millisecond),
+			Confidence: t.Confidence,
+		})
+	}
+	return tiers
 }
 
 // OTLPConfig contains collector endpoint settings.
@@ -60,6 +135,212 @@ type CDGateConfig struct {
 	FailOpen      bool    `yaml:"fail_open"`
 }
 
+// SpoolConfig configures the on-disk buffer for undeliverable output batches.
+type SpoolConfig struct {
+	Enabled          bool   `yaml:"enabled"`
+	Dir              string `yaml:"dir"`
+	MaxBytes         int64  `yaml:"max_bytes"`
+	SegmentBytes     int64  `yaml:"segment_bytes"`
+	MaxAgeSeconds    int    `yaml:"max_age_seconds"`
+	ReplayIntervalMS int    `yaml:"replay_interval_ms"`
+}
+
+// WebhookOutputName is the sink name the agent gives its incident webhook.
+// Configured outputs may not use it.
+const WebhookOutputName = "webhook"
+
+// outputKinds lists the event kinds each output type can write.
+var outputKinds = map[string][]string{
+	"stdout": {"slo", "probe", "incident"},
+	"jsonl":  {"slo", "probe", "incident"},
+	"otlp":   {"slo", "probe"},
+}
+
+// OutputConfig declares one output sink. An empty Kinds list routes SLO and
+// probe events. Rotation fields apply to jsonl sinks only. Unset MaxBytes
+// and MaxFiles fall back to DefaultOutputMaxBytes and DefaultOutputMaxFiles;
+// 0 means unlimited.
+type OutputConfig struct {
+	Name                  string   `yaml:"name"`
+	Type                  string   `yaml:"type"`
+	Path                  string   `yaml:"path"`
+	Endpoint              string   `yaml:"endpoint"`
+	TimeoutMS             int      `yaml:"timeout_ms"`
+	Kinds                 []string `yaml:"kinds"`
+	BatchSize             int      `yaml:"batch_size"`
+	FlushIntervalMS       int      `yaml:"flush_interval_ms"`
+	QueueSize             int      `yaml:"queue_size"`
+	MaxBytes              *int64   `yaml:"max_bytes"`
+	RotateIntervalSeconds int      `yaml:"rotate_interval_seconds"`
+	MaxFiles              *int     `yaml:"max_files"`
+	Compression           string   `yaml:"compression"`
+	Truncate              bool     `yaml:"truncate"`
+}
+
+// ThresholdsConfig overrides per-signal warning/error cutoffs. Services
+// keys are a service name or "namespace/service"; more specific scopes win.
+type ThresholdsConfig struct {
+	Defaults   map[string]ThresholdConfig            `yaml:"defaults"`
+	Namespaces map[string]map[string]ThresholdConfig `yaml:"namespaces"`
+	Services   map[string]map[string]ThresholdConfig `yaml:"services"`
+}
+
+// ThresholdConfig sets one or both cutoffs of a signal; unset fields inherit.
+type ThresholdConfig struct {
+	Warning *float64 `yaml:"warning"`
+	Error   *float64 `yaml:"error"`
+}
+
+// Table converts the config into a resolver over the signal registry.
+func (c ThresholdsConfig) Table() *signalspec.ThresholdTable {
+	convert := func(in map[string]ThresholdConfig) map[string]signalspec.ThresholdOverride {
+		out := make(map[string]signalspec.ThresholdOverride, len(in))
+		for signal, th := range in {
+			out[signal] = signalspec.ThresholdOverride{Warning: th.Warning, Error: th.Error}
+		}
+		return out
+	}
+	scoped := func(in map[string]map[string]ThresholdConfig) map[string]map[string]signalspec.ThresholdOverride {
+		out := make(map[string]map[string]signalspec.ThresholdOverride, len(in))
+		for scope, overrides := range in {
+			out[scope] = convert(overrides)
+		}
+		return out
+	}
+	return &signalspec.ThresholdTable{
+		Defaults:   convert(c.Defaults),
+		Namespaces: scoped(c.Namespaces),
+		Services:   scoped(c.Services),
+	}
+}
+
+// AttributionConfig selects how attribution decides a signal is elevated.
+type AttributionConfig struct {
+	Elevation       string         `yaml:"elevation"`
+	ZScoreThreshold float64        `yaml:"zscore_threshold"`
+	Baseline        BaselineConfig `yaml:"baseline"`
+}
+
+// BaselineConfig tunes the per-workload rolling baselines used by zscore
+// elevation.
+type BaselineConfig struct {
+	Window          int `yaml:"window"`
+	WarmupSamples   int `yaml:"warmup_samples"`
+	HalfLifeSeconds int `yaml:"half_life_seconds"`
+}
+
+// Config converts the YAML settings into tracker settings.
+func (c BaselineConfig) Config() baseline.Config {
+	return baseline.Config{
+		Window:        c.Window,
+		WarmupSamples: c.WarmupSamples,
+		HalfLife:      time.Duration(c.HalfLifeSeconds) * time.Second,
+	}
+}
+
+// ProviderHTTPConfig enables HTTP status capture at the TLS plaintext
+// boundary and in Go net/http. Only responses on connections to Hosts are
+// counted; a leading "*." matches any subdomain.
+type ProviderHTTPConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	Hosts   []string `yaml:"hosts"`
+}
+
+// DefaultProviderHosts are the hosted LLM APIs whose responses are counted
+// when provider_http.hosts is empty.
+var DefaultProviderHosts = []string{
+	"api.openai.com",
+	"*.openai.azure.com",
+	"api.anthropic.com",
+	"generativelanguage.googleapis.com",
+	"api.mistral.ai",
+	"api.cohere.com",
+	"api.groq.com",
+}
+
+// RequestTimingConfig enables kernel-side request timing on the listening
+// ports of LLM servers that carry no instrumentation. Reads and writes on
+// accepted connections to ServerPorts are timed into TTFT and latency
+// samples; a response quiet for IdleTimeoutMS is complete.
+type RequestTimingConfig struct {
+	Enabled       bool  `yaml:"enabled"`
+	ServerPorts   []int `yaml:"server_ports"`
+	IdleTimeoutMS int   `yaml:"idle_timeout_ms"`
+}
+
+// DeepTracingConfig attaches expensive probes, scoped to a workload's
+// cgroups, while its SLO burns. When enabled, Signals leave the always-on
+// signal set.
+type DeepTracingConfig struct {
+	Enabled           bool     `yaml:"enabled"`
+	Signals           []string `yaml:"signals"`
+	Objective         float64  `yaml:"objective"`
+	BurnRateThreshold float64  `yaml:"burn_rate_threshold"`
+	WindowSeconds     int      `yaml:"window_seconds"`
+	MinEvents         int      `yaml:"min_events"`
+	Streak            int      `yaml:"streak"`
+	DurationSeconds   int      `yaml:"duration_seconds"`
+	CooldownSeconds   int      `yaml:"cooldown_seconds"`
+	MaxActive         int      `yaml:"max_active"`
+}
+
+// Config converts the YAML settings into controller settings.
+func (c DeepTracingConfig) Config() deeptrace.Config {
+	return deeptrace.Config{
+		Signals:           c.Signals,
+		Objective:         c.Objective,
+		BurnRateThreshold: c.BurnRateThreshold,
+		Window:            time.Duration(c.WindowSeconds) * time.Second,
+		MinEvents:         c.MinEvents,
+		Streak:            c.Streak,
+		Duration:          time.Duration(c.DurationSeconds) * time.Second,
+		Cooldown:          time.Duration(c.CooldownSeconds) * time.Second,
+		MaxActive:         c.MaxActive,
+	}
+}
+
+// DefaultServerPorts are the default listening ports of vLLM, llama.cpp
+// server and Ollama.
+var DefaultServerPorts = []int{8000, 8080, 11434}
+
+// Idle returns the idle timeout as a duration.
+func (c RequestTimingConfig) Idle() time.Duration {
+	return time.Duration(c.IdleTimeoutMS) * time.Millisecond
+}
+
+// DependencyConfig is one dependency catalog entry. Connections whose
+// destination is in CIDRs and on one of Ports, and DNS queries for Hosts,
+// are labeled Name; Domain ("provider" or a fault domain) says which
+// hypothesis their evidence supports.
+type DependencyConfig struct {
+	Name   string   `yaml:"name"`
+	Domain string   `yaml:"domain"`
+	CIDRs  []string `yaml:"cidrs"`
+	Ports  []int    `yaml:"ports"`
+	Hosts  []string `yaml:"hosts"`
+}
+
+// DependencyCatalog builds the dependency catalog.
+func (c ToolkitConfig) DependencyCatalog() (*dependency.Catalog, error) {
+	deps := make([]dependency.Dependency, 0, len(c.Dependencies))
+	for _, d := range c.Dependencies {
+		deps = append(deps, dependency.Dependency{
+			Name:   d.Name,
+			Domain: d.Domain,
+			CIDRs:  d.CIDRs,
+			Ports:  d.Ports,
+			Hosts:  d.Hosts,
+		})
+	}
+	return dependency.NewCatalog(deps)
+}
+
+// JSONL rotation defaults applied when a jsonl output leaves them unset.
+const (
+	DefaultOutputMaxBytes int64 = 64 << 20
+	DefaultOutputMaxFiles       = 5
+)
+
 // Default returns v1alpha1 defaults.
 func Default() ToolkitConfig {
 	return ToolkitConfig{
@@ -82,6 +363,7 @@ func Default() ToolkitConfig {
 		},
 		Correlation: CorrelationConfig{
 			WindowMS: 2000,
+			Tiers:    defaultCorrelationTiers(),
 		},
 		OTLP: OTLPConfig{
 			Endpoint: "http://otel-collector:4317",
@@ -104,6 +386,44 @@ func Default() ToolkitConfig {
 			BurnRate:      2.0,
 			FailOpen:      true,
 		},
+		Spool: SpoolConfig{
+			Enabled:          false,
+			Dir:              "/var/lib/llm-slo-agent/spool",
+			MaxBytes:         256 << 20,
+			SegmentBytes:     8 << 20,
+			MaxAgeSeconds:    3600,
+			ReplayIntervalMS: 2000,
+		},
+		Attribution: AttributionConfig{
+			Elevation:       baseline.ElevationStatic,
+			ZScoreThreshold: baseline.DefaultZThreshold,
+			Baseline: BaselineConfig{
+				Window:          baseline.DefaultConfig().Window,
+				WarmupSamples:   baseline.DefaultConfig().WarmupSamples,
+				HalfLifeSeconds: int(baseline.DefaultConfig().HalfLife / time.Second),
+			},
+		},
+		ProviderHTTP: ProviderHTTPConfig{
+			Enabled: false,
+			Hosts:   append([]string(nil), DefaultProviderHosts...),
+		},
+		RequestTiming: RequestTimingConfig{
+			Enabled:       false,
+			ServerPorts:   append([]int(nil), DefaultServerPorts...),
+			IdleTimeoutMS: 5000,
+		},
+		DeepTracing: DeepTracingConfig{
+			Enabled:           false,
+			Signals:           deeptrace.DefaultConfig().Signals,
+			Objective:         deeptrace.DefaultConfig().Objective,
+			BurnRateThreshold: deeptrace.DefaultConfig().BurnRateThreshold,
+			WindowSeconds:     int(deeptrace.DefaultConfig().Window / time.Second),
+			MinEvents:         deeptrace.DefaultConfig().MinEvents,
+			Streak:            deeptrace.DefaultConfig().Streak,
+			DurationSeconds:   int(deeptrace.DefaultConfig().Duration / time.Second),
+			CooldownSeconds:   int(deeptrace.DefaultConfig().Cooldown / time.Second),
+			MaxActive:         deeptrace.DefaultConfig().MaxActive,
+		},
 	}
 }
 
@@ -119,9 +439,113 @@ func Load(path string) (ToolkitConfig, error) {
 		return cfg, fmt.Errorf("unmarshal config %s: %w", path, err)
 	}
 	normalize(&cfg)
+	if err := cfg.Thresholds.Table().Validate(); err != nil {
+		return cfg, fmt.Errorf("config %s: %w", path, err)
+	}
+	elevation, err := baseline.ParseElevation(cfg.Attribution.Elevation)
+	if err != nil {
+		return cfg, fmt.Errorf("config %s: attribution: %w", path, err)
+	}
+	cfg.Attribution.Elevation = elevation
+	names := make(map[string]bool, len(cfg.Outputs))
+	for _, out := range cfg.Outputs {
+		if err := out.validate(); err != nil {
+			return cfg, fmt.Errorf("config %s: output %s: %w", path, out.Name, err)
+		}
+		// Sink names label metrics and name spool directories.
+		if names[out.Name] {
+			return cfg, fmt.Errorf("config %s: duplicate output name %q", path, out.Name)
+		}
+		names[out.Name] = true
+	}
+	if err := cfg.Correlation.validate(); err != nil {
+		return cfg, fmt.Errorf("config %s: correlation: %w", path, err)
+	}
+	if err := cfg.ProviderHTTP.validate(); err != nil {
+		return cfg, fmt.Errorf("config %s: provider_http: %w", path, err)
+	}
+	if err := cfg.RequestTiming.validate(); err != nil {
+		return cfg, fmt.Errorf("config %s: request_timing: %w", path, err)
+	}
+	if err := cfg.DeepTracing.validate(); err != nil {
+		return cfg, fmt.Errorf("config %s: deep_tracing: %w", path, err)
+	}
+	if _, err := cfg.DependencyCatalog(); err != nil {
+		return cfg, fmt.Errorf("config %s: dependencies: %w", path, err)
+	}
 	return cfg, nil
 }
 
+func (c OutputConfig) validate() error {
+	if c.Name == WebhookOutputName {
+		return fmt.Errorf("name %q is reserved for the incident webhook", c.Name)
+	}
+	supported, ok := outputKinds[c.Type]
+	if !ok {
+		return fmt.Errorf("unsupported type %q (expected stdout|jsonl|otlp)", c.Type)
+	}
+	for _, kind := range c.Kinds {
+		if !slices.Contains(supported, strings.ToLower(strings.TrimSpace(kind))) {
+			return fmt.Errorf("%s outputs do not support %s events", c.Type, kind)
+		}
+	}
+	if c.MaxBytes != nil && *c.MaxBytes < 0 {
+		return fmt.Errorf("negative max_bytes %d", *c.MaxBytes)
+	}
+	if c.MaxFiles != nil && *c.MaxFiles < 0 {
+		return fmt.Errorf("negative max_files %d", *c.MaxFiles)
+	}
+	return nil
+}
+
+func (c CorrelationConfig) validate() error {
+	all := make(correlation.Tiers, 0, len(c.Tiers))
+	for _, t := range c.Tiers {
+		if t.WindowMS < 0 {
+			return fmt.Errorf("tier %s: negative window_ms %d", t.Name, t.WindowMS)
+		}
+		all = append(all, correlation.Tier{Name: t.Name, Key: correlation.JoinKey(t.Key), Confidence: t.Confidence})
+	}
+	if err := all.Validate(); err != nil {
+		return err
+	}
+	if len(c.TierTable()) == 0 {
+		return fmt.Errorf("no enabled tiers")
+	}
+	return nil
+}
+
+func (c ProviderHTTPConfig) validate() error {
+	for _, host := range c.Hosts {
+		name := strings.TrimPrefix(host, "*.")
+		if name == "" || strings.ContainsAny(name, "*/: ") {
+			return fmt.Errorf("invalid host %q", host)
+		}
+	}
+	return nil
+}
+
+func (c RequestTimingConfig) validate() error {
+	for _, port := range c.ServerPorts {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("invalid server port %d", port)
+		}
+	}
+	return nil
+}
+
+func (c DeepTracingConfig) validate() error {
+	for _, signal := range c.Signals {
+		if _, ok := signalspec.Lookup(signal); !ok {
+			return fmt.Errorf("unknown signal %q", signal)
+		}
+	}
+	if c.Objective >= 1 {
+		return fmt.Errorf("objective %v must be below 1", c.Objective)
+	}
+	return nil
+}
+
 func normalize(cfg *ToolkitConfig) {
 	defaults := Default()
 
@@ -137,6 +561,9 @@ func normalize(cfg *ToolkitConfig) {
 	if cfg.Correlation.WindowMS <= 0 {
 		cfg.Correlation.WindowMS = defaults.Correlation.WindowMS
 	}
+	if len(cfg.Correlation.Tiers) == 0 {
+		cfg.Correlation.Tiers = defaults.Correlation.Tiers
+	}
 	if cfg.OTLP.Endpoint == "" {
 		cfg.OTLP.Endpoint = defaults.OTLP.Endpoint
 	}
@@ -161,6 +588,86 @@ func normalize(cfg *ToolkitConfig) {
 	if cfg.CDGate.BurnRate <= 0 {
 		cfg.CDGate.BurnRate = defaults.CDGate.BurnRate
 	}
+	if cfg.Spool.Dir == "" {
+		cfg.Spool.Dir = defaults.Spool.Dir
+	}
+	if cfg.Spool.MaxBytes <= 0 {
+		cfg.Spool.MaxBytes = defaults.Spool.MaxBytes
+	}
+	if cfg.Spool.SegmentBytes <= 0 {
+		cfg.Spool.SegmentBytes = defaults.Spool.SegmentBytes
+	}
+	if cfg.Spool.MaxAgeSeconds <= 0 {
+		cfg.Spool.MaxAgeSeconds = defaults.Spool.MaxAgeSeconds
+	}
+	if cfg.Spool.ReplayIntervalMS <= 0 {
+		cfg.Spool.ReplayIntervalMS = defaults.Spool.ReplayIntervalMS
+	}
+	if cfg.Attribution.ZScoreThreshold <= 0 {
+		cfg.Attribution.ZScoreThreshold = defaults.Attribution.ZScoreThreshold
+	}
+	if cfg.Attribution.Baseline.Window <= 0 {
+		cfg.Attribution.Baseline.Window = defaults.Attribution.Baseline.Window
+	}
+	if cfg.Attribution.Baseline.WarmupSamples <= 0 {
+		cfg.Attribution.Baseline.WarmupSamples = defaults.Attribution.Baseline.WarmupSamples
+	}
+	if cfg.Attribution.Baseline.HalfLifeSeconds <= 0 {
+		cfg.Attribution.Baseline.HalfLifeSeconds = defaults.Attribution.Baseline.HalfLifeSeconds
+	}
+	if len(cfg.ProviderHTTP.Hosts) == 0 {
+		cfg.ProviderHTTP.Hosts = defaults.ProviderHTTP.Hosts
+	}
+	if len(cfg.RequestTiming.ServerPorts) == 0 {
+		cfg.RequestTiming.ServerPorts = defaults.RequestTiming.ServerPorts
+	}
+	if cfg.RequestTiming.IdleTimeoutMS <= 0 {
+		cfg.RequestTiming.IdleTimeoutMS = defaults.RequestTiming.IdleTimeoutMS
+	}
+	if len(cfg.DeepTracing.Signals) == 0 {
+		cfg.DeepTracing.Signals = defaults.DeepTracing.Signals
+	}
+	if cfg.DeepTracing.Objective <= 0 {
+		cfg.DeepTracing.Objective = defaults.DeepTracing.Objective
+	}
+	if cfg.DeepTracing.BurnRateThreshold <= 0 {
+		cfg.DeepTracing.BurnRateThreshold = defaults.DeepTracing.BurnRateThreshold
+	}
+	if cfg.DeepTracing.WindowSeconds <= 0 {
+		cfg.DeepTracing.WindowSeconds = defaults.DeepTracing.WindowSeconds
+	}
+	if cfg.DeepTracing.MinEvents <= 0 {
+		cfg.DeepTracing.MinEvents = defaults.DeepTracing.MinEvents
+	}
+	if cfg.DeepTracing.Streak <= 0 {
+		cfg.DeepTracing.Streak = defaults.DeepTracing.Streak
+	}
+	if cfg.DeepTracing.DurationSeconds <= 0 {
+		cfg.DeepTracing.DurationSeconds = defaults.DeepTracing.DurationSeconds
+	}
+	if cfg.DeepTracing.CooldownSeconds <= 0 {
+		cfg.DeepTracing.CooldownSeconds = defaults.DeepTracing.CooldownSeconds
+	}
+	if cfg.DeepTracing.MaxActive <= 0 {
+		cfg.DeepTracing.MaxActive = defaults.DeepTracing.MaxActive
+	}
+	for i := range cfg.Outputs {
+		out := &cfg.Outputs[i]
+		if out.Name == "" {
+			out.Name = fmt.Sprintf("%s-%d", out.Type, i)
+		}
+		if out.TimeoutMS <= 0 {
+			out.TimeoutMS = 5000
+		}
+		if out.Type == "jsonl" && out.MaxBytes == nil {
+			maxBytes := DefaultOutputMaxBytes
+			out.MaxBytes = &maxBytes
+		}
+		if out.Type == "jsonl" && out.MaxFiles == nil {
+			maxFiles := DefaultOutputMaxFiles
+			out.MaxFiles = &maxFiles
+		}
+	}
 	if cfg.APIVersion == "" {
 		cfg.APIVersion = defaults.APIVersion
 	}
@@ -3,7 +3,15 @@ package toolkitcfg
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/baseline"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/correlation"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/signalspec"
 )
 
 func TestLoad(t *testing.T) {
@@ -106,3 +114,398 @@ safety:
 		t.Fatalf("default signal set expected 9, got %d", len(Default().SignalSet))
 	}
 }
+
+func TestLoadSpoolConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "toolkit.yaml")
+	content := `
+signal_set:
+  - dns_latency_ms
+spool:
+  enabled: true
+  dir: /tmp/agent-spool
+  max_bytes: 1048576
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !cfg.Spool.Enabled || cfg.Spool.Dir != "/tmp/agent-spool" || cfg.Spool.MaxBytes != 1048576 {
+		t.Fatalf("unexpected spool config: %+v", cfg.Spool)
+	}
+	if cfg.Spool.SegmentBytes != 8<<20 || cfg.Spool.MaxAgeSeconds != 3600 || cfg.Spool.ReplayIntervalMS != 2000 {
+		t.Fatalf("unexpected spool defaults: %+v", cfg.Spool)
+	}
+}
+
+func TestLoadOutputsConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "toolkit.yaml")
+	content := `
+outputs:
+  - name: central
+    type: otlp
+    endpoint: http://collector:4318/v1/logs
+    kinds: [slo]
+  - type: jsonl
+    path: /tmp/audit.jsonl
+    kinds: [probe]
+    batch_size: 10
+  - name: archive
+    type: jsonl
+    path: /tmp/archive.jsonl
+    max_bytes: 0
+    max_files: 0
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(cfg.Outputs) != 3 {
+		t.Fatalf("expected 3 outputs, got %d", len(cfg.Outputs))
+	}
+	if cfg.Outputs[0].Name != "central" || cfg.Outputs[0].Kinds[0] != "slo" || cfg.Outputs[0].TimeoutMS != 5000 {
+		t.Fatalf("unexpected first output: %+v", cfg.Outputs[0])
+	}
+	if cfg.Outputs[1].Name != "jsonl-1" || cfg.Outputs[1].BatchSize != 10 {
+		t.Fatalf("unexpected second output: %+v", cfg.Outputs[1])
+	}
+	if out := cfg.Outputs[1]; out.MaxBytes == nil || *out.MaxBytes != DefaultOutputMaxBytes || out.MaxFiles == nil || *out.MaxFiles != DefaultOutputMaxFiles {
+		t.Fatalf("expected jsonl rotation defaults, got %+v", out)
+	}
+	if out := cfg.Outputs[2]; out.MaxBytes == nil || *out.MaxBytes != 0 || out.MaxFiles == nil || *out.MaxFiles != 0 {
+		t.Fatalf("explicit 0 should keep rotation unlimited, got %+v", out)
+	}
+	if cfg.Outputs[0].MaxBytes != nil {
+		t.Fatalf("rotation defaults should only apply to jsonl outputs: %+v", cfg.Outputs[0])
+	}
+
+	negative := `
+outputs:
+  - type: jsonl
+    path: /tmp/audit.jsonl
+    max_files: -1
+`
+	if err := os.WriteFile(path, []byte(negative), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "negative max_files") {
+		t.Fatalf("expected negative max_files to fail, got %v", err)
+	}
+
+	incidents := `
+outputs:
+  - type: otlp
+    endpoint: http://collector:4318/v1/logs
+    kinds: [incident]
+`
+	if err := os.WriteFile(path, []byte(incidents), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "otlp outputs do not support incident events") {
+		t.Fatalf("expected otlp incidents to fail, got %v", err)
+	}
+
+	for name, content := range map[string]string{
+		"duplicate output name": `
+outputs:
+  - name: audit
+    type: jsonl
+    path: /tmp/a.jsonl
+  - name: audit
+    type: otlp
+    endpoint: http://collector:4318/v1/logs
+`,
+		// The default name of the second output is jsonl-1.
+		`duplicate output name "jsonl-1"`: `
+outputs:
+  - name: jsonl-1
+    type: jsonl
+    path: /tmp/a.jsonl
+  - type: jsonl
+    path: /tmp/b.jsonl
+`,
+		"reserved for the incident webhook": `
+outputs:
+  - name: webhook
+    type: stdout
+`,
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		if _, err := Load(path); err == nil || !strings.Contains(err.Error(), name) {
+			t.Fatalf("expected %q, got %v", name, err)
+		}
+	}
+}
+
+func TestLoadThresholdsConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "toolkit.yaml")
+	content := `
+thresholds:
+  defaults:
+    dns_latency_ms: {warning: 60, error: 180}
+  namespaces:
+    apac:
+      dns_latency_ms: {warning: 90, error: 300}
+  services:
+    apac/rag:
+      dns_latency_ms: {error: 400}
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	eff, ok := cfg.Thresholds.Table().Resolve("dns_latency_ms", signalspec.Workload{Namespace: "apac", Service: "rag"})
+	if !ok || eff.Warning != 90 || eff.Error != 400 {
+		t.Fatalf("unexpected effective thresholds: %+v", eff)
+	}
+
+	bad := "thresholds:\n  defaults:\n    dns_latency_ms: {warning: 500}\n"
+	if err := os.WriteFile(path, []byte(bad), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "must be below error") {
+		t.Fatalf("expected threshold validation error, got %v", err)
+	}
+}
+
+func TestLoadAttributionConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "toolkit.yaml")
+	content := `
+attribution:
+  elevation: zscore
+  zscore_threshold: 4
+  baseline:
+    window: 60
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Attribution.Elevation != baseline.ElevationZScore || cfg.Attribution.ZScoreThreshold != 4 {
+		t.Fatalf("unexpected attribution config: %+v", cfg.Attribution)
+	}
+	bc := cfg.Attribution.Baseline.Config()
+	if bc.Window != 60 || bc.WarmupSamples != 20 || bc.HalfLife != time.Hour {
+		t.Fatalf("expected defaults for unset baseline fields, got %+v", bc)
+	}
+
+	if err := os.WriteFile(path, []byte("attribution:\n  elevation: ewma\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "unsupported elevation mode") {
+		t.Fatalf("expected elevation validation error, got %v", err)
+	}
+}
+
+func TestLoadProviderHTTPConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "toolkit.yaml")
+	if err := os.WriteFile(path, []byte("provider_http:\n  enabled: true\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !cfg.ProviderHTTP.Enabled || len(cfg.ProviderHTTP.Hosts) != len(DefaultProviderHosts) {
+		t.Fatalf("expected default provider hosts, got %+v", cfg.ProviderHTTP)
+	}
+
+	content := "provider_http:\n  enabled: true\n  hosts: [llm-proxy.internal, \"*.openai.azure.com\"]\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(cfg.ProviderHTTP.Hosts) != 2 || cfg.ProviderHTTP.Hosts[0] != "llm-proxy.internal" {
+		t.Fatalf("unexpected provider hosts %v", cfg.ProviderHTTP.Hosts)
+	}
+
+	if err := os.WriteFile(path, []byte("provider_http:\n  hosts: [\"https://api.openai.com\"]\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "invalid host") {
+		t.Fatalf("expected host validation error, got %v", err)
+	}
+}
+
+func TestLoadRequestTimingConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "toolkit.yaml")
+	if err := os.WriteFile(path, []byte("request_timing:\n  enabled: true\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !cfg.RequestTiming.Enabled || len(cfg.RequestTiming.ServerPorts) != len(DefaultServerPorts) || cfg.RequestTiming.Idle() != 5*time.Second {
+		t.Fatalf("expected request timing defaults, got %+v", cfg.RequestTiming)
+	}
+
+	content := "request_timing:\n  enabled: true\n  server_ports: [8000]\n  idle_timeout_ms: 15000\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(cfg.RequestTiming.ServerPorts) != 1 || cfg.RequestTiming.Idle() != 15*time.Second {
+		t.Fatalf("unexpected request timing config %+v", cfg.RequestTiming)
+	}
+
+	if err := os.WriteFile(path, []byte("request_timing:\n  server_ports: [70000]\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "invalid server port") {
+		t.Fatalf("expected port validation error, got %v", err)
+	}
+}
+
+func TestLoadDeepTracingConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "toolkit.yaml")
+	content := "deep_tracing:\n  enabled: true\n  signals: [tls_handshake_ms]\n  duration_seconds: 60\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	got := cfg.DeepTracing.Config()
+	if !cfg.DeepTracing.Enabled || len(got.Signals) != 1 || got.Duration != time.Minute {
+		t.Fatalf("unexpected deep tracing config %+v", got)
+	}
+	if got.Window != 5*time.Minute || got.Objective != 0.99 || got.MaxActive != 4 {
+		t.Fatalf("expected controller defaults for unset fields, got %+v", got)
+	}
+
+	if err := os.WriteFile(path, []byte("deep_tracing:\n  signals: [tls_handshake_seconds]\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "unknown signal") {
+		t.Fatalf("expected signal validation error, got %v", err)
+	}
+}
+
+func TestLoadCorrelationTiers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "toolkit.yaml")
+	if err := os.WriteFile(path, []byte("correlation:\n  window_ms: 1500\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := cfg.Correlation.TierTable(); !reflect.DeepEqual(got, correlation.DefaultTiers()) {
+		t.Fatalf("expected the default ladder, got %+v", got)
+	}
+
+	content := `correlation:
+  window_ms: 1500
+  tiers:
+    - name: trace_id_exact
+      key: trace_id
+      confidence: 1.0
+    - name: pod_pid_50ms
+      key: pod_pid
+      window_ms: 50
+      confidence: 0.9
+    - name: service_node_500ms
+      key: service_node
+      window_ms: 500
+      confidence: 0.65
+      enabled: false
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	c := cfg.Correlation.Correlator()
+	if c.WindowMS != 1500 || len(c.Tiers) != 2 || c.Tiers[1].Window != 50*time.Millisecond || c.Tiers[1].Key != correlation.JoinPodPID {
+		t.Fatalf("unexpected correlator %+v", c)
+	}
+	if c.EnrichmentThreshold != 0.7 || c.MaxJoinFanout != 3 {
+		t.Fatalf("expected default threshold and fanout, got %+v", c)
+	}
+
+	for content, want := range map[string]string{
+		"correlation:\n  tiers:\n    - {name: a, key: pod_ip, confidence: 0.9}\n":                 "unknown join key",
+		"correlation:\n  tiers:\n    - {name: a, key: trace_id, confidence: 1, enabled: false}\n": "no enabled tiers",
+		"correlation:\n  tiers:\n    - {name: a, key: pod_pid, window_ms: -5, confidence: 0.9}\n": "negative window_ms",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		if _, err := Load(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q, got %v", want, err)
+		}
+	}
+}
+
+func TestLoadDependencies(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "toolkit.yaml")
+	content := `dependencies:
+  - name: qdrant
+    domain: retrieval_backend
+    cidrs: [10.244.3.0/24]
+    ports: [6333]
+  - name: openai
+    domain: provider
+    hosts: [api.openai.com]
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	catalog, err := cfg.DependencyCatalog()
+	if err != nil {
+		t.Fatalf("catalog: %v", err)
+	}
+	if dep, ok := catalog.Match(&schema.ConnTuple{DstIP: "10.244.3.8", DstPort: 6333}); !ok || dep.Name != "qdrant" {
+		t.Fatalf("expected qdrant, got %+v", dep)
+	}
+	if dep, ok := catalog.MatchHost("api.openai.com"); !ok || dep.Domain != "provider" {
+		t.Fatalf("expected openai, got %+v", dep)
+	}
+
+	if err := os.WriteFile(path, []byte("dependencies:\n  - name: x\n    domain: cpu_throttle\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "dependencies") {
+		t.Fatalf("expected dependency validation error, got %v", err)
+	}
+}
@@ -9,9 +9,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/semconv"
 )
 
 // Format selects the webhook payload format.
@@ -59,6 +61,10 @@ type nonRetryableError struct{ err error }
 func (e *nonRetryableError) Error() string { return e.err.Error() }
 func (e *nonRetryableError) Unwrap() error { return e.err }
 
+// Retryable lets callers that hold the error tell it apart from transient
+// delivery failures.
+func (e *nonRetryableError) Retryable() bool { return false }
+
 // Send delivers one incident attribution to the webhook endpoint.
 func (e *Exporter) Send(attr schema.IncidentAttribution) error {
 	payload, contentType, err := e.buildPayload(attr)
@@ -127,6 +133,41 @@ func (e *Exporter) doPost(payload []byte, contentType string) error {
 	return nil
 }
 
+// dstHosts joins the destination hosts named in attr's evidence, so
+// responders see which upstream the fault points at without parsing the
+// evidence list.
+func dstHosts(attr schema.IncidentAttribution) string {
+	var hosts []string
+	for _, e := range attr.Evidence {
+		if e.Signal == semconv.AttrDstHost {
+			hosts = append(hosts, fmt.Sprint(e.Value))
+		}
+	}
+	return strings.Join(hosts, ", ")
+}
+
+// findings joins the named findings in attr's evidence, such as a
+// connection reuse regression, which responders should read before the
+// fault domain.
+func findings(attr schema.IncidentAttribution) string {
+	var out []string
+	for _, e := range attr.Evidence {
+		if e.Signal == semconv.AttrConnReuseRegression {
+			out = append(out, fmt.Sprint(e.Value))
+		}
+	}
+	return strings.Join(out, ", ")
+}
+
+// headline is the one-line incident title shared by the alerting formats.
+func headline(attr schema.IncidentAttribution) string {
+	title := fmt.Sprintf("[%s] %s fault detected", attr.Service, attr.PredictedFaultDomain)
+	if f := findings(attr); f != "" {
+		title += ", " + f
+	}
+	return title
+}
+
 func computeHMAC(payload []byte, secret string) string {
 	mac := hmac.New(sha256.New, []byte(secret))
 	mac.Write(payload)
@@ -5,11 +5,13 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/schema"
+	"github.com/ogulcanaydogan/llm-slo-ebpf-toolkit/pkg/semconv"
 )
 
 func sampleAttribution() schema.IncidentAttribution {
@@ -191,3 +193,73 @@ func TestNoRetryOn4xx(t *testing.T) {
 		t.Errorf("expected 1 attempt for 4xx, got %d", attempts)
 	}
 }
+
+func TestPayloadsCarryDstHosts(t *testing.T) {
+	attr := sampleAttribution()
+	attr.Evidence = append(attr.Evidence,
+		schema.Evidence{Signal: semconv.AttrDstHost, Value: "api.openai.com", Source: "ebpf"},
+		schema.Evidence{Signal: semconv.AttrDstHost, Value: "qdrant.rag.svc", Source: "ebpf"},
+	)
+
+	pd, _, err := BuildPagerDutyPayload(attr)
+	if err != nil {
+		t.Fatalf("pagerduty: %v", err)
+	}
+	var pdPayload pagerDutyPayload
+	if err := json.Unmarshal(pd, &pdPayload); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got := pdPayload.Payload.CustomDetails["dst_hosts"]; got != "api.openai.com, qdrant.rag.svc" {
+		t.Errorf("unexpected pagerduty dst_hosts %q", got)
+	}
+
+	og, _, err := BuildOpsgeniePayload(attr)
+	if err != nil {
+		t.Fatalf("opsgenie: %v", err)
+	}
+	var ogPayload opsgeniePayload
+	if err := json.Unmarshal(og, &ogPayload); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got := ogPayload.Details["dst_hosts"]; got != "api.openai.com, qdrant.rag.svc" {
+		t.Errorf("unexpected opsgenie dst_hosts %q", got)
+	}
+
+	pd, _, _ = BuildPagerDutyPayload(sampleAttribution())
+	if strings.Contains(string(pd), "dst_hosts") {
+		t.Error("dst_hosts should be omitted without host evidence")
+	}
+}
+
+func TestPayloadsHeadlineFindings(t *testing.T) {
+	attr := sampleAttribution()
+	attr.PredictedFaultDomain = "network_egress"
+	attr.Evidence = append(attr.Evidence, schema.Evidence{Signal: semconv.AttrConnReuseRegression, Value: "connection reuse regression", Source: "ebpf"})
+
+	pd, _, err := BuildPagerDutyPayload(attr)
+	if err != nil {
+		t.Fatalf("pagerduty: %v", err)
+	}
+	var pdPayload pagerDutyPayload
+	if err := json.Unmarshal(pd, &pdPayload); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got := pdPayload.Payload.Summary; got != "[chat] network_egress fault detected, connection reuse regression (confidence=0.92)" {
+		t.Errorf("unexpected pagerduty summary %q", got)
+	}
+	if got := pdPayload.Payload.CustomDetails["findings"]; got != "connection reuse regression" {
+		t.Errorf("unexpected pagerduty findings %q", got)
+	}
+
+	og, _, err := BuildOpsgeniePayload(attr)
+	if err != nil {
+		t.Fatalf("opsgenie: %v", err)
+	}
+	var ogPayload opsgeniePayload
+	if err := json.Unmarshal(og, &ogPayload); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if ogPayload.Message != "[chat] network_egress fault detected, connection reuse regression" {
+		t.Errorf("unexpected opsgenie message %q", ogPayload.Message)
+	}
+}
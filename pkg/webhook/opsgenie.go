@@ -36,7 +36,7 @@ func BuildOpsgeniePayload(attr schema.IncidentAttribution) ([]byte, string, erro
 	}
 
 	payload := opsgeniePayload{
-		Message:     fmt.Sprintf("[%s] %s fault detected", attr.Service, attr.PredictedFaultDomain),
+		Message:     headline(attr),
 		Alias:       attr.IncidentID,
 		Description: fmt.Sprintf("Fault domain: %s\nConfidence: %.4f\nBurn rate: %.2f\nEvidence: %s", attr.PredictedFaultDomain, attr.Confidence, attr.SLOImpact.BurnRate, strings.Join(evidenceStrs, "; ")),
 		Priority:    priority,
@@ -53,6 +53,13 @@ func BuildOpsgeniePayload(attr schema.IncidentAttribution) ([]byte, string, erro
 		Entity: fmt.Sprintf("%s/%s", attr.Cluster, attr.Service),
 	}
 
+	if hosts := dstHosts(attr); hosts != "" {
+		payload.Details["dst_hosts"] = hosts
+	}
+	if f := findings(attr); f != "" {
+		payload.Details["findings"] = f
+	}
+
 	data, err := json.Marshal(payload)
 	return data, "application/json", err
 }
@@ -40,7 +40,7 @@ func BuildPagerDutyPayload(attr schema.IncidentAttribution) ([]byte, string, err
 	payload := pagerDutyPayload{
 		EventAction: "trigger",
 		Payload: pdEventPayload{
-			Summary:   fmt.Sprintf("[%s] %s fault detected (confidence=%.2f)", attr.Service, attr.PredictedFaultDomain, attr.Confidence),
+			Summary:   fmt.Sprintf("%s (confidence=%.2f)", headline(attr), attr.Confidence),
 			Source:    fmt.Sprintf("%s/%s", attr.Cluster, attr.Service),
 			Severity:  severity,
 			Timestamp: attr.Timestamp.Format("2006-01-02T15:04:05.000+0000"),
@@ -56,6 +56,13 @@ func BuildPagerDutyPayload(attr schema.IncidentAttribution) ([]byte, string, err
 		},
 	}
 
+	if hosts := dstHosts(attr); hosts != "" {
+		payload.Payload.CustomDetails["dst_hosts"] = hosts
+	}
+	if f := findings(attr); f != "" {
+		payload.Payload.CustomDetails["findings"] = f
+	}
+
 	data, err := json.Marshal(payload)
 	return data, "application/json", err
 }
@@ -180,7 +180,8 @@ for scenario in "${SCENARIOS[@]}"; do
   go run "$ROOT_DIR/cmd/collector" \
     --input "$scenario_dir/raw_samples.jsonl" \
     --output jsonl \
-    --output-path "$scenario_dir/slo_events.jsonl"
+    --output-path "$scenario_dir/slo_events.jsonl" \
+    --output-append=false
 
   go run "$ROOT_DIR/cmd/faultreplay" \
     --scenario "$scenario" \